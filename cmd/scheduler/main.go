@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	"github.com/segyhp/billing-engine/pkg/logger"
 
 	"github.com/robfig/cron/v3"
 )
@@ -20,11 +27,26 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Structured logging; level and format were already validated by config.Load
+	if err := logger.Setup(cfg.App.LogLevel, cfg.App.LogFormat); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+
+	// Initialize database
+	db, err := initDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	loanRepo := repository.NewLoanRepository(db, repository.WithQueryTimeout(cfg.Database.QueryTimeout))
+	jobs := scheduler.NewScheduler(loanRepo, cfg)
+
 	// Initialize cron scheduler
 	c := cron.New(cron.WithSeconds())
 
 	// Schedule tasks
-	setupCronJobs(c, cfg)
+	setupCronJobs(c, jobs)
 
 	// Start the scheduler
 	c.Start()
@@ -40,17 +62,38 @@ func main() {
 	log.Println("Scheduler stopped")
 }
 
-func setupCronJobs(c *cron.Cron, cfg *config.Config) {
+func initDB(cfg *config.Config) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("postgres", cfg.Database.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+
+	return db, nil
+}
+
+func setupCronJobs(c *cron.Cron, jobs *scheduler.Scheduler) {
 	// Daily job to update overdue payments (runs at midnight)
 	_, err := c.AddFunc("0 0 0 * * *", func() {
 		log.Println("Running daily overdue payment update job...")
-		// TODO: Implement overdue payment update logic
-		updateOverduePayments()
+		updateOverduePayments(jobs)
 	})
 	if err != nil {
 		log.Printf("Error scheduling overdue payment update job: %v", err)
 	}
 
+	// Daily consistency job closing loans that are fully paid but still open (runs at 1 AM)
+	_, err = c.AddFunc("0 0 1 * * *", func() {
+		log.Println("Running daily fully paid loan closing job...")
+		closeFullyPaidLoans(jobs)
+	})
+	if err != nil {
+		log.Printf("Error scheduling fully paid loan closing job: %v", err)
+	}
+
 	// Weekly job to send payment reminders (runs on Sundays at 9 AM)
 	_, err = c.AddFunc("0 0 9 * * SUN", func() {
 		log.Println("Running weekly payment reminder job...")
@@ -64,14 +107,25 @@ func setupCronJobs(c *cron.Cron, cfg *config.Config) {
 	log.Println("Cron jobs scheduled successfully")
 }
 
-// TODO: Implement this function to mark overdue payments
-func updateOverduePayments() {
-	// Business logic to implement:
-	// 1. Get all active loans
-	// 2. For each loan, check which payments are overdue
-	// 3. Update loan_schedule status from 'pending' to 'overdue'
-	// 4. Update loan status to 'delinquent' if applicable
-	log.Println("TODO: Implement updateOverduePayments logic")
+// updateOverduePayments marks overdue weeks and moves loans between active, delinquent and default
+func updateOverduePayments(jobs *scheduler.Scheduler) {
+	run, err := jobs.UpdateOverduePayments(context.Background(), time.Now())
+	if err != nil {
+		log.Printf("Overdue payment update finished with errors: %v", err)
+	}
+	if run != nil {
+		log.Printf("Overdue payment update marked %d weeks overdue; %d loans became delinquent, %d defaulted and %d caught up",
+			run.SchedulesMarked, run.LoansDelinquent, run.LoansDefaulted, run.LoansReactivated)
+	}
+}
+
+// closeFullyPaidLoans closes open loans whose schedule is fully paid
+func closeFullyPaidLoans(jobs *scheduler.Scheduler) {
+	closed, err := jobs.CloseFullyPaidLoans(context.Background(), time.Now())
+	if err != nil {
+		log.Printf("Fully paid loan closing finished with errors: %v", err)
+	}
+	log.Printf("Fully paid loan closing closed %d loans", closed)
 }
 
 // TODO: Implement this function to send payment reminders
@@ -14,10 +14,15 @@ import (
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
+	"github.com/segyhp/billing-engine/internal/cache"
 	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/handler"
+	"github.com/segyhp/billing-engine/internal/metrics"
 	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/internal/scheduler"
 	"github.com/segyhp/billing-engine/internal/service"
+	"github.com/segyhp/billing-engine/pkg/logger"
+	"github.com/segyhp/billing-engine/pkg/response"
 )
 
 func main() {
@@ -27,6 +32,16 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Structured logging; level and format were already validated by config.Load
+	if err := logger.Setup(cfg.App.LogLevel, cfg.App.LogFormat); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+
+	// Response timestamps; the location was already validated by config.Load
+	location, _ := cfg.Server.ResponseLocation()
+	response.ConfigureTimestamps(location, cfg.Server.ResponseTimestampUTC)
+	response.ConfigureErrorDetails(cfg.ErrorDetailsEnabled())
+
 	// Initialize database
 	db, err := initDB(cfg)
 	if err != nil {
@@ -38,17 +53,43 @@ func main() {
 	redisClient := initRedis(cfg)
 	defer redisClient.Close()
 
+	if err := pingRedis(redisClient); err != nil {
+		if cfg.Redis.Required {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		log.Printf("Redis unavailable, continuing without it: %v", err)
+	}
+
 	//Initialize repositories
-	loanRepo := repository.NewLoanRepository(db)
-	paymentRepo := repository.NewPaymentRepository(db)
+	queryTimeout := repository.WithQueryTimeout(cfg.Database.QueryTimeout)
+	loanRepo := repository.NewLoanRepository(db, queryTimeout)
+	paymentRepo := repository.NewPaymentRepository(db, queryTimeout)
+	unitOfWork := repository.NewUnitOfWork(db, queryTimeout)
 
 	//Initialize service
-	billingService := service.NewBillingService(loanRepo, paymentRepo, redisClient, cfg)
-	billingHandler := handler.NewBillingHandler(billingService, cfg)
-	healthHandler := handler.NewHealthHandler(db, redisClient)
+	billingService := service.NewBillingService(loanRepo, paymentRepo, unitOfWork, redisClient, cfg)
+	// Prometheus collectors, registered once; the handlers record them after each service call
+	registry := metrics.NewRegistry()
+	appMetrics := metrics.New(registry)
+
+	billingHandler := handler.NewBillingHandler(billingService, cfg, appMetrics)
+	healthHandler := handler.NewHealthHandler(db, redisClient, cfg.Redis.Required)
+	metricsHandler := handler.NewMetricsHandler(cfg.Metrics, registry)
+	jobs := scheduler.NewScheduler(loanRepo, cfg)
+	adminHandler := handler.NewAdminHandler(cfg.Admin, jobs)
+
+	// Optional background cache warmer, stopped on shutdown
+	warmerCtx, stopWarmer := context.WithCancel(context.Background())
+	defer stopWarmer()
+	if cfg.Redis.CacheWarmerEnabled {
+		loanCache := cache.NewCache(redisClient, cfg.Redis.CacheTTL)
+		warmer := scheduler.NewCacheWarmer(jobs, loanRepo, paymentRepo, loanCache, cfg)
+		go warmer.Run(warmerCtx)
+		log.Printf("Cache warmer running every %s", cfg.Redis.CacheWarmerInterval)
+	}
 
 	// Setup routes
-	router := setupRoutes(billingHandler, healthHandler)
+	router := setupRoutes(billingHandler, healthHandler, metricsHandler, adminHandler, appMetrics)
 
 	// Start server
 	server := &http.Server{
@@ -71,6 +112,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopWarmer()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -104,20 +146,62 @@ func initRedis(cfg *config.Config) *redis.Client {
 	})
 }
 
-func setupRoutes(billingHandler *handler.BillingHandler, healthHandler *handler.HealthHandler) *mux.Router {
+// pingRedis checks Redis is reachable at startup
+func pingRedis(client *redis.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+func setupRoutes(billingHandler *handler.BillingHandler, healthHandler *handler.HealthHandler, metricsHandler *handler.MetricsHandler, adminHandler *handler.AdminHandler, appMetrics *metrics.Metrics) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(response.LoggingMiddleware, appMetrics.Middleware)
 
 	// Health check
 	router.HandleFunc("/health", healthHandler.Health).Methods("GET")
 	router.HandleFunc("/health/ready", healthHandler.Ready).Methods("GET")
 
+	// Metrics, guarded by METRICS_* settings rather than API auth
+	router.HandleFunc("/metrics", metricsHandler.Metrics).Methods("GET")
+
 	/// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
 
 	api.HandleFunc("/loans", billingHandler.CreateLoan).Methods("POST")
+	api.HandleFunc("/loans/batch", billingHandler.CreateLoansBatch).Methods("POST")
+	api.HandleFunc("/loans", billingHandler.ListLoans).Methods("GET")
+	api.HandleFunc("/loans/{loanId}", billingHandler.LoanExists).Methods("HEAD")
+	api.HandleFunc("/loans/{loanId}", billingHandler.GetLoan).Methods("GET")
 	api.HandleFunc("/loans/{loanId}/outstanding", billingHandler.GetOutstanding).Methods("GET")
+	api.HandleFunc("/loans/outstanding/batch", billingHandler.GetOutstandingBatch).Methods("POST")
+	api.HandleFunc("/loans/delinquent/batch", billingHandler.GetDelinquencyBatch).Methods("POST")
 	api.HandleFunc("/loans/{loanId}/delinquent", billingHandler.IsDelinquent).Methods("GET")
 	api.HandleFunc("/loans/{loanId}/payment", billingHandler.MakePayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/payments", billingHandler.GetLoanPayments).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/payments/undo", billingHandler.UndoLatestPayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/payments/{paymentId}/reverse", billingHandler.ReversePayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/schedule", billingHandler.GetSchedule).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/schedule/integrity", billingHandler.CheckScheduleIntegrity).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/remaining", billingHandler.GetRemaining).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/current-week", billingHandler.GetCurrentWeek).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/interest-paid", billingHandler.GetInterestPaid).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/cost", billingHandler.GetCostOfCredit).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/payment-streak", billingHandler.GetPaymentStreak).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/delinquency-history", billingHandler.GetDelinquencyHistory).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/forbearance", billingHandler.SetForbearance).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/notes", billingHandler.UpdateNotes).Methods("PATCH")
+	api.HandleFunc("/loans/{loanId}/recompute-payment", billingHandler.RecomputeWeeklyPayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/export", billingHandler.ExportLoan).Methods("GET")
+	api.HandleFunc("/borrowers/{borrowerId}/loans", billingHandler.GetBorrowerLoans).Methods("GET")
+	api.HandleFunc("/payments", billingHandler.ListPayments).Methods("GET")
+	api.HandleFunc("/payments/{paymentId}", billingHandler.GetPayment).Methods("GET")
+	api.HandleFunc("/reports/collections", billingHandler.GetCollectionsReport).Methods("GET")
+	api.HandleFunc("/reports/expected-collections", billingHandler.GetExpectedCollectionsReport).Methods("GET")
+
+	// Admin routes, guarded by ADMIN_USERNAME/ADMIN_PASSWORD basic auth
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(adminHandler.RequireAuth)
+	admin.HandleFunc("/loans/close-fully-paid", adminHandler.CloseFullyPaidLoans).Methods("POST")
 
 	return router
 }
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Cache keeps computed loan views in Redis so hot reads can skip the database.
+// Entries expire after the configured TTL; nothing here is a source of truth.
+type Cache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewCache(client *redis.Client, ttl time.Duration) *Cache {
+	return &Cache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// OutstandingKey is where a loan's outstanding balance is cached
+func OutstandingKey(loanID string) string {
+	return "loan:outstanding:" + loanID
+}
+
+// OutstandingBreakdownKey is where a loan's principal and interest outstanding are cached
+func OutstandingBreakdownKey(loanID string) string {
+	return "loan:outstanding_breakdown:" + loanID
+}
+
+// GetOutstanding returns a loan's cached outstanding balance, reporting false on a miss
+func (c *Cache) GetOutstanding(ctx context.Context, loanID string) (decimal.Decimal, bool, error) {
+	value, err := c.client.Get(ctx, OutstandingKey(loanID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return decimal.Zero, false, nil
+	}
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+
+	outstanding, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	return outstanding, true, nil
+}
+
+// SetOutstanding caches a loan's outstanding balance as a decimal string
+func (c *Cache) SetOutstanding(ctx context.Context, loanID string, outstanding decimal.Decimal) error {
+	return c.client.Set(ctx, OutstandingKey(loanID), outstanding.String(), c.ttl).Err()
+}
+
+// GetOutstandingBreakdown returns a loan's cached outstanding breakdown, reporting false on a miss
+func (c *Cache) GetOutstandingBreakdown(ctx context.Context, loanID string) (*domain.OutstandingBreakdown, bool, error) {
+	data, err := c.client.Get(ctx, OutstandingBreakdownKey(loanID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var breakdown domain.OutstandingBreakdown
+	if err := json.Unmarshal(data, &breakdown); err != nil {
+		return nil, false, err
+	}
+	return &breakdown, true, nil
+}
+
+// SetOutstandingBreakdown caches a loan's outstanding breakdown as its JSON encoding
+func (c *Cache) SetOutstandingBreakdown(ctx context.Context, loanID string, breakdown *domain.OutstandingBreakdown) error {
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, OutstandingBreakdownKey(loanID), data, c.ttl).Err()
+}
+
+// InvalidateLoan drops every cached view of a loan so the next read recomputes it
+func (c *Cache) InvalidateLoan(ctx context.Context, loanID string) error {
+	return c.client.Del(ctx, OutstandingKey(loanID), OutstandingBreakdownKey(loanID)).Err()
+}
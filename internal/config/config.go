@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/segyhp/billing-engine/pkg/logger"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/utils"
 	"github.com/spf13/viper"
 )
 
@@ -13,6 +18,8 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Redis    RedisConfig    `mapstructure:"redis"`
 	App      AppConfig      `mapstructure:"app"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Admin    AdminConfig    `mapstructure:"admin"`
 }
 
 type ServerConfig struct {
@@ -20,9 +27,22 @@ type ServerConfig struct {
 	Port         string        `mapstructure:"port"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	// Response timestamps: ResponseTimezone is an IANA zone (empty means the server's local zone);
+	// ResponseTimestampUTC forces RFC3339 UTC at second precision instead
+	ResponseTimezone     string `mapstructure:"response_timezone"`
+	ResponseTimestampUTC bool   `mapstructure:"response_timestamp_utc"`
+
+	// ErrorDetails includes the underlying error text in error responses; unset means
+	// on everywhere except production (see Config.ErrorDetailsEnabled)
+	ErrorDetails *bool `mapstructure:"error_details"`
 }
 
 type DatabaseConfig struct {
+	// URL is a full connection string (postgres://... or key=value); when set it wins over the
+	// individual connection fields below
+	URL string `mapstructure:"url"`
+
 	Host            string        `mapstructure:"host"`
 	Port            string        `mapstructure:"port"`
 	User            string        `mapstructure:"user"`
@@ -31,6 +51,8 @@ type DatabaseConfig struct {
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	// QueryTimeout bounds each repository statement; 0 leaves them bounded only by the request
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
 }
 
 type RedisConfig struct {
@@ -38,17 +60,158 @@ type RedisConfig struct {
 	Port     string `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+
+	// Required makes startup and readiness fail while Redis is unreachable;
+	// when false Redis is treated as a cache and the service runs degraded without it
+	Required bool `mapstructure:"required"`
+
+	// CacheTTL is how long cached loan views live; 0 keeps them until they're overwritten
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+
+	// The optional cache warmer reloads active loans into the cache every CacheWarmerInterval
+	CacheWarmerEnabled  bool          `mapstructure:"cache_warmer_enabled"`
+	CacheWarmerInterval time.Duration `mapstructure:"cache_warmer_interval"`
+}
+
+// MetricsConfig guards the /metrics endpoint independently of the API
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Optional basic auth, enforced when Username is set
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// Optional allowlist of client IPs or CIDR ranges; empty allows any address
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+}
+
+// AdminConfig holds the basic auth credentials for the /api/v1/admin endpoints, which are
+// disabled while Username is empty
+type AdminConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
 }
 
 type AppConfig struct {
 	Environment              string  `mapstructure:"environment"`
 	LogLevel                 string  `mapstructure:"log_level"`
+	LogFormat                string  `mapstructure:"log_format"`
 	LoanAmount               float64 `mapstructure:"loan_amount"`
 	LoanDurationWeeks        int     `mapstructure:"loan_duration_weeks"`
 	AnnualInterestRate       float64 `mapstructure:"annual_interest_rate"`
 	DelinquentWeeksThreshold int     `mapstructure:"delinquent_weeks_threshold"`
+	DefaultWeeksThreshold    int     `mapstructure:"default_weeks_threshold"`
+	BatchConcurrency         int     `mapstructure:"batch_concurrency"`
+	OverduePaymentPolicy     string  `mapstructure:"overdue_payment_policy"`
+	InterestRateBasis        string  `mapstructure:"interest_rate_basis"`
+	SchedulerBatchSize       int     `mapstructure:"scheduler_batch_size"`
+	MaxScheduleHorizonWeeks  int     `mapstructure:"max_schedule_horizon_weeks"`
+	GenerateLoanID           bool    `mapstructure:"generate_loan_id"`
+	LoanIDScheme             string  `mapstructure:"loan_id_scheme"`
+	OverpaymentPolicy        string  `mapstructure:"overpayment_policy"`
+	OverpaymentTolerance     float64 `mapstructure:"overpayment_tolerance"`
+	OverdueGraceDays         int     `mapstructure:"overdue_grace_days"`
+	OverdueBoundary          string  `mapstructure:"overdue_boundary"`
+	OnTimeRebateRate         float64 `mapstructure:"on_time_rebate_rate"`
+	MaxBackdateDays          int     `mapstructure:"max_backdate_days"`
+	MaxPaymentAmount         float64 `mapstructure:"max_payment_amount"`
+	MaxLoanAmount            float64 `mapstructure:"max_loan_amount"`
+	MaxDurationWeeks         int     `mapstructure:"max_duration_weeks"`
+	MaxInterestRate          float64 `mapstructure:"max_interest_rate"`
+	RatePrecision            int     `mapstructure:"rate_precision"`
+	RatePrecisionPolicy      string  `mapstructure:"rate_precision_policy"`
+	PaymentDayPolicy         string  `mapstructure:"payment_day_policy"`
+
+	// Holidays are the YYYY-MM-DD dates, besides weekends, that are not business days
+	Holidays []string `mapstructure:"holidays"`
+
+	// MinPaymentInterval is how long after a loan's latest payment was recorded another one is
+	// refused, to catch accidental double submissions; zero turns the check off
+	MinPaymentInterval time.Duration `mapstructure:"min_payment_interval"`
+
+	// StoragePrecision is how many fractional digits amounts are calculated, stored and matched at;
+	// DisplayPrecision is how many responses round them to. Unset means money.Scale for both.
+	StoragePrecision *int32 `mapstructure:"storage_precision"`
+	DisplayPrecision *int32 `mapstructure:"display_precision"`
 }
 
+// EnvironmentProduction is the APP_ENV value for production deployments
+const EnvironmentProduction = "production"
+
+// Interest rate bases decide how a loan's interest rate is applied
+const (
+	// InterestRateBasisPerTerm charges the rate once, flat, over the whole loan term
+	InterestRateBasisPerTerm = "per_term"
+	// InterestRateBasisAnnual treats the rate as yearly and scales it by the term length (weeks/52)
+	InterestRateBasisAnnual = "annual"
+)
+
+// Overdue payment policies decide how a payment is applied when every unpaid week is overdue
+const (
+	// OverduePaymentPolicyCatchUp applies each payment to the oldest overdue week
+	OverduePaymentPolicyCatchUp = "catch_up"
+	// OverduePaymentPolicyAllOverdue requires a single payment covering every overdue week
+	OverduePaymentPolicyAllOverdue = "all_overdue"
+)
+
+// Overdue boundaries decide whether a week due exactly OVERDUE_GRACE_DAYS ago is overdue. Due
+// dates are whole days, so the boundary is that whole day.
+const (
+	// OverdueBoundaryExclusive keeps the week on time through the boundary day; it is overdue from
+	// the next one
+	OverdueBoundaryExclusive = "exclusive"
+	// OverdueBoundaryInclusive makes the week overdue from the first second of the boundary day
+	OverdueBoundaryInclusive = "inclusive"
+)
+
+// Loan ID schemes decide how server-generated loan IDs are formed
+const (
+	// LoanIDSchemeUUID generates "LOAN-<UUIDv7>", unique without coordination and sortable by creation time
+	LoanIDSchemeUUID = "uuid"
+	// LoanIDSchemeSequence numbers loans from the loan_id_seq database sequence: "LOAN-00000042"
+	LoanIDSchemeSequence = "sequence"
+)
+
+// Overpayment policies decide what happens when the payment that closes a loan exceeds what is due
+const (
+	// OverpaymentPolicyReject refuses any payment that doesn't match the amount due
+	OverpaymentPolicyReject = "reject"
+	// OverpaymentPolicyCredit closes the loan and records the excess (up to the tolerance) as a credit balance
+	OverpaymentPolicyCredit = "credit"
+)
+
+// Rate precision policies decide what happens to a new loan's interest rate with more decimal
+// places than RatePrecision
+const (
+	// RatePrecisionPolicyRound rounds the rate to RatePrecision decimal places
+	RatePrecisionPolicyRound = "round"
+	// RatePrecisionPolicyReject refuses the loan
+	RatePrecisionPolicyReject = "reject"
+)
+
+// Payment day policies decide what happens to a payment dated on a weekend or holiday
+const (
+	// PaymentDayPolicyAny posts payments on whatever day they were made
+	PaymentDayPolicyAny = "any"
+	// PaymentDayPolicyReject refuses payments made outside business days
+	PaymentDayPolicyReject = "reject"
+	// PaymentDayPolicyDefer accepts the payment but posts it on the next business day
+	PaymentDayPolicyDefer = "defer"
+)
+
+// MaxRatePrecision is the most decimal places the interest_rate column in scripts/init.sql holds
+const MaxRatePrecision = 4
+
+// DefaultMaxInterestRate is the interest rate ceiling when none is configured: 1.0 is 100%, which
+// catches callers sending 10 when they mean 10%
+const DefaultMaxInterestRate = 1.0
+
+// MaxStoragePrecision is the most fractional digits the amount columns in scripts/init.sql hold
+const MaxStoragePrecision = 4
+
+// DefaultMaxScheduleHorizonWeeks caps how far out (from today) a loan's last due date may fall: 10 years
+const DefaultMaxScheduleHorizonWeeks = 520
+
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
@@ -74,6 +237,10 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -83,8 +250,11 @@ func setDefaults() {
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
+	viper.SetDefault("server.response_timezone", "")
+	viper.SetDefault("server.response_timestamp_utc", false)
 
 	// Database defaults
+	viper.SetDefault("database.url", "")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("database.user", "billing_user")
@@ -93,20 +263,59 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 25)
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("database.conn_max_lifetime", "300s")
+	viper.SetDefault("database.query_timeout", "10s")
 
 	// Redis defaults
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", "6379")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.required", true)
+	viper.SetDefault("redis.cache_ttl", "5m")
+	viper.SetDefault("redis.cache_warmer_enabled", false)
+	viper.SetDefault("redis.cache_warmer_interval", "5m")
+
+	// Metrics defaults
+	viper.SetDefault("metrics.enabled", false)
+	viper.SetDefault("metrics.username", "")
+	viper.SetDefault("metrics.password", "")
+	viper.SetDefault("metrics.allowed_ips", []string{})
+
+	// Admin defaults
+	viper.SetDefault("admin.username", "")
+	viper.SetDefault("admin.password", "")
 
 	// App defaults
 	viper.SetDefault("app.environment", "development")
 	viper.SetDefault("app.log_level", "debug")
+	viper.SetDefault("app.log_format", logger.FormatJSON)
 	viper.SetDefault("app.loan_amount", 5000000.0)
 	viper.SetDefault("app.loan_duration_weeks", 50)
 	viper.SetDefault("app.annual_interest_rate", 0.10)
 	viper.SetDefault("app.delinquent_weeks_threshold", 2)
+	viper.SetDefault("app.default_weeks_threshold", 4)
+	viper.SetDefault("app.batch_concurrency", 4)
+	viper.SetDefault("app.overdue_payment_policy", OverduePaymentPolicyCatchUp)
+	viper.SetDefault("app.interest_rate_basis", InterestRateBasisPerTerm)
+	viper.SetDefault("app.scheduler_batch_size", 100)
+	viper.SetDefault("app.max_schedule_horizon_weeks", DefaultMaxScheduleHorizonWeeks)
+	viper.SetDefault("app.generate_loan_id", false)
+	viper.SetDefault("app.loan_id_scheme", LoanIDSchemeUUID)
+	viper.SetDefault("app.overpayment_policy", OverpaymentPolicyReject)
+	viper.SetDefault("app.overpayment_tolerance", 0.0)
+	viper.SetDefault("app.overdue_grace_days", 0)
+	viper.SetDefault("app.overdue_boundary", OverdueBoundaryExclusive)
+	viper.SetDefault("app.on_time_rebate_rate", 0.0)
+	viper.SetDefault("app.max_backdate_days", 0)
+	viper.SetDefault("app.max_payment_amount", 0.0)
+	viper.SetDefault("app.max_loan_amount", 0.0)
+	viper.SetDefault("app.max_duration_weeks", 0)
+	viper.SetDefault("app.max_interest_rate", DefaultMaxInterestRate)
+	viper.SetDefault("app.rate_precision", MaxRatePrecision)
+	viper.SetDefault("app.rate_precision_policy", RatePrecisionPolicyRound)
+	viper.SetDefault("app.payment_day_policy", PaymentDayPolicyAny)
+	viper.SetDefault("app.holidays", []string{})
+	viper.SetDefault("app.min_payment_interval", 0)
 }
 
 func bindEnvVars() {
@@ -115,8 +324,12 @@ func bindEnvVars() {
 	viper.BindEnv("server.port", "SERVER_PORT")
 	viper.BindEnv("server.read_timeout", "SERVER_READ_TIMEOUT")
 	viper.BindEnv("server.write_timeout", "SERVER_WRITE_TIMEOUT")
+	viper.BindEnv("server.response_timezone", "RESPONSE_TIMEZONE")
+	viper.BindEnv("server.response_timestamp_utc", "RESPONSE_TIMESTAMP_UTC")
+	viper.BindEnv("server.error_details", "ERROR_DETAILS")
 
 	// Database
+	viper.BindEnv("database.url", "DATABASE_URL")
 	viper.BindEnv("database.host", "DB_HOST")
 	viper.BindEnv("database.port", "DB_PORT")
 	viper.BindEnv("database.user", "DB_USER")
@@ -125,23 +338,327 @@ func bindEnvVars() {
 	viper.BindEnv("database.max_open_conns", "DB_MAX_OPEN_CONNS")
 	viper.BindEnv("database.max_idle_conns", "DB_MAX_IDLE_CONNS")
 	viper.BindEnv("database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME")
+	viper.BindEnv("database.query_timeout", "DB_QUERY_TIMEOUT")
 
 	// Redis
 	viper.BindEnv("redis.host", "REDIS_HOST")
 	viper.BindEnv("redis.port", "REDIS_PORT")
 	viper.BindEnv("redis.password", "REDIS_PASSWORD")
 	viper.BindEnv("redis.db", "REDIS_DB")
+	viper.BindEnv("redis.required", "REDIS_REQUIRED")
+	viper.BindEnv("redis.cache_ttl", "CACHE_TTL")
+	viper.BindEnv("redis.cache_warmer_enabled", "CACHE_WARMER_ENABLED")
+	viper.BindEnv("redis.cache_warmer_interval", "CACHE_WARMER_INTERVAL")
+
+	// Metrics
+	viper.BindEnv("metrics.enabled", "METRICS_ENABLED")
+	viper.BindEnv("metrics.username", "METRICS_USERNAME")
+	viper.BindEnv("metrics.password", "METRICS_PASSWORD")
+	viper.BindEnv("metrics.allowed_ips", "METRICS_ALLOWED_IPS")
+
+	// Admin
+	viper.BindEnv("admin.username", "ADMIN_USERNAME")
+	viper.BindEnv("admin.password", "ADMIN_PASSWORD")
 
 	// App
 	viper.BindEnv("app.environment", "APP_ENV")
 	viper.BindEnv("app.log_level", "LOG_LEVEL")
+	viper.BindEnv("app.log_format", "LOG_FORMAT")
 	viper.BindEnv("app.loan_amount", "LOAN_AMOUNT")
 	viper.BindEnv("app.loan_duration_weeks", "LOAN_DURATION_WEEKS")
 	viper.BindEnv("app.annual_interest_rate", "ANNUAL_INTEREST_RATE")
 	viper.BindEnv("app.delinquent_weeks_threshold", "DELINQUENT_WEEKS_THRESHOLD")
+	viper.BindEnv("app.default_weeks_threshold", "DEFAULT_WEEKS_THRESHOLD")
+	viper.BindEnv("app.batch_concurrency", "BATCH_CONCURRENCY")
+	viper.BindEnv("app.overdue_payment_policy", "OVERDUE_PAYMENT_POLICY")
+	viper.BindEnv("app.interest_rate_basis", "INTEREST_RATE_BASIS")
+	viper.BindEnv("app.scheduler_batch_size", "SCHEDULER_BATCH_SIZE")
+	viper.BindEnv("app.max_schedule_horizon_weeks", "MAX_SCHEDULE_HORIZON_WEEKS")
+	viper.BindEnv("app.generate_loan_id", "GENERATE_LOAN_ID")
+	viper.BindEnv("app.loan_id_scheme", "LOAN_ID_SCHEME")
+	viper.BindEnv("app.overpayment_policy", "OVERPAYMENT_POLICY")
+	viper.BindEnv("app.overpayment_tolerance", "OVERPAYMENT_TOLERANCE")
+	viper.BindEnv("app.overdue_grace_days", "OVERDUE_GRACE_DAYS")
+	viper.BindEnv("app.overdue_boundary", "OVERDUE_BOUNDARY")
+	viper.BindEnv("app.on_time_rebate_rate", "ON_TIME_REBATE_RATE")
+	viper.BindEnv("app.max_backdate_days", "MAX_BACKDATE_DAYS")
+	viper.BindEnv("app.max_payment_amount", "MAX_PAYMENT_AMOUNT")
+	viper.BindEnv("app.max_loan_amount", "MAX_LOAN_AMOUNT")
+	viper.BindEnv("app.max_duration_weeks", "MAX_DURATION_WEEKS")
+	viper.BindEnv("app.max_interest_rate", "MAX_INTEREST_RATE")
+	viper.BindEnv("app.rate_precision", "RATE_PRECISION")
+	viper.BindEnv("app.rate_precision_policy", "RATE_PRECISION_POLICY")
+	viper.BindEnv("app.payment_day_policy", "PAYMENT_DAY_POLICY")
+	viper.BindEnv("app.holidays", "HOLIDAYS")
+	viper.BindEnv("app.min_payment_interval", "MIN_PAYMENT_INTERVAL")
+	viper.BindEnv("app.storage_precision", "STORAGE_PRECISION")
+	viper.BindEnv("app.display_precision", "DISPLAY_PRECISION")
+}
+
+// Validate checks settings that have no safe fallback
+func (c *Config) Validate() error {
+	if _, err := logger.ParseLevel(c.App.LogLevel); err != nil {
+		return fmt.Errorf("LOG_LEVEL must be debug, info, warn or error: %w", err)
+	}
+	if !logger.ValidFormat(c.App.LogFormat) {
+		return fmt.Errorf("LOG_FORMAT must be %s or %s, got %q", logger.FormatJSON, logger.FormatText, c.App.LogFormat)
+	}
+	if c.Database.QueryTimeout < 0 {
+		return fmt.Errorf("DB_QUERY_TIMEOUT must not be negative, got %s", c.Database.QueryTimeout)
+	}
+	if c.App.LoanAmount < 0 {
+		return fmt.Errorf("LOAN_AMOUNT must not be negative, got %v", c.App.LoanAmount)
+	}
+	if c.App.LoanDurationWeeks < 0 {
+		return fmt.Errorf("LOAN_DURATION_WEEKS must not be negative, got %d", c.App.LoanDurationWeeks)
+	}
+	if c.App.AnnualInterestRate < 0 {
+		return fmt.Errorf("ANNUAL_INTEREST_RATE must not be negative, got %v", c.App.AnnualInterestRate)
+	}
+	if c.App.DelinquentWeeksThreshold < 0 {
+		return fmt.Errorf("DELINQUENT_WEEKS_THRESHOLD must not be negative, got %d", c.App.DelinquentWeeksThreshold)
+	}
+	if c.App.SchedulerBatchSize <= 0 {
+		return fmt.Errorf("SCHEDULER_BATCH_SIZE must be positive, got %d", c.App.SchedulerBatchSize)
+	}
+	if c.App.MaxScheduleHorizonWeeks <= 0 {
+		return fmt.Errorf("MAX_SCHEDULE_HORIZON_WEEKS must be positive, got %d", c.App.MaxScheduleHorizonWeeks)
+	}
+	if c.App.DefaultWeeksThreshold < 0 {
+		return fmt.Errorf("DEFAULT_WEEKS_THRESHOLD must not be negative, got %d", c.App.DefaultWeeksThreshold)
+	}
+	if c.App.DefaultWeeksThreshold > 0 && c.App.DefaultWeeksThreshold < c.App.DelinquentWeeksThreshold {
+		return fmt.Errorf("DEFAULT_WEEKS_THRESHOLD must not be below DELINQUENT_WEEKS_THRESHOLD (%d), got %d",
+			c.App.DelinquentWeeksThreshold, c.App.DefaultWeeksThreshold)
+	}
+	switch c.App.LoanIDScheme {
+	case "", LoanIDSchemeUUID, LoanIDSchemeSequence:
+	default:
+		return fmt.Errorf("LOAN_ID_SCHEME must be %s or %s, got %q", LoanIDSchemeUUID, LoanIDSchemeSequence, c.App.LoanIDScheme)
+	}
+	switch c.App.InterestRateBasis {
+	case "", InterestRateBasisPerTerm, InterestRateBasisAnnual:
+	default:
+		return fmt.Errorf("INTEREST_RATE_BASIS must be %s or %s, got %q", InterestRateBasisPerTerm, InterestRateBasisAnnual, c.App.InterestRateBasis)
+	}
+	switch c.App.OverduePaymentPolicy {
+	case "", OverduePaymentPolicyCatchUp, OverduePaymentPolicyAllOverdue:
+	default:
+		return fmt.Errorf("OVERDUE_PAYMENT_POLICY must be %s or %s, got %q", OverduePaymentPolicyCatchUp, OverduePaymentPolicyAllOverdue, c.App.OverduePaymentPolicy)
+	}
+	switch c.App.OverpaymentPolicy {
+	case "", OverpaymentPolicyReject, OverpaymentPolicyCredit:
+	default:
+		return fmt.Errorf("OVERPAYMENT_POLICY must be %s or %s, got %q", OverpaymentPolicyReject, OverpaymentPolicyCredit, c.App.OverpaymentPolicy)
+	}
+	switch c.App.PaymentDayPolicy {
+	case "", PaymentDayPolicyAny, PaymentDayPolicyReject, PaymentDayPolicyDefer:
+	default:
+		return fmt.Errorf("PAYMENT_DAY_POLICY must be %s, %s or %s, got %q", PaymentDayPolicyAny, PaymentDayPolicyReject, PaymentDayPolicyDefer, c.App.PaymentDayPolicy)
+	}
+	if c.App.OverpaymentTolerance < 0 {
+		return fmt.Errorf("OVERPAYMENT_TOLERANCE must not be negative, got %v", c.App.OverpaymentTolerance)
+	}
+	if c.App.OverdueGraceDays < 0 {
+		return fmt.Errorf("OVERDUE_GRACE_DAYS must not be negative, got %d", c.App.OverdueGraceDays)
+	}
+	switch c.App.OverdueBoundary {
+	case "", OverdueBoundaryExclusive, OverdueBoundaryInclusive:
+	default:
+		return fmt.Errorf("OVERDUE_BOUNDARY must be %s or %s, got %q", OverdueBoundaryExclusive, OverdueBoundaryInclusive, c.App.OverdueBoundary)
+	}
+	if c.App.OnTimeRebateRate < 0 || c.App.OnTimeRebateRate > 1 {
+		return fmt.Errorf("ON_TIME_REBATE_RATE must be between 0 and 1, got %v", c.App.OnTimeRebateRate)
+	}
+	if c.App.MaxBackdateDays < 0 {
+		return fmt.Errorf("MAX_BACKDATE_DAYS must not be negative, got %d", c.App.MaxBackdateDays)
+	}
+	if c.App.MaxPaymentAmount < 0 {
+		return fmt.Errorf("MAX_PAYMENT_AMOUNT must not be negative, got %v", c.App.MaxPaymentAmount)
+	}
+	if c.App.MinPaymentInterval < 0 {
+		return fmt.Errorf("MIN_PAYMENT_INTERVAL must not be negative, got %s", c.App.MinPaymentInterval)
+	}
+	if c.App.MaxLoanAmount < 0 {
+		return fmt.Errorf("MAX_LOAN_AMOUNT must not be negative, got %v", c.App.MaxLoanAmount)
+	}
+	if c.App.MaxDurationWeeks < 0 {
+		return fmt.Errorf("MAX_DURATION_WEEKS must not be negative, got %d", c.App.MaxDurationWeeks)
+	}
+	if c.App.MaxInterestRate < 0 {
+		return fmt.Errorf("MAX_INTEREST_RATE must not be negative, got %v", c.App.MaxInterestRate)
+	}
+	// The loan defaults fill in omitted request fields, so they must pass the same limits
+	if c.App.MaxLoanAmount > 0 && c.App.LoanAmount > c.App.MaxLoanAmount {
+		return fmt.Errorf("LOAN_AMOUNT must not exceed MAX_LOAN_AMOUNT (%v), got %v", c.App.MaxLoanAmount, c.App.LoanAmount)
+	}
+	if c.App.MaxDurationWeeks > 0 && c.App.LoanDurationWeeks > c.App.MaxDurationWeeks {
+		return fmt.Errorf("LOAN_DURATION_WEEKS must not exceed MAX_DURATION_WEEKS (%d), got %d", c.App.MaxDurationWeeks, c.App.LoanDurationWeeks)
+	}
+	if maxRate := c.App.maxInterestRate(); c.App.AnnualInterestRate > maxRate {
+		return fmt.Errorf("ANNUAL_INTEREST_RATE must not exceed MAX_INTEREST_RATE (%v), got %v", maxRate, c.App.AnnualInterestRate)
+	}
+	// Zero means unset and falls back to MaxRatePrecision
+	if c.App.RatePrecision < 0 || c.App.RatePrecision > MaxRatePrecision {
+		return fmt.Errorf("RATE_PRECISION must be between 1 and %d, got %d", MaxRatePrecision, c.App.RatePrecision)
+	}
+	if _, err := c.App.HolidayDates(); err != nil {
+		return fmt.Errorf("HOLIDAYS is invalid: %w", err)
+	}
+	if storage := c.App.StorageScale(); storage < 0 || storage > MaxStoragePrecision {
+		return fmt.Errorf("STORAGE_PRECISION must be between 0 and %d, got %d", MaxStoragePrecision, storage)
+	}
+	if display := c.App.DisplayScale(); display < 0 || display > c.App.StorageScale() {
+		return fmt.Errorf("DISPLAY_PRECISION must be between 0 and STORAGE_PRECISION (%d), got %d",
+			c.App.StorageScale(), display)
+	}
+	if c.Redis.CacheTTL < 0 {
+		return fmt.Errorf("CACHE_TTL must not be negative, got %v", c.Redis.CacheTTL)
+	}
+	if c.Redis.CacheWarmerEnabled && c.Redis.CacheWarmerInterval <= 0 {
+		return fmt.Errorf("CACHE_WARMER_INTERVAL must be positive when the cache warmer is enabled, got %v", c.Redis.CacheWarmerInterval)
+	}
+	if _, err := c.Server.ResponseLocation(); err != nil {
+		return fmt.Errorf("RESPONSE_TIMEZONE is not a valid timezone: %w", err)
+	}
+	if c.Metrics.Username != "" && c.Metrics.Password == "" {
+		return fmt.Errorf("METRICS_PASSWORD is required when METRICS_USERNAME is set")
+	}
+	if _, err := c.Metrics.AllowedNetworks(); err != nil {
+		return fmt.Errorf("METRICS_ALLOWED_IPS is invalid: %w", err)
+	}
+	if c.Admin.Username != "" && c.Admin.Password == "" {
+		return fmt.Errorf("ADMIN_PASSWORD is required when ADMIN_USERNAME is set")
+	}
+	return nil
+}
+
+// ErrorDetailsEnabled reports whether error responses may carry the underlying error text.
+// An explicit ERROR_DETAILS wins; otherwise details are hidden only in production.
+func (c *Config) ErrorDetailsEnabled() bool {
+	if c.Server.ErrorDetails != nil {
+		return *c.Server.ErrorDetails
+	}
+	return c.App.Environment != EnvironmentProduction
+}
+
+// ResponseLocation resolves ResponseTimezone; nil means the server's local zone
+func (s *ServerConfig) ResponseLocation() (*time.Location, error) {
+	if s.ResponseTimezone == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(s.ResponseTimezone)
+}
+
+// OverdueGrace is how long after its due date an unpaid week becomes overdue
+func (a *AppConfig) OverdueGrace() time.Duration {
+	return time.Duration(a.OverdueGraceDays) * 24 * time.Hour
+}
+
+// OverdueInclusive reports whether a week due exactly OverdueGrace ago is already overdue
+func (a *AppConfig) OverdueInclusive() bool {
+	return a.OverdueBoundary == OverdueBoundaryInclusive
+}
+
+// maxInterestRate is MaxInterestRate, or DefaultMaxInterestRate when unset
+func (a *AppConfig) maxInterestRate() float64 {
+	if a.MaxInterestRate > 0 {
+		return a.MaxInterestRate
+	}
+	return DefaultMaxInterestRate
+}
+
+// HolidayDates parses Holidays into the set utils.IsBusinessDay takes
+func (a *AppConfig) HolidayDates() (map[string]bool, error) {
+	holidays := make(map[string]bool, len(a.Holidays))
+	for _, entry := range a.Holidays {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		date, err := time.Parse(utils.DateLayout, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a YYYY-MM-DD date", entry)
+		}
+		holidays[date.Format(utils.DateLayout)] = true
+	}
+	return holidays, nil
+}
+
+// StorageScale is the number of fractional digits amounts are calculated, stored and matched at
+func (a *AppConfig) StorageScale() int32 {
+	if a.StoragePrecision == nil {
+		return money.Scale
+	}
+	return *a.StoragePrecision
 }
 
+// DisplayScale is the number of fractional digits amounts are rounded to in responses
+func (a *AppConfig) DisplayScale() int32 {
+	if a.DisplayPrecision == nil {
+		return money.Scale
+	}
+	return *a.DisplayPrecision
+}
+
+// AllowedNetworks parses AllowedIPs; a bare IP becomes a single-address network
+func (m *MetricsConfig) AllowedNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(m.AllowedIPs))
+	for _, entry := range m.AllowedIPs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// DSN is the postgres connection string: URL when set, otherwise one built from the individual
+// fields, with values quoted where libpq needs it
 func (d *DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		d.Host, d.Port, d.User, d.Password, d.Name)
+		dsnValue(d.Host), dsnValue(d.Port), dsnValue(d.User), dsnValue(d.Password), dsnValue(d.Name))
+}
+
+// dsnValue quotes a key=value connection string value that is empty or holds spaces, quotes or
+// backslashes, escaping the latter two
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + escaped + "'"
+}
+
+// BatchConcurrencyLimit returns how many batch items may be processed at once.
+// It never exceeds the database pool size so batch jobs cannot exhaust connections.
+func (c *Config) BatchConcurrencyLimit() int {
+	limit := c.App.BatchConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+	if c.Database.MaxOpenConns > 0 && limit > c.Database.MaxOpenConns {
+		limit = c.Database.MaxOpenConns
+	}
+	return limit
 }
@@ -1,22 +1,60 @@
 package domain
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/segyhp/billing-engine/pkg/money"
 	"github.com/shopspring/decimal"
 )
 
 const (
-	LoanStatusActive  = "active"
-	LoanStatusClosed  = "closed"
-	LoanStatusDefault = "default"
+	LoanStatusActive     = "active"
+	LoanStatusDelinquent = "delinquent"
+	LoanStatusClosed     = "closed"
+	LoanStatusDefault    = "default"
+	LoanStatusWrittenOff = "written_off"
 )
 
+// OpenLoanStatuses are the statuses of loans still being repaid. A delinquent loan is behind
+// but still takes payments; it returns to active once the borrower catches up.
+var OpenLoanStatuses = []string{LoanStatusActive, LoanStatusDelinquent}
+
+// IsValidLoanStatus reports whether status is one of the known loan statuses
+func IsValidLoanStatus(status string) bool {
+	switch status {
+	case LoanStatusActive, LoanStatusDelinquent, LoanStatusClosed, LoanStatusDefault, LoanStatusWrittenOff:
+		return true
+	}
+	return false
+}
+
+// ProgressLoanStatus returns the status an open loan should have given its current run of missed
+// weeks: default once the run reaches defaultWeeks, delinquent once it reaches delinquentWeeks, and
+// active otherwise. A threshold of zero or less is disabled. Loans that aren't open keep their status,
+// so default is final.
+func ProgressLoanStatus(status string, missedWeeks, delinquentWeeks, defaultWeeks int) string {
+	if status != LoanStatusActive && status != LoanStatusDelinquent {
+		return status
+	}
+	switch {
+	case defaultWeeks > 0 && missedWeeks >= defaultWeeks:
+		return LoanStatusDefault
+	case delinquentWeeks > 0 && missedWeeks >= delinquentWeeks:
+		return LoanStatusDelinquent
+	default:
+		return LoanStatusActive
+	}
+}
+
 // Loan represents a loan entity
 type Loan struct {
 	ID            uuid.UUID       `json:"id" db:"id"`
 	LoanID        string          `json:"loan_id" db:"loan_id"`
+	BorrowerID    string          `json:"borrower_id,omitempty" db:"borrower_id"` // empty for loans created without one
 	Amount        decimal.Decimal `json:"amount" db:"amount"`
 	InterestRate  decimal.Decimal `json:"interest_rate" db:"interest_rate"`
 	DurationWeeks int             `json:"duration_weeks" db:"duration_weeks"`
@@ -24,29 +62,243 @@ type Loan struct {
 	Status        string          `json:"status" db:"status"`
 	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+	DeletedAt     *time.Time      `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Interest-only period: the first InterestOnlyWeeks pay interest only and
+	// WeeklyPayment is the amortizing installment that follows
+	InterestOnlyWeeks int `json:"interest_only_weeks" db:"interest_only_weeks"`
+
+	// CreditBalance is the overpayment kept on a loan closed under the credit overpayment policy
+	CreditBalance decimal.Decimal `json:"credit_balance" db:"credit_balance"`
+
+	// RebateAmount is the interest waived at payoff for paying every week on time
+	RebateAmount decimal.Decimal `json:"rebate_amount" db:"rebate_amount"`
+
+	// InterestRateBasis is the INTEREST_RATE_BASIS the loan was priced under, kept so that changing
+	// the setting later doesn't reprice loans already on the books
+	InterestRateBasis string `json:"interest_rate_basis" db:"interest_rate_basis"`
+
+	// Forbearance window: overdue weeks due inside it don't count toward delinquency
+	ForbearanceStart *time.Time `json:"forbearance_start,omitempty" db:"forbearance_start"`
+	ForbearanceEnd   *time.Time `json:"forbearance_end,omitempty" db:"forbearance_end"`
+
+	// Notes holds free-form support annotations as a JSON object
+	Notes json.RawMessage `json:"notes,omitempty" db:"notes"`
+
+	// Tags categorize the loan for lenders, e.g. product type or branch
+	Tags pq.StringArray `json:"tags" db:"tags"`
+}
+
+// MaxLoanNotesBytes caps the size of a loan's notes JSON document
+const MaxLoanNotesBytes = 16 * 1024
+
+// InForbearance reports whether date falls within the loan's forbearance window (both ends inclusive)
+func (l *Loan) InForbearance(date time.Time) bool {
+	if l.ForbearanceStart == nil || l.ForbearanceEnd == nil {
+		return false
+	}
+	return !date.Before(*l.ForbearanceStart) && !date.After(*l.ForbearanceEnd)
+}
+
+// NormalizeTags trims and lowercases tags, dropping blanks and duplicates while keeping their order
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// LoanBalance pairs a loan with the total amount paid against it
+type LoanBalance struct {
+	Loan
+	TotalPaid decimal.Decimal `json:"total_paid" db:"total_paid"`
 }
 
 // DTOs for requests and responses
 
 type CreateLoanRequest struct {
 	LoanID        string          `json:"loan_id" validate:"required"`
-	Amount        decimal.Decimal `json:"amount" validate:"required,decimal_gt=0"`
-	InterestRate  decimal.Decimal `json:"interest_rate" validate:"required,decimal_gte=0"`
+	BorrowerID    string          `json:"borrower_id" validate:"max=100"`         // optional; trimmed before the loan is stored
+	Amount        decimal.Decimal `json:"amount" validate:"decimal_gt=0"`         // required can't see a zero decimal; decimal_gt rejects it
+	InterestRate  decimal.Decimal `json:"interest_rate" validate:"decimal_gte=0"` // 0 is a valid zero-interest loan
 	DurationWeeks int             `json:"duration_weeks" validate:"required,gt=0"`
+	// InterestOnlyWeeks is the number of leading weeks that pay interest only; principal amortizes afterwards
+	InterestOnlyWeeks int `json:"interest_only_weeks" validate:"gte=0,ltfield=DurationWeeks"`
+	// Tags are normalized (trimmed, lowercased, de-duplicated) before the loan is stored
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+// LoanFilter narrows a loan listing; zero values mean "no filter"
+type LoanFilter struct {
+	Tag    string
+	Status string
+	Limit  int
+	Offset int
+}
+
+type LoanListResponse struct {
+	Loans  []*Loan `json:"loans"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// BorrowerLoansResponse lists every live loan belonging to one borrower
+type BorrowerLoansResponse struct {
+	BorrowerID string  `json:"borrower_id"`
+	Loans      []*Loan `json:"loans"`
 }
 
 type CreateLoanResponse struct {
 	Loan     *Loan           `json:"loan"`
-	Schedule []*LoanSchedule `json:"schedule"`
+	Schedule []*LoanSchedule `json:"schedule,omitempty"`
+}
+
+// MaxBatchCreateLoans caps how many loans one batch import may carry
+const MaxBatchCreateLoans = 500
+
+// BatchCreateLoanRequest carries the loans of a batch import. Each entry is decoded as a
+// CreateLoanRequest, keeping the raw JSON so omitted fields can take the configured defaults.
+type BatchCreateLoanRequest struct {
+	Loans []json.RawMessage `json:"loans" validate:"required,min=1,max=500"`
+}
+
+// BatchCreateLoanResult is one loan's outcome in a batch import, at its index in the request
+type BatchCreateLoanResult struct {
+	Index  int    `json:"index"`
+	LoanID string `json:"loan_id"`
+	// Loan is set when the loan was created; Code and Error explain why it wasn't otherwise
+	Loan  *Loan  `json:"loan,omitempty"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type BatchCreateLoanResponse struct {
+	Created int                      `json:"created"`
+	Failed  int                      `json:"failed"`
+	Results []*BatchCreateLoanResult `json:"results"`
+}
+
+// CloseFullyPaidLoansResponse reports how many fully paid loans an admin run closed
+type CloseFullyPaidLoansResponse struct {
+	Closed int64 `json:"closed"`
+}
+
+type ForbearanceRequest struct {
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
+}
+
+type LoanNotesResponse struct {
+	LoanID string          `json:"loan_id"`
+	Notes  json.RawMessage `json:"notes"`
+}
+
+// OutstandingBreakdown splits a loan's outstanding balance into what remains of its principal and
+// of its interest
+type OutstandingBreakdown struct {
+	PrincipalOutstanding decimal.Decimal `json:"principal_outstanding"`
+	InterestOutstanding  decimal.Decimal `json:"interest_outstanding"`
+	TotalOutstanding     decimal.Decimal `json:"total_outstanding"`
 }
 
 type OutstandingResponse struct {
-	LoanID      string          `json:"loan_id"`
-	Outstanding decimal.Decimal `json:"outstanding"`
+	LoanID string `json:"loan_id"`
+	// Outstanding predates the breakdown and always equals TotalOutstanding
+	Outstanding          money.Money      `json:"outstanding"`
+	PrincipalOutstanding money.Money      `json:"principal_outstanding"`
+	InterestOutstanding  money.Money      `json:"interest_outstanding"`
+	TotalOutstanding     money.Money      `json:"total_outstanding"`
+	TotalPaid            money.Money      `json:"total_paid"`
+	NextDue              *NextDueResponse `json:"next_due"`
+}
+
+type BatchOutstandingRequest struct {
+	LoanIDs []string `json:"loan_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+type BatchOutstandingResponse struct {
+	Outstanding map[string]money.Money `json:"outstanding"`
+	NotFound    []string               `json:"not_found"`
 }
 
 type DelinquentResponse struct {
-	LoanID       string `json:"loan_id"`
-	IsDelinquent bool   `json:"is_delinquent"`
-	MissedWeeks  int    `json:"missed_weeks"`
+	LoanID       string     `json:"loan_id"`
+	IsDelinquent bool       `json:"is_delinquent"`
+	MissedWeeks  int        `json:"missed_weeks"`
+	AsOf         *time.Time `json:"as_of,omitempty"` // set when the check was simulated for a given date
+}
+
+type BatchDelinquencyRequest struct {
+	LoanIDs []string `json:"loan_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// DelinquencyStatus is one loan's entry in a batch delinquency check
+type DelinquencyStatus struct {
+	IsDelinquent bool `json:"is_delinquent"`
+	MissedWeeks  int  `json:"missed_weeks"`
+}
+
+type BatchDelinquencyResponse struct {
+	Delinquency map[string]*DelinquencyStatus `json:"delinquency"`
+	NotFound    []string                      `json:"not_found"`
+}
+
+// LoanDelinquency summarizes a loan's overdue weeks as computed in the database
+type LoanDelinquency struct {
+	LoanID string `db:"loan_id"`
+	Status string `db:"status"`
+	// MissedWeeks is the current run of consecutive overdue unpaid weeks
+	MissedWeeks int `db:"missed_weeks"`
+	// LongestMissedStreak is the longest run of consecutive overdue unpaid weeks so far
+	LongestMissedStreak int `db:"longest_missed_streak"`
+}
+
+// DelinquencySnapshot captures a loan's delinquency state as of a schedule due date
+type DelinquencySnapshot struct {
+	WeekNumber   int       `json:"week_number"`
+	AsOf         time.Time `json:"as_of"`
+	MissedWeeks  int       `json:"missed_weeks"`
+	IsDelinquent bool      `json:"is_delinquent"`
+}
+
+type DelinquencyHistoryResponse struct {
+	LoanID  string                 `json:"loan_id"`
+	History []*DelinquencySnapshot `json:"history"`
+}
+
+// LoanExport is a self-contained snapshot of a loan, its full schedule and every payment,
+// used for migrations and support escalations
+type LoanExport struct {
+	Loan       *Loan           `json:"loan"`
+	Deleted    bool            `json:"deleted"` // the loan is soft-deleted; loan.deleted_at says when
+	Schedule   []*LoanSchedule `json:"schedule"`
+	Payments   []*Payment      `json:"payments"`
+	ExportedAt time.Time       `json:"exported_at"`
+}
+
+// CostOfCredit is what a loan costs the borrower over its whole term, taken from its schedule.
+// CostPercent is interest and fees as a percentage of the principal.
+type CostOfCredit struct {
+	Principal      decimal.Decimal
+	TotalRepayable decimal.Decimal
+	TotalInterest  decimal.Decimal
+	TotalFees      decimal.Decimal
+	CostPercent    decimal.Decimal
+}
+
+type CostOfCreditResponse struct {
+	LoanID         string          `json:"loan_id"`
+	Principal      money.Money     `json:"principal"`
+	TotalRepayable money.Money     `json:"total_repayable"`
+	TotalInterest  money.Money     `json:"total_interest"`
+	TotalFees      money.Money     `json:"total_fees"`
+	CostPercent    decimal.Decimal `json:"cost_of_credit_percent"`
 }
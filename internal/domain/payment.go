@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/segyhp/billing-engine/pkg/money"
 	"github.com/shopspring/decimal"
 )
 
@@ -14,17 +15,110 @@ type Payment struct {
 	Amount      decimal.Decimal `json:"amount" db:"amount"`
 	PaymentDate time.Time       `json:"payment_date" db:"payment_date"`
 	WeekNumber  int             `json:"week_number" db:"week_number"`
-	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	// RecordedBy and Channel audit who took the payment and how, e.g. an agent ID and "branch"
+	RecordedBy string    `json:"recorded_by,omitempty" db:"recorded_by"`
+	Channel    string    `json:"channel,omitempty" db:"channel"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
 
 type MakePaymentRequest struct {
 	LoanID string          `json:"loan_id" validate:"required"`
 	Amount decimal.Decimal `json:"amount" validate:"required,decimal_gt=0"`
+	// PaymentDate backdates the payment; omitted means now. It may go back at most MAX_BACKDATE_DAYS.
+	PaymentDate *time.Time `json:"payment_date,omitempty"`
+	// RecordedBy and Channel come from the X-Recorded-By and X-Payment-Channel headers
+	RecordedBy string `json:"-" validate:"max=100"`
+	Channel    string `json:"-" validate:"max=50"`
 }
 
 type MakePaymentResponse struct {
-	Payment        *Payment        `json:"payment"`
-	Outstanding    decimal.Decimal `json:"outstanding"`
-	IsDelinquent   bool            `json:"is_delinquent"`
-	PaidWeekNumber int             `json:"paid_week_number"`
+	Payment        *Payment         `json:"payment"`
+	Outstanding    money.Money      `json:"outstanding"`
+	IsDelinquent   bool             `json:"is_delinquent"`
+	PaidWeekNumber int              `json:"paid_week_number"`
+	SettledWeek    *ScheduleWeek    `json:"settled_week"`
+	NextDue        *NextDueResponse `json:"next_due"`
+}
+
+// UndoPaymentResponse describes the loan after its latest payment was reversed
+type UndoPaymentResponse struct {
+	ReversedPayment    *Payment    `json:"reversed_payment"`
+	Outstanding        money.Money `json:"outstanding"`
+	IsDelinquent       bool        `json:"is_delinquent"`
+	ReopenedWeekNumber int         `json:"reopened_week_number"`
+}
+
+type ReversePaymentResponse struct {
+	LoanID            string      `json:"loan_id"`
+	ReversedPaymentID uuid.UUID   `json:"reversed_payment_id"`
+	Outstanding       money.Money `json:"outstanding"`
+}
+
+// InterestPaidSummary splits what has been paid on a loan into principal and interest
+type InterestPaidSummary struct {
+	InterestPaid  decimal.Decimal
+	PrincipalPaid decimal.Decimal
+	TotalPaid     decimal.Decimal
+}
+
+type InterestPaidResponse struct {
+	LoanID        string      `json:"loan_id"`
+	InterestPaid  money.Money `json:"interest_paid"`
+	PrincipalPaid money.Money `json:"principal_paid"`
+	TotalPaid     money.Money `json:"total_paid"`
+}
+
+// PaymentStreak counts a loan's weeks paid in full by the end of their due date. CurrentStreak
+// runs back from the latest week that is due or paid; any late or missed week resets it.
+type PaymentStreak struct {
+	LoanID        string `json:"loan_id"`
+	CurrentStreak int    `json:"current_streak"`
+	LongestStreak int    `json:"longest_streak"`
+}
+
+// PaymentFilter narrows a payment listing; zero values mean "no filter"
+type PaymentFilter struct {
+	LoanID string
+	From   *time.Time // inclusive
+	To     *time.Time // exclusive
+	Limit  int
+	Offset int
+}
+
+type PaymentListResponse struct {
+	Payments []*Payment `json:"payments"`
+	Total    int        `json:"total"`
+	Limit    int        `json:"limit"`
+	Offset   int        `json:"offset"`
+}
+
+// WeekRangePayments lists a loan's payments for schedule weeks FromWeek through ToWeek inclusive
+type WeekRangePayments struct {
+	LoanID   string     `json:"loan_id"`
+	FromWeek int        `json:"from_week"`
+	ToWeek   int        `json:"to_week"`
+	Payments []*Payment `json:"payments"`
+}
+
+// DateRangePayments lists a loan's payments with payment_date in [From, To); a nil bound is open
+type DateRangePayments struct {
+	LoanID   string     `json:"loan_id"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Payments []*Payment `json:"payments"`
+}
+
+// CollectionStats aggregates payments received in a date window
+type CollectionStats struct {
+	TotalCollected decimal.Decimal `db:"total_collected"`
+	PaymentCount   int             `db:"payment_count"`
+	LoansPaid      int             `db:"loans_paid"`
+}
+
+type CollectionsReportResponse struct {
+	From           time.Time   `json:"from"`
+	To             time.Time   `json:"to"`
+	TotalCollected money.Money `json:"total_collected"`
+	PaymentCount   int         `json:"payment_count"`
+	LoansPaid      int         `json:"loans_paid"`
 }
@@ -1,9 +1,12 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/utils"
 	"github.com/shopspring/decimal"
 )
 
@@ -14,18 +17,145 @@ const (
 	ScheduleStatusOverdue = "overdue"
 )
 
+// UnpaidScheduleStatuses are the statuses of weeks still awaiting payment. A pending week
+// becomes overdue once the scheduler sees its due date pass.
+var UnpaidScheduleStatuses = []string{ScheduleStatusPending, ScheduleStatusOverdue}
+
 // LoanSchedule represents a loan schedule entry
 type LoanSchedule struct {
-	ID         uuid.UUID       `json:"id" db:"id"`
-	LoanID     string          `json:"loan_id" db:"loan_id"`
-	WeekNumber int             `json:"week_number" db:"week_number"`
-	DueAmount  decimal.Decimal `json:"due_amount" db:"due_amount"`
-	DueDate    time.Time       `json:"due_date" db:"due_date"`
-	Status     string          `json:"status" db:"status"` // pending, paid, overdue
-	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	ID              uuid.UUID       `json:"id" db:"id"`
+	LoanID          string          `json:"loan_id" db:"loan_id"`
+	WeekNumber      int             `json:"week_number" db:"week_number"`
+	DueAmount       decimal.Decimal `json:"due_amount" db:"due_amount"` // PrincipalAmount + InterestAmount
+	PrincipalAmount decimal.Decimal `json:"principal_amount" db:"principal_amount"`
+	InterestAmount  decimal.Decimal `json:"interest_amount" db:"interest_amount"`
+	DueDate         time.Time       `json:"due_date" db:"due_date"`
+	Status          string          `json:"status" db:"status"` // pending, paid, overdue
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+}
+
+// IsPaid reports whether the week has been paid, matching case-insensitively because older
+// payments recorded the status as "PAID"
+func (s *LoanSchedule) IsPaid() bool {
+	return strings.EqualFold(s.Status, ScheduleStatusPaid)
+}
+
+// IsUnpaid reports whether the week is still awaiting payment, whether or not the scheduler
+// has marked it overdue yet
+func (s *LoanSchedule) IsUnpaid() bool {
+	return s.Status == ScheduleStatusPending || s.Status == ScheduleStatusOverdue
+}
+
+// IsOverdueAt reports whether the week is unpaid and its due date plus grace has passed at now,
+// inclusive deciding the boundary day as utils.OverdueCutoff does. It looks at the due date rather
+// than trusting a stored overdue status; paid is matched case-insensitively because older
+// payments recorded it as "PAID".
+func (s *LoanSchedule) IsOverdueAt(now time.Time, grace time.Duration, inclusive bool) bool {
+	return s.DaysLateAt(now, grace, inclusive) > 0
+}
+
+// DaysLateAt counts the whole days between the week's due date and the overdue cutoff at now,
+// zero for a paid week or one not yet overdue. With the exclusive boundary and no grace a week
+// due today isn't late; with the inclusive one it is a day late on the boundary day itself.
+func (s *LoanSchedule) DaysLateAt(now time.Time, grace time.Duration, inclusive bool) int {
+	if s.IsPaid() {
+		return 0
+	}
+	late := utils.OverdueCutoff(now, grace, inclusive).Sub(s.DueDate.Truncate(24 * time.Hour))
+	if late <= 0 {
+		return 0
+	}
+	return int(late.Hours() / 24)
+}
+
+// RemainingSummary describes what is left to pay on a loan's schedule
+type RemainingSummary struct {
+	RemainingInstallments int
+	RemainingAmount       decimal.Decimal
+	NextDueDate           *time.Time
+}
+
+// ScheduleIntegrity compares how many schedule rows a loan has with its term. A mismatch means
+// weeks are missing (e.g. a schedule insert that failed partway) or duplicated.
+type ScheduleIntegrity struct {
+	LoanID        string `json:"loan_id"`
+	DurationWeeks int    `json:"duration_weeks"`
+	ScheduleWeeks int    `json:"schedule_weeks"`
+	Consistent    bool   `json:"consistent"`
+}
+
+// OutstandingDeviation compares the outstanding balance a loan's schedule expects by now with
+// the actual one. A positive deviation means the borrower is behind schedule, a negative one
+// that they have paid ahead.
+type OutstandingDeviation struct {
+	CurrentWeek         int
+	ExpectedOutstanding decimal.Decimal
+	ActualOutstanding   decimal.Decimal
+	Deviation           decimal.Decimal
+}
+
+// NextDue is the earliest week of a loan still awaiting payment, whether pending or overdue
+type NextDue struct {
+	WeekNumber int
+	DueDate    time.Time
+	DueAmount  decimal.Decimal
+}
+
+// NextDueResponse is NextDue as shown to clients; responses carry null once a loan is fully paid
+type NextDueResponse struct {
+	WeekNumber int         `json:"week_number"`
+	DueDate    time.Time   `json:"due_date"`
+	DueAmount  money.Money `json:"due_amount"`
+}
+
+type RemainingResponse struct {
+	LoanID                string      `json:"loan_id"`
+	RemainingInstallments int         `json:"remaining_installments"`
+	RemainingAmount       money.Money `json:"remaining_amount"`
+	NextDueDate           *time.Time  `json:"next_due_date"`
+	CurrentWeek           int         `json:"current_week"`
+	ExpectedOutstanding   money.Money `json:"expected_outstanding"`
+	ActualOutstanding     money.Money `json:"actual_outstanding"`
+	Deviation             money.Money `json:"deviation"`
+}
+
+type CurrentWeekResponse struct {
+	LoanID      string `json:"loan_id"`
+	CurrentWeek int    `json:"current_week"`
+	TotalWeeks  int    `json:"total_weeks"`
 }
 
 type ScheduleResponse struct {
-	LoanID   string          `json:"loan_id"`
-	Schedule []*LoanSchedule `json:"schedule"`
+	LoanID      string          `json:"loan_id"`
+	HasSchedule bool            `json:"has_schedule"`
+	Schedule    []*ScheduleWeek `json:"schedule"`
+}
+
+// ScheduleWeek is a schedule entry with its overdue state computed when the schedule is read,
+// next to the stored status the scheduler maintains
+type ScheduleWeek struct {
+	*LoanSchedule
+	IsOverdue bool `json:"is_overdue"`
+	DaysLate  int  `json:"days_late"`
+}
+
+// ExpectedCollectionWeek totals the pending installments of open loans falling due in one week,
+// which starts on the Monday WeekStart
+type ExpectedCollectionWeek struct {
+	WeekStart      time.Time       `db:"week_start"`
+	ExpectedAmount decimal.Decimal `db:"expected_amount"`
+	Installments   int             `db:"installments"`
+}
+
+type ExpectedCollectionsWeekResponse struct {
+	WeekStart      time.Time   `json:"week_start"`
+	ExpectedAmount money.Money `json:"expected_amount"`
+	Installments   int         `json:"installments"`
+}
+
+type ExpectedCollectionsReportResponse struct {
+	From          time.Time                          `json:"from"`
+	To            time.Time                          `json:"to"`
+	TotalExpected money.Money                        `json:"total_expected"`
+	Weeks         []*ExpectedCollectionsWeekResponse `json:"weeks"`
 }
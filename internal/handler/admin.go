@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/pkg/response"
+)
+
+// LoanCloser closes every open loan whose schedule is fully paid; *scheduler.Scheduler implements it
+type LoanCloser interface {
+	CloseFullyPaidLoans(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AdminHandler serves the /api/v1/admin endpoints operations staff run by hand, such as month-end
+// clean-up that otherwise waits for the scheduler
+type AdminHandler struct {
+	cfg    config.AdminConfig
+	closer LoanCloser
+}
+
+func NewAdminHandler(cfg config.AdminConfig, closer LoanCloser) *AdminHandler {
+	return &AdminHandler{
+		cfg:    cfg,
+		closer: closer,
+	}
+}
+
+// RequireAuth guards the admin routes with basic auth; they answer 404 while no admin username
+// is configured
+func (h *AdminHandler) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.cfg.Username == "" {
+			response.NotFound(w, "Admin API is disabled")
+			return
+		}
+
+		if !basicAuthMatches(r, h.cfg.Username, h.cfg.Password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			response.Unauthorized(w, "Admin credentials required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CloseFullyPaidLoans closes every open loan whose schedule is fully paid and reports how many
+// were closed
+func (h *AdminHandler) CloseFullyPaidLoans(w http.ResponseWriter, r *http.Request) {
+	closed, err := h.closer.CloseFullyPaidLoans(r.Context(), time.Now())
+	if err != nil {
+		// Loans that failed stay open for the next run; the others are already closed
+		response.InternalServerError(w, "Failed to close fully paid loans", err)
+		return
+	}
+
+	response.Success(w, &domain.CloseFullyPaidLoansResponse{Closed: closed})
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// basicAuthMatches reports whether the request carries exactly the given basic auth credentials,
+// comparing in constant time
+func basicAuthMatches(r *http.Request, username, password string) bool {
+	gotUsername, gotPassword, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	usernameMatch := subtle.ConstantTimeCompare([]byte(gotUsername), []byte(username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare([]byte(gotPassword), []byte(password)) == 1
+	return usernameMatch && passwordMatch
+}
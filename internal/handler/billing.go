@@ -1,28 +1,55 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/metrics"
 	"github.com/segyhp/billing-engine/internal/service"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/request"
 	"github.com/segyhp/billing-engine/pkg/response"
 	"github.com/shopspring/decimal"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+	maxLoanPageLimit = 200
+)
+
+// maxGeneratedLoanIDAttempts bounds how many generated IDs one loan creation tries before giving up
+const maxGeneratedLoanIDAttempts = 3
+
+// Headers that audit who recorded a payment and through which channel
+const (
+	recordedByHeader     = "X-Recorded-By"
+	paymentChannelHeader = "X-Payment-Channel"
+)
+
 type BillingHandler struct {
 	service   service.BillingService
 	validator *validator.Validate
 	config    *config.Config
+	metrics   *metrics.Metrics
 }
 
-func NewBillingHandler(service service.BillingService, config *config.Config) *BillingHandler {
+// NewBillingHandler builds the billing API handler; metrics may be nil to record nothing
+func NewBillingHandler(service service.BillingService, config *config.Config, metrics *metrics.Metrics) *BillingHandler {
 	validate := validator.New()
 
 	// Register custom validation tags for decimal
@@ -33,13 +60,23 @@ func NewBillingHandler(service service.BillingService, config *config.Config) *B
 		service:   service,
 		validator: validate,
 		config:    config,
+		metrics:   metrics,
 	}
 }
 
 func (h *BillingHandler) CreateLoan(w http.ResponseWriter, r *http.Request) {
-	var req domain.CreateLoanRequest
+	// The schedule is embedded by default; long loans can skip it with include_schedule=false
+	includeSchedule, found, err := request.Bool(r.URL.Query(), "include_schedule")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+	if !found {
+		includeSchedule = true
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		response.BadRequest(w, "Invalid JSON payload", err)
 		return
 	}
@@ -48,40 +85,254 @@ func (h *BillingHandler) CreateLoan(w http.ResponseWriter, r *http.Request) {
 	// for testing purposes
 	// these can be overridden by request payload
 	// e.g. curl -X POST http://localhost:8080/api/v1/loans -d '{"amount":1500,"duration_weeks":30,"interest_rate":0.12, loan_id:"custom-loan-id"}' -H "Content-Type: application/json"
-	if req.Amount.IsZero() {
-		req.Amount = decimal.NewFromFloat(h.config.App.LoanAmount)
-	}
-	if req.DurationWeeks == 0 {
-		req.DurationWeeks = h.config.App.LoanDurationWeeks
-	}
-	if req.InterestRate.IsZero() {
-		req.InterestRate = decimal.NewFromFloat(h.config.App.AnnualInterestRate)
+	req, err := h.decodeCreateLoanRequest(body)
+	if err != nil {
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
 	}
-	if req.LoanID == "" {
-		timestamp := time.Now().Format("20060102_150405")
-		loanID := fmt.Sprintf("loan_%s", timestamp)
-		req.LoanID = loanID
+	generatedID := req.LoanID == "" && h.config.App.GenerateLoanID
+	if err := h.assignLoanID(r.Context(), req, ""); err != nil {
+		response.InternalServerError(w, "Failed to generate loan ID", err)
+		return
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
+	if err := h.validator.Struct(req); err != nil {
 		response.BadRequest(w, "Validation failed", err)
 		return
 	}
 
-	loan, schedule, err := h.service.CreateLoan(r.Context(), &req)
+	loan, schedule, err := h.createLoan(r.Context(), req, generatedID)
 	if err != nil {
+		if errors.Is(err, customError.ErrScheduleTooLong) {
+			response.BadRequest(w, "Loan schedule exceeds the maximum horizon", err)
+			return
+		}
+		if errors.Is(err, customError.ErrInvalidInterestRate) {
+			response.BadRequest(w, "Invalid interest rate", err)
+			return
+		}
+		var limitErr *customError.BusinessError
+		if errors.Is(err, customError.ErrLoanLimitExceeded) && errors.As(err, &limitErr) {
+			response.UnprocessableEntity(w, limitErr.Message, err)
+			return
+		}
+		if errors.Is(err, customError.ErrLoanAlreadyExists) {
+			response.Conflict(w, "Loan already exists", err)
+			return
+		}
 		response.InternalServerError(w, "Failed to create loan", err)
 		return
 	}
 
-	responseData := domain.CreateLoanResponse{
-		Loan:     loan,
-		Schedule: schedule,
+	h.metrics.LoanCreated()
+
+	responseData := domain.CreateLoanResponse{Loan: loan}
+	if includeSchedule {
+		responseData.Schedule = schedule
 	}
 
 	response.Created(w, responseData)
 }
 
+// CreateLoansBatch imports several loans at once. The whole payload is decoded and validated first,
+// so a malformed entry or a loan ID repeated within the batch rejects it before anything is created;
+// after that each loan is created or fails on its own, reported at its index.
+func (h *BillingHandler) CreateLoansBatch(w http.ResponseWriter, r *http.Request) {
+	var batch domain.BatchCreateLoanRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
+	}
+
+	if err := h.validator.Struct(&batch); err != nil {
+		response.BadRequest(w, "Validation failed", err)
+		return
+	}
+
+	requests := make([]*domain.CreateLoanRequest, len(batch.Loans))
+	firstIndex := make(map[string]int, len(batch.Loans))
+	var invalid []error
+	for i, raw := range batch.Loans {
+		req, err := h.decodeCreateLoanRequest(raw)
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("loans[%d]: %w", i, err))
+			continue
+		}
+		if err := h.assignLoanID(r.Context(), req, fmt.Sprintf("_%d", i+1)); err != nil {
+			response.InternalServerError(w, "Failed to generate loan ID", err)
+			return
+		}
+		if err := h.validator.Struct(req); err != nil {
+			invalid = append(invalid, fmt.Errorf("loans[%d]: %w", i, err))
+			continue
+		}
+		if first, ok := firstIndex[req.LoanID]; ok {
+			invalid = append(invalid, fmt.Errorf("loans[%d]: loan_id %q repeats loans[%d]", i, req.LoanID, first))
+			continue
+		}
+		firstIndex[req.LoanID] = i
+		requests[i] = req
+	}
+	if len(invalid) > 0 {
+		response.BadRequest(w, "Validation failed", errors.Join(invalid...))
+		return
+	}
+
+	results := h.service.CreateLoansBatch(r.Context(), requests)
+
+	responseData := domain.BatchCreateLoanResponse{Results: results}
+	for _, result := range results {
+		if result.Loan == nil {
+			responseData.Failed++
+			continue
+		}
+		responseData.Created++
+		h.metrics.LoanCreated()
+	}
+
+	response.Success(w, responseData)
+}
+
+// createLoan creates the loan. When its ID was generated and turns out to be taken by the time the
+// loan is inserted, e.g. by a client-supplied ID of the same form, a fresh ID is drawn and tried.
+func (h *BillingHandler) createLoan(ctx context.Context, req *domain.CreateLoanRequest, generatedID bool) (*domain.Loan, []*domain.LoanSchedule, error) {
+	for attempt := 1; ; attempt++ {
+		loan, schedule, err := h.service.CreateLoan(ctx, req)
+		if !generatedID || attempt == maxGeneratedLoanIDAttempts || !errors.Is(err, customError.ErrLoanAlreadyExists) {
+			return loan, schedule, err
+		}
+
+		req.LoanID = ""
+		if err := h.assignLoanID(ctx, req, ""); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// decodeCreateLoanRequest decodes one loan's JSON and fills in config defaults for omitted fields
+func (h *BillingHandler) decodeCreateLoanRequest(body []byte) (*domain.CreateLoanRequest, error) {
+	var req domain.CreateLoanRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+
+	// Decode again into a field map so an explicit zero can be told apart from an omitted field
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	h.applyCreateLoanDefaults(&req, fields)
+	return &req, nil
+}
+
+// assignLoanID gives a loan sent without an ID one: generated when GENERATE_LOAN_ID is on, otherwise
+// "loan_<timestamp>" followed by suffix, which keeps the loans of one batch apart
+func (h *BillingHandler) assignLoanID(ctx context.Context, req *domain.CreateLoanRequest, suffix string) error {
+	if req.LoanID != "" {
+		return nil
+	}
+
+	if h.config.App.GenerateLoanID {
+		loanID, err := h.service.GenerateLoanID(ctx)
+		if err != nil {
+			return err
+		}
+		req.LoanID = loanID
+		return nil
+	}
+
+	req.LoanID = fmt.Sprintf("loan_%s%s", time.Now().Format("20060102_150405"), suffix)
+	return nil
+}
+
+// RecomputeWeeklyPayment re-derives and stores a loan's weekly payment; only allowed before any payment
+func (h *BillingHandler) RecomputeWeeklyPayment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	loan, err := h.service.RecomputeWeeklyPayment(r.Context(), loanID)
+	if err != nil {
+		if writeLoanStatusConflict(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, customError.ErrLoanNotFound):
+			response.NotFound(w, "Loan not found")
+		case errors.Is(err, customError.ErrLoanAlreadyClosed):
+			response.Conflict(w, "Loan is not active", err)
+		case errors.Is(err, customError.ErrLoanHasPayments):
+			response.Conflict(w, "Loan already has payments", err)
+		default:
+			response.InternalServerError(w, "Failed to recompute weekly payment", err)
+		}
+		return
+	}
+
+	response.Success(w, loan)
+}
+
+// LoanExists answers HEAD requests with 200 if the loan exists and 404 otherwise, without a body
+func (h *BillingHandler) LoanExists(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.service.LoanExists(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrQueryTimeout) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetLoan returns a loan's details: amount, rate, duration, weekly payment and status
+func (h *BillingHandler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	loan, err := h.service.GetLoan(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get loan", err)
+		return
+	}
+
+	if checkNotModified(w, r, loan.UpdatedAt) {
+		return
+	}
+
+	response.Success(w, loan)
+}
+
 // GetOutstanding returns the outstanding amount for a loan
 func (h *BillingHandler) GetOutstanding(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -92,15 +343,114 @@ func (h *BillingHandler) GetOutstanding(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	outstanding, err := h.service.GetOutstanding(r.Context(), loanID)
+	// The loan's updated_at moves with every payment, so it validates the balance too and a
+	// current client copy skips the balance calculation
+	loan, err := h.service.GetLoan(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get outstanding", err)
+		return
+	}
+	if checkNotModified(w, r, loan.UpdatedAt) {
+		return
+	}
+
+	breakdown, err := h.service.GetOutstandingBreakdown(r.Context(), loanID)
 	if err != nil {
 		response.InternalServerError(w, "Failed to get outstanding", err)
 		return
 	}
 
+	totalPaid, err := h.service.GetTotalPaid(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get total paid", err)
+		return
+	}
+
+	nextDue, err := h.service.GetNextDue(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get next due payment", err)
+		return
+	}
+
+	// Interest is shown as the rounded total less the rounded principal, so the parts still add up
+	// when amounts are displayed at fewer places than they are stored at
+	total := h.displayAmount(breakdown.TotalOutstanding)
+	principal := h.displayAmount(breakdown.PrincipalOutstanding)
 	responseData := domain.OutstandingResponse{
-		LoanID:      loanID,
-		Outstanding: outstanding,
+		LoanID:               loanID,
+		Outstanding:          total,
+		PrincipalOutstanding: principal,
+		InterestOutstanding:  h.displayAmount(total.Sub(principal.Decimal)),
+		TotalOutstanding:     total,
+		TotalPaid:            h.displayAmount(totalPaid),
+		NextDue:              h.nextDueResponse(nextDue),
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetOutstandingBatch returns outstanding balances for a list of loans in one call
+func (h *BillingHandler) GetOutstandingBatch(w http.ResponseWriter, r *http.Request) {
+	var req domain.BatchOutstandingRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		response.BadRequest(w, "Validation failed", err)
+		return
+	}
+
+	outstanding, notFound, err := h.service.GetOutstandingBatch(r.Context(), req.LoanIDs)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get outstanding", err)
+		return
+	}
+
+	responseData := domain.BatchOutstandingResponse{
+		Outstanding: make(map[string]money.Money, len(outstanding)),
+		NotFound:    notFound,
+	}
+	for loanID, amount := range outstanding {
+		responseData.Outstanding[loanID] = h.displayAmount(amount)
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetDelinquencyBatch checks delinquency for a list of loans in one call
+func (h *BillingHandler) GetDelinquencyBatch(w http.ResponseWriter, r *http.Request) {
+	var req domain.BatchDelinquencyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		response.BadRequest(w, "Validation failed", err)
+		return
+	}
+
+	delinquency, notFound, err := h.service.GetDelinquencyBatch(r.Context(), req.LoanIDs)
+	if err != nil {
+		h.metrics.DelinquencyChecked(metrics.DelinquencyResultError)
+		response.InternalServerError(w, "Failed to check delinquency", err)
+		return
+	}
+	for _, status := range delinquency {
+		h.metrics.DelinquencyChecked(delinquencyResult(status.IsDelinquent))
+	}
+
+	responseData := domain.BatchDelinquencyResponse{
+		Delinquency: delinquency,
+		NotFound:    notFound,
 	}
 
 	response.Success(w, responseData)
@@ -116,23 +466,38 @@ func (h *BillingHandler) IsDelinquent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isDelinquent, err := h.service.IsDelinquent(r.Context(), loanID)
+	var (
+		status *domain.DelinquentResponse
+		asOf   *time.Time
+		err    error
+	)
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, _, parseErr := parseDateParam(value)
+		if parseErr != nil {
+			response.BadRequest(w, "Invalid as_of date", parseErr)
+			return
+		}
+		asOf = &parsed
+		status, err = h.service.GetDelinquencyStatusAsOf(r.Context(), loanID, parsed)
+	} else {
+		status, err = h.service.GetDelinquencyStatus(r.Context(), loanID)
+	}
 	if err != nil {
+		if errors.Is(err, customError.ErrInvalidAsOfDate) {
+			response.BadRequest(w, "Invalid as_of date", err)
+			return
+		}
+		h.metrics.DelinquencyChecked(metrics.DelinquencyResultError)
 		response.InternalServerError(w, "Failed to check delinquency", err)
 		return
 	}
-
-	// Calculate missed weeks by checking the service logic
-	// For now, we'll set it based on delinquency status
-	missedWeeks := 0
-	if isDelinquent {
-		missedWeeks = 2 // Minimum threshold for delinquency
-	}
+	h.metrics.DelinquencyChecked(delinquencyResult(status.IsDelinquent))
 
 	responseData := domain.DelinquentResponse{
 		LoanID:       loanID,
-		IsDelinquent: isDelinquent,
-		MissedWeeks:  missedWeeks,
+		IsDelinquent: status.IsDelinquent,
+		MissedWeeks:  status.MissedWeeks,
+		AsOf:         asOf,
 	}
 
 	response.Success(w, responseData)
@@ -157,6 +522,10 @@ func (h *BillingHandler) MakePayment(w http.ResponseWriter, r *http.Request) {
 	// Set the loan ID from URL params
 	req.LoanID = loanID
 
+	// Audit fields are set by whoever authenticated the caller, e.g. the agent portal or app gateway
+	req.RecordedBy = strings.TrimSpace(r.Header.Get(recordedByHeader))
+	req.Channel = strings.TrimSpace(r.Header.Get(paymentChannelHeader))
+
 	if err := h.validator.Struct(&req); err != nil {
 		response.BadRequest(w, "Validation failed", err)
 		return
@@ -164,9 +533,22 @@ func (h *BillingHandler) MakePayment(w http.ResponseWriter, r *http.Request) {
 
 	payment, err := h.service.MakePayment(r.Context(), req)
 	if err != nil {
-		response.InternalServerError(w, "Failed to process payment", err)
+		if writeLoanStatusConflict(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, customError.ErrInvalidPaymentDate):
+			response.BadRequest(w, "Invalid payment date", err)
+		case errors.Is(err, customError.ErrPaymentAmountTooLarge):
+			response.BadRequest(w, "Payment amount exceeds the per-transaction maximum", err)
+		case errors.Is(err, customError.ErrPaymentTooSoon):
+			response.TooManyRequests(w, "Payment submitted too soon after the previous one", err)
+		default:
+			response.InternalServerError(w, "Failed to process payment", err)
+		}
 		return
 	}
+	h.metrics.PaymentProcessed(payment.Amount.InexactFloat64())
 
 	// Get updated outstanding balance after payment
 	outstanding, err := h.service.GetOutstanding(r.Context(), loanID)
@@ -175,23 +557,942 @@ func (h *BillingHandler) MakePayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if borrower is still delinquent after payment
+	// Check if borrower is still delinquent after payment; the closing payment leaves nothing to miss
 	isDelinquent, err := h.service.IsDelinquent(r.Context(), loanID)
 	if err != nil {
 		response.InternalServerError(w, "Failed to check delinquency status", err)
 		return
 	}
 
+	nextDue, err := h.service.GetNextDue(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get next due payment", err)
+		return
+	}
+
+	settledWeek, err := h.service.GetScheduleWeek(r.Context(), loanID, payment.WeekNumber)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get settled schedule week", err)
+		return
+	}
+
 	responseData := domain.MakePaymentResponse{
 		Payment:        payment,
-		Outstanding:    outstanding,
+		Outstanding:    h.displayAmount(outstanding),
 		IsDelinquent:   isDelinquent,
 		PaidWeekNumber: payment.WeekNumber,
+		SettledWeek:    h.scheduleWeeks([]*domain.LoanSchedule{settledWeek}, time.Now())[0],
+		NextDue:        h.nextDueResponse(nextDue),
+	}
+
+	response.Success(w, responseData)
+}
+
+// UndoLatestPayment reverses the most recent payment on a loan
+func (h *BillingHandler) UndoLatestPayment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	payment, err := h.service.UndoLatestPayment(r.Context(), loanID)
+	if err != nil {
+		if writeLoanStatusConflict(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, customError.ErrLoanNotFound):
+			response.NotFound(w, "Loan not found")
+		case errors.Is(err, customError.ErrNoPaymentsToUndo):
+			response.Conflict(w, "Loan has no payments to undo", err)
+		default:
+			response.InternalServerError(w, "Failed to undo payment", err)
+		}
+		return
+	}
+
+	outstanding, err := h.service.GetOutstanding(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get outstanding balance", err)
+		return
+	}
+
+	isDelinquent, err := h.service.IsDelinquent(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to check delinquency status", err)
+		return
+	}
+
+	responseData := domain.UndoPaymentResponse{
+		ReversedPayment:    payment,
+		Outstanding:        h.displayAmount(outstanding),
+		IsDelinquent:       isDelinquent,
+		ReopenedWeekNumber: payment.WeekNumber,
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetSchedule returns the repayment schedule for a loan
+func (h *BillingHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	overdueOnly, _, err := request.Bool(r.URL.Query(), "overdue")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get schedule", err)
+		return
+	}
+
+	now := time.Now()
+	responseData := domain.ScheduleResponse{
+		LoanID:      loanID,
+		HasSchedule: len(schedule) > 0,
+	}
+	if overdueOnly {
+		schedule = h.overdueWeeks(schedule, now)
+	}
+	responseData.Schedule = h.scheduleWeeks(schedule, now)
+
+	response.Success(w, responseData)
+}
+
+// GetRemaining returns the number of unpaid installments, their total and the next due date,
+// along with how far the actual outstanding has drifted from the schedule
+func (h *BillingHandler) GetRemaining(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	summary, err := h.service.GetRemaining(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get remaining installments", err)
+		return
+	}
+
+	deviation, err := h.service.GetOutstandingDeviation(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get remaining installments", err)
+		return
+	}
+
+	responseData := domain.RemainingResponse{
+		LoanID:                loanID,
+		RemainingInstallments: summary.RemainingInstallments,
+		RemainingAmount:       h.displayAmount(summary.RemainingAmount),
+		NextDueDate:           summary.NextDueDate,
+		CurrentWeek:           deviation.CurrentWeek,
+		ExpectedOutstanding:   h.displayAmount(deviation.ExpectedOutstanding),
+		ActualOutstanding:     h.displayAmount(deviation.ActualOutstanding),
+		Deviation:             h.displayAmount(deviation.Deviation),
 	}
 
 	response.Success(w, responseData)
 }
 
+// GetCurrentWeek returns which week of its term a loan is in and how many weeks it has
+func (h *BillingHandler) GetCurrentWeek(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	currentWeek, err := h.service.GetCurrentWeek(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get current week", err)
+		return
+	}
+
+	response.Success(w, currentWeek)
+}
+
+// GetInterestPaid returns how much of what a loan's borrower has paid so far was interest
+func (h *BillingHandler) GetInterestPaid(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	summary, err := h.service.GetInterestPaid(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get interest paid", err)
+		return
+	}
+
+	responseData := domain.InterestPaidResponse{
+		LoanID:        loanID,
+		InterestPaid:  h.displayAmount(summary.InterestPaid),
+		PrincipalPaid: h.displayAmount(summary.PrincipalPaid),
+		TotalPaid:     h.displayAmount(summary.TotalPaid),
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetCostOfCredit returns the total a loan costs over its term: total repayable, interest, fees
+// and the cost of credit as a percentage of the principal
+func (h *BillingHandler) GetCostOfCredit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	cost, err := h.service.GetCostOfCredit(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get cost of credit", err)
+		return
+	}
+
+	responseData := domain.CostOfCreditResponse{
+		LoanID:         loanID,
+		Principal:      h.displayAmount(cost.Principal),
+		TotalRepayable: h.displayAmount(cost.TotalRepayable),
+		TotalInterest:  h.displayAmount(cost.TotalInterest),
+		TotalFees:      h.displayAmount(cost.TotalFees),
+		CostPercent:    cost.CostPercent,
+	}
+
+	response.Success(w, responseData)
+}
+
+// CheckScheduleIntegrity reports whether a loan's schedule has one row per week of its term
+func (h *BillingHandler) CheckScheduleIntegrity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	integrity, err := h.service.CheckScheduleIntegrity(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to check schedule integrity", err)
+		return
+	}
+
+	response.Success(w, integrity)
+}
+
+// GetPaymentStreak returns a loan's current and longest runs of weeks paid on time
+func (h *BillingHandler) GetPaymentStreak(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	streak, err := h.service.GetPaymentStreak(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get payment streak", err)
+		return
+	}
+
+	response.Success(w, streak)
+}
+
+// SetForbearance stores a forbearance window during which overdue weeks don't count toward delinquency
+func (h *BillingHandler) SetForbearance(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	var req domain.ForbearanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		response.BadRequest(w, "Validation failed", err)
+		return
+	}
+
+	loan, err := h.service.SetForbearance(r.Context(), loanID, req.StartDate, req.EndDate)
+	if err != nil {
+		if writeLoanStatusConflict(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, customError.ErrLoanNotFound):
+			response.NotFound(w, "Loan not found")
+		case errors.Is(err, customError.ErrLoanAlreadyClosed):
+			response.Conflict(w, "Loan is not active", err)
+		default:
+			response.InternalServerError(w, "Failed to set forbearance", err)
+		}
+		return
+	}
+
+	response.Success(w, loan)
+}
+
+// UpdateNotes merges a JSON object into a loan's notes; keys set to null are removed
+func (h *BillingHandler) UpdateNotes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, domain.MaxLoanNotesBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(w, http.StatusRequestEntityTooLarge, "Notes too large", err)
+			return
+		}
+		response.BadRequest(w, "Invalid JSON payload", err)
+		return
+	}
+
+	if !json.Valid(body) {
+		response.BadRequest(w, "Invalid JSON payload", nil)
+		return
+	}
+
+	notes, err := h.service.UpdateNotes(r.Context(), loanID, body)
+	if err != nil {
+		switch {
+		case errors.Is(err, customError.ErrLoanNotFound):
+			response.NotFound(w, "Loan not found")
+		case errors.Is(err, customError.ErrInvalidNotes):
+			response.BadRequest(w, "Invalid notes", err)
+		default:
+			response.InternalServerError(w, "Failed to update notes", err)
+		}
+		return
+	}
+
+	responseData := domain.LoanNotesResponse{
+		LoanID: loanID,
+		Notes:  notes,
+	}
+
+	response.Success(w, responseData)
+}
+
+// ExportLoan returns the loan, its full schedule and all payments as one JSON document
+func (h *BillingHandler) ExportLoan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	export, err := h.service.ExportLoan(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to export loan", err)
+		return
+	}
+
+	// Served as a download; the document is encoded directly onto the response writer
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, url.PathEscape(loanID)))
+	response.Success(w, export)
+}
+
+// GetDelinquencyHistory returns weekly delinquency snapshots for a loan
+func (h *BillingHandler) GetDelinquencyHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	history, err := h.service.GetDelinquencyHistory(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get delinquency history", err)
+		return
+	}
+
+	responseData := domain.DelinquencyHistoryResponse{
+		LoanID:  loanID,
+		History: history,
+	}
+
+	response.Success(w, responseData)
+}
+
+// ListPayments returns payments across all loans, optionally filtered by loan and payment date range.
+// Query params: loan_id, from, to (RFC3339 or YYYY-MM-DD; a bare "to" date includes that whole day),
+// limit (1-100, default 50) and offset.
+func (h *BillingHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := domain.PaymentFilter{
+		LoanID: query.Get("loan_id"),
+	}
+
+	from, to, ok := parseDateRange(w, query)
+	if !ok {
+		return
+	}
+	filter.From = from
+	filter.To = to
+
+	filter.Limit, filter.Offset, ok = parsePagination(w, query, maxPageLimit)
+	if !ok {
+		return
+	}
+
+	payments, total, err := h.service.ListPayments(r.Context(), filter)
+	if err != nil {
+		response.InternalServerError(w, "Failed to list payments", err)
+		return
+	}
+
+	responseData := domain.PaymentListResponse{
+		Payments: payments,
+		Total:    total,
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetLoanPayments returns a loan's payments for a range of schedule weeks or payment dates.
+// Query params: from_week (default 1) and to_week (default the loan's last week), both inclusive;
+// or from and to (RFC3339 or YYYY-MM-DD; a bare "to" date includes that whole day). The two
+// kinds of range can't be combined.
+func (h *BillingHandler) GetLoanPayments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	query := r.URL.Query()
+
+	if query.Has("from") || query.Has("to") {
+		if query.Has("from_week") || query.Has("to_week") {
+			response.BadRequest(w, "Use either from/to or from_week/to_week, not both", nil)
+			return
+		}
+		h.getLoanPaymentsByDate(w, r, loanID)
+		return
+	}
+
+	fromWeek, found, err := request.Int(query, "from_week")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+	if !found {
+		fromWeek = 1
+	}
+
+	toWeek, found, err := request.Int(query, "to_week")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	if fromWeek < 1 || (found && toWeek < 1) {
+		response.BadRequest(w, "from_week and to_week must be positive integers", nil)
+		return
+	}
+	if found && fromWeek > toWeek {
+		response.BadRequest(w, "from_week must not be after to_week", nil)
+		return
+	}
+
+	payments, err := h.service.GetPaymentsByWeekRange(r.Context(), loanID, fromWeek, toWeek)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get payments", err)
+		return
+	}
+
+	response.Success(w, payments)
+}
+
+// getLoanPaymentsByDate serves GetLoanPayments when it's filtered by payment date
+func (h *BillingHandler) getLoanPaymentsByDate(w http.ResponseWriter, r *http.Request, loanID string) {
+	from, to, ok := parseDateRange(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	payments, err := h.service.GetPaymentsByDateRange(r.Context(), loanID, from, to)
+	if err != nil {
+		if errors.Is(err, customError.ErrLoanNotFound) {
+			response.NotFound(w, "Loan not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get payments", err)
+		return
+	}
+
+	response.Success(w, payments)
+}
+
+// ListLoans returns live loans, newest first, optionally filtered by tag and status.
+// Query params: tag, status, limit (1-200, default 50) and offset.
+func (h *BillingHandler) ListLoans(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := domain.LoanFilter{
+		Tag:    strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+		Status: strings.ToLower(strings.TrimSpace(query.Get("status"))),
+	}
+	if filter.Status != "" && !domain.IsValidLoanStatus(filter.Status) {
+		response.BadRequest(w, fmt.Sprintf("Invalid query parameter status: unknown loan status %q", filter.Status), nil)
+		return
+	}
+
+	var ok bool
+	filter.Limit, filter.Offset, ok = parsePagination(w, query, maxLoanPageLimit)
+	if !ok {
+		return
+	}
+
+	loans, total, err := h.service.ListLoans(r.Context(), filter)
+	if err != nil {
+		response.InternalServerError(w, "Failed to list loans", err)
+		return
+	}
+
+	responseData := domain.LoanListResponse{
+		Loans:  loans,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetBorrowerLoans lists a borrower's loans with their statuses; a borrower without loans gets an empty list
+func (h *BillingHandler) GetBorrowerLoans(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	borrowerID := strings.TrimSpace(vars["borrowerId"])
+
+	if borrowerID == "" {
+		response.BadRequest(w, "Borrower ID is required", nil)
+		return
+	}
+
+	loans, err := h.service.GetBorrowerLoans(r.Context(), borrowerID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get borrower loans", err)
+		return
+	}
+
+	responseData := domain.BorrowerLoansResponse{
+		BorrowerID: borrowerID,
+		Loans:      loans,
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetPayment returns a single payment by ID
+func (h *BillingHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	paymentID, err := uuid.Parse(vars["paymentId"])
+	if err != nil {
+		response.BadRequest(w, "Invalid payment ID", err)
+		return
+	}
+
+	payment, err := h.service.GetPayment(r.Context(), paymentID)
+	if err != nil {
+		if errors.Is(err, customError.ErrPaymentNotFound) {
+			response.NotFound(w, "Payment not found")
+			return
+		}
+		response.InternalServerError(w, "Failed to get payment", err)
+		return
+	}
+
+	response.Success(w, payment)
+}
+
+// ReversePayment removes a payment posted in error and reopens its week
+func (h *BillingHandler) ReversePayment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID := vars["loanId"]
+
+	if loanID == "" {
+		response.BadRequest(w, "Loan ID is required", nil)
+		return
+	}
+
+	paymentID, err := uuid.Parse(vars["paymentId"])
+	if err != nil {
+		response.BadRequest(w, "Invalid payment ID", err)
+		return
+	}
+
+	if err := h.service.ReversePayment(r.Context(), loanID, paymentID); err != nil {
+		if writeLoanStatusConflict(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, customError.ErrLoanNotFound):
+			response.NotFound(w, "Loan not found")
+		case errors.Is(err, customError.ErrPaymentNotFound):
+			response.NotFound(w, "Payment not found")
+		default:
+			response.InternalServerError(w, "Failed to reverse payment", err)
+		}
+		return
+	}
+
+	outstanding, err := h.service.GetOutstanding(r.Context(), loanID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get outstanding balance", err)
+		return
+	}
+
+	responseData := domain.ReversePaymentResponse{
+		LoanID:            loanID,
+		ReversedPaymentID: paymentID,
+		Outstanding:       h.displayAmount(outstanding),
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetCollectionsReport returns how much was collected between from (inclusive) and to (exclusive).
+// Both dates are required and accept the same formats as ListPayments.
+func (h *BillingHandler) GetCollectionsReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		response.BadRequest(w, "from and to are required", nil)
+		return
+	}
+
+	from, to, ok := parseDateRange(w, query)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetCollectionStats(r.Context(), *from, *to)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get collection stats", err)
+		return
+	}
+
+	responseData := domain.CollectionsReportResponse{
+		From:           *from,
+		To:             *to,
+		TotalCollected: h.displayAmount(stats.TotalCollected),
+		PaymentCount:   stats.PaymentCount,
+		LoansPaid:      stats.LoansPaid,
+	}
+
+	response.Success(w, responseData)
+}
+
+// GetExpectedCollectionsReport returns what is left on the unpaid installments of open loans due between from
+// (inclusive) and to (exclusive), summed per week. Both dates are required and accept the same
+// formats as ListPayments.
+func (h *BillingHandler) GetExpectedCollectionsReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		response.BadRequest(w, "from and to are required", nil)
+		return
+	}
+
+	from, to, ok := parseDateRange(w, query)
+	if !ok {
+		return
+	}
+
+	weeks, err := h.service.GetExpectedCollections(r.Context(), *from, *to)
+	if err != nil {
+		response.InternalServerError(w, "Failed to get expected collections", err)
+		return
+	}
+
+	total := decimal.Zero
+	responseWeeks := make([]*domain.ExpectedCollectionsWeekResponse, 0, len(weeks))
+	for _, week := range weeks {
+		total = total.Add(week.ExpectedAmount)
+		responseWeeks = append(responseWeeks, &domain.ExpectedCollectionsWeekResponse{
+			WeekStart:      week.WeekStart,
+			ExpectedAmount: h.displayAmount(week.ExpectedAmount),
+			Installments:   week.Installments,
+		})
+	}
+
+	responseData := domain.ExpectedCollectionsReportResponse{
+		From:          *from,
+		To:            *to,
+		TotalExpected: h.displayAmount(total),
+		Weeks:         responseWeeks,
+	}
+
+	response.Success(w, responseData)
+}
+
+// applyCreateLoanDefaults fills in config defaults for fields omitted from the payload (absent or null).
+// An explicit zero is kept as sent: 0 is a valid interest rate, while a 0 amount or duration
+// is left for validation to reject instead of being silently replaced.
+func (h *BillingHandler) applyCreateLoanDefaults(req *domain.CreateLoanRequest, fields map[string]json.RawMessage) {
+	if !hasField(fields, "amount") {
+		req.Amount = decimal.NewFromFloat(h.config.App.LoanAmount)
+	}
+	if !hasField(fields, "duration_weeks") {
+		req.DurationWeeks = h.config.App.LoanDurationWeeks
+	}
+	if !hasField(fields, "interest_rate") {
+		req.InterestRate = decimal.NewFromFloat(h.config.App.AnnualInterestRate)
+	}
+}
+
+// hasField reports whether a JSON object contained the key with a non-null value
+func hasField(fields map[string]json.RawMessage, key string) bool {
+	value, ok := fields[key]
+	return ok && string(value) != "null"
+}
+
+// nextDueResponse shows a loan's next due week, or nil once the loan is fully paid
+func (h *BillingHandler) nextDueResponse(nextDue *domain.NextDue) *domain.NextDueResponse {
+	if nextDue == nil {
+		return nil
+	}
+	return &domain.NextDueResponse{
+		WeekNumber: nextDue.WeekNumber,
+		DueDate:    nextDue.DueDate,
+		DueAmount:  h.displayAmount(nextDue.DueAmount),
+	}
+}
+
+// overdueWeeks keeps the weeks that are overdue at now, judged by due date and the configured grace and boundary
+func (h *BillingHandler) overdueWeeks(schedule []*domain.LoanSchedule, now time.Time) []*domain.LoanSchedule {
+	grace, inclusive := h.config.App.OverdueGrace(), h.config.App.OverdueInclusive()
+	overdue := make([]*domain.LoanSchedule, 0, len(schedule))
+	for _, week := range schedule {
+		if week.IsOverdueAt(now, grace, inclusive) {
+			overdue = append(overdue, week)
+		}
+	}
+	return overdue
+}
+
+// displayAmount rounds an amount to the configured display precision; calculations and storage
+// keep their own precision, so this is only applied when building a response
+func (h *BillingHandler) displayAmount(amount decimal.Decimal) money.Money {
+	return money.Display(amount, h.config.App.DisplayScale())
+}
+
+// scheduleWeeks adds each week's overdue flag and days late at now, allowing the configured grace and boundary
+func (h *BillingHandler) scheduleWeeks(schedule []*domain.LoanSchedule, now time.Time) []*domain.ScheduleWeek {
+	grace, inclusive := h.config.App.OverdueGrace(), h.config.App.OverdueInclusive()
+	weeks := make([]*domain.ScheduleWeek, 0, len(schedule))
+	for _, week := range schedule {
+		daysLate := week.DaysLateAt(now, grace, inclusive)
+		weeks = append(weeks, &domain.ScheduleWeek{
+			LoanSchedule: week,
+			IsOverdue:    daysLate > 0,
+			DaysLate:     daysLate,
+		})
+	}
+	return weeks
+}
+
+// writeParamError answers a malformed query parameter with a 400 naming the parameter
+func writeParamError(w http.ResponseWriter, err error) {
+	var paramErr *request.ParamError
+	if errors.As(err, &paramErr) {
+		response.BadRequest(w, fmt.Sprintf("Invalid query parameter %s: %s", paramErr.Param, paramErr.Reason), err)
+		return
+	}
+	response.BadRequest(w, "Invalid query parameter", err)
+}
+
+// delinquencyResult is the delinquency_checks_total label for a completed check
+func delinquencyResult(isDelinquent bool) string {
+	if isDelinquent {
+		return metrics.DelinquencyResultDelinquent
+	}
+	return metrics.DelinquencyResultCurrent
+}
+
+// writeLoanStatusConflict answers 409 when an operation was refused because the loan is
+// defaulted or written off, reporting whether it wrote a response
+func writeLoanStatusConflict(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, customError.ErrLoanDefaulted):
+		response.Conflict(w, "Loan is in default", err)
+	case errors.Is(err, customError.ErrLoanWrittenOff):
+		response.Conflict(w, "Loan has been written off", err)
+	default:
+		return false
+	}
+	return true
+}
+
+// parsePagination reads the optional limit (1-maxLimit, default 50) and offset query params.
+// On invalid input it writes a 400 and returns ok=false.
+func parsePagination(w http.ResponseWriter, query url.Values, maxLimit int) (limit, offset int, ok bool) {
+	limit = defaultPageLimit
+
+	value, found, err := request.Int(query, "limit")
+	if err != nil {
+		writeParamError(w, err)
+		return 0, 0, false
+	}
+	if found {
+		if value < 1 || value > maxLimit {
+			response.BadRequest(w, fmt.Sprintf("limit must be between 1 and %d", maxLimit), nil)
+			return 0, 0, false
+		}
+		limit = value
+	}
+
+	value, found, err = request.Int(query, "offset")
+	if err != nil {
+		writeParamError(w, err)
+		return 0, 0, false
+	}
+	if found {
+		if value < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer", nil)
+			return 0, 0, false
+		}
+		offset = value
+	}
+
+	return limit, offset, true
+}
+
+// parseDateRange reads the optional from/to query params as a half-open [from, to) window.
+// A bare "to" date includes that whole day. On invalid input it writes a 400 and returns ok=false.
+func parseDateRange(w http.ResponseWriter, query url.Values) (from, to *time.Time, ok bool) {
+	if value := query.Get("from"); value != "" {
+		parsed, _, err := parseDateParam(value)
+		if err != nil {
+			response.BadRequest(w, "Invalid from date", err)
+			return nil, nil, false
+		}
+		from = &parsed
+	}
+
+	if value := query.Get("to"); value != "" {
+		parsed, dateOnly, err := parseDateParam(value)
+		if err != nil {
+			response.BadRequest(w, "Invalid to date", err)
+			return nil, nil, false
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		to = &parsed
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		response.BadRequest(w, "from must be before to", nil)
+		return nil, nil, false
+	}
+
+	return from, to, true
+}
+
+// parseDateParam parses an RFC3339 timestamp or a YYYY-MM-DD date, reporting which form was used
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %q", value)
+	}
+
+	return t, true, nil
+}
+
 // validateDecimalGt validates that decimal is greater than the parameter
 func validateDecimalGt(fl validator.FieldLevel) bool {
 	dec, ok := fl.Field().Interface().(decimal.Decimal)
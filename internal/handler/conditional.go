@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkNotModified sets ETag and Last-Modified from a loan's updated_at and answers 304 when the
+// client's cached copy is still current, reporting whether it wrote a response. If-None-Match
+// takes precedence over If-Modified-Since, as RFC 9110 requires.
+func checkNotModified(w http.ResponseWriter, r *http.Request, updatedAt time.Time) bool {
+	if updatedAt.IsZero() {
+		return false
+	}
+
+	etag := `"` + strconv.FormatInt(updatedAt.UnixNano(), 36) + `"`
+	lastModified := updatedAt.UTC().Truncate(time.Second)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	notModified := false
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		notModified = etagMatches(ifNoneMatch, etag)
+	} else if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		if since, err := http.ParseTime(ifModifiedSince); err == nil {
+			notModified = !lastModified.After(since)
+		}
+	}
+
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// etagMatches applies the weak comparison If-None-Match uses to a comma-separated header value
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -7,19 +7,32 @@ import (
 
 	"github.com/segyhp/billing-engine/pkg/response"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/redis/go-redis/v9"
 )
 
+// DBPinger is the part of *sqlx.DB the readiness check needs
+type DBPinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// RedisPinger is the part of *redis.Client the readiness check needs
+type RedisPinger interface {
+	Ping(ctx context.Context) *redis.StatusCmd
+}
+
 type HealthHandler struct {
-	db    *sqlx.DB
-	redis *redis.Client
+	db            DBPinger
+	redis         RedisPinger
+	redisRequired bool
 }
 
-func NewHealthHandler(db *sqlx.DB, redis *redis.Client) *HealthHandler {
+// NewHealthHandler builds the health handler; with redisRequired false an unreachable Redis
+// only degrades readiness instead of failing it
+func NewHealthHandler(db DBPinger, redis RedisPinger, redisRequired bool) *HealthHandler {
 	return &HealthHandler{
-		db:    db,
-		redis: redis,
+		db:            db,
+		redis:         redis,
+		redisRequired: redisRequired,
 	}
 }
 
@@ -63,8 +76,16 @@ func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
 	defer redisCancel()
 
 	if err := h.redis.Ping(redisCtx).Err(); err != nil {
-		status.Status = "error"
-		status.Checks["redis"] = "failed: " + err.Error()
+		if h.redisRequired {
+			status.Status = "error"
+			status.Checks["redis"] = "failed: " + err.Error()
+		} else {
+			// Redis is only a cache here, so keep serving without it
+			if status.Status == "ok" {
+				status.Status = "degraded"
+			}
+			status.Checks["redis"] = "degraded: " + err.Error()
+		}
 	} else {
 		status.Checks["redis"] = "ok"
 	}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/pkg/response"
+)
+
+type MetricsHandler struct {
+	cfg      config.MetricsConfig
+	networks []*net.IPNet
+	metrics  http.Handler
+}
+
+// NewMetricsHandler builds the /metrics handler serving gatherer in the Prometheus text format;
+// cfg is expected to have passed config validation
+func NewMetricsHandler(cfg config.MetricsConfig, gatherer prometheus.Gatherer) *MetricsHandler {
+	networks, _ := cfg.AllowedNetworks()
+	return &MetricsHandler{
+		cfg:      cfg,
+		networks: networks,
+		metrics:  promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+	}
+}
+
+// Metrics serves Prometheus metrics when enabled, checking the IP allowlist and basic auth first
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if !h.cfg.Enabled {
+		response.NotFound(w, "Metrics are disabled")
+		return
+	}
+
+	if !h.clientAllowed(r) {
+		response.Forbidden(w, "Metrics access denied")
+		return
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+		response.Unauthorized(w, "Metrics credentials required")
+		return
+	}
+
+	h.metrics.ServeHTTP(w, r)
+}
+
+// clientAllowed checks the connection's remote address against the allowlist.
+// Forwarding headers are ignored since any client can set them.
+func (h *MetricsHandler) clientAllowed(r *http.Request) bool {
+	if len(h.networks) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range h.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorized checks basic auth credentials when a metrics username is configured
+func (h *MetricsHandler) authorized(r *http.Request) bool {
+	if h.cfg.Username == "" {
+		return true
+	}
+	return basicAuthMatches(r, h.cfg.Username, h.cfg.Password)
+}
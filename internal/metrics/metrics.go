@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Delinquency check results recorded on delinquency_checks_total
+const (
+	DelinquencyResultDelinquent = "delinquent"
+	DelinquencyResultCurrent    = "current"
+	DelinquencyResultError      = "error"
+)
+
+// Metrics holds the loan and payment collectors. The billing service knows nothing about them;
+// handlers record each operation after the service call returns. A nil *Metrics records nothing.
+type Metrics struct {
+	loansCreated      prometheus.Counter
+	paymentsProcessed prometheus.Counter
+	paymentAmount     prometheus.Histogram
+	delinquencyChecks *prometheus.CounterVec
+	requestDuration   *prometheus.HistogramVec
+}
+
+// NewRegistry returns a registry with the Go runtime and process collectors already registered
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// New creates the collectors and registers them with registerer; call it once at startup
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		loansCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "loans_created_total",
+			Help: "Loans created.",
+		}),
+		paymentsProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "payments_processed_total",
+			Help: "Payments accepted.",
+		}),
+		paymentAmount: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "payment_amount_rupiah",
+			Help: "Accepted payment amounts in rupiah.",
+			// 10 thousand to 1 billion rupiah
+			Buckets: prometheus.ExponentialBuckets(10000, 10, 6),
+		}),
+		delinquencyChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "delinquency_checks_total",
+			Help: "Loan delinquency checks by result.",
+		}, []string{"result"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration by route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "status"}),
+	}
+
+	registerer.MustRegister(m.loansCreated, m.paymentsProcessed, m.paymentAmount, m.delinquencyChecks, m.requestDuration)
+	return m
+}
+
+// LoanCreated counts a created loan
+func (m *Metrics) LoanCreated() {
+	if m == nil {
+		return
+	}
+	m.loansCreated.Inc()
+}
+
+// PaymentProcessed counts an accepted payment and observes its amount
+func (m *Metrics) PaymentProcessed(amount float64) {
+	if m == nil {
+		return
+	}
+	m.paymentsProcessed.Inc()
+	m.paymentAmount.Observe(amount)
+}
+
+// DelinquencyChecked counts a delinquency check with its result
+func (m *Metrics) DelinquencyChecked(result string) {
+	if m == nil {
+		return
+	}
+	m.delinquencyChecks.WithLabelValues(result).Inc()
+}
+
+// Middleware observes request durations labeled by the matched route template, so loan IDs
+// in the path do not each become a label value
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		m.requestDuration.WithLabelValues(route, strconv.Itoa(recorder.statusCode)).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rec *statusRecorder) WriteHeader(statusCode int) {
+	rec.statusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
@@ -2,33 +2,108 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/shopspring/decimal"
 )
 
+// LoanQueryOptions tweaks which loans a query can see
+type LoanQueryOptions struct {
+	// IncludeDeleted also returns soft-deleted loans (admin and audit queries)
+	IncludeDeleted bool
+}
+
 // LoanRepository defines the interface for loan data operations
 type LoanRepository interface {
-	// Create creates a new loan
+	// Create creates a new loan, returning customError.ErrLoanAlreadyExists if its loan ID is taken,
+	// including by a soft-deleted loan
 	Create(ctx context.Context, loan *domain.Loan) error
 
-	// GetByLoanID retrieves a loan by its loan ID
+	// GetByLoanID retrieves a loan by its loan ID, ignoring soft-deleted loans
 	GetByLoanID(ctx context.Context, loanID string) (*domain.Loan, error)
 
+	// Exists reports whether a (non-deleted) loan with the given loan ID exists
+	Exists(ctx context.Context, loanID string) (bool, error)
+
+	// NextLoanIDSequence returns the next value of the loan ID sequence; concurrent callers never
+	// get the same value
+	NextLoanIDSequence(ctx context.Context) (int64, error)
+
+	// FindByLoanID retrieves a loan by its loan ID using the given query options
+	FindByLoanID(ctx context.Context, loanID string, opts LoanQueryOptions) (*domain.Loan, error)
+
 	// Update updates a loan
 	Update(ctx context.Context, loan *domain.Loan) error
 
-	// CreateSchedule creates loan schedule entries
+	// SetForbearance stores the forbearance window on a loan
+	SetForbearance(ctx context.Context, loanID string, start, end time.Time) error
+
+	// UpdateNotes replaces a loan's notes JSON document
+	UpdateNotes(ctx context.Context, loanID string, notes json.RawMessage) error
+
+	// Delete soft-deletes a loan, returning sql.ErrNoRows if there is no live loan with that ID
+	Delete(ctx context.Context, loanID string) error
+
+	// CreateWithSchedule creates a loan and its schedule entries in one transaction,
+	// so a failed schedule insert leaves no loan row behind; a taken loan ID fails as in Create
+	CreateWithSchedule(ctx context.Context, loan *domain.Loan, schedules []*domain.LoanSchedule) error
+
+	// CreateSchedule creates loan schedule entries; weeks that already exist for the loan are left untouched
 	CreateSchedule(ctx context.Context, schedules []*domain.LoanSchedule) error
 
 	// GetScheduleByLoanID retrieves loan schedule by loan ID
 	GetScheduleByLoanID(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error)
 
+	// GetScheduleWeek retrieves one week of a loan's schedule, returning sql.ErrNoRows if it doesn't exist
+	GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error)
+
+	// CountSchedule returns how many schedule rows a loan has without loading them
+	CountSchedule(ctx context.Context, loanID string) (int, error)
+
+	// GetEarliestUnpaidWeek retrieves the lowest-numbered pending schedule entry of a loan,
+	// returning customError.ErrNoOutstandingBalance if every week is paid
+	GetEarliestUnpaidWeek(ctx context.Context, loanID string) (*domain.LoanSchedule, error)
+
 	// UpdateScheduleStatus updates the status of a specific schedule entry
 	UpdateScheduleStatus(ctx context.Context, loanID string, weekNumber int, status string) error
 
-	// GetOverdueSchedules gets schedules that are overdue for a loan
-	GetOverdueSchedules(ctx context.Context, loanID string, currentDate time.Time) ([]*domain.LoanSchedule, error)
+	// UpdateScheduleStatuses sets the status of several schedule entries in one statement
+	UpdateScheduleStatuses(ctx context.Context, loanID string, weeks []int, status string) error
+
+	// GetOverdueSchedules gets a loan's unpaid weeks due before cutoff; pass utils.OverdueCutoff so
+	// grace and the boundary policy apply the same way as everywhere else
+	GetOverdueSchedules(ctx context.Context, loanID string, cutoff time.Time) ([]*domain.LoanSchedule, error)
+
+	// ListActiveLoanIDs returns up to limit open (active or delinquent) loan IDs ordered by loan ID, starting after afterLoanID.
+	// Pass an empty afterLoanID for the first page.
+	ListActiveLoanIDs(ctx context.Context, afterLoanID string, limit int) ([]string, error)
+
+	// MarkSchedulesOverdue flips pending weeks of open loans due before the given time to overdue,
+	// skipping weeks due inside a loan's forbearance window, and returns how many weeks changed
+	MarkSchedulesOverdue(ctx context.Context, before time.Time) (int64, error)
+
+	// List retrieves live loans matching the filter, newest first, with the total match count
+	List(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error)
+
+	// GetByBorrowerID retrieves a borrower's live loans, oldest first; an unknown borrower has none
+	GetByBorrowerID(ctx context.Context, borrowerID string) ([]*domain.Loan, error)
+
+	// GetDelinquencyByLoanIDs summarizes the overdue weeks of several loans in one query. Weeks due
+	// on or before asOf count; weeks due inside a loan's forbearance window are skipped, and a gap in
+	// the week numbers ends a run of missed weeks. Loan IDs that don't exist are absent from the result.
+	GetDelinquencyByLoanIDs(ctx context.Context, loanIDs []string, asOf time.Time) ([]*domain.LoanDelinquency, error)
+
+	// GetBalancesByLoanIDs retrieves several loans with their total payments in one query.
+	// Loan IDs that don't exist are absent from the result.
+	GetBalancesByLoanIDs(ctx context.Context, loanIDs []string) ([]*domain.LoanBalance, error)
+
+	// GetExpectedCollections sums what is left to pay on the unpaid (pending or overdue) weeks of open
+	// loans due in [from, to), grouped by the week they fall due in, oldest first. A partially paid
+	// week counts only its remainder. Weeks with nothing due are absent.
+	GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error)
 }
 
 // PaymentRepository defines the interface for payment data operations
@@ -36,12 +111,47 @@ type PaymentRepository interface {
 	// Create creates a new payment record
 	Create(ctx context.Context, payment *domain.Payment) error
 
+	// CreateAndSettleWeek records a payment and sets the status of the loan's schedule week in one
+	// transaction, returning sql.ErrNoRows (and recording nothing) if the week doesn't exist
+	CreateAndSettleWeek(ctx context.Context, payment *domain.Payment, loanID string, week int, status string) error
+
 	// GetByLoanID retrieves all payments for a loan
 	GetByLoanID(ctx context.Context, loanID string) ([]*domain.Payment, error)
 
-	// GetTotalPaid calculates total amount paid for a loan
-	GetTotalPaid(ctx context.Context, loanID string) (float64, error)
+	// GetByWeekRange retrieves a loan's payments for weeks fromWeek through toWeek inclusive,
+	// ordered by week then payment date
+	GetByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) ([]*domain.Payment, error)
+
+	// GetByLoanIDBetween retrieves a loan's payments with payment_date in [from, to), oldest first;
+	// a nil bound leaves that side open
+	GetByLoanIDBetween(ctx context.Context, loanID string, from, to *time.Time) ([]*domain.Payment, error)
+
+	// GetTotalPaid sums a loan's payments at full precision, zero when there are none
+	GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error)
 
-	// GetLatestPayment gets the most recent payment for a loan
+	// GetTotalPaidForWeek sums the payments recorded against one schedule week, zero when there are none
+	GetTotalPaidForWeek(ctx context.Context, loanID string, weekNumber int) (decimal.Decimal, error)
+
+	// GetByID retrieves a single payment, returning errors.ErrPaymentNotFound if it doesn't exist
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error)
+
+	// GetLatestPayment gets the most recently recorded payment for a loan, whatever its payment date, returning
+	// errors.ErrPaymentNotFound if it has none. Of the records one payment made across several weeks, the latest week wins.
 	GetLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error)
+
+	// List retrieves payments across loans matching the filter, newest first, with the total match count
+	List(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error)
+
+	// Delete removes a payment record, returning sql.ErrNoRows if it doesn't exist
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetCollectionStats aggregates payments with payment_date in [from, to)
+	GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error)
+}
+
+// UnitOfWork runs several repository operations in one database transaction
+type UnitOfWork interface {
+	// Do calls fn with repositories bound to a new transaction, committing if fn returns nil
+	// and rolling everything back otherwise
+	Do(ctx context.Context, fn func(loans LoanRepository, payments PaymentRepository) error) error
 }
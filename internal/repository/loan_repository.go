@@ -2,48 +2,120 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/segyhp/billing-engine/internal/domain"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// loanColumns lists the loans table columns selected into domain.Loan
+const loanColumns = `id, loan_id, borrower_id, amount, interest_rate, duration_weeks, weekly_payment, interest_only_weeks, credit_balance, rebate_amount, interest_rate_basis,
+	status, created_at, updated_at, deleted_at, forbearance_start, forbearance_end, notes, tags`
+
+// scheduleColumns lists the loan_schedule table columns selected into domain.LoanSchedule
+const scheduleColumns = `id, loan_id, week_number, due_amount, principal_amount, interest_amount, due_date, status, created_at`
+
+// loanIDConstraint is the name Postgres gives the UNIQUE constraint on loans.loan_id
+const loanIDConstraint = "loans_loan_id_key"
+
+// uniqueViolation is the Postgres error code for a broken UNIQUE constraint
+const uniqueViolation = pq.ErrorCode("23505")
+
 type loanRepository struct {
-	db *sqlx.DB
+	db DBTX
 }
 
-func NewLoanRepository(db *sqlx.DB) LoanRepository {
-	return &loanRepository{db: db}
+func NewLoanRepository(db *sqlx.DB, opts ...Option) LoanRepository {
+	return &loanRepository{db: newTimeoutDB(db, opts)}
 }
 
 func (r *loanRepository) Create(ctx context.Context, loan *domain.Loan) error {
 	query := `
-		INSERT INTO loans (id, loan_id, amount, interest_rate, duration_weeks, weekly_payment, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO loans (id, loan_id, borrower_id, amount, interest_rate, duration_weeks, weekly_payment, interest_only_weeks, interest_rate_basis, status, created_at, updated_at, tags)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
+	// A nil array would be stored as NULL rather than an empty tag list
+	tags := loan.Tags
+	if tags == nil {
+		tags = pq.StringArray{}
+	}
+
 	_, err := r.db.ExecContext(ctx, query,
 		loan.ID,
 		loan.LoanID,
+		loan.BorrowerID,
 		loan.Amount,
 		loan.InterestRate,
 		loan.DurationWeeks,
 		loan.WeeklyPayment,
+		loan.InterestOnlyWeeks,
+		loan.InterestRateBasis,
 		loan.Status,
 		loan.CreatedAt,
 		loan.UpdatedAt,
+		tags,
 	)
 
+	// Another request can insert the same loan ID between the caller's existence check and this insert
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == loanIDConstraint {
+		return customError.WrapLoanAlreadyExists(loan.LoanID)
+	}
 	return err
 }
 
+func (r *loanRepository) CreateWithSchedule(ctx context.Context, loan *domain.Loan, schedules []*domain.LoanSchedule) error {
+	return withTx(ctx, r.db, func(tx DBTX) error {
+		loans := &loanRepository{db: tx}
+		if err := loans.Create(ctx, loan); err != nil {
+			return err
+		}
+		return loans.CreateSchedule(ctx, schedules)
+	})
+}
+
 func (r *loanRepository) GetByLoanID(ctx context.Context, loanID string) (*domain.Loan, error) {
+	return r.FindByLoanID(ctx, loanID, LoanQueryOptions{})
+}
+
+func (r *loanRepository) Exists(ctx context.Context, loanID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM loans WHERE loan_id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, loanID)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *loanRepository) NextLoanIDSequence(ctx context.Context) (int64, error) {
+	var next int64
+	if err := r.db.GetContext(ctx, &next, `SELECT nextval('loan_id_seq')`); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (r *loanRepository) FindByLoanID(ctx context.Context, loanID string, opts LoanQueryOptions) (*domain.Loan, error) {
 	query := `
-		SELECT id, loan_id, amount, interest_rate, duration_weeks, weekly_payment, status, created_at, updated_at
+		SELECT ` + loanColumns + `
 		FROM loans
 		WHERE loan_id = $1
 	`
+	if !opts.IncludeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
 
 	var loan domain.Loan
 	err := r.db.GetContext(ctx, &loan, query, loanID)
@@ -57,8 +129,8 @@ func (r *loanRepository) GetByLoanID(ctx context.Context, loanID string) (*domai
 func (r *loanRepository) Update(ctx context.Context, loan *domain.Loan) error {
 	query := `
 		UPDATE loans
-		SET amount = $2, interest_rate = $3, duration_weeks = $4, weekly_payment = $5, status = $6, updated_at = $7
-		WHERE loan_id = $1
+		SET amount = $2, interest_rate = $3, duration_weeks = $4, weekly_payment = $5, status = $6, credit_balance = $7, rebate_amount = $8, updated_at = $9
+		WHERE loan_id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -68,45 +140,118 @@ func (r *loanRepository) Update(ctx context.Context, loan *domain.Loan) error {
 		loan.DurationWeeks,
 		loan.WeeklyPayment,
 		loan.Status,
+		loan.CreditBalance,
+		loan.RebateAmount,
 		time.Now(),
 	)
 
 	return err
 }
 
-func (r *loanRepository) CreateSchedule(ctx context.Context, schedules []*domain.LoanSchedule) error {
+func (r *loanRepository) SetForbearance(ctx context.Context, loanID string, start, end time.Time) error {
 	query := `
-		INSERT INTO loan_schedule (id, loan_id, week_number, due_amount, due_date, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		UPDATE loans
+		SET forbearance_start = $2, forbearance_end = $3, updated_at = $4
+		WHERE loan_id = $1 AND deleted_at IS NULL
 	`
 
-	tx, err := r.db.BeginTxx(ctx, nil)
+	result, err := r.db.ExecContext(ctx, query, loanID, start, end, time.Now())
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
-
-	for _, schedule := range schedules {
-		_, err = tx.ExecContext(ctx, query,
-			schedule.ID,
-			schedule.LoanID,
-			schedule.WeekNumber,
-			schedule.DueAmount,
-			schedule.DueDate,
-			schedule.Status,
-			schedule.CreatedAt,
-		)
-		if err != nil {
-			return err
-		}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *loanRepository) UpdateNotes(ctx context.Context, loanID string, notes json.RawMessage) error {
+	query := `
+		UPDATE loans
+		SET notes = $2::jsonb, updated_at = $3
+		WHERE loan_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, loanID, string(notes), time.Now())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
 	}
 
-	return tx.Commit()
+	return nil
+}
+
+// Delete soft-deletes a loan by stamping deleted_at; the row and its history are kept for auditing
+func (r *loanRepository) Delete(ctx context.Context, loanID string) error {
+	query := `
+		UPDATE loans
+		SET deleted_at = $2, updated_at = $2
+		WHERE loan_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, loanID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CreateSchedule skips weeks the loan already has, so retrying after a partial failure
+// never duplicates rows
+func (r *loanRepository) CreateSchedule(ctx context.Context, schedules []*domain.LoanSchedule) error {
+	query := `
+		INSERT INTO loan_schedule (id, loan_id, week_number, due_amount, principal_amount, interest_amount, due_date, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (loan_id, week_number) DO NOTHING
+	`
+
+	return withTx(ctx, r.db, func(tx DBTX) error {
+		for _, schedule := range schedules {
+			_, err := tx.ExecContext(ctx, query,
+				schedule.ID,
+				schedule.LoanID,
+				schedule.WeekNumber,
+				schedule.DueAmount,
+				schedule.PrincipalAmount,
+				schedule.InterestAmount,
+				schedule.DueDate,
+				schedule.Status,
+				schedule.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *loanRepository) GetScheduleByLoanID(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error) {
 	query := `
-		SELECT id, loan_id, week_number, due_amount, due_date, status, created_at
+		SELECT ` + scheduleColumns + `
 		FROM loan_schedule
 		WHERE loan_id = $1
 		ORDER BY week_number
@@ -121,6 +266,55 @@ func (r *loanRepository) GetScheduleByLoanID(ctx context.Context, loanID string)
 	return schedules, nil
 }
 
+func (r *loanRepository) GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM loan_schedule
+		WHERE loan_id = $1 AND week_number = $2
+	`
+
+	var schedule domain.LoanSchedule
+	err := r.db.GetContext(ctx, &schedule, query, loanID, weekNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (r *loanRepository) CountSchedule(ctx context.Context, loanID string) (int, error) {
+	query := `SELECT COUNT(*) FROM loan_schedule WHERE loan_id = $1`
+
+	var count int
+	err := r.db.GetContext(ctx, &count, query, loanID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *loanRepository) GetEarliestUnpaidWeek(ctx context.Context, loanID string) (*domain.LoanSchedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM loan_schedule
+		WHERE loan_id = $1 AND status = ANY($2)
+		ORDER BY week_number
+		LIMIT 1
+	`
+
+	var schedule domain.LoanSchedule
+	err := r.db.GetContext(ctx, &schedule, query, loanID, pq.Array(domain.UnpaidScheduleStatuses))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapNoOutstandingBalance(loanID)
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
 func (r *loanRepository) UpdateScheduleStatus(ctx context.Context, loanID string, weekNumber int, status string) error {
 	query := `
 		UPDATE loan_schedule
@@ -132,19 +326,224 @@ func (r *loanRepository) UpdateScheduleStatus(ctx context.Context, loanID string
 	return err
 }
 
-func (r *loanRepository) GetOverdueSchedules(ctx context.Context, loanID string, currentDate time.Time) ([]*domain.LoanSchedule, error) {
+func (r *loanRepository) UpdateScheduleStatuses(ctx context.Context, loanID string, weeks []int, status string) error {
 	query := `
-		SELECT id, loan_id, week_number, due_amount, due_date, status, created_at
+		UPDATE loan_schedule
+		SET status = $3
+		WHERE loan_id = $1 AND week_number = ANY($2)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, loanID, pq.Array(weeks), status)
+	return err
+}
+
+func (r *loanRepository) GetOverdueSchedules(ctx context.Context, loanID string, cutoff time.Time) ([]*domain.LoanSchedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
 		FROM loan_schedule
-		WHERE loan_id = $1 AND status = 'pending' AND due_date < $2
+		WHERE loan_id = $1 AND status = ANY($3) AND due_date < $2
 		ORDER BY week_number
 	`
 
 	var schedules []*domain.LoanSchedule
-	err := r.db.SelectContext(ctx, &schedules, query, loanID, currentDate)
+	err := r.db.SelectContext(ctx, &schedules, query, loanID, cutoff, pq.Array(domain.UnpaidScheduleStatuses))
 	if err != nil {
 		return nil, err
 	}
 
 	return schedules, nil
 }
+
+func (r *loanRepository) ListActiveLoanIDs(ctx context.Context, afterLoanID string, limit int) ([]string, error) {
+	query := `
+		SELECT loan_id
+		FROM loans
+		WHERE status = ANY($1) AND deleted_at IS NULL AND loan_id > $2
+		ORDER BY loan_id
+		LIMIT $3
+	`
+
+	var loanIDs []string
+	err := r.db.SelectContext(ctx, &loanIDs, query, pq.Array(domain.OpenLoanStatuses), afterLoanID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return loanIDs, nil
+}
+
+func (r *loanRepository) MarkSchedulesOverdue(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		UPDATE loan_schedule s
+		SET status = $1
+		FROM loans l
+		WHERE l.loan_id = s.loan_id AND l.status = ANY($2) AND l.deleted_at IS NULL
+			AND s.status = $3 AND s.due_date < $4
+			AND NOT (l.forbearance_start IS NOT NULL AND l.forbearance_end IS NOT NULL
+				AND s.due_date BETWEEN l.forbearance_start AND l.forbearance_end)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, domain.ScheduleStatusOverdue, pq.Array(domain.OpenLoanStatuses), domain.ScheduleStatusPending, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *loanRepository) List(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(tags)", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM loans ` + where
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM loans
+		%s
+		ORDER BY created_at DESC, loan_id
+		LIMIT $%d OFFSET $%d
+	`, loanColumns, where, len(args)+1, len(args)+2)
+
+	loans := []*domain.Loan{}
+	err := r.db.SelectContext(ctx, &loans, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return loans, total, nil
+}
+
+func (r *loanRepository) GetByBorrowerID(ctx context.Context, borrowerID string) ([]*domain.Loan, error) {
+	query := `
+		SELECT ` + loanColumns + `
+		FROM loans
+		WHERE borrower_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at, loan_id
+	`
+
+	loans := []*domain.Loan{}
+	err := r.db.SelectContext(ctx, &loans, query, borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+func (r *loanRepository) GetDelinquencyByLoanIDs(ctx context.Context, loanIDs []string, asOf time.Time) ([]*domain.LoanDelinquency, error) {
+	// Overdue weeks are split into runs of consecutive missed weeks (gaps and islands): among the
+	// missed and forborne weeks of a loan, the week number minus the row number is constant per run.
+	// A paid week or a gap in the week numbers starts a new run; a forborne week bridges the run
+	// around it without adding to it.
+	query := `
+		WITH due AS (
+			SELECT s.loan_id, s.week_number, s.status = ANY($2) AS missed,
+				(l.forbearance_start IS NOT NULL AND l.forbearance_end IS NOT NULL
+					AND s.due_date BETWEEN l.forbearance_start AND l.forbearance_end) AS forborne
+			FROM loan_schedule s
+			JOIN loans l ON l.loan_id = s.loan_id
+			WHERE s.loan_id = ANY($1) AND l.deleted_at IS NULL AND s.due_date <= $3
+		),
+		runs AS (
+			SELECT loan_id, week_number, missed AND NOT forborne AS missed,
+				week_number - ROW_NUMBER() OVER (PARTITION BY loan_id ORDER BY week_number) AS run
+			FROM due
+			WHERE missed OR forborne
+		),
+		streaks AS (
+			SELECT loan_id, COUNT(*) FILTER (WHERE missed) AS length, MAX(week_number) FILTER (WHERE missed) AS last_week
+			FROM runs
+			GROUP BY loan_id, run
+			HAVING COUNT(*) FILTER (WHERE missed) > 0
+		),
+		last_due AS (
+			SELECT loan_id, MAX(week_number) AS last_week
+			FROM due
+			WHERE NOT forborne
+			GROUP BY loan_id
+		)
+		SELECT l.loan_id, l.status,
+			COALESCE(MAX(st.length) FILTER (WHERE st.last_week = ld.last_week), 0) AS missed_weeks,
+			COALESCE(MAX(st.length), 0) AS longest_missed_streak
+		FROM loans l
+		LEFT JOIN last_due ld ON ld.loan_id = l.loan_id
+		LEFT JOIN streaks st ON st.loan_id = l.loan_id
+		WHERE l.loan_id = ANY($1) AND l.deleted_at IS NULL
+		GROUP BY l.loan_id, l.status
+	`
+
+	var delinquencies []*domain.LoanDelinquency
+	err := r.db.SelectContext(ctx, &delinquencies, query, pq.Array(loanIDs), pq.Array(domain.UnpaidScheduleStatuses), asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return delinquencies, nil
+}
+
+func (r *loanRepository) GetBalancesByLoanIDs(ctx context.Context, loanIDs []string) ([]*domain.LoanBalance, error) {
+	query := `
+		SELECT l.id, l.loan_id, l.borrower_id, l.amount, l.interest_rate, l.duration_weeks, l.weekly_payment, l.interest_only_weeks, l.credit_balance, l.rebate_amount, l.status,
+			l.created_at, l.updated_at, l.deleted_at, l.forbearance_start, l.forbearance_end, l.notes, l.tags,
+			COALESCE(p.total_paid, 0) AS total_paid
+		FROM loans l
+		LEFT JOIN (
+			SELECT loan_id, SUM(amount) AS total_paid
+			FROM payments
+			WHERE loan_id = ANY($1)
+			GROUP BY loan_id
+		) p ON p.loan_id = l.loan_id
+		WHERE l.loan_id = ANY($1) AND l.deleted_at IS NULL
+	`
+
+	var balances []*domain.LoanBalance
+	err := r.db.SelectContext(ctx, &balances, query, pq.Array(loanIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (r *loanRepository) GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error) {
+	query := `
+		SELECT date_trunc('week', s.due_date)::date AS week_start,
+			SUM(s.due_amount - COALESCE(p.total_paid, 0)) AS expected_amount,
+			COUNT(*) AS installments
+		FROM loan_schedule s
+		JOIN loans l ON l.loan_id = s.loan_id
+		LEFT JOIN (
+			SELECT loan_id, week_number, SUM(amount) AS total_paid
+			FROM payments
+			GROUP BY loan_id, week_number
+		) p ON p.loan_id = s.loan_id AND p.week_number = s.week_number
+		WHERE l.status = ANY($1) AND l.deleted_at IS NULL
+			AND s.status = ANY($2) AND s.due_date >= $3 AND s.due_date < $4
+		GROUP BY week_start
+		ORDER BY week_start
+	`
+
+	var weeks []*domain.ExpectedCollectionWeek
+	err := r.db.SelectContext(ctx, &weeks, query, pq.Array(domain.OpenLoanStatuses), pq.Array(domain.UnpaidScheduleStatuses), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return weeks, nil
+}
@@ -2,41 +2,97 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/segyhp/billing-engine/internal/domain"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/shopspring/decimal"
 
 	"github.com/jmoiron/sqlx"
 )
 
+const paymentColumns = `id, loan_id, amount, payment_date, week_number, recorded_by, channel, created_at`
+
 type paymentRepository struct {
-	db *sqlx.DB
+	db DBTX
 }
 
-func NewPaymentRepository(db *sqlx.DB) PaymentRepository {
-	return &paymentRepository{db: db}
+func NewPaymentRepository(db *sqlx.DB, opts ...Option) PaymentRepository {
+	return &paymentRepository{db: newTimeoutDB(db, opts)}
 }
 
+// Create records a payment and bumps the loan's updated_at, which clients use to validate cached
+// loan and balance reads
 func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
 	query := `
-		INSERT INTO payments (id, loan_id, amount, payment_date, week_number, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO payments (` + paymentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		payment.ID,
-		payment.LoanID,
-		payment.Amount,
-		payment.PaymentDate,
-		payment.WeekNumber,
-		payment.CreatedAt,
-	)
+	return withTx(ctx, r.db, func(tx DBTX) error {
+		_, err := tx.ExecContext(ctx, query,
+			payment.ID,
+			payment.LoanID,
+			payment.Amount,
+			payment.PaymentDate,
+			payment.WeekNumber,
+			payment.RecordedBy,
+			payment.Channel,
+			payment.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		return touchLoan(ctx, tx, payment.LoanID)
+	})
+}
+
+// touchLoan bumps a loan's updated_at after its payments change
+func touchLoan(ctx context.Context, db DBTX, loanID string) error {
+	query := `UPDATE loans SET updated_at = $2 WHERE loan_id = $1`
 
+	_, err := db.ExecContext(ctx, query, loanID, time.Now())
 	return err
 }
 
+func (r *paymentRepository) CreateAndSettleWeek(ctx context.Context, payment *domain.Payment, loanID string, week int, status string) error {
+	query := `
+		UPDATE loan_schedule
+		SET status = $3
+		WHERE loan_id = $1 AND week_number = $2
+	`
+
+	return withTx(ctx, r.db, func(tx DBTX) error {
+		if err := (&paymentRepository{db: tx}).Create(ctx, payment); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, query, loanID, week, status)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
 func (r *paymentRepository) GetByLoanID(ctx context.Context, loanID string) ([]*domain.Payment, error) {
 	query := `
-		SELECT id, loan_id, amount, payment_date, week_number, created_at
+		SELECT ` + paymentColumns + `
 		FROM payments
 		WHERE loan_id = $1
 		ORDER BY payment_date DESC
@@ -51,17 +107,79 @@ func (r *paymentRepository) GetByLoanID(ctx context.Context, loanID string) ([]*
 	return payments, nil
 }
 
-func (r *paymentRepository) GetTotalPaid(ctx context.Context, loanID string) (float64, error) {
+func (r *paymentRepository) GetByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) ([]*domain.Payment, error) {
 	query := `
-		SELECT COALESCE(SUM(amount), 0) as total_paid
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE loan_id = $1 AND week_number BETWEEN $2 AND $3
+		ORDER BY week_number, payment_date, created_at
+	`
+
+	payments := []*domain.Payment{}
+	err := r.db.SelectContext(ctx, &payments, query, loanID, fromWeek, toWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (r *paymentRepository) GetByLoanIDBetween(ctx context.Context, loanID string, from, to *time.Time) ([]*domain.Payment, error) {
+	conditions := []string{"loan_id = $1"}
+	args := []interface{}{loanID}
+
+	if from != nil {
+		args = append(args, *from)
+		conditions = append(conditions, fmt.Sprintf("payment_date >= $%d", len(args)))
+	}
+	if to != nil {
+		args = append(args, *to)
+		conditions = append(conditions, fmt.Sprintf("payment_date < $%d", len(args)))
+	}
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY payment_date, created_at
+	`
+
+	payments := []*domain.Payment{}
+	err := r.db.SelectContext(ctx, &payments, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (r *paymentRepository) GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) AS total_paid
 		FROM payments
 		WHERE loan_id = $1
 	`
 
-	var totalPaid float64
+	var totalPaid decimal.Decimal
 	err := r.db.GetContext(ctx, &totalPaid, query, loanID)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
+	}
+
+	return totalPaid, nil
+}
+
+func (r *paymentRepository) GetTotalPaidForWeek(ctx context.Context, loanID string, weekNumber int) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) AS total_paid
+		FROM payments
+		WHERE loan_id = $1 AND week_number = $2
+	`
+
+	var totalPaid decimal.Decimal
+	err := r.db.GetContext(ctx, &totalPaid, query, loanID, weekNumber)
+	if err != nil {
+		return decimal.Zero, err
 	}
 
 	return totalPaid, nil
@@ -69,18 +187,119 @@ func (r *paymentRepository) GetTotalPaid(ctx context.Context, loanID string) (fl
 
 func (r *paymentRepository) GetLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error) {
 	query := `
-		SELECT id, loan_id, amount, payment_date, week_number, created_at
+		SELECT ` + paymentColumns + `
 		FROM payments
 		WHERE loan_id = $1
-		ORDER BY payment_date DESC, created_at DESC
+		ORDER BY created_at DESC, week_number DESC
 		LIMIT 1
 	`
 
 	var payment domain.Payment
 	err := r.db.GetContext(ctx, &payment, query, loanID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLatestPaymentNotFound(loanID)
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE id = $1
+	`
+
+	var payment domain.Payment
+	err := r.db.GetContext(ctx, &payment, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapPaymentNotFound(id.String())
+		}
 		return nil, err
 	}
 
 	return &payment, nil
 }
+
+func (r *paymentRepository) List(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if filter.LoanID != "" {
+		args = append(args, filter.LoanID)
+		conditions = append(conditions, fmt.Sprintf("loan_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("payment_date >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("payment_date < $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM payments ` + where
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+paymentColumns+`
+		FROM payments
+		%s
+		ORDER BY payment_date DESC, created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	payments := []*domain.Payment{}
+	err := r.db.SelectContext(ctx, &payments, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payments, total, nil
+}
+
+// Delete removes a payment and bumps its loan's updated_at; a missing payment is sql.ErrNoRows
+func (r *paymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM payments WHERE id = $1 RETURNING loan_id`
+
+	return withTx(ctx, r.db, func(tx DBTX) error {
+		var loanID string
+		if err := tx.GetContext(ctx, &loanID, query, id); err != nil {
+			return err
+		}
+
+		return touchLoan(ctx, tx, loanID)
+	})
+}
+
+func (r *paymentRepository) GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) AS total_collected,
+			COUNT(*) AS payment_count,
+			COUNT(DISTINCT loan_id) AS loans_paid
+		FROM payments
+		WHERE payment_date >= $1 AND payment_date < $2
+	`
+
+	var stats domain.CollectionStats
+	err := r.db.GetContext(ctx, &stats, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
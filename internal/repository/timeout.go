@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+)
+
+// Option configures the repositories and unit of work
+type Option func(*timeoutDB)
+
+// WithQueryTimeout bounds every statement by timeout on top of the caller's deadline, so one slow
+// query can't hold a request past the server's write timeout; zero leaves statements unbounded
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(db *timeoutDB) {
+		db.timeout = timeout
+	}
+}
+
+// timeoutDB runs each statement under its own deadline and reports a statement that ran out of
+// time, whether its own or the caller's, as customError.ErrQueryTimeout
+type timeoutDB struct {
+	db      DBTX
+	timeout time.Duration
+}
+
+func newTimeoutDB(db DBTX, opts []Option) *timeoutDB {
+	t := &timeoutDB{db: db}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// on returns a timeoutDB with the same settings running statements on db, such as a transaction
+func (t *timeoutDB) on(db DBTX) *timeoutDB {
+	return &timeoutDB{db: db, timeout: t.timeout}
+}
+
+func (t *timeoutDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := t.queryContext(ctx)
+	defer cancel()
+
+	result, err := t.db.ExecContext(ctx, query, args...)
+	return result, queryError(ctx, err)
+}
+
+func (t *timeoutDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := t.queryContext(ctx)
+	defer cancel()
+
+	return queryError(ctx, t.db.GetContext(ctx, dest, query, args...))
+}
+
+func (t *timeoutDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := t.queryContext(ctx)
+	defer cancel()
+
+	return queryError(ctx, t.db.SelectContext(ctx, dest, query, args...))
+}
+
+func (t *timeoutDB) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+// queryError wraps err as a query timeout when it came from ctx running out of time. The driver
+// reports a statement cancelled mid-flight as its own error rather than the context's, so the
+// context is checked instead of err.
+func queryError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return customError.WrapQueryTimeout(err)
+	}
+	return err
+}
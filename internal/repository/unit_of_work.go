@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX is the query surface shared by *sqlx.DB and *sqlx.Tx, so a repository works the same
+// whether it runs on the pool or inside a unit of work
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+type unitOfWork struct {
+	db *timeoutDB
+}
+
+func NewUnitOfWork(db *sqlx.DB, opts ...Option) UnitOfWork {
+	return &unitOfWork{db: newTimeoutDB(db, opts)}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(loans LoanRepository, payments PaymentRepository) error) error {
+	pool, ok := u.db.db.(*sqlx.DB)
+	if !ok {
+		return fmt.Errorf("unit of work needs a database pool to begin a transaction, got %T", u.db.db)
+	}
+
+	tx, err := pool.BeginTxx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	conn := u.db.on(tx)
+	if err := fn(&loanRepository{db: conn}, &paymentRepository{db: conn}); err != nil {
+		return err
+	}
+
+	return queryError(ctx, tx.Commit())
+}
+
+// withTx runs fn in a transaction of its own, or directly when db is already a transaction
+// so the work joins the surrounding unit of work
+func withTx(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	conn, ok := db.(*timeoutDB)
+	if !ok {
+		return fmt.Errorf("repository connection %T can't run a transaction", db)
+	}
+	pool, ok := conn.db.(*sqlx.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := pool.BeginTxx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(conn.on(tx)); err != nil {
+		return err
+	}
+
+	return queryError(ctx, tx.Commit())
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/cache"
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/internal/service"
+)
+
+// CacheWarmer periodically loads the outstanding balance and its breakdown for every active loan
+// into the cache, so the first read after an entry expires doesn't pay for a cold cache
+type CacheWarmer struct {
+	jobs     *Scheduler
+	service  service.BillingService
+	cache    *cache.Cache
+	interval time.Duration
+}
+
+// NewCacheWarmer computes what it warms from the repositories on every cycle. Its billing service has
+// no cache of its own, since reading through the cache would rewrite stale entries with a fresh TTL.
+func NewCacheWarmer(jobs *Scheduler, loanRepo repository.LoanRepository, paymentRepo repository.PaymentRepository, loanCache *cache.Cache, cfg *config.Config) *CacheWarmer {
+	return &CacheWarmer{
+		jobs:     jobs,
+		service:  service.NewBillingService(loanRepo, paymentRepo, nil, nil, cfg),
+		cache:    loanCache,
+		interval: cfg.Redis.CacheWarmerInterval,
+	}
+}
+
+// Run warms the cache straight away and then every interval until ctx is cancelled
+func (w *CacheWarmer) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Warm(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Cache warm cycle finished with errors: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Warm runs one cycle over every active loan; a loan that fails doesn't stop the others
+func (w *CacheWarmer) Warm(ctx context.Context) error {
+	return w.jobs.ForEachActiveLoan(ctx, func(ctx context.Context, loanID string) error {
+		outstanding, err := w.service.GetOutstanding(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		if err := w.cache.SetOutstanding(ctx, loanID, outstanding); err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+
+		breakdown, err := w.service.GetOutstandingBreakdown(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		if err := w.cache.SetOutstandingBreakdown(ctx, loanID, breakdown); err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/pkg/utils"
+)
+
+// OverdueRun counts what one overdue update changed
+type OverdueRun struct {
+	SchedulesMarked  int64
+	LoansDelinquent  int64
+	LoansDefaulted   int64
+	LoansReactivated int64
+}
+
+// UpdateOverduePayments marks pending weeks whose due date plus grace has passed as overdue (see
+// utils.OverdueCutoff for the boundary day), then
+// moves each open loan to the status its current run of missed weeks calls for: delinquent at the
+// delinquency threshold, default at the default threshold, and back to active once a delinquent
+// borrower catches up. Loans already in the right status are left alone, so rerunning it the same
+// day changes nothing. A loan that fails doesn't stop the others.
+func (s *Scheduler) UpdateOverduePayments(ctx context.Context, now time.Time) (*OverdueRun, error) {
+	cutoff := utils.OverdueCutoff(now, s.overdueGrace, s.overdueInclusive)
+
+	marked, err := s.loanRepo.MarkSchedulesOverdue(ctx, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("mark overdue weeks: %w", err)
+	}
+
+	run := &OverdueRun{SchedulesMarked: marked}
+	if s.delinquentWeeks <= 0 && s.defaultWeeks <= 0 {
+		return run, nil
+	}
+
+	// Due dates are whole days, so weeks due on or before the previous day are the overdue ones
+	asOf := cutoff.AddDate(0, 0, -1)
+
+	var delinquent, defaulted, reactivated int64
+	err = s.ForEachActiveLoan(ctx, func(ctx context.Context, loanID string) error {
+		delinquencies, err := s.loanRepo.GetDelinquencyByLoanIDs(ctx, []string{loanID}, asOf)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		if len(delinquencies) == 0 {
+			return nil
+		}
+
+		delinquency := delinquencies[0]
+		status := domain.ProgressLoanStatus(delinquency.Status, delinquency.MissedWeeks, s.delinquentWeeks, s.defaultWeeks)
+		if status == delinquency.Status {
+			return nil
+		}
+
+		loan, err := s.loanRepo.GetByLoanID(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		// The loan may have been paid off or closed since it was listed
+		if loan.Status != delinquency.Status {
+			return nil
+		}
+
+		loan.Status = status
+		loan.UpdatedAt = now
+		if err := s.loanRepo.Update(ctx, loan); err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+
+		switch status {
+		case domain.LoanStatusDelinquent:
+			atomic.AddInt64(&delinquent, 1)
+		case domain.LoanStatusDefault:
+			atomic.AddInt64(&defaulted, 1)
+		case domain.LoanStatusActive:
+			atomic.AddInt64(&reactivated, 1)
+		}
+		return nil
+	})
+	run.LoansDelinquent = delinquent
+	run.LoansDefaulted = defaulted
+	run.LoansReactivated = reactivated
+
+	return run, err
+}
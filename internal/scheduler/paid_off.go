@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/domain"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+)
+
+// CloseFullyPaidLoans closes open loans with nothing left to pay: every week of the schedule is
+// paid. The payment that settles a loan normally closes it, so this only catches loans that slipped
+// through. A loan without any schedule rows is left alone rather than treated as paid off, and a
+// loan that fails doesn't stop the others. It returns how many loans were closed.
+func (s *Scheduler) CloseFullyPaidLoans(ctx context.Context, now time.Time) (int64, error) {
+	var closed int64
+	err := s.ForEachActiveLoan(ctx, func(ctx context.Context, loanID string) error {
+		_, err := s.loanRepo.GetEarliestUnpaidWeek(ctx, loanID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, customError.ErrNoOutstandingBalance) {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+
+		weeks, err := s.loanRepo.CountSchedule(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		if weeks == 0 {
+			return nil
+		}
+
+		loan, err := s.loanRepo.GetByLoanID(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+		// The loan may have been closed since it was listed
+		if !slices.Contains(domain.OpenLoanStatuses, loan.Status) {
+			return nil
+		}
+
+		loan.Status = domain.LoanStatusClosed
+		loan.UpdatedAt = now
+		if err := s.loanRepo.Update(ctx, loan); err != nil {
+			return fmt.Errorf("loan %s: %w", loanID, err)
+		}
+
+		atomic.AddInt64(&closed, 1)
+		return nil
+	})
+
+	return closed, err
+}
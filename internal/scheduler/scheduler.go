@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/pkg/workerpool"
+)
+
+// Scheduler runs background jobs over loans in fixed-size batches
+type Scheduler struct {
+	loanRepo         repository.LoanRepository
+	batchSize        int
+	concurrency      int
+	delinquentWeeks  int
+	defaultWeeks     int
+	overdueGrace     time.Duration
+	overdueInclusive bool
+}
+
+func NewScheduler(loanRepo repository.LoanRepository, cfg *config.Config) *Scheduler {
+	return &Scheduler{
+		loanRepo:         loanRepo,
+		batchSize:        cfg.App.SchedulerBatchSize,
+		concurrency:      cfg.BatchConcurrencyLimit(),
+		delinquentWeeks:  cfg.App.DelinquentWeeksThreshold,
+		defaultWeeks:     cfg.App.DefaultWeeksThreshold,
+		overdueGrace:     cfg.App.OverdueGrace(),
+		overdueInclusive: cfg.App.OverdueInclusive(),
+	}
+}
+
+// ForEachActiveLoan pages through open (active or delinquent) loans batchSize at a time and calls fn for every loan.
+// Loans within a batch are processed concurrently (bounded by the batch concurrency limit);
+// a failing loan doesn't stop the run and all failures are returned joined together.
+func (s *Scheduler) ForEachActiveLoan(ctx context.Context, fn func(ctx context.Context, loanID string) error) error {
+	var (
+		errs   []error
+		cursor string
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		loanIDs, err := s.loanRepo.ListActiveLoanIDs(ctx, cursor, s.batchSize)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		if len(loanIDs) == 0 {
+			break
+		}
+
+		err = workerpool.Run(ctx, s.concurrency, len(loanIDs), func(ctx context.Context, i int) error {
+			return fn(ctx, loanIDs[i])
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// A short page means there's nothing left to fetch
+		if len(loanIDs) < s.batchSize {
+			break
+		}
+		cursor = loanIDs[len(loanIDs)-1]
+	}
+
+	return errors.Join(errs...)
+}
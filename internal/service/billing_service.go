@@ -3,51 +3,130 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/segyhp/billing-engine/internal/cache"
 	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/domain"
 	"github.com/segyhp/billing-engine/internal/repository"
 	customError "github.com/segyhp/billing-engine/pkg/errors"
-	//"github.com/segyhp/billing-engine/pkg/utils"
+	"github.com/segyhp/billing-engine/pkg/logger"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/utils"
+	"github.com/segyhp/billing-engine/pkg/workerpool"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/shopspring/decimal"
 )
 
+// defaultDelinquencyThreshold is the number of consecutive missed payments that makes a borrower
+// delinquent when DELINQUENT_WEEKS_THRESHOLD is unset
+const defaultDelinquencyThreshold = 2
+
+const (
+	// loanIDPrefix marks server-generated loan IDs
+	loanIDPrefix = "LOAN-"
+	// maxLoanIDAttempts bounds how many candidate IDs are tried before giving up
+	maxLoanIDAttempts = 5
+)
+
 type billingService struct {
 	LoanRepo    repository.LoanRepository
 	PaymentRepo repository.PaymentRepository
+	UnitOfWork  repository.UnitOfWork
 	redis       *redis.Client
+	cache       *cache.Cache
 	config      *config.Config
 }
 
 type BillingService interface {
 	CreateLoan(ctx context.Context, request *domain.CreateLoanRequest) (*domain.Loan, []*domain.LoanSchedule, error)
+	CreateLoansBatch(ctx context.Context, requests []*domain.CreateLoanRequest) []*domain.BatchCreateLoanResult
+	LoanExists(ctx context.Context, loanID string) (bool, error)
+	GetLoan(ctx context.Context, loanID string) (*domain.Loan, error)
 	GetOutstanding(ctx context.Context, loanID string) (decimal.Decimal, error)
+	GetOutstandingBreakdown(ctx context.Context, loanID string) (*domain.OutstandingBreakdown, error)
+	GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error)
+	GetOutstandingBatch(ctx context.Context, loanIDs []string) (map[string]decimal.Decimal, []string, error)
 	IsDelinquent(ctx context.Context, loanID string) (bool, error)
+	IsDelinquentAsOf(ctx context.Context, loanID string, asOf time.Time) (bool, error)
+	GetDelinquencyStatus(ctx context.Context, loanID string) (*domain.DelinquentResponse, error)
+	GetDelinquencyStatusAsOf(ctx context.Context, loanID string, asOf time.Time) (*domain.DelinquentResponse, error)
 	MakePayment(ctx context.Context, request domain.MakePaymentRequest) (*domain.Payment, error)
+	GetSchedule(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error)
+	GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error)
+	CheckScheduleIntegrity(ctx context.Context, loanID string) (*domain.ScheduleIntegrity, error)
+	GetRemaining(ctx context.Context, loanID string) (*domain.RemainingSummary, error)
+	GetNextDue(ctx context.Context, loanID string) (*domain.NextDue, error)
+	GetCurrentWeek(ctx context.Context, loanID string) (*domain.CurrentWeekResponse, error)
+	GetOutstandingDeviation(ctx context.Context, loanID string) (*domain.OutstandingDeviation, error)
+	SetForbearance(ctx context.Context, loanID string, start, end time.Time) (*domain.Loan, error)
+	UpdateNotes(ctx context.Context, loanID string, patch json.RawMessage) (json.RawMessage, error)
+	GetDelinquencyHistory(ctx context.Context, loanID string) ([]*domain.DelinquencySnapshot, error)
+	ListPayments(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error)
+	GetPaymentsByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) (*domain.WeekRangePayments, error)
+	GetPaymentsByDateRange(ctx context.Context, loanID string, from, to *time.Time) (*domain.DateRangePayments, error)
+	ListLoans(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error)
+	GetBorrowerLoans(ctx context.Context, borrowerID string) ([]*domain.Loan, error)
+	GetDelinquencyBatch(ctx context.Context, loanIDs []string) (map[string]*domain.DelinquencyStatus, []string, error)
+	GetInterestPaid(ctx context.Context, loanID string) (*domain.InterestPaidSummary, error)
+	GetCostOfCredit(ctx context.Context, loanID string) (*domain.CostOfCredit, error)
+	GetPaymentStreak(ctx context.Context, loanID string) (*domain.PaymentStreak, error)
+	UndoLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error)
+	ReversePayment(ctx context.Context, loanID string, paymentID uuid.UUID) error
+	RecomputeWeeklyPayment(ctx context.Context, loanID string) (*domain.Loan, error)
+	GetPayment(ctx context.Context, paymentID uuid.UUID) (*domain.Payment, error)
+	GenerateLoanID(ctx context.Context) (string, error)
+	GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error)
+	GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error)
+	ExportLoan(ctx context.Context, loanID string) (*domain.LoanExport, error)
 }
 
 func NewBillingService(
 	loanRepo repository.LoanRepository,
 	paymentRepo repository.PaymentRepository,
+	unitOfWork repository.UnitOfWork,
 	redis *redis.Client,
 	config *config.Config,
 ) BillingService {
-	return &billingService{
+	service := &billingService{
 		LoanRepo:    loanRepo,
 		PaymentRepo: paymentRepo,
+		UnitOfWork:  unitOfWork,
 		redis:       redis,
 		config:      config,
 	}
+
+	// Outstanding balances are cached only when Redis is wired in
+	if redis != nil {
+		var ttl time.Duration
+		if config != nil {
+			ttl = config.Redis.CacheTTL
+		}
+		service.cache = cache.NewCache(redis, ttl)
+	}
+
+	return service
 }
 
 // CreateLoan creates a new loan with payment schedule
 func (s *billingService) CreateLoan(ctx context.Context, request *domain.CreateLoanRequest) (*domain.Loan, []*domain.LoanSchedule, error) {
+	// Bring the rate to the configured precision, then check the terms against the configured
+	// maximums before touching the database
+	if err := s.normalizeInterestRate(request); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkLoanLimits(request); err != nil {
+		return nil, nil, err
+	}
+
 	// Check if loan already exists
 	existingLoan, err := s.LoanRepo.GetByLoanID(ctx, request.LoanID)
 	if err == nil && existingLoan != nil {
@@ -58,28 +137,33 @@ func (s *billingService) CreateLoan(ctx context.Context, request *domain.CreateL
 		return nil, nil, customError.WrapDatabaseError(err)
 	}
 
-	// 2. Calculate weekly payment amount: (Principal + Interest) / Duration
-	totalInterest := request.Amount.Mul(request.InterestRate)
-	totalAmount := request.Amount.Add(totalInterest)
-	weeklyPayment := totalAmount.Div(decimal.NewFromInt(int64(request.DurationWeeks)))
+	// 2. Split repayments into weekly installments: (Principal + Interest) / Duration, rounded for currency,
+	// or interest only for the first InterestOnlyWeeks with principal amortizing afterwards
+	basis := s.interestRateBasis()
+	termRate := termInterestRate(basis, request.InterestRate, request.DurationWeeks)
+	installments := utils.BuildInstallments(request.Amount, termRate, request.DurationWeeks, request.InterestOnlyWeeks, s.storageScale())
 
-	// Round to 2 decimal places for currency
-	weeklyPayment = weeklyPayment.Round(2)
-
-	// 3. Create loan entity
+	// 3. Create loan entity, stamped now: the current week and as-of lookups count from CreatedAt
+	now := time.Now()
 	loan := &domain.Loan{
-		ID:            uuid.New(),
-		LoanID:        request.LoanID,
-		Amount:        request.Amount,
-		InterestRate:  request.InterestRate,
-		DurationWeeks: request.DurationWeeks,
-		WeeklyPayment: weeklyPayment,
-		Status:        domain.LoanStatusActive,
+		ID:                uuid.New(),
+		LoanID:            request.LoanID,
+		BorrowerID:        strings.TrimSpace(request.BorrowerID),
+		Amount:            request.Amount,
+		InterestRate:      request.InterestRate,
+		DurationWeeks:     request.DurationWeeks,
+		WeeklyPayment:     utils.RegularInstallment(installments, request.InterestOnlyWeeks).Total,
+		InterestOnlyWeeks: request.InterestOnlyWeeks,
+		InterestRateBasis: basis,
+		Status:            domain.LoanStatusActive,
+		Tags:              domain.NormalizeTags(request.Tags),
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	// 4. Generate payment schedule for specified weeks
 	schedules := make([]*domain.LoanSchedule, 0, request.DurationWeeks)
-	startDate := time.Now().Truncate(24 * time.Hour) // Start from today at midnight
+	startDate := now.Truncate(24 * time.Hour) // Start from today at midnight
 
 	//Assumption: Payments are due every 7 days from the start date to simplify
 	// In real-world, might need to consider weekends/holidays/business days
@@ -88,34 +172,258 @@ func (s *billingService) CreateLoan(ctx context.Context, request *domain.CreateL
 		// Calculate due date (every 7 days)
 		dueDate := startDate.AddDate(0, 0, 7*(week-1))
 
+		installment := installments[week-1]
 		schedule := &domain.LoanSchedule{
-			ID:         uuid.New(),
-			LoanID:     request.LoanID,
-			WeekNumber: week,
-			DueAmount:  weeklyPayment,
-			DueDate:    dueDate,
-			Status:     domain.ScheduleStatusPending,
+			ID:              uuid.New(),
+			LoanID:          request.LoanID,
+			WeekNumber:      week,
+			DueAmount:       installment.Total,
+			PrincipalAmount: installment.Principal,
+			InterestAmount:  installment.Interest,
+			DueDate:         dueDate,
+			Status:          domain.ScheduleStatusPending,
+			CreatedAt:       now,
 		}
 		schedules = append(schedules, schedule)
 	}
 
-	// 5. Save loan to database
-	if err = s.LoanRepo.Create(ctx, loan); err != nil {
-		return nil, nil, customError.WrapDatabaseError(err)
+	// Reject schedules whose maturity is implausibly far out
+	if err = s.checkScheduleHorizon(schedules, startDate); err != nil {
+		return nil, nil, err
 	}
 
-	// 6. Save schedule to database
-	if err = s.LoanRepo.CreateSchedule(ctx, schedules); err != nil {
-		// Rollback: try to delete the loan if schedule creation fails
-		// Note: In a real implementation, need to use database transactions in one service operation
+	// 5. Save the loan and its schedule together; a failed schedule insert rolls back the loan.
+	// A concurrent request creating the same loan ID can get past the check above, leaving the
+	// database's unique constraint to refuse this one.
+	if err = s.LoanRepo.CreateWithSchedule(ctx, loan, schedules); err != nil {
+		if errors.Is(err, customError.ErrLoanAlreadyExists) {
+			return nil, nil, err
+		}
 		return nil, nil, customError.WrapDatabaseError(err)
 	}
 
 	return loan, schedules, nil
 }
 
+// CreateLoansBatch creates each loan through CreateLoan, up to the batch concurrency limit at once,
+// and reports every loan's outcome at its index in requests. A failed loan doesn't stop the others;
+// loans not attempted because ctx ended are reported as failed with the context error.
+func (s *billingService) CreateLoansBatch(ctx context.Context, requests []*domain.CreateLoanRequest) []*domain.BatchCreateLoanResult {
+	results := make([]*domain.BatchCreateLoanResult, len(requests))
+	for i, request := range requests {
+		results[i] = &domain.BatchCreateLoanResult{Index: i, LoanID: request.LoanID}
+	}
+
+	limit := 1
+	if s.config != nil {
+		limit = s.config.BatchConcurrencyLimit()
+	}
+
+	err := workerpool.Run(ctx, limit, len(requests), func(ctx context.Context, i int) error {
+		loan, _, err := s.CreateLoan(ctx, requests[i])
+		if err != nil {
+			results[i].Code, results[i].Error = batchItemError(err)
+			return nil
+		}
+		results[i].Loan = loan
+		return nil
+	})
+	if err != nil {
+		for _, result := range results {
+			if result.Loan == nil && result.Error == "" {
+				result.Code, result.Error = batchItemError(err)
+			}
+		}
+	}
+
+	return results
+}
+
+// batchItemError splits a failed batch item's error into a code and message for the response
+func batchItemError(err error) (code, message string) {
+	var businessErr *customError.BusinessError
+	if errors.As(err, &businessErr) {
+		return businessErr.Code, businessErr.Message
+	}
+	return "", err.Error()
+}
+
+// RecomputeWeeklyPayment re-derives weekly_payment from the loan's stored amount, rate and duration
+// under the current rules. The schedule is left untouched, so it's only allowed before any payment is made.
+func (s *billingService) RecomputeWeeklyPayment(ctx context.Context, loanID string) (*domain.Loan, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	if err := requireActive(loan); err != nil {
+		return nil, err
+	}
+
+	_, err = s.PaymentRepo.GetLatestPayment(ctx, loanID)
+	if err == nil {
+		return nil, customError.WrapLoanHasPayments(loanID)
+	}
+	if !errors.Is(err, customError.ErrPaymentNotFound) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	termRate := loanTermRate(loan)
+	installments := utils.BuildInstallments(loan.Amount, termRate, loan.DurationWeeks, loan.InterestOnlyWeeks, s.storageScale())
+	loan.WeeklyPayment = utils.RegularInstallment(installments, loan.InterestOnlyWeeks).Total
+
+	if err = s.LoanRepo.Update(ctx, loan); err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return loan, nil
+}
+
+// normalizeInterestRate rounds a rate with more decimal places than the configured rate precision,
+// or rejects it under the reject policy
+func (s *billingService) normalizeInterestRate(request *domain.CreateLoanRequest) error {
+	places := int32(config.MaxRatePrecision)
+	policy := config.RatePrecisionPolicyRound
+	if s.config != nil {
+		if s.config.App.RatePrecision > 0 {
+			places = int32(s.config.App.RatePrecision)
+		}
+		if s.config.App.RatePrecisionPolicy == config.RatePrecisionPolicyReject {
+			policy = config.RatePrecisionPolicyReject
+		}
+	}
+
+	rounded := request.InterestRate.Round(places)
+	if rounded.Equal(request.InterestRate) {
+		return nil
+	}
+	if policy == config.RatePrecisionPolicyReject {
+		return customError.WrapInvalidInterestRate(
+			fmt.Sprintf("%s has more than %d decimal places", request.InterestRate.String(), places))
+	}
+
+	request.InterestRate = rounded
+	return nil
+}
+
+// checkLoanLimits rejects a loan whose amount, duration or interest rate is above its configured maximum.
+// Amount and duration are unbounded unless configured; the interest rate always has a ceiling.
+func (s *billingService) checkLoanLimits(request *domain.CreateLoanRequest) error {
+	maxRate := decimal.NewFromFloat(config.DefaultMaxInterestRate)
+	if s.config != nil && s.config.App.MaxInterestRate > 0 {
+		maxRate = decimal.NewFromFloat(s.config.App.MaxInterestRate)
+	}
+	if request.InterestRate.GreaterThan(maxRate) {
+		return customError.WrapLoanLimitExceeded("interest_rate", maxRate.String(), request.InterestRate.String())
+	}
+
+	if s.config == nil {
+		return nil
+	}
+	if s.config.App.MaxLoanAmount > 0 {
+		maxAmount := decimal.NewFromFloat(s.config.App.MaxLoanAmount)
+		if request.Amount.GreaterThan(maxAmount) {
+			return customError.WrapLoanLimitExceeded("amount", maxAmount.String(), request.Amount.String())
+		}
+	}
+	if maxWeeks := s.config.App.MaxDurationWeeks; maxWeeks > 0 && request.DurationWeeks > maxWeeks {
+		return customError.WrapLoanLimitExceeded("duration_weeks", strconv.Itoa(maxWeeks), strconv.Itoa(request.DurationWeeks))
+	}
+
+	return nil
+}
+
+// checkScheduleHorizon makes sure the last due date falls within the configured horizon from today
+func (s *billingService) checkScheduleHorizon(schedules []*domain.LoanSchedule, today time.Time) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	horizonWeeks := config.DefaultMaxScheduleHorizonWeeks
+	if s.config != nil && s.config.App.MaxScheduleHorizonWeeks > 0 {
+		horizonWeeks = s.config.App.MaxScheduleHorizonWeeks
+	}
+
+	lastDueDate := schedules[len(schedules)-1].DueDate
+	if lastDueDate.After(today.AddDate(0, 0, 7*horizonWeeks)) {
+		return customError.WrapScheduleTooLong(lastDueDate, horizonWeeks)
+	}
+
+	return nil
+}
+
+// GenerateLoanID returns a new loan ID in the configured LOAN_ID_SCHEME that no existing loan uses:
+// "LOAN-<UUIDv7>" by default, which sorts by creation time, or "LOAN-<n>" from the loan_id_seq
+// sequence. Neither scheme hands the same candidate to two concurrent callers; the existence check
+// only guards against client-supplied IDs that happen to take the same form.
+func (s *billingService) GenerateLoanID(ctx context.Context) (string, error) {
+	for attempt := 1; attempt <= maxLoanIDAttempts; attempt++ {
+		loanID, err := s.loanIDCandidate(ctx, attempt)
+		if err != nil {
+			return "", err
+		}
+
+		exists, err := s.LoanRepo.Exists(ctx, loanID)
+		if err != nil {
+			return "", customError.WrapDatabaseError(err)
+		}
+		if !exists {
+			return loanID, nil
+		}
+	}
+
+	return "", customError.WrapLoanIDGeneration(maxLoanIDAttempts)
+}
+
+// loanIDCandidate draws the attempt'th candidate ID from the configured scheme
+func (s *billingService) loanIDCandidate(ctx context.Context, attempt int) (string, error) {
+	if s.config != nil && s.config.App.LoanIDScheme == config.LoanIDSchemeSequence {
+		next, err := s.LoanRepo.NextLoanIDSequence(ctx)
+		if err != nil {
+			return "", customError.WrapDatabaseError(err)
+		}
+		return fmt.Sprintf("%s%08d", loanIDPrefix, next), nil
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", customError.WrapLoanIDGeneration(attempt)
+	}
+	return loanIDPrefix + strings.ToUpper(id.String()), nil
+}
+
+// LoanExists reports whether a loan exists without loading it
+func (s *billingService) LoanExists(ctx context.Context, loanID string) (bool, error) {
+	exists, err := s.LoanRepo.Exists(ctx, loanID)
+	if err != nil {
+		return false, customError.WrapDatabaseError(err)
+	}
+
+	return exists, nil
+}
+
+// GetLoan returns a loan's stored details
+func (s *billingService) GetLoan(ctx context.Context, loanID string) (*domain.Loan, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return loan, nil
+}
+
 // GetOutstanding calculates and returns the outstanding balance for a loan
 func (s *billingService) GetOutstanding(ctx context.Context, loanID string) (decimal.Decimal, error) {
+	if outstanding, found := s.cachedOutstanding(ctx, loanID); found {
+		return outstanding, nil
+	}
+
 	// Get loan details
 	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
 	if err != nil {
@@ -134,157 +442,1643 @@ func (s *billingService) GetOutstanding(ctx context.Context, loanID string) (dec
 		totalPayments = totalPayments.Add(payment.Amount)
 	}
 
-	// Calculate total loan amount (principal + interest)
-	totalInterest := loan.Amount.Mul(loan.InterestRate)
-	totalLoanAmount := loan.Amount.Add(totalInterest)
-
 	// Outstanding = Total Loan Amount (including interest) - Total Payments
-	outstanding := totalLoanAmount.Sub(totalPayments)
+	outstanding := s.outstandingAfter(loan, totalPayments)
+
+	if s.cache != nil {
+		if err := s.cache.SetOutstanding(ctx, loanID, outstanding); err != nil {
+			logger.FromContext(ctx).Warn("Failed to cache outstanding", "loan_id", loanID, "error", customError.WrapCacheError(err))
+		}
+	}
 
 	return outstanding, nil
 }
 
-// IsDelinquent checks if a borrower is delinquent (missed 2+ consecutive payments)
-func (s *billingService) IsDelinquent(ctx context.Context, loanID string) (bool, error) {
-	// Get loan details
-	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+// GetTotalPaid sums every payment recorded against a loan, zero when there are none
+func (s *billingService) GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error) {
+	totalPaid, err := s.PaymentRepo.GetTotalPaid(ctx, loanID)
 	if err != nil {
-		return false, customError.WrapDatabaseError(err)
+		return decimal.Zero, customError.WrapDatabaseError(err)
 	}
 
-	if loan.Status != domain.LoanStatusActive {
-		// Only active loans can be delinquent
-		return false, customError.WrapLoanAlreadyClosed(loanID)
+	return totalPaid, nil
+}
+
+// cachedOutstanding looks up a loan's outstanding balance in the cache. A cache outage is logged
+// and treated as a miss so reads fall back to the database.
+func (s *billingService) cachedOutstanding(ctx context.Context, loanID string) (decimal.Decimal, bool) {
+	if s.cache == nil {
+		return decimal.Zero, false
 	}
 
-	// Get loan schedule for the loan
-	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	outstanding, found, err := s.cache.GetOutstanding(ctx, loanID)
 	if err != nil {
-		return false, customError.WrapDatabaseError(err)
+		logger.FromContext(ctx).Warn("Failed to read cached outstanding", "loan_id", loanID, "error", customError.WrapCacheError(err))
+		return decimal.Zero, false
 	}
+	return outstanding, found
+}
 
-	// Sort schedules by due date to ensure proper order
-	sort.Slice(schedules, func(i, j int) bool {
-		return schedules[i].DueDate.Before(schedules[j].DueDate)
-	})
-
-	// Count consecutive missed payments
-	consecutiveMissed := 0
-	now := time.Now()
-	const threshold = 2 // 2 weeks threshold
+// invalidateLoanCache drops a loan's cached views after its payments change. A failure is only
+// logged: the write has already happened and the stale entry expires with the cache TTL.
+func (s *billingService) invalidateLoanCache(ctx context.Context, loanID string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.InvalidateLoan(ctx, loanID); err != nil {
+		logger.FromContext(ctx).Warn("Failed to invalidate loan cache", "loan_id", loanID, "error", customError.WrapCacheError(err))
+	}
+}
 
-	// Check which payments are overdue
-	for _, schedule := range schedules {
-		// Only check past due dates (not including today)
-		// for now we assume that timezone is not an issue
-		// In real-world, need to consider timezone differences between server and client (vary in timezone)
-		// e.g., if due date is today but time has not yet reached due time
-		if schedule.DueDate.After(now.Truncate(24 * time.Hour)) {
-			break // Don't check future payments or today's payment
+// GetOutstandingBatch returns outstanding balances for several loans along with the IDs that don't exist
+func (s *billingService) GetOutstandingBatch(ctx context.Context, loanIDs []string) (map[string]decimal.Decimal, []string, error) {
+	// Drop duplicates so each loan is reported once
+	seen := make(map[string]bool, len(loanIDs))
+	uniqueIDs := make([]string, 0, len(loanIDs))
+	for _, loanID := range loanIDs {
+		if !seen[loanID] {
+			seen[loanID] = true
+			uniqueIDs = append(uniqueIDs, loanID)
 		}
+	}
 
-		// Check if this payment is overdue (past due date and still pending)
-		if schedule.Status == domain.ScheduleStatusPending {
-			consecutiveMissed++
+	balances, err := s.LoanRepo.GetBalancesByLoanIDs(ctx, uniqueIDs)
+	if err != nil {
+		return nil, nil, customError.WrapDatabaseError(err)
+	}
 
-			// Return true if missed payments >= threshold (2 weeks)
-			if consecutiveMissed >= threshold {
-				return true, nil
-			}
-		} else if schedule.Status == domain.ScheduleStatusPaid {
-			// Reset counter when payment is made
-			consecutiveMissed = 0
+	outstanding := make(map[string]decimal.Decimal, len(balances))
+	for _, balance := range balances {
+		outstanding[balance.LoanID] = s.outstandingAfter(&balance.Loan, balance.TotalPaid)
+	}
+
+	notFound := make([]string, 0)
+	for _, loanID := range uniqueIDs {
+		if _, ok := outstanding[loanID]; !ok {
+			notFound = append(notFound, loanID)
 		}
-		// Note: We don't reset for future payments - only process overdue ones
 	}
 
-	return false, nil
+	return outstanding, notFound, nil
 }
 
-// MakePayment processes a payment for a loan
-func (s *billingService) MakePayment(ctx context.Context, request domain.MakePaymentRequest) (*domain.Payment, error) {
-	// 1. Validate payment amount
-	if request.Amount.LessThanOrEqual(decimal.Zero) {
-		invalidAmount, _ := request.Amount.Float64()
-		return nil, customError.WrapInvalidPaymentAmount(invalidAmount)
+// GetDelinquencyBatch checks delinquency for several loans with one set-based query, applying the
+// same rules as IsDelinquent. Loans that aren't active are reported as not delinquent, and unknown
+// loan IDs are returned separately.
+func (s *billingService) GetDelinquencyBatch(ctx context.Context, loanIDs []string) (map[string]*domain.DelinquencyStatus, []string, error) {
+	// Drop duplicates so each loan is reported once
+	seen := make(map[string]bool, len(loanIDs))
+	uniqueIDs := make([]string, 0, len(loanIDs))
+	for _, loanID := range loanIDs {
+		if !seen[loanID] {
+			seen[loanID] = true
+			uniqueIDs = append(uniqueIDs, loanID)
+		}
 	}
 
-	// 2. Validate loan exists and is active
-	loan, err := s.LoanRepo.GetByLoanID(ctx, request.LoanID)
+	// Like IsDelinquent, only overdue weeks count as missed: those due on or before the day
+	// before the overdue cutoff
+	asOf := s.overdueCutoff(time.Now()).AddDate(0, 0, -1)
+	delinquencies, err := s.LoanRepo.GetDelinquencyByLoanIDs(ctx, uniqueIDs, asOf)
 	if err != nil {
-		return nil, customError.WrapDatabaseError(err)
+		return nil, nil, customError.WrapDatabaseError(err)
 	}
 
-	if loan.Status != domain.LoanStatusActive {
-		return nil, customError.WrapLoanAlreadyClosed(request.LoanID)
+	statuses := make(map[string]*domain.DelinquencyStatus, len(delinquencies))
+	for _, delinquency := range delinquencies {
+		status := &domain.DelinquencyStatus{}
+		if tracksDelinquency(delinquency.Status) {
+			status.IsDelinquent = delinquency.LongestMissedStreak >= s.delinquencyThreshold()
+			status.MissedWeeks = delinquency.MissedWeeks
+		}
+		statuses[delinquency.LoanID] = status
 	}
 
-	// 3. Find the earliest unpaid week in the schedule
-	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, request.LoanID)
+	notFound := make([]string, 0)
+	for _, loanID := range uniqueIDs {
+		if _, ok := statuses[loanID]; !ok {
+			notFound = append(notFound, loanID)
+		}
+	}
+
+	return statuses, notFound, nil
+}
+
+// totalLoanAmount returns principal plus the interest charged over the loan's term,
+// less any on-time rebate granted at payoff
+func (s *billingService) totalLoanAmount(loan *domain.Loan) decimal.Decimal {
+	termRate := loanTermRate(loan)
+	return loan.Amount.Add(utils.CalculateTotalInterest(loan.Amount, termRate, s.storageScale())).Sub(loan.RebateAmount)
+}
+
+// outstandingAfter is what is left to repay on a loan once totalPaid has been paid, never below
+// zero. Anything paid beyond the total is an overpayment, kept as the loan's credit balance when
+// the closing payment is accepted.
+func (s *billingService) outstandingAfter(loan *domain.Loan, totalPaid decimal.Decimal) decimal.Decimal {
+	outstanding := s.totalLoanAmount(loan).Sub(totalPaid)
+	if outstanding.IsNegative() {
+		return decimal.Zero
+	}
+	return outstanding
+}
+
+// onTimeRebate returns the interest waived on a loan's final payment when every week, including
+// the ones being paid now, is paid by the end of its due date. It is zero when the rebate is
+// disabled or any week was late, and never exceeds the final installment.
+func (s *billingService) onTimeRebate(ctx context.Context, loan *domain.Loan, weeksToPay []*domain.LoanSchedule, now time.Time) (decimal.Decimal, error) {
+	if s.config == nil || s.config.App.OnTimeRebateRate <= 0 {
+		return decimal.Zero, nil
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loan.LoanID)
 	if err != nil {
-		return nil, customError.WrapDatabaseError(err)
+		return decimal.Zero, customError.WrapDatabaseError(err)
+	}
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loan.LoanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, customError.WrapDatabaseError(err)
+	}
+
+	// The weeks being paid now, including one partially paid earlier, are completed now
+	paidAt := completionDates(payments)
+	for _, week := range weeksToPay {
+		paidAt[week.WeekNumber] = now
 	}
 
-	// Find the earliest unpaid week
-	var earliestUnpaid *domain.LoanSchedule
 	for _, schedule := range schedules {
-		if schedule.Status == domain.ScheduleStatusPending {
-			earliestUnpaid = schedule
-			break
+		paidDate, ok := paidAt[schedule.WeekNumber]
+		if !ok {
+			// Marked paid without a payment record, so we can't tell when; assume on time
+			continue
+		}
+		cutoff := schedule.DueDate.Truncate(24*time.Hour).AddDate(0, 0, 1)
+		if !paidDate.Before(cutoff) {
+			return decimal.Zero, nil
 		}
 	}
 
-	if earliestUnpaid == nil {
-		return nil, customError.WrapNoOutstandingBalance(request.LoanID)
+	termRate := loanTermRate(loan)
+	interest := utils.CalculateTotalInterest(loan.Amount, termRate, s.storageScale())
+	rebate := interest.Mul(decimal.NewFromFloat(s.config.App.OnTimeRebateRate)).Round(s.storageScale())
+
+	finalInstallment := weeksToPay[len(weeksToPay)-1].DueAmount
+	if rebate.GreaterThan(finalInstallment) {
+		rebate = finalInstallment
 	}
+	return rebate, nil
+}
 
-	// 4. Validate payment amount matches exactly
-	if !request.Amount.Equal(loan.WeeklyPayment) {
-		invalidAmount, _ := request.Amount.Float64()
-		return nil, customError.WrapInvalidPaymentAmount(invalidAmount)
+// interestRateBasis returns the configured INTEREST_RATE_BASIS, per-term when unset
+func (s *billingService) interestRateBasis() string {
+	if s.config != nil && s.config.App.InterestRateBasis != "" {
+		return s.config.App.InterestRateBasis
 	}
+	return config.InterestRateBasisPerTerm
+}
 
-	// 5. Create payment record
-	payment := &domain.Payment{
-		ID:          uuid.New(),
-		LoanID:      request.LoanID,
-		Amount:      request.Amount,
-		PaymentDate: time.Now(),
-		WeekNumber:  earliestUnpaid.WeekNumber,
+// loanTermRate returns the flat rate charged over a loan's term, under the basis the loan was
+// created with rather than the one configured now
+func loanTermRate(loan *domain.Loan) decimal.Decimal {
+	return termInterestRate(loan.InterestRateBasis, loan.InterestRate, loan.DurationWeeks)
+}
+
+// termInterestRate returns the flat rate charged over a term of the given weeks.
+// Rates are per-term unless the basis is annual, in which case they're scaled by weeks/52.
+func termInterestRate(basis string, rate decimal.Decimal, weeks int) decimal.Decimal {
+	if basis == config.InterestRateBasisAnnual {
+		return utils.TermInterestRate(rate, weeks)
 	}
+	return rate
+}
 
-	err = s.PaymentRepo.Create(ctx, payment)
+// IsDelinquent checks if a borrower is delinquent (missed DELINQUENT_WEEKS_THRESHOLD consecutive payments),
+// moving the loan to the status its missed weeks call for along the way
+func (s *billingService) IsDelinquent(ctx context.Context, loanID string) (bool, error) {
+	status, err := s.GetDelinquencyStatus(ctx, loanID)
 	if err != nil {
-		return nil, customError.WrapDatabaseError(err)
+		return false, err
 	}
+	return status.IsDelinquent, nil
+}
 
-	// 6. Update loan schedule status for that week
-	err = s.LoanRepo.UpdateScheduleStatus(ctx, request.LoanID, earliestUnpaid.WeekNumber, "PAID")
+// IsDelinquentAsOf checks delinquency as it would stand on asOf if no further payments arrive,
+// so a future date forecasts it. asOf may not be before the day the loan was created.
+func (s *billingService) IsDelinquentAsOf(ctx context.Context, loanID string, asOf time.Time) (bool, error) {
+	status, err := s.GetDelinquencyStatusAsOf(ctx, loanID, asOf)
 	if err != nil {
-		return nil, customError.WrapDatabaseError(err)
+		return false, err
+	}
+	return status.IsDelinquent, nil
+}
+
+// GetDelinquencyStatus reports whether a borrower is delinquent today along with how many
+// overdue weeks they have missed in a row. The loan is moved to the status its missed weeks
+// call for (see domain.ProgressLoanStatus), the same transition the scheduler makes nightly.
+func (s *billingService) GetDelinquencyStatus(ctx context.Context, loanID string) (*domain.DelinquentResponse, error) {
+	loan, status, err := s.delinquencyStatusAsOf(ctx, loanID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.progressLoanStatus(ctx, loan, status.MissedWeeks)
+	return status, nil
+}
+
+// GetDelinquencyStatusAsOf is GetDelinquencyStatus as it would stand on asOf if no further
+// payments arrive. MissedWeeks is the current run of consecutive overdue unpaid weeks.
+// Being a forecast, it never changes the loan's status.
+func (s *billingService) GetDelinquencyStatusAsOf(ctx context.Context, loanID string, asOf time.Time) (*domain.DelinquentResponse, error) {
+	_, status, err := s.delinquencyStatusAsOf(ctx, loanID, asOf)
+	return status, err
+}
+
+// progressLoanStatus persists the status an open loan's current run of missed weeks calls for.
+// A failure is only logged: the delinquency reported is still right and the scheduler retries
+// the transition on its next run.
+func (s *billingService) progressLoanStatus(ctx context.Context, loan *domain.Loan, missedWeeks int) {
+	status := domain.ProgressLoanStatus(loan.Status, missedWeeks, s.delinquencyThreshold(), s.defaultWeeksThreshold())
+	if status == loan.Status {
+		return
+	}
+
+	loan.Status = status
+	loan.UpdatedAt = time.Now()
+	if err := s.LoanRepo.Update(ctx, loan); err != nil {
+		logger.FromContext(ctx).Error("Failed to move loan status", "loan_id", loan.LoanID, "status", status, "error", err)
+		return
+	}
+	s.invalidateLoanCache(ctx, loan.LoanID)
+}
+
+// storageScale is the number of fractional digits amounts are calculated, stored and matched at
+func (s *billingService) storageScale() int32 {
+	if s.config == nil {
+		return money.Scale
+	}
+	return s.config.App.StorageScale()
+}
+
+// overdueCutoff is the due date before which unpaid weeks are overdue at now, allowing the
+// configured grace and boundary
+func (s *billingService) overdueCutoff(now time.Time) time.Time {
+	if s.config == nil {
+		return utils.OverdueCutoff(now, 0, false)
+	}
+	return utils.OverdueCutoff(now, s.config.App.OverdueGrace(), s.config.App.OverdueInclusive())
+}
+
+// delinquencyThreshold is the configured number of consecutive missed weeks that makes a loan
+// delinquent, or the default when unset
+func (s *billingService) delinquencyThreshold() int {
+	if s.config == nil || s.config.App.DelinquentWeeksThreshold <= 0 {
+		return defaultDelinquencyThreshold
+	}
+	return s.config.App.DelinquentWeeksThreshold
+}
+
+// defaultWeeksThreshold is the run of missed weeks that defaults a loan; zero disables it
+func (s *billingService) defaultWeeksThreshold() int {
+	if s.config == nil {
+		return 0
+	}
+	return s.config.App.DefaultWeeksThreshold
+}
+
+// tracksDelinquency reports whether missed weeks still matter for a loan: open loans and
+// defaulted ones, which keep missing payments, but not loans that are closed or written off
+func tracksDelinquency(status string) bool {
+	switch status {
+	case domain.LoanStatusActive, domain.LoanStatusDelinquent, domain.LoanStatusDefault:
+		return true
+	}
+	return false
+}
+
+// delinquencyStatusAsOf computes the delinquency behind GetDelinquencyStatusAsOf and returns the loan it read
+func (s *billingService) delinquencyStatusAsOf(ctx context.Context, loanID string, asOf time.Time) (*domain.Loan, *domain.DelinquentResponse, error) {
+	// Get loan details
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, nil, customError.WrapDatabaseError(err)
+	}
+
+	if !tracksDelinquency(loan.Status) {
+		// Closed and written-off loans have nothing left to miss, so they are never delinquent
+		return loan, &domain.DelinquentResponse{LoanID: loanID}, nil
+	}
+
+	if asOf.Before(loan.CreatedAt.Truncate(24 * time.Hour)) {
+		return nil, nil, customError.WrapInvalidAsOfDate("before the loan was created")
+	}
+
+	// Get loan schedule for the loan
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, nil, customError.WrapDatabaseError(err)
 	}
 
-	// 7. Check if loan is fully paid and update status
-	allPaid := true
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].WeekNumber < schedules[j].WeekNumber
+	})
+
+	missed, longest := s.missedWeeks(ctx, loan, schedules, asOf, (*domain.LoanSchedule).IsPaid)
+	return loan, &domain.DelinquentResponse{
+		LoanID:       loanID,
+		IsDelinquent: longest >= s.delinquencyThreshold(),
+		MissedWeeks:  missed,
+	}, nil
+}
+
+// missedWeeks walks a loan's schedule, sorted by week number, as it stood at asOf and returns the
+// run of consecutive missed weeks at the end along with the longest run along the way. A week
+// overdue at asOf is missed unless paid reports it paid; weeks due during forbearance neither
+// count nor end a run.
+func (s *billingService) missedWeeks(ctx context.Context, loan *domain.Loan, schedules []*domain.LoanSchedule, asOf time.Time, paid func(*domain.LoanSchedule) bool) (int, int) {
+	cutoff := s.overdueCutoff(asOf)
+
+	// Count consecutive missed payments
+	consecutiveMissed, longestMissed := 0, 0
+	previousWeek := 0
+	restart := false
+
+	// Check which payments are overdue
 	for _, schedule := range schedules {
-		// Skip the schedule we just paid
-		if schedule.WeekNumber == earliestUnpaid.WeekNumber {
-			continue
+		// Week numbers should be contiguous; a gap means schedule rows are missing, and since
+		// nothing is known about the missing weeks the streak restarts at the next week counted
+		if schedule.WeekNumber != previousWeek+1 {
+			logger.FromContext(ctx).Warn("Loan schedule is not contiguous", "loan_id", loan.LoanID, "week", schedule.WeekNumber, "previous_week", previousWeek)
+			restart = true
 		}
-		// Check if any other schedule is still pending
-		if schedule.Status == domain.ScheduleStatusPending {
-			allPaid = false
-			break
+		previousWeek = schedule.WeekNumber
+
+		// Only check overdue weeks, allowing the configured grace and boundary
+		// for now we assume that timezone is not an issue
+		// In real-world, need to consider timezone differences between server and client (vary in timezone)
+		// e.g., if due date is today but time has not yet reached due time
+		if !schedule.DueDate.Truncate(24 * time.Hour).Before(cutoff) {
+			continue // Don't check future payments or ones still within grace
 		}
-	}
 
-	if allPaid {
-		loan.Status = domain.LoanStatusClosed
-		err = s.LoanRepo.Update(ctx, loan)
-		if err != nil {
-			return nil, customError.WrapDatabaseError(err)
+		// Weeks due during forbearance neither count as missed nor reset the streak
+		if loan.InForbearance(schedule.DueDate) {
+			continue
 		}
-	}
 
-	return payment, nil
+		if restart {
+			consecutiveMissed, restart = 0, false
+		}
+
+		if paid(schedule) {
+			// Reset counter when payment is made
+			consecutiveMissed = 0
+			continue
+		}
+
+		// Past due date and still unpaid
+		consecutiveMissed++
+		if consecutiveMissed > longestMissed {
+			longestMissed = consecutiveMissed
+		}
+	}
+
+	return consecutiveMissed, longestMissed
+}
+
+// overdueAt returns the moment a week due on dueDate becomes overdue, allowing the configured grace
+// and boundary: the first instant at which overdueCutoff has moved past its due date
+func (s *billingService) overdueAt(dueDate time.Time) time.Time {
+	at := dueDate.Truncate(24 * time.Hour)
+	if s.config == nil {
+		return at.AddDate(0, 0, 1)
+	}
+	at = at.Add(s.config.App.OverdueGrace())
+	if !s.config.App.OverdueInclusive() {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// MakePayment processes a payment for a loan.
+// A payment smaller than what's left on the earliest unpaid week is recorded against it and the week
+// stays unpaid until its payments add up to the due amount; anything beyond that week rolls into the
+// following unpaid weeks. When a payment covers several weeks, the record for the latest week is returned.
+func (s *billingService) MakePayment(ctx context.Context, request domain.MakePaymentRequest) (*domain.Payment, error) {
+	// 1. Validate payment amount, matched against the schedule at storage precision
+	request.Amount = request.Amount.Round(s.storageScale())
+	if request.Amount.LessThanOrEqual(decimal.Zero) {
+		invalidAmount, _ := request.Amount.Float64()
+		return nil, customError.WrapInvalidPaymentAmount(invalidAmount)
+	}
+	if maximum, ok := s.maxPaymentAmount(); ok && request.Amount.GreaterThan(maximum) {
+		return nil, customError.WrapPaymentAmountTooLarge(maximum.String(), request.Amount.String())
+	}
+
+	now := time.Now()
+	paymentDate, err := s.paymentDate(request.PaymentDate, now)
+	if err != nil {
+		return nil, err
+	}
+	paymentDate, err = s.postingDate(paymentDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Validate loan exists and is active
+	loan, err := s.LoanRepo.GetByLoanID(ctx, request.LoanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	if err := requireActive(loan); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPaymentInterval(ctx, request.LoanID, now); err != nil {
+		return nil, err
+	}
+
+	// 3. Find the earliest unpaid week in the schedule
+	earliestUnpaid, err := s.LoanRepo.GetEarliestUnpaidWeek(ctx, request.LoanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrNoOutstandingBalance) {
+			return nil, err
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// Decide which weeks this payment covers and whether that settles the loan
+	weeksToPay, closesLoan, err := s.weeksToPay(ctx, loan, earliestUnpaid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Earlier partial payments towards the earliest unpaid week count against what it still owes
+	alreadyPaid, err := s.PaymentRepo.GetTotalPaidForWeek(ctx, request.LoanID, earliestUnpaid.WeekNumber)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// Only a single week may be paid in part; more than it owes rolls into the following unpaid weeks
+	partialAllowed := len(weeksToPay) == 1
+	if partialAllowed && !closesLoan && request.Amount.GreaterThan(earliestUnpaid.DueAmount.Sub(alreadyPaid)) {
+		weeksToPay, err = s.unpaidWeeks(ctx, request.LoanID)
+		if err != nil {
+			return nil, err
+		}
+		closesLoan = true
+	}
+
+	// 4. Validate payment amount against what's left on those weeks, less any on-time rebate
+	expectedAmount := alreadyPaid.Neg()
+	for _, week := range weeksToPay {
+		expectedAmount = expectedAmount.Add(week.DueAmount)
+	}
+	rebate := decimal.Zero
+	if closesLoan {
+		rebate, err = s.onTimeRebate(ctx, loan, weeksToPay, paymentDate)
+		if err != nil {
+			return nil, err
+		}
+		expectedAmount = expectedAmount.Sub(rebate)
+	}
+	credit := decimal.Zero
+	switch {
+	case request.Amount.LessThan(expectedAmount):
+		if !partialAllowed {
+			return nil, customError.WrapPaymentAmountMismatch(expectedAmount.String(), request.Amount.String())
+		}
+		// A partial payment settles nothing beyond the weeks it fully covers, so the loan stays open
+		closesLoan = false
+		rebate = decimal.Zero
+	case request.Amount.GreaterThan(expectedAmount):
+		// A small overpayment on the closing payment may be kept as credit
+		overpayment := request.Amount.Sub(expectedAmount)
+		if !closesLoan || !s.acceptsOverpayment(overpayment) {
+			return nil, customError.WrapPaymentAmountMismatch(expectedAmount.String(), request.Amount.String())
+		}
+		credit = overpayment
+	}
+
+	// 5-7. Record the payments, mark the weeks paid and close the loan when it's settled,
+	// all in one transaction so a failure part-way leaves the loan untouched
+	var payment *domain.Payment
+	err = s.UnitOfWork.Do(ctx, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		// 5. Apply the payment to the covered weeks oldest first, recording what each one receives
+		remaining := request.Amount.Sub(credit)
+		weekNumbers := make([]int, 0, len(weeksToPay))
+		for i, week := range weeksToPay {
+			if !remaining.IsPositive() {
+				break
+			}
+
+			owed := week.DueAmount
+			if i == 0 {
+				owed = owed.Sub(alreadyPaid)
+			}
+			if i == len(weeksToPay)-1 {
+				// The rebate comes off the final installment
+				owed = owed.Sub(rebate)
+			}
+			amount := decimal.Min(owed, remaining)
+			remaining = remaining.Sub(amount)
+
+			payment = &domain.Payment{
+				ID:          uuid.New(),
+				LoanID:      request.LoanID,
+				Amount:      amount,
+				PaymentDate: paymentDate,
+				WeekNumber:  week.WeekNumber,
+				RecordedBy:  request.RecordedBy,
+				Channel:     request.Channel,
+				CreatedAt:   now,
+			}
+
+			if err := payments.Create(ctx, payment); err != nil {
+				return err
+			}
+
+			if amount.Equal(owed) {
+				weekNumbers = append(weekNumbers, week.WeekNumber)
+			}
+		}
+
+		// 6. Update loan schedule status for the weeks now paid in full
+		if len(weekNumbers) > 0 {
+			if err := loans.UpdateScheduleStatuses(ctx, request.LoanID, weekNumbers, domain.ScheduleStatusPaid); err != nil {
+				return err
+			}
+		}
+
+		// 7. Close the loan once every week is paid, keeping any accepted overpayment as credit
+		if closesLoan {
+			loan.Status = domain.LoanStatusClosed
+			loan.CreditBalance = credit
+			loan.RebateAmount = rebate
+			return loans.Update(ctx, loan)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	s.invalidateLoanCache(ctx, request.LoanID)
+
+	return payment, nil
+}
+
+// GetCollectionStats reports the total collected, payment count and distinct loans paid in [from, to)
+func (s *billingService) GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error) {
+	stats, err := s.PaymentRepo.GetCollectionStats(ctx, from, to)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return stats, nil
+}
+
+// GetExpectedCollections reports, week by week, the installments open loans still have due in [from, to)
+func (s *billingService) GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error) {
+	weeks, err := s.LoanRepo.GetExpectedCollections(ctx, from, to)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return weeks, nil
+}
+
+// GetPayment looks up a single payment by its ID
+func (s *billingService) GetPayment(ctx context.Context, paymentID uuid.UUID) (*domain.Payment, error) {
+	payment, err := s.PaymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, customError.ErrPaymentNotFound) {
+			return nil, err
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return payment, nil
+}
+
+// UndoLatestPayment reverses the most recent payment on a loan. A payment covering several weeks is
+// recorded once per week, all stamped with the same created_at, so every one of those records is
+// undone together. In one transaction the records are removed, the weeks they paid are reopened and
+// a loan closed by the payment is reopened too. The latest record is returned.
+func (s *billingService) UndoLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// A closed loan is reopened by the undo below; defaulted and written-off loans stay frozen
+	if loan.Status == domain.LoanStatusDefault || loan.Status == domain.LoanStatusWrittenOff {
+		return nil, requireActive(loan)
+	}
+
+	payment, err := s.PaymentRepo.GetLatestPayment(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrPaymentNotFound) {
+			return nil, customError.WrapNoPaymentsToUndo(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	batch, err := s.paymentBatch(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.UnitOfWork.Do(ctx, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		weeks := make(map[int]bool, len(batch))
+		for _, record := range batch {
+			if err := payments.Delete(ctx, record.ID); err != nil {
+				return err
+			}
+			weeks[record.WeekNumber] = true
+		}
+
+		if err := s.reopenWeeks(ctx, loans, loanID, weeks); err != nil {
+			return err
+		}
+
+		// The week is unpaid again, so a loan closed by this payment has a balance to collect;
+		// the rebate and credit were settled at payoff and are earned again when it next closes
+		if loan.Status == domain.LoanStatusClosed {
+			loan.Status = domain.LoanStatusActive
+			loan.RebateAmount = decimal.Zero
+			loan.CreditBalance = decimal.Zero
+			return loans.Update(ctx, loan)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	s.invalidateLoanCache(ctx, loanID)
+
+	return payment, nil
+}
+
+// paymentBatch returns every record written by the payment request that wrote payment, i.e. the
+// loan's records stamped with the same created_at. Records from before payments were stamped all
+// carry the zero time, so those are treated one at a time.
+func (s *billingService) paymentBatch(ctx context.Context, payment *domain.Payment) ([]*domain.Payment, error) {
+	if payment.CreatedAt.IsZero() {
+		return []*domain.Payment{payment}, nil
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, payment.LoanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	batch := []*domain.Payment{payment}
+	for _, record := range payments {
+		if record.ID != payment.ID && record.CreatedAt.Equal(payment.CreatedAt) {
+			batch = append(batch, record)
+		}
+	}
+	return batch, nil
+}
+
+// reopenWeeks sets the given weeks of a loan back to unpaid once their payments are removed. Only
+// a paid week reopens; a partially paid week keeps its status. A week whose due date plus grace
+// has passed goes back to overdue rather than pending, so it doesn't read as on time until the
+// nightly job next runs.
+func (s *billingService) reopenWeeks(ctx context.Context, loans repository.LoanRepository, loanID string, weeks map[int]bool) error {
+	schedules, err := loans.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return err
+	}
+
+	cutoff := s.overdueCutoff(time.Now())
+	var pending, overdue []int
+	for _, week := range schedules {
+		if !weeks[week.WeekNumber] || !week.IsPaid() {
+			continue
+		}
+		if week.DueDate.Truncate(24 * time.Hour).Before(cutoff) {
+			overdue = append(overdue, week.WeekNumber)
+		} else {
+			pending = append(pending, week.WeekNumber)
+		}
+	}
+
+	if len(pending) > 0 {
+		if err := loans.UpdateScheduleStatuses(ctx, loanID, pending, domain.ScheduleStatusPending); err != nil {
+			return err
+		}
+	}
+	if len(overdue) > 0 {
+		if err := loans.UpdateScheduleStatuses(ctx, loanID, overdue, domain.ScheduleStatusOverdue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReversePayment removes any payment posted in error, not just the latest. In one transaction
+// the payment is deleted, its week is reopened and a loan closed by the
+// payment is reopened.
+func (s *billingService) ReversePayment(ctx context.Context, loanID string, paymentID uuid.UUID) error {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return customError.WrapLoanNotFound(loanID)
+		}
+		return customError.WrapDatabaseError(err)
+	}
+
+	// A closed loan is reopened below; defaulted and written-off loans stay frozen
+	if loan.Status == domain.LoanStatusDefault || loan.Status == domain.LoanStatusWrittenOff {
+		return requireActive(loan)
+	}
+
+	payment, err := s.PaymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, customError.ErrPaymentNotFound) {
+			return err
+		}
+		return customError.WrapDatabaseError(err)
+	}
+	// A payment on another loan is not found as far as this loan is concerned
+	if payment.LoanID != loanID {
+		return customError.WrapPaymentNotFound(paymentID.String())
+	}
+
+	err = s.UnitOfWork.Do(ctx, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		if err := payments.Delete(ctx, payment.ID); err != nil {
+			return err
+		}
+
+		if err := s.reopenWeeks(ctx, loans, loanID, map[int]bool{payment.WeekNumber: true}); err != nil {
+			return err
+		}
+
+		// The week is unpaid again, so a loan closed by this payment has a balance to collect;
+		// the rebate and credit were settled at payoff and are earned again when it next closes
+		if loan.Status == domain.LoanStatusClosed {
+			loan.Status = domain.LoanStatusActive
+			loan.RebateAmount = decimal.Zero
+			loan.CreditBalance = decimal.Zero
+			return loans.Update(ctx, loan)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return customError.WrapDatabaseError(err)
+	}
+
+	s.invalidateLoanCache(ctx, loanID)
+
+	return nil
+}
+
+// requireActive rejects mutating operations on a loan that isn't open; a delinquent loan is
+// still open. Defaulted and written-off loans get their own errors; any other status counts as closed.
+func requireActive(loan *domain.Loan) error {
+	switch loan.Status {
+	case domain.LoanStatusActive, domain.LoanStatusDelinquent:
+		return nil
+	case domain.LoanStatusDefault:
+		return customError.WrapLoanDefaulted(loan.LoanID)
+	case domain.LoanStatusWrittenOff:
+		return customError.WrapLoanWrittenOff(loan.LoanID)
+	default:
+		return customError.WrapLoanAlreadyClosed(loan.LoanID)
+	}
+}
+
+// weeksToPay returns the schedule entries a payment is applied to and whether paying them closes the loan.
+// Normally that's the earliest unpaid week; when every unpaid week is overdue and the
+// all_overdue policy is configured, the payment must cover all of them at once.
+// Closing is decided from the unpaid weeks left rather than the week number, since a reversed
+// payment can reopen a week in the middle of a schedule whose later weeks are already paid.
+func (s *billingService) weeksToPay(ctx context.Context, loan *domain.Loan, earliestUnpaid *domain.LoanSchedule) ([]*domain.LoanSchedule, bool, error) {
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loan.LoanID)
+	if err != nil {
+		return nil, false, customError.WrapDatabaseError(err)
+	}
+
+	oldestOnly := []*domain.LoanSchedule{earliestUnpaid}
+	if s.overduePaymentPolicy() != config.OverduePaymentPolicyAllOverdue {
+		return oldestOnly, s.closesLoan(schedules, oldestOnly), nil
+	}
+
+	cutoff := s.overdueCutoff(time.Now())
+	var unpaid []*domain.LoanSchedule
+	for _, schedule := range schedules {
+		if !schedule.IsUnpaid() {
+			continue
+		}
+		if !schedule.DueDate.Truncate(24 * time.Hour).Before(cutoff) {
+			// At least one week isn't overdue yet, so pay the oldest one as usual
+			return oldestOnly, s.closesLoan(schedules, oldestOnly), nil
+		}
+		unpaid = append(unpaid, schedule)
+	}
+
+	sort.Slice(unpaid, func(i, j int) bool {
+		return unpaid[i].WeekNumber < unpaid[j].WeekNumber
+	})
+
+	return unpaid, s.closesLoan(schedules, unpaid), nil
+}
+
+// unpaidWeeks returns every unpaid week of a loan's schedule, oldest first
+func (s *billingService) unpaidWeeks(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error) {
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	var unpaid []*domain.LoanSchedule
+	for _, schedule := range schedules {
+		if schedule.IsUnpaid() {
+			unpaid = append(unpaid, schedule)
+		}
+	}
+	sort.Slice(unpaid, func(i, j int) bool {
+		return unpaid[i].WeekNumber < unpaid[j].WeekNumber
+	})
+	return unpaid, nil
+}
+
+// closesLoan reports whether paying weeksToPay leaves no unpaid week on the schedule
+func (s *billingService) closesLoan(schedules, weeksToPay []*domain.LoanSchedule) bool {
+	paying := make(map[int]bool, len(weeksToPay))
+	for _, week := range weeksToPay {
+		paying[week.WeekNumber] = true
+	}
+	for _, schedule := range schedules {
+		if !paying[schedule.WeekNumber] && schedule.IsUnpaid() {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptsOverpayment reports whether the overpayment policy lets a closing payment exceed
+// the amount due by overpayment
+func (s *billingService) acceptsOverpayment(overpayment decimal.Decimal) bool {
+	if s.config == nil || s.config.App.OverpaymentPolicy != config.OverpaymentPolicyCredit {
+		return false
+	}
+	tolerance := decimal.NewFromFloat(s.config.App.OverpaymentTolerance)
+	return overpayment.IsPositive() && overpayment.LessThanOrEqual(tolerance)
+}
+
+// paymentDate resolves when a payment was made: now when no date is given, otherwise the requested
+// date as long as it isn't in the future or before the start of the day MAX_BACKDATE_DAYS ago
+func (s *billingService) paymentDate(requested *time.Time, now time.Time) (time.Time, error) {
+	if requested == nil {
+		return now, nil
+	}
+
+	if requested.After(now) {
+		return time.Time{}, customError.WrapInvalidPaymentDate("payment date is in the future")
+	}
+
+	maxBackdateDays := 0
+	if s.config != nil {
+		maxBackdateDays = s.config.App.MaxBackdateDays
+	}
+	earliest := now.Truncate(24*time.Hour).AddDate(0, 0, -maxBackdateDays)
+	if requested.Before(earliest) {
+		return time.Time{}, customError.WrapInvalidPaymentDate(
+			fmt.Sprintf("payments can be backdated at most %d days, to %s", maxBackdateDays, earliest.Format("2006-01-02")))
+	}
+
+	return *requested, nil
+}
+
+// postingDate applies PAYMENT_DAY_POLICY to a payment made on a weekend or holiday: rejected, or
+// moved to the start of the next business day. Any other day, or the default policy, keeps the date.
+func (s *billingService) postingDate(paymentDate time.Time) (time.Time, error) {
+	if s.config == nil {
+		return paymentDate, nil
+	}
+	policy := s.config.App.PaymentDayPolicy
+	if policy != config.PaymentDayPolicyReject && policy != config.PaymentDayPolicyDefer {
+		return paymentDate, nil
+	}
+
+	// Holidays were validated when the config loaded
+	holidays, _ := s.config.App.HolidayDates()
+	if utils.IsBusinessDay(paymentDate, holidays) {
+		return paymentDate, nil
+	}
+
+	if policy == config.PaymentDayPolicyReject {
+		return time.Time{}, customError.WrapInvalidPaymentDate(
+			fmt.Sprintf("payments are only accepted on business days, and %s is not one", paymentDate.Format(utils.DateLayout)))
+	}
+	return utils.NextBusinessDay(paymentDate, holidays), nil
+}
+
+// maxPaymentAmount is the largest amount a single payment may carry (MAX_PAYMENT_AMOUNT);
+// ok is false when no limit is configured
+func (s *billingService) maxPaymentAmount() (maximum decimal.Decimal, ok bool) {
+	if s.config == nil || s.config.App.MaxPaymentAmount <= 0 {
+		return decimal.Zero, false
+	}
+	return decimal.NewFromFloat(s.config.App.MaxPaymentAmount), true
+}
+
+// checkPaymentInterval refuses a payment arriving less than MIN_PAYMENT_INTERVAL after the loan's
+// latest payment was recorded. It goes by when that payment was recorded rather than its
+// payment_date, which may be backdated.
+func (s *billingService) checkPaymentInterval(ctx context.Context, loanID string, now time.Time) error {
+	if s.config == nil || s.config.App.MinPaymentInterval <= 0 {
+		return nil
+	}
+
+	latest, err := s.PaymentRepo.GetLatestPayment(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrPaymentNotFound) {
+			return nil
+		}
+		return customError.WrapDatabaseError(err)
+	}
+
+	since := now.Sub(latest.CreatedAt)
+	if since < s.config.App.MinPaymentInterval {
+		return customError.WrapPaymentTooSoon(loanID, since.Truncate(time.Second), s.config.App.MinPaymentInterval)
+	}
+	return nil
+}
+
+// overduePaymentPolicy returns the configured overdue payment policy, defaulting to catch-up
+func (s *billingService) overduePaymentPolicy() string {
+	if s.config == nil || s.config.App.OverduePaymentPolicy == "" {
+		return config.OverduePaymentPolicyCatchUp
+	}
+	return s.config.App.OverduePaymentPolicy
+}
+
+// GetSchedule returns the repayment schedule for a loan ordered by week number
+func (s *billingService) GetSchedule(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error) {
+	// Check the loan exists so callers can tell "no such loan" from "no schedule yet"
+	if _, err := s.LoanRepo.GetByLoanID(ctx, loanID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// A loan without schedule rows returns an empty slice (serialized as [])
+	if schedules == nil {
+		schedules = []*domain.LoanSchedule{}
+	}
+
+	return schedules, nil
+}
+
+// GetScheduleWeek returns one week of a loan's schedule as it stands now
+func (s *billingService) GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error) {
+	week, err := s.LoanRepo.GetScheduleWeek(ctx, loanID, weekNumber)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return week, nil
+}
+
+// GetRemaining summarizes the unpaid part of a loan's schedule; a partially paid week counts
+// only what is still owed on it
+func (s *billingService) GetRemaining(ctx context.Context, loanID string) (*domain.RemainingSummary, error) {
+	schedules, err := s.GetSchedule(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.RemainingSummary{RemainingAmount: decimal.Zero}
+	for _, schedule := range schedules {
+		if schedule.IsPaid() {
+			continue
+		}
+
+		paid, err := s.PaymentRepo.GetTotalPaidForWeek(ctx, loanID, schedule.WeekNumber)
+		if err != nil {
+			return nil, customError.WrapDatabaseError(err)
+		}
+
+		summary.RemainingInstallments++
+		summary.RemainingAmount = summary.RemainingAmount.Add(decimal.Max(schedule.DueAmount.Sub(paid), decimal.Zero))
+
+		if summary.NextDueDate == nil || schedule.DueDate.Before(*summary.NextDueDate) {
+			dueDate := schedule.DueDate
+			summary.NextDueDate = &dueDate
+		}
+	}
+
+	return summary, nil
+}
+
+// GetNextDue returns the earliest week of a loan still awaiting payment, or nil once every week is paid.
+// Its due amount is what is left on the week after any partial payments.
+func (s *billingService) GetNextDue(ctx context.Context, loanID string) (*domain.NextDue, error) {
+	week, err := s.LoanRepo.GetEarliestUnpaidWeek(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, customError.ErrNoOutstandingBalance) {
+			return nil, nil
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	paid, err := s.PaymentRepo.GetTotalPaidForWeek(ctx, loanID, week.WeekNumber)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return &domain.NextDue{
+		WeekNumber: week.WeekNumber,
+		DueDate:    week.DueDate,
+		DueAmount:  decimal.Max(week.DueAmount.Sub(paid), decimal.Zero),
+	}, nil
+}
+
+// GetCurrentWeek reports which week of its term a loan is in today. Loans past their last week
+// report the last week.
+func (s *billingService) GetCurrentWeek(ctx context.Context, loanID string) (*domain.CurrentWeekResponse, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// The schedule starts at midnight on the day the loan was created
+	currentWeek := utils.GetCurrentWeek(loan.CreatedAt.Truncate(24*time.Hour), time.Now())
+	if currentWeek > loan.DurationWeeks {
+		currentWeek = loan.DurationWeeks
+	}
+
+	return &domain.CurrentWeekResponse{
+		LoanID:      loanID,
+		CurrentWeek: currentWeek,
+		TotalWeeks:  loan.DurationWeeks,
+	}, nil
+}
+
+// GetOutstandingDeviation compares a loan's actual outstanding with what its schedule expects
+// today, i.e. the total repayable less every installment due on or before today
+func (s *billingService) GetOutstandingDeviation(ctx context.Context, loanID string) (*domain.OutstandingDeviation, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	total := s.totalLoanAmount(loan)
+	today := time.Now().Truncate(24 * time.Hour)
+
+	deviation := &domain.OutstandingDeviation{ExpectedOutstanding: total}
+	for _, schedule := range schedules {
+		if schedule.DueDate.After(today) {
+			continue
+		}
+		deviation.ExpectedOutstanding = deviation.ExpectedOutstanding.Sub(schedule.DueAmount)
+		if schedule.WeekNumber > deviation.CurrentWeek {
+			deviation.CurrentWeek = schedule.WeekNumber
+		}
+	}
+	totalPaid := decimal.Zero
+	for _, payment := range payments {
+		totalPaid = totalPaid.Add(payment.Amount)
+	}
+	deviation.ActualOutstanding = s.outstandingAfter(loan, totalPaid)
+	deviation.Deviation = deviation.ActualOutstanding.Sub(deviation.ExpectedOutstanding)
+
+	return deviation, nil
+}
+
+// GetCostOfCredit totals what a loan costs over its term from its schedule: everything due less
+// the principal is interest, since the engine charges no fees. A loan without schedule rows falls
+// back to the interest its terms imply.
+func (s *billingService) GetCostOfCredit(ctx context.Context, loanID string) (*domain.CostOfCredit, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	totalRepayable := decimal.Zero
+	for _, schedule := range schedules {
+		totalRepayable = totalRepayable.Add(schedule.DueAmount)
+	}
+	if len(schedules) == 0 {
+		termRate := loanTermRate(loan)
+		totalRepayable = loan.Amount.Add(utils.CalculateTotalInterest(loan.Amount, termRate, s.storageScale()))
+	}
+
+	cost := &domain.CostOfCredit{
+		Principal:      loan.Amount,
+		TotalRepayable: totalRepayable,
+		TotalInterest:  totalRepayable.Sub(loan.Amount),
+		TotalFees:      decimal.Zero,
+		CostPercent:    decimal.Zero,
+	}
+	if loan.Amount.IsPositive() {
+		cost.CostPercent = cost.TotalInterest.Add(cost.TotalFees).Div(loan.Amount).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+
+	return cost, nil
+}
+
+// GetInterestPaid totals the interest paid on a loan to date. Each payment is split using its
+// week's schedule: the week's principal is covered first and the rest is interest, so a rebated
+// final installment shows up as less interest paid. Weeks without a principal/interest breakdown
+// are split in proportion to the loan's total principal and interest.
+func (s *billingService) GetInterestPaid(ctx context.Context, loanID string) (*domain.InterestPaidSummary, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return s.splitPayments(loan, schedules, payments), nil
+}
+
+// GetOutstandingBreakdown splits what is left to repay on a loan into remaining principal and
+// remaining interest, allocating payments the same way GetInterestPaid does. Any on-time rebate
+// comes off the interest; the two parts always add up to the total.
+func (s *billingService) GetOutstandingBreakdown(ctx context.Context, loanID string) (*domain.OutstandingBreakdown, error) {
+	if breakdown, found := s.cachedOutstandingBreakdown(ctx, loanID); found {
+		return breakdown, nil
+	}
+
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	paid := s.splitPayments(loan, schedules, payments)
+	termRate := loanTermRate(loan)
+	totalInterest := utils.CalculateTotalInterest(loan.Amount, termRate, s.storageScale())
+
+	principal := decimal.Max(loan.Amount.Sub(paid.PrincipalPaid), decimal.Zero)
+	interest := decimal.Max(totalInterest.Sub(loan.RebateAmount).Sub(paid.InterestPaid), decimal.Zero)
+
+	breakdown := &domain.OutstandingBreakdown{
+		PrincipalOutstanding: principal,
+		InterestOutstanding:  interest,
+		TotalOutstanding:     principal.Add(interest),
+	}
+
+	if s.cache != nil {
+		if err := s.cache.SetOutstandingBreakdown(ctx, loanID, breakdown); err != nil {
+			logger.FromContext(ctx).Warn("Failed to cache outstanding breakdown", "loan_id", loanID, "error", customError.WrapCacheError(err))
+		}
+	}
+
+	return breakdown, nil
+}
+
+// cachedOutstandingBreakdown looks up a loan's outstanding breakdown in the cache, treating an
+// outage as a miss the same way cachedOutstanding does
+func (s *billingService) cachedOutstandingBreakdown(ctx context.Context, loanID string) (*domain.OutstandingBreakdown, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+
+	breakdown, found, err := s.cache.GetOutstandingBreakdown(ctx, loanID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to read cached outstanding breakdown", "loan_id", loanID, "error", customError.WrapCacheError(err))
+		return nil, false
+	}
+	return breakdown, found
+}
+
+// splitPayments allocates each payment to the principal of the week it paid first and the rest to
+// that week's interest; several partial payments on one week share its principal between them,
+// so together they never cover more than it. Payments against weeks without a recorded split are divided in the loan's
+// overall principal-to-interest proportion.
+func (s *billingService) splitPayments(loan *domain.Loan, schedules []*domain.LoanSchedule, payments []*domain.Payment) *domain.InterestPaidSummary {
+	weeks := make(map[int]*domain.LoanSchedule, len(schedules))
+	for _, schedule := range schedules {
+		weeks[schedule.WeekNumber] = schedule
+	}
+
+	termRate := loanTermRate(loan)
+	totalInterest := utils.CalculateTotalInterest(loan.Amount, termRate, s.storageScale())
+	totalRepayable := loan.Amount.Add(totalInterest)
+
+	summary := &domain.InterestPaidSummary{
+		InterestPaid:  decimal.Zero,
+		PrincipalPaid: decimal.Zero,
+		TotalPaid:     decimal.Zero,
+	}
+	principalApplied := make(map[int]decimal.Decimal)
+	for _, payment := range payments {
+		var interest decimal.Decimal
+		week, ok := weeks[payment.WeekNumber]
+		if ok && !(week.PrincipalAmount.IsZero() && week.InterestAmount.IsZero()) {
+			principalLeft := decimal.Max(week.PrincipalAmount.Sub(principalApplied[week.WeekNumber]), decimal.Zero)
+			principal := decimal.Min(payment.Amount, principalLeft)
+			principalApplied[week.WeekNumber] = principalApplied[week.WeekNumber].Add(principal)
+			interest = payment.Amount.Sub(principal)
+		} else if totalRepayable.IsPositive() {
+			interest = payment.Amount.Mul(totalInterest).Div(totalRepayable).Round(s.storageScale())
+		}
+
+		summary.InterestPaid = summary.InterestPaid.Add(interest)
+		summary.PrincipalPaid = summary.PrincipalPaid.Add(payment.Amount.Sub(interest))
+		summary.TotalPaid = summary.TotalPaid.Add(payment.Amount)
+	}
+
+	return summary
+}
+
+// CheckScheduleIntegrity confirms a loan has one schedule row per week of its term,
+// counting the rows rather than loading them
+func (s *billingService) CheckScheduleIntegrity(ctx context.Context, loanID string) (*domain.ScheduleIntegrity, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	count, err := s.LoanRepo.CountSchedule(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return &domain.ScheduleIntegrity{
+		LoanID:        loanID,
+		DurationWeeks: loan.DurationWeeks,
+		ScheduleWeeks: count,
+		Consistent:    count == loan.DurationWeeks,
+	}, nil
+}
+
+// GetPaymentStreak counts a loan's consecutive on-time weeks. A week is on time when it was paid
+// in full by the end of its due date, judged by the payment that completed it. Weeks not yet due
+// are skipped; a late week, or one whose due date passed unpaid, breaks the streak.
+func (s *billingService) GetPaymentStreak(ctx context.Context, loanID string) (*domain.PaymentStreak, error) {
+	if _, err := s.LoanRepo.GetByLoanID(ctx, loanID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	paidAt := completionDates(payments)
+
+	now := time.Now()
+	streak := &domain.PaymentStreak{LoanID: loanID}
+	for _, schedule := range schedules {
+		cutoff := schedule.DueDate.Truncate(24*time.Hour).AddDate(0, 0, 1)
+
+		onTime := false
+		if schedule.IsPaid() {
+			paidDate, ok := paidAt[schedule.WeekNumber]
+			// Marked paid without a payment record, so we can't tell when; assume on time
+			onTime = !ok || paidDate.Before(cutoff)
+		} else if now.Before(cutoff) {
+			continue
+		}
+
+		if !onTime {
+			streak.CurrentStreak = 0
+			continue
+		}
+		streak.CurrentStreak++
+		if streak.CurrentStreak > streak.LongestStreak {
+			streak.LongestStreak = streak.CurrentStreak
+		}
+	}
+
+	return streak, nil
+}
+
+// completionDates returns the latest payment date per week, i.e. when a week paid in
+// installments was completed
+func completionDates(payments []*domain.Payment) map[int]time.Time {
+	paidAt := make(map[int]time.Time)
+	for _, payment := range payments {
+		if paidDate, ok := paidAt[payment.WeekNumber]; !ok || payment.PaymentDate.After(paidDate) {
+			paidAt[payment.WeekNumber] = payment.PaymentDate
+		}
+	}
+	return paidAt
+}
+
+// SetForbearance records a forbearance window on an active loan.
+// Weeks due inside the window don't count toward delinquency; counting resumes after it ends.
+func (s *billingService) SetForbearance(ctx context.Context, loanID string, start, end time.Time) (*domain.Loan, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	if err := requireActive(loan); err != nil {
+		return nil, err
+	}
+
+	if err = s.LoanRepo.SetForbearance(ctx, loanID, start, end); err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	loan.ForbearanceStart = &start
+	loan.ForbearanceEnd = &end
+
+	return loan, nil
+}
+
+// UpdateNotes merges a JSON object into a loan's notes.
+// Top-level keys in the patch replace existing ones and keys set to null are removed.
+func (s *billingService) UpdateNotes(ctx context.Context, loanID string, patch json.RawMessage) (json.RawMessage, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return nil, customError.WrapInvalidNotes("notes must be a JSON object")
+	}
+
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	notes := make(map[string]json.RawMessage)
+	if len(loan.Notes) > 0 {
+		if err = json.Unmarshal(loan.Notes, &notes); err != nil {
+			return nil, customError.WrapDatabaseError(err)
+		}
+	}
+
+	for key, value := range changes {
+		if string(value) == "null" {
+			delete(notes, key)
+			continue
+		}
+		notes[key] = value
+	}
+
+	merged, err := json.Marshal(notes)
+	if err != nil {
+		return nil, customError.WrapInvalidNotes(err.Error())
+	}
+
+	if len(merged) > domain.MaxLoanNotesBytes {
+		return nil, customError.WrapInvalidNotes(fmt.Sprintf("notes exceed %d bytes", domain.MaxLoanNotesBytes))
+	}
+
+	if err = s.LoanRepo.UpdateNotes(ctx, loanID, merged); err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return merged, nil
+}
+
+// ExportLoan gathers a loan with its full schedule and every payment.
+// Soft-deleted loans are exported too, flagged as deleted, since exports feed migrations and audits.
+func (s *billingService) ExportLoan(ctx context.Context, loanID string) (*domain.LoanExport, error) {
+	loan, err := s.LoanRepo.FindByLoanID(ctx, loanID, repository.LoanQueryOptions{IncludeDeleted: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	// Empty sections serialize as [] rather than null
+	if schedules == nil {
+		schedules = []*domain.LoanSchedule{}
+	}
+	if payments == nil {
+		payments = []*domain.Payment{}
+	}
+
+	return &domain.LoanExport{
+		Loan:       loan,
+		Deleted:    loan.DeletedAt != nil,
+		Schedule:   schedules,
+		Payments:   payments,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// GetDelinquencyHistory returns a weekly delinquency snapshot for every week that has become overdue.
+// Each snapshot is taken the moment that week became overdue, allowing the configured grace and
+// boundary, and counts missed weeks the way GetDelinquencyStatusAsOf does, using payment dates to
+// decide which weeks had been paid at that point in time.
+func (s *billingService) GetDelinquencyHistory(ctx context.Context, loanID string) ([]*domain.DelinquencySnapshot, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	schedules, err := s.LoanRepo.GetScheduleByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanID(ctx, loanID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	paidAt := completionDates(payments)
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].WeekNumber < schedules[j].WeekNumber
+	})
+
+	history := make([]*domain.DelinquencySnapshot, 0, len(schedules))
+	cutoff := s.overdueCutoff(time.Now())
+
+	for _, current := range schedules {
+		if !current.DueDate.Truncate(24 * time.Hour).Before(cutoff) {
+			break // Weeks not yet overdue have no history yet
+		}
+
+		asOf := s.overdueAt(current.DueDate)
+		missed, longest := s.missedWeeks(ctx, loan, schedules, asOf, func(schedule *domain.LoanSchedule) bool {
+			// A partially paid week isn't paid until the payment that completes it
+			if !schedule.IsPaid() {
+				return false
+			}
+			paidDate, ok := paidAt[schedule.WeekNumber]
+			// Paid without a payment record, so we can't tell when; assume on time
+			return !ok || paidDate.Before(asOf)
+		})
+
+		history = append(history, &domain.DelinquencySnapshot{
+			WeekNumber:   current.WeekNumber,
+			AsOf:         asOf,
+			MissedWeeks:  missed,
+			IsDelinquent: longest >= s.delinquencyThreshold(),
+		})
+	}
+
+	return history, nil
+}
+
+// ListLoans returns live loans matching the filter along with the total match count
+func (s *billingService) ListLoans(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error) {
+	loans, total, err := s.LoanRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, customError.WrapDatabaseError(err)
+	}
+
+	return loans, total, nil
+}
+
+// GetBorrowerLoans returns every live loan belonging to a borrower, with its current status
+func (s *billingService) GetBorrowerLoans(ctx context.Context, borrowerID string) ([]*domain.Loan, error) {
+	loans, err := s.LoanRepo.GetByBorrowerID(ctx, borrowerID)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return loans, nil
+}
+
+// ListPayments returns payments across all loans matching the filter along with the total match count
+func (s *billingService) ListPayments(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error) {
+	payments, total, err := s.PaymentRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, customError.WrapDatabaseError(err)
+	}
+
+	return payments, total, nil
+}
+
+// GetPaymentsByWeekRange returns a loan's payments for weeks fromWeek through toWeek inclusive.
+// A toWeek of 0 runs to the loan's last week.
+func (s *billingService) GetPaymentsByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) (*domain.WeekRangePayments, error) {
+	loan, err := s.LoanRepo.GetByLoanID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	if toWeek == 0 {
+		toWeek = loan.DurationWeeks
+	}
+
+	payments, err := s.PaymentRepo.GetByWeekRange(ctx, loanID, fromWeek, toWeek)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return &domain.WeekRangePayments{
+		LoanID:   loanID,
+		FromWeek: fromWeek,
+		ToWeek:   toWeek,
+		Payments: payments,
+	}, nil
+}
+
+// GetPaymentsByDateRange returns a loan's payments made in [from, to), oldest first.
+// Either bound may be nil to leave that side open.
+func (s *billingService) GetPaymentsByDateRange(ctx context.Context, loanID string, from, to *time.Time) (*domain.DateRangePayments, error) {
+	if _, err := s.LoanRepo.GetByLoanID(ctx, loanID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customError.WrapLoanNotFound(loanID)
+		}
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	payments, err := s.PaymentRepo.GetByLoanIDBetween(ctx, loanID, from, to)
+	if err != nil {
+		return nil, customError.WrapDatabaseError(err)
+	}
+
+	return &domain.DateRangePayments{
+		LoanID:   loanID,
+		From:     from,
+		To:       to,
+		Payments: payments,
+	}, nil
 }
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Domain errors
@@ -12,8 +13,23 @@ var (
 	ErrInvalidLoanAmount     = errors.New("invalid loan amount")
 	ErrInvalidPaymentAmount  = errors.New("invalid payment amount")
 	ErrLoanAlreadyClosed     = errors.New("loan is already closed")
+	ErrLoanDefaulted         = errors.New("loan is in default")
+	ErrLoanWrittenOff        = errors.New("loan has been written off")
 	ErrPaymentAmountMismatch = errors.New("payment amount must match weekly payment amount exactly")
 	ErrNoOutstandingBalance  = errors.New("no outstanding balance")
+	ErrInvalidNotes          = errors.New("invalid loan notes")
+	ErrNoPaymentsToUndo      = errors.New("loan has no payments to undo")
+	ErrScheduleTooLong       = errors.New("schedule exceeds maximum horizon")
+	ErrLoanHasPayments       = errors.New("loan already has payments")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrLoanIDGeneration      = errors.New("could not generate a unique loan ID")
+	ErrInvalidPaymentDate    = errors.New("invalid payment date")
+	ErrInvalidAsOfDate       = errors.New("invalid as-of date")
+	ErrPaymentAmountTooLarge = errors.New("payment amount exceeds the per-transaction maximum")
+	ErrLoanLimitExceeded     = errors.New("loan terms exceed a configured maximum")
+	ErrInvalidInterestRate   = errors.New("invalid interest rate")
+	ErrPaymentTooSoon        = errors.New("payment too soon after the previous one")
+	ErrQueryTimeout          = errors.New("database query timed out")
 )
 
 // BusinessError represents a business logic error
@@ -50,9 +66,24 @@ const (
 	ErrCodeInvalidLoanAmount     = "INVALID_LOAN_AMOUNT"
 	ErrCodeInvalidPaymentAmount  = "INVALID_PAYMENT_AMOUNT"
 	ErrCodeLoanAlreadyClosed     = "LOAN_ALREADY_CLOSED"
+	ErrCodeLoanDefaulted         = "LOAN_DEFAULTED"
+	ErrCodeLoanWrittenOff        = "LOAN_WRITTEN_OFF"
 	ErrCodePaymentAmountMismatch = "PAYMENT_AMOUNT_MISMATCH"
 	ErrCodeNoOutstandingBalance  = "NO_OUTSTANDING_BALANCE"
+	ErrCodeInvalidNotes          = "INVALID_NOTES"
+	ErrCodeNoPaymentsToUndo      = "NO_PAYMENTS_TO_UNDO"
+	ErrCodeScheduleTooLong       = "SCHEDULE_TOO_LONG"
+	ErrCodeLoanHasPayments       = "LOAN_HAS_PAYMENTS"
+	ErrCodePaymentNotFound       = "PAYMENT_NOT_FOUND"
+	ErrCodeLoanIDGeneration      = "LOAN_ID_GENERATION_FAILED"
+	ErrCodeInvalidPaymentDate    = "INVALID_PAYMENT_DATE"
+	ErrCodeInvalidAsOfDate       = "INVALID_AS_OF_DATE"
+	ErrCodePaymentAmountTooLarge = "PAYMENT_AMOUNT_TOO_LARGE"
+	ErrCodeLoanLimitExceeded     = "LOAN_LIMIT_EXCEEDED"
+	ErrCodeInvalidInterestRate   = "INVALID_INTEREST_RATE"
+	ErrCodePaymentTooSoon        = "PAYMENT_TOO_SOON"
 	ErrCodeDatabaseError         = "DATABASE_ERROR"
+	ErrCodeQueryTimeout          = "QUERY_TIMEOUT"
 	ErrCodeCacheError            = "CACHE_ERROR"
 )
 
@@ -65,6 +96,22 @@ func WrapLoanNotFound(loanID string) *BusinessError {
 	)
 }
 
+func WrapPaymentNotFound(paymentID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodePaymentNotFound,
+		fmt.Sprintf("Payment with ID %s not found", paymentID),
+		ErrPaymentNotFound,
+	)
+}
+
+func WrapLatestPaymentNotFound(loanID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodePaymentNotFound,
+		fmt.Sprintf("No payments found for loan with ID %s", loanID),
+		ErrPaymentNotFound,
+	)
+}
+
 func WrapLoanAlreadyExists(loanID string) *BusinessError {
 	return NewBusinessError(
 		ErrCodeLoanAlreadyExists,
@@ -89,7 +136,29 @@ func WrapLoanAlreadyClosed(loanID string) *BusinessError {
 	)
 }
 
+func WrapLoanDefaulted(loanID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeLoanDefaulted,
+		fmt.Sprintf("Loan with ID %s is in default", loanID),
+		ErrLoanDefaulted,
+	)
+}
+
+func WrapLoanWrittenOff(loanID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeLoanWrittenOff,
+		fmt.Sprintf("Loan with ID %s has been written off", loanID),
+		ErrLoanWrittenOff,
+	)
+}
+
+// WrapDatabaseError wraps a failed database operation; a query timeout is returned as it is so
+// callers still see its own code
 func WrapDatabaseError(err error) *BusinessError {
+	var timeout *BusinessError
+	if errors.As(err, &timeout) && timeout.Code == ErrCodeQueryTimeout {
+		return timeout
+	}
 	return NewBusinessError(
 		ErrCodeDatabaseError,
 		"database operation failed",
@@ -97,6 +166,16 @@ func WrapDatabaseError(err error) *BusinessError {
 	)
 }
 
+// WrapQueryTimeout wraps a statement that ran out of time; the result matches both
+// ErrQueryTimeout and the driver's own error
+func WrapQueryTimeout(err error) *BusinessError {
+	return NewBusinessError(
+		ErrCodeQueryTimeout,
+		"Database query timed out",
+		fmt.Errorf("%w: %w", ErrQueryTimeout, err),
+	)
+}
+
 func WrapCacheError(err error) *BusinessError {
 	return NewBusinessError(
 		ErrCodeCacheError,
@@ -120,3 +199,93 @@ func WrapInvalidPaymentAmount(amount float64) *BusinessError {
 		ErrInvalidPaymentAmount,
 	)
 }
+
+func WrapPaymentAmountTooLarge(maximum, actual string) *BusinessError {
+	return NewBusinessError(
+		ErrCodePaymentAmountTooLarge,
+		fmt.Sprintf("Payment amount %s exceeds the maximum of %s per transaction", actual, maximum),
+		ErrPaymentAmountTooLarge,
+	)
+}
+
+// WrapPaymentTooSoon says how long ago the loan's latest payment was recorded and the gap required
+func WrapPaymentTooSoon(loanID string, since, interval time.Duration) *BusinessError {
+	return NewBusinessError(
+		ErrCodePaymentTooSoon,
+		fmt.Sprintf("Loan with ID %s was paid %s ago; payments must be at least %s apart", loanID, since, interval),
+		ErrPaymentTooSoon,
+	)
+}
+
+// WrapLoanLimitExceeded names the request field that broke its configured maximum
+func WrapLoanLimitExceeded(field, maximum, actual string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeLoanLimitExceeded,
+		fmt.Sprintf("%s %s exceeds the maximum of %s", field, actual, maximum),
+		ErrLoanLimitExceeded,
+	)
+}
+
+func WrapInvalidInterestRate(reason string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeInvalidInterestRate,
+		fmt.Sprintf("Invalid interest rate: %s", reason),
+		ErrInvalidInterestRate,
+	)
+}
+
+func WrapInvalidPaymentDate(reason string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeInvalidPaymentDate,
+		fmt.Sprintf("Invalid payment date: %s", reason),
+		ErrInvalidPaymentDate,
+	)
+}
+
+func WrapInvalidAsOfDate(reason string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeInvalidAsOfDate,
+		fmt.Sprintf("Invalid as-of date: %s", reason),
+		ErrInvalidAsOfDate,
+	)
+}
+
+func WrapInvalidNotes(reason string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeInvalidNotes,
+		fmt.Sprintf("Invalid notes: %s", reason),
+		ErrInvalidNotes,
+	)
+}
+
+func WrapNoPaymentsToUndo(loanID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeNoPaymentsToUndo,
+		fmt.Sprintf("Loan with ID %s has no payments to undo", loanID),
+		ErrNoPaymentsToUndo,
+	)
+}
+
+func WrapScheduleTooLong(lastDueDate time.Time, horizonWeeks int) *BusinessError {
+	return NewBusinessError(
+		ErrCodeScheduleTooLong,
+		fmt.Sprintf("Last due date %s is more than %d weeks away", lastDueDate.Format("2006-01-02"), horizonWeeks),
+		ErrScheduleTooLong,
+	)
+}
+
+func WrapLoanHasPayments(loanID string) *BusinessError {
+	return NewBusinessError(
+		ErrCodeLoanHasPayments,
+		fmt.Sprintf("Loan with ID %s already has payments", loanID),
+		ErrLoanHasPayments,
+	)
+}
+
+func WrapLoanIDGeneration(attempts int) *BusinessError {
+	return NewBusinessError(
+		ErrCodeLoanIDGeneration,
+		fmt.Sprintf("No unique loan ID found after %d attempts", attempts),
+		ErrLoanIDGeneration,
+	)
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log formats accepted by LOG_FORMAT
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// RequestIDKey is the attribute name the correlation ID is logged under
+const RequestIDKey = "request_id"
+
+type requestIDContextKey struct{}
+
+// ParseLevel maps LOG_LEVEL to a slog level; an empty value means info
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// ValidFormat reports whether format is a supported LOG_FORMAT; an empty value means json
+func ValidFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "", FormatJSON, FormatText:
+		return true
+	default:
+		return false
+	}
+}
+
+// New builds a logger writing to w at the given level and format
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	if !ValidFormat(format) {
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+	if strings.ToLower(format) == FormatText {
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	}
+	return slog.New(slog.NewJSONHandler(w, options)), nil
+}
+
+// Setup installs the configured logger as the process default. The standard log package is
+// routed through it too, so existing log.Printf calls come out in the same format.
+func Setup(level, format string) error {
+	l, err := New(os.Stdout, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(l)
+	return nil
+}
+
+// WithRequestID returns a context carrying the request's correlation ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestID returns the correlation ID carried by ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the default logger tagged with the context's correlation ID, if any
+func FromContext(ctx context.Context) *slog.Logger {
+	l := slog.Default()
+	if requestID := RequestID(ctx); requestID != "" {
+		return l.With(RequestIDKey, requestID)
+	}
+	return l
+}
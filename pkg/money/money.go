@@ -0,0 +1,66 @@
+package money
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Scale is the number of fractional digits money amounts carry on the wire
+const Scale = 2
+
+// Money wraps decimal.Decimal so API amounts are always encoded as fixed-scale strings
+// (e.g. "5500000.00") and decoded without silently dropping sub-cent precision.
+type Money struct {
+	decimal.Decimal
+
+	// places overrides Scale when encoding; set by Display
+	places  int32
+	display bool
+}
+
+// New wraps a decimal value as Money
+func New(d decimal.Decimal) Money {
+	return Money{Decimal: d}
+}
+
+// Display wraps a decimal value as Money rounded to places fractional digits, for responses
+// that show amounts at a different precision than they are stored at
+func Display(d decimal.Decimal, places int32) Money {
+	return Money{Decimal: d.Round(places), places: places, display: true}
+}
+
+// Places is the number of fractional digits the amount is encoded with
+func (m Money) Places() int32 {
+	if m.display {
+		return m.places
+	}
+	return Scale
+}
+
+// MarshalJSON encodes the amount as a quoted string with exactly Places fractional digits
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + m.StringFixed(m.Places()) + `"`), nil
+}
+
+// UnmarshalJSON accepts either a quoted string or a bare JSON number and rejects
+// amounts with more than Scale significant fractional digits.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	str := string(bytes.Trim(data, `"`))
+	d, err := decimal.NewFromString(str)
+	if err != nil {
+		return fmt.Errorf("invalid money amount %s: %w", string(data), err)
+	}
+
+	if !d.Equal(d.Truncate(Scale)) {
+		return fmt.Errorf("money amount %s exceeds %d decimal places", str, Scale)
+	}
+
+	m.Decimal = d
+	return nil
+}
@@ -0,0 +1,69 @@
+package request
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ParamError reports a query parameter that is present but can't be parsed
+type ParamError struct {
+	Param  string
+	Value  string
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Param, e.Value, e.Reason)
+}
+
+// Decimal parses a decimal query parameter.
+// found is false when the parameter is absent or empty; a malformed value returns a *ParamError.
+func Decimal(query url.Values, name string) (value decimal.Decimal, found bool, err error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return decimal.Zero, false, nil
+	}
+
+	value, err = decimal.NewFromString(raw)
+	if err != nil {
+		return decimal.Zero, true, &ParamError{Param: name, Value: raw, Reason: "must be a decimal number"}
+	}
+
+	return value, true, nil
+}
+
+// Int parses an integer query parameter.
+// found is false when the parameter is absent or empty; a malformed value returns a *ParamError.
+func Int(query url.Values, name string) (value int, found bool, err error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return 0, false, nil
+	}
+
+	value, err = strconv.Atoi(raw)
+	if err != nil {
+		return 0, true, &ParamError{Param: name, Value: raw, Reason: "must be an integer"}
+	}
+
+	return value, true, nil
+}
+
+// Bool parses a boolean query parameter (true/false, 1/0, t/f).
+// found is false when the parameter is absent or empty; a malformed value returns a *ParamError.
+func Bool(query url.Values, name string) (value bool, found bool, err error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return false, false, nil
+	}
+
+	value, err = strconv.ParseBool(raw)
+	if err != nil {
+		return false, true, &ParamError{Param: name, Value: raw, Reason: "must be true or false"}
+	}
+
+	return value, true, nil
+}
@@ -2,11 +2,57 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/google/uuid"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/pkg/logger"
 )
 
+// RequestIDHeader carries a request's correlation ID in and back out
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength caps a caller-supplied correlation ID before it goes into the logs
+const maxRequestIDLength = 128
+
+// timestampOptions controls how response timestamps are stamped; set once at startup via ConfigureTimestamps
+var timestampOptions struct {
+	location *time.Location
+	utc      bool
+}
+
+// ConfigureTimestamps sets the zone response timestamps are reported in (nil means the server's local zone).
+// With utc set, timestamps are RFC3339 in UTC at second precision regardless of location.
+func ConfigureTimestamps(location *time.Location, utc bool) {
+	timestampOptions.location = location
+	timestampOptions.utc = utc
+}
+
+// hideErrorDetails keeps raw error text out of error responses; set once at startup via ConfigureErrorDetails
+var hideErrorDetails bool
+
+// ConfigureErrorDetails sets whether error responses include the underlying error text.
+// When hidden, clients get only the message and error code, and the details are logged instead.
+func ConfigureErrorDetails(show bool) {
+	hideErrorDetails = !show
+}
+
+// timestamp returns the current time as configured for responses
+func timestamp() time.Time {
+	now := time.Now()
+	if timestampOptions.utc {
+		return now.UTC().Truncate(time.Second)
+	}
+	if timestampOptions.location != nil {
+		return now.In(timestampOptions.location)
+	}
+	return now
+}
+
 type Response struct {
 	Success   bool        `json:"success"`
 	Message   string      `json:"message,omitempty"`
@@ -17,8 +63,9 @@ type Response struct {
 
 type ErrorResponse struct {
 	Success   bool      `json:"success"`
-	Error     string    `json:"error"`
+	Error     string    `json:"error,omitempty"`
 	Message   string    `json:"message,omitempty"`
+	Code      string    `json:"code,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
@@ -27,7 +74,7 @@ func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	response := Response{
 		Success:   statusCode >= 200 && statusCode < 300,
 		Data:      data,
-		Timestamp: time.Now(),
+		Timestamp: timestamp(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -53,11 +100,20 @@ func Error(w http.ResponseWriter, statusCode int, message string, err error) {
 	response := ErrorResponse{
 		Success:   false,
 		Message:   message,
-		Timestamp: time.Now(),
+		Timestamp: timestamp(),
 	}
 
 	if err != nil {
-		response.Error = err.Error()
+		var businessErr *customError.BusinessError
+		if errors.As(err, &businessErr) {
+			response.Code = businessErr.Code
+		}
+
+		if hideErrorDetails {
+			slog.Error(message, "status", statusCode, "error", err, logger.RequestIDKey, w.Header().Get(RequestIDHeader))
+		} else {
+			response.Error = err.Error()
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -78,8 +134,28 @@ func NotFound(w http.ResponseWriter, message string) {
 	Error(w, http.StatusNotFound, message, nil)
 }
 
-// InternalServerError sends a 500 internal server error response
+// Conflict sends a 409 conflict response
+func Conflict(w http.ResponseWriter, message string, err error) {
+	Error(w, http.StatusConflict, message, err)
+}
+
+// UnprocessableEntity sends a 422 response for a well-formed request the business rules refuse
+func UnprocessableEntity(w http.ResponseWriter, message string, err error) {
+	Error(w, http.StatusUnprocessableEntity, message, err)
+}
+
+// TooManyRequests sends a 429 response for a request that may succeed if retried later
+func TooManyRequests(w http.ResponseWriter, message string, err error) {
+	Error(w, http.StatusTooManyRequests, message, err)
+}
+
+// InternalServerError sends a 500 internal server error response, or a 504 when the failure was a
+// database query running out of time, which the client may retry
 func InternalServerError(w http.ResponseWriter, message string, err error) {
+	if errors.Is(err, customError.ErrQueryTimeout) {
+		Error(w, http.StatusGatewayTimeout, message, err)
+		return
+	}
 	Error(w, http.StatusInternalServerError, message, err)
 }
 
@@ -105,7 +181,7 @@ func JSONMiddleware(next http.Handler) http.Handler {
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
@@ -117,21 +193,48 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// LoggingMiddleware logs HTTP requests
+// LoggingMiddleware tags each request with a correlation ID, taken from X-Request-ID or generated,
+// echoes it back in the response header and carries it in the request context, then writes one
+// access log line per request
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		ctx := logger.WithRequestID(r.Context(), requestID)
+
 		// Create a response recorder to capture the status code
 		recorder := &responseRecorder{ResponseWriter: w, statusCode: 200}
 
-		next.ServeHTTP(recorder, r)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
 
-		duration := time.Since(start)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, recorder.statusCode, duration)
+		logger.FromContext(ctx).Info("http request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.statusCode,
+			"duration", time.Since(start),
+		)
 	})
 }
 
+// validRequestID accepts a caller's correlation ID only if it is short and printable ASCII, so
+// it cannot forge or break log lines
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
 type responseRecorder struct {
 	http.ResponseWriter
 	statusCode int
@@ -6,15 +6,84 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-// CalculateWeeklyPayment calculates the weekly payment amount
+// WeeksPerYear is used to convert annual interest rates into rates for a loan's term
+const WeeksPerYear = 52
+
+// TermInterestRate converts an annual rate into the flat rate charged over a loan of the given length
+// Formula: Annual Rate * Weeks / 52
+func TermInterestRate(annualRate decimal.Decimal, weeks int) decimal.Decimal {
+	return annualRate.Mul(decimal.NewFromInt(int64(weeks))).Div(decimal.NewFromInt(WeeksPerYear))
+}
+
+// CalculateTotalInterest calculates the flat interest charged over the whole term, rounded to places decimal places
+func CalculateTotalInterest(principal decimal.Decimal, termRate decimal.Decimal, places int32) decimal.Decimal {
+	return principal.Mul(termRate).Round(places)
+}
+
+// CalculateWeeklyPayment calculates the weekly payment amount, rounded to places decimal places
 // Formula: (Principal + Interest) / Duration
-func CalculateWeeklyPayment(principal decimal.Decimal, annualRate decimal.Decimal, weeks int) decimal.Decimal {
-	totalInterest := principal.Mul(annualRate)
+func CalculateWeeklyPayment(principal decimal.Decimal, termRate decimal.Decimal, weeks int, places int32) decimal.Decimal {
+	totalInterest := CalculateTotalInterest(principal, termRate, places)
 	totalAmount := principal.Add(totalInterest)
 	weeklyPayment := totalAmount.Div(decimal.NewFromInt(int64(weeks)))
 
-	// Round to 2 decimal places
-	return weeklyPayment.Round(2)
+	return weeklyPayment.Round(places)
+}
+
+// Installment is one week's amount split into principal and interest
+type Installment struct {
+	Principal decimal.Decimal
+	Interest  decimal.Decimal
+	Total     decimal.Decimal
+}
+
+// BuildInstallments splits a loan's repayments into weekly installments.
+// Flat interest is spread evenly over every week. Without an interest-only period each week pays the
+// regular weekly payment (see CalculateWeeklyPayment); with one, the first interestOnlyWeeks pay interest
+// only and the principal is spread evenly over the remaining weeks. Amounts are rounded to places decimal
+// places, and the final week absorbs the rounding remainder so the installments add up exactly to the
+// principal plus the total interest.
+func BuildInstallments(principal decimal.Decimal, termRate decimal.Decimal, weeks int, interestOnlyWeeks int, places int32) []Installment {
+	if interestOnlyWeeks < 0 {
+		interestOnlyWeeks = 0
+	}
+	amortizingWeeks := weeks - interestOnlyWeeks
+
+	totalInterest := CalculateTotalInterest(principal, termRate, places)
+	weeklyInterest := totalInterest.Div(decimal.NewFromInt(int64(weeks))).Round(places)
+
+	weeklyPrincipal := principal.Div(decimal.NewFromInt(int64(amortizingWeeks))).Round(places)
+	if interestOnlyWeeks == 0 {
+		weeklyPrincipal = CalculateWeeklyPayment(principal, termRate, weeks, places).Sub(weeklyInterest)
+	}
+
+	installments := make([]Installment, 0, weeks)
+	for week := 1; week <= weeks; week++ {
+		weekPrincipal, weekInterest := weeklyPrincipal, weeklyInterest
+		if week <= interestOnlyWeeks {
+			weekPrincipal = decimal.Zero
+		}
+		if week == weeks {
+			weekPrincipal = principal.Sub(weeklyPrincipal.Mul(decimal.NewFromInt(int64(amortizingWeeks - 1))))
+			weekInterest = totalInterest.Sub(weeklyInterest.Mul(decimal.NewFromInt(int64(weeks - 1))))
+		}
+		installments = append(installments, Installment{
+			Principal: weekPrincipal,
+			Interest:  weekInterest,
+			Total:     weekPrincipal.Add(weekInterest),
+		})
+	}
+
+	return installments
+}
+
+// RegularInstallment is the installment due on a loan's amortizing weeks: the first week after any
+// interest-only period. Only the final week may differ from it, by the rounding remainder.
+func RegularInstallment(installments []Installment, interestOnlyWeeks int) Installment {
+	if interestOnlyWeeks < 0 {
+		interestOnlyWeeks = 0
+	}
+	return installments[interestOnlyWeeks]
 }
 
 // CalculateDueDate calculates the due date for a specific week
@@ -24,9 +93,10 @@ func CalculateDueDate(loanStartDate time.Time, weekNumber int) time.Time {
 	return loanStartDate.AddDate(0, 0, days)
 }
 
-// GetCurrentWeek calculates which week we're currently in based on loan start date
-func GetCurrentWeek(loanStartDate time.Time, loanEndDate time.Time) int {
-	duration := loanEndDate.Sub(loanStartDate)
+// GetCurrentWeek calculates which week of the loan now falls in. Week 1 runs from the start date
+// (its due date) for 7 days, matching the schedule where week N is due 7*(N-1) days after the start.
+func GetCurrentWeek(loanStartDate time.Time, now time.Time) int {
+	duration := now.Sub(loanStartDate)
 	days := int(duration.Hours() / 24)
 	week := (days / 7) + 1
 
@@ -37,9 +107,45 @@ func GetCurrentWeek(loanStartDate time.Time, loanEndDate time.Time) int {
 	return week
 }
 
-// IsDateOverdue checks if a date is overdue (past current date)
-func IsDateOverdue(dueDate time.Time) bool {
-	return time.Now().After(dueDate)
+// OverdueCutoff returns the due date before which an unpaid week is overdue at now, allowing grace
+// after the due date. Due dates are whole days, so the boundary is the whole day grace after a
+// week's due date: inclusive makes the week overdue from the first second of that day, otherwise
+// it stays on time until the day has passed.
+func OverdueCutoff(now time.Time, grace time.Duration, inclusive bool) time.Time {
+	cutoff := now.Add(-grace).Truncate(24 * time.Hour)
+	if inclusive {
+		cutoff = cutoff.AddDate(0, 0, 1)
+	}
+	return cutoff
+}
+
+// IsDateOverdue reports whether a week due on dueDate is overdue at now; see OverdueCutoff
+func IsDateOverdue(dueDate, now time.Time, grace time.Duration, inclusive bool) bool {
+	return dueDate.Truncate(24 * time.Hour).Before(OverdueCutoff(now, grace, inclusive))
+}
+
+// DateLayout is the calendar date format holidays are written and keyed in
+const DateLayout = "2006-01-02"
+
+// IsBusinessDay reports whether day, in its own location, falls Monday to Friday and is not one
+// of holidays, a set keyed by DateLayout
+func IsBusinessDay(day time.Time, holidays map[string]bool) bool {
+	switch day.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return !holidays[day.Format(DateLayout)]
+}
+
+// NextBusinessDay returns midnight, in day's location, of the first business day after day
+func NextBusinessDay(day time.Time, holidays map[string]bool) time.Time {
+	next := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	for {
+		next = next.AddDate(0, 0, 1)
+		if IsBusinessDay(next, holidays) {
+			return next
+		}
+	}
 }
 
 // DecimalFromFloat converts float64 to decimal.Decimal
@@ -2,21 +2,36 @@
 CREATE TABLE IF NOT EXISTS loans (
     id UUID PRIMARY KEY,
     loan_id VARCHAR(50) UNIQUE NOT NULL,
-    amount DECIMAL(15,2) NOT NULL,
+    borrower_id VARCHAR(100) NOT NULL DEFAULT '',
+    amount DECIMAL(17,4) NOT NULL,
     interest_rate DECIMAL(5,4) NOT NULL,
     duration_weeks INTEGER NOT NULL,
-    weekly_payment DECIMAL(15,2) NOT NULL,
+    weekly_payment DECIMAL(17,4) NOT NULL,
+    interest_only_weeks INTEGER NOT NULL DEFAULT 0,
+    credit_balance DECIMAL(17,4) NOT NULL DEFAULT 0,
+    rebate_amount DECIMAL(17,4) NOT NULL DEFAULT 0,
+    interest_rate_basis VARCHAR(20) NOT NULL DEFAULT 'per_term',
     status VARCHAR(20) DEFAULT 'active',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    forbearance_start TIMESTAMP WITH TIME ZONE,
+    forbearance_end TIMESTAMP WITH TIME ZONE,
+    notes JSONB NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}'
 );
 
+-- Numbers server-generated loan IDs when LOAN_ID_SCHEME=sequence
+CREATE SEQUENCE IF NOT EXISTS loan_id_seq;
+
 -- Create loan_schedule table
 CREATE TABLE IF NOT EXISTS loan_schedule (
     id UUID PRIMARY KEY,
     loan_id VARCHAR(50) NOT NULL REFERENCES loans(loan_id),
     week_number INTEGER NOT NULL,
-    due_amount DECIMAL(15,2) NOT NULL,
+    due_amount DECIMAL(17,4) NOT NULL,
+    principal_amount DECIMAL(17,4) NOT NULL DEFAULT 0,
+    interest_amount DECIMAL(17,4) NOT NULL DEFAULT 0,
     due_date TIMESTAMP WITH TIME ZONE NOT NULL,
     status VARCHAR(20) DEFAULT 'pending',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
@@ -27,14 +42,18 @@ CREATE TABLE IF NOT EXISTS loan_schedule (
 CREATE TABLE IF NOT EXISTS payments (
     id UUID PRIMARY KEY,
     loan_id VARCHAR(50) NOT NULL REFERENCES loans(loan_id),
-    amount DECIMAL(15,2) NOT NULL,
+    amount DECIMAL(17,4) NOT NULL,
     payment_date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     week_number INTEGER NOT NULL,
+    recorded_by VARCHAR(100) NOT NULL DEFAULT '',
+    channel VARCHAR(50) NOT NULL DEFAULT '',
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Create indexes
 CREATE INDEX IF NOT EXISTS idx_loans_loan_id ON loans(loan_id);
+CREATE INDEX IF NOT EXISTS idx_loans_tags ON loans USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_loans_borrower_id ON loans(borrower_id);
 CREATE INDEX IF NOT EXISTS idx_loan_schedule_loan_id ON loan_schedule(loan_id);
 CREATE INDEX IF NOT EXISTS idx_loan_schedule_status ON loan_schedule(status);
 CREATE INDEX IF NOT EXISTS idx_payments_loan_id ON payments(loan_id);
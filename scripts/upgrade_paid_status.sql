@@ -0,0 +1,4 @@
+-- One-off upgrade for databases created by releases that stored paid weeks as 'PAID'.
+-- scripts/init.sql only runs when the database is first created, so run this by hand against an
+-- existing database. It only touches uppercase rows, so running it again is harmless.
+UPDATE loan_schedule SET status = 'paid' WHERE status = 'PAID';
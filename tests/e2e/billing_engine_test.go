@@ -24,6 +24,7 @@ import (
 	"github.com/segyhp/billing-engine/internal/handler"
 	"github.com/segyhp/billing-engine/internal/repository"
 	"github.com/segyhp/billing-engine/internal/service"
+	"github.com/segyhp/billing-engine/pkg/response"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -178,9 +179,10 @@ func setupTestEnvironment(t *testing.T) (*httptest.Server, *sqlx.DB, *redis.Clie
 	// Initialize repositories and services
 	loanRepo := repository.NewLoanRepository(testDB)
 	paymentRepo := repository.NewPaymentRepository(testDB)
-	billingService := service.NewBillingService(loanRepo, paymentRepo, redisClient, cfg)
-	billingHandler := handler.NewBillingHandler(billingService, cfg)
-	healthHandler := handler.NewHealthHandler(testDB, redisClient)
+	unitOfWork := repository.NewUnitOfWork(testDB)
+	billingService := service.NewBillingService(loanRepo, paymentRepo, unitOfWork, redisClient, cfg)
+	billingHandler := handler.NewBillingHandler(billingService, cfg, nil)
+	healthHandler := handler.NewHealthHandler(testDB, redisClient, true)
 
 	// Setup routes
 	router := setupTestRoutes(billingHandler, healthHandler)
@@ -258,6 +260,22 @@ func TestBillingEngineEndToEnd(t *testing.T) {
 		assert.Equal(t, 2, paymentResponse.Payment.WeekNumber)
 		assert.False(t, paymentResponse.IsDelinquent)
 
+		// The schedule shows the two paid weeks followed by the pending rest of the plan
+		schedule := getSchedule(t, server.URL, loanID)
+		require.Len(t, schedule.Schedule, durationWeeks)
+		for i, week := range schedule.Schedule {
+			assert.Equal(t, i+1, week.WeekNumber)
+			assert.True(t, expectedWeeklyPayment.Equal(week.DueAmount))
+			if i > 0 {
+				assert.WithinDuration(t, schedule.Schedule[i-1].DueDate.AddDate(0, 0, 7), week.DueDate, time.Hour)
+			}
+			if week.WeekNumber <= 2 {
+				assert.Equal(t, domain.ScheduleStatusPaid, week.Status)
+			} else {
+				assert.Equal(t, domain.ScheduleStatusPending, week.Status)
+			}
+		}
+
 		// Step 6: Simulate Overdue Payments (make payments overdue)
 		t.Log("Step 6: Simulating overdue payments")
 		simulateOverduePayments(t, db, loanID, 3) // Make week 3+ overdue
@@ -266,7 +284,7 @@ func TestBillingEngineEndToEnd(t *testing.T) {
 		t.Log("Step 7: Checking delinquency after overdue")
 		delinquency = checkDelinquency(t, server.URL, loanID)
 		assert.True(t, delinquency.IsDelinquent)
-		assert.Equal(t, 2, delinquency.MissedWeeks) // Hardcoded in handler
+		assert.Equal(t, 48, delinquency.MissedWeeks) // Weeks 3-50 are all overdue and unpaid
 
 		// Step 8: Make Payment While Delinquent
 		t.Log("Step 8: Making payment while delinquent")
@@ -275,19 +293,28 @@ func TestBillingEngineEndToEnd(t *testing.T) {
 		assert.Equal(t, 3, paymentResponse.Payment.WeekNumber) // Should pay earliest unpaid
 		// Note: IsDelinquent might still be true if there are more overdue payments
 
-		// Step 9: Test Invalid Payment Amount
-		t.Log("Step 9: Testing invalid payment amount")
-		invalidAmount := decimal.NewFromFloat(50000) // Less than weekly payment
-		resp := makePaymentRequest(t, server.URL, loanID, invalidAmount)
-		defer resp.Body.Close()
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		// Step 9: Partial Payment
+		t.Log("Step 9: Making a partial payment")
+		partialAmount := decimal.NewFromFloat(50000) // Less than weekly payment
+		paymentResponse = makePayment(t, server.URL, loanID, partialAmount)
+		assert.Equal(t, 4, paymentResponse.Payment.WeekNumber) // Recorded against week 4, which stays unpaid
+
+		paymentResponse = makePayment(t, server.URL, loanID, expectedWeeklyPayment.Sub(partialAmount))
+		assert.Equal(t, 4, paymentResponse.Payment.WeekNumber) // Tops week 4 up to its due amount
 
 		// Step 10: Test Payment for Non-existent Loan
 		t.Log("Step 10: Testing payment for non-existent loan")
-		resp = makePaymentRequest(t, server.URL, "NON-EXISTENT", expectedWeeklyPayment)
+		resp := makePaymentRequest(t, server.URL, "NON-EXISTENT", expectedWeeklyPayment)
 		defer resp.Body.Close()
 		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 
+		// Step 11: Schedule for Non-existent Loan
+		t.Log("Step 11: Testing schedule for non-existent loan")
+		resp, err := http.Get(server.URL + "/api/v1/loans/NON-EXISTENT/schedule")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
 		t.Log("✅ E2E Test completed successfully")
 	})
 }
@@ -295,6 +322,7 @@ func TestBillingEngineEndToEnd(t *testing.T) {
 // setupTestRoutes sets up the test routes
 func setupTestRoutes(billingHandler *handler.BillingHandler, healthHandler *handler.HealthHandler) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(response.LoggingMiddleware)
 
 	// Health check
 	router.HandleFunc("/health", healthHandler.Health).Methods("GET")
@@ -303,9 +331,35 @@ func setupTestRoutes(billingHandler *handler.BillingHandler, healthHandler *hand
 	// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
 	api.HandleFunc("/loans", billingHandler.CreateLoan).Methods("POST")
+	api.HandleFunc("/loans/batch", billingHandler.CreateLoansBatch).Methods("POST")
+	api.HandleFunc("/loans", billingHandler.ListLoans).Methods("GET")
+	api.HandleFunc("/loans/{loanId}", billingHandler.LoanExists).Methods("HEAD")
+	api.HandleFunc("/loans/{loanId}", billingHandler.GetLoan).Methods("GET")
 	api.HandleFunc("/loans/{loanId}/outstanding", billingHandler.GetOutstanding).Methods("GET")
+	api.HandleFunc("/loans/outstanding/batch", billingHandler.GetOutstandingBatch).Methods("POST")
+	api.HandleFunc("/loans/delinquent/batch", billingHandler.GetDelinquencyBatch).Methods("POST")
 	api.HandleFunc("/loans/{loanId}/delinquent", billingHandler.IsDelinquent).Methods("GET")
 	api.HandleFunc("/loans/{loanId}/payment", billingHandler.MakePayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/payments", billingHandler.GetLoanPayments).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/payments/undo", billingHandler.UndoLatestPayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/payments/{paymentId}/reverse", billingHandler.ReversePayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/schedule", billingHandler.GetSchedule).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/schedule/integrity", billingHandler.CheckScheduleIntegrity).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/remaining", billingHandler.GetRemaining).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/current-week", billingHandler.GetCurrentWeek).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/interest-paid", billingHandler.GetInterestPaid).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/cost", billingHandler.GetCostOfCredit).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/payment-streak", billingHandler.GetPaymentStreak).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/delinquency-history", billingHandler.GetDelinquencyHistory).Methods("GET")
+	api.HandleFunc("/loans/{loanId}/forbearance", billingHandler.SetForbearance).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/notes", billingHandler.UpdateNotes).Methods("PATCH")
+	api.HandleFunc("/loans/{loanId}/recompute-payment", billingHandler.RecomputeWeeklyPayment).Methods("POST")
+	api.HandleFunc("/loans/{loanId}/export", billingHandler.ExportLoan).Methods("GET")
+	api.HandleFunc("/borrowers/{borrowerId}/loans", billingHandler.GetBorrowerLoans).Methods("GET")
+	api.HandleFunc("/payments", billingHandler.ListPayments).Methods("GET")
+	api.HandleFunc("/payments/{paymentId}", billingHandler.GetPayment).Methods("GET")
+	api.HandleFunc("/reports/collections", billingHandler.GetCollectionsReport).Methods("GET")
+	api.HandleFunc("/reports/expected-collections", billingHandler.GetExpectedCollectionsReport).Methods("GET")
 
 	return router
 }
@@ -348,7 +402,23 @@ func getOutstanding(t *testing.T, serverURL, loanID string) decimal.Decimal {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	require.NoError(t, err)
 
-	return response.Data.Outstanding
+	return response.Data.Outstanding.Decimal
+}
+
+func getSchedule(t *testing.T, serverURL, loanID string) *domain.ScheduleResponse {
+	resp, err := http.Get(serverURL + "/api/v1/loans/" + loanID + "/schedule")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data domain.ScheduleResponse `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	return &response.Data
 }
 
 func checkDelinquency(t *testing.T, serverURL, loanID string) *domain.DelinquentResponse {
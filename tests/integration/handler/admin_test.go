@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/handler"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_CloseFullyPaidLoans(t *testing.T) {
+	admin := config.AdminConfig{Username: "admin", Password: "secret"}
+	closedLoan := func(loanID string) interface{} {
+		return mock.MatchedBy(func(loan *domain.Loan) bool {
+			return loan.LoanID == loanID && loan.Status == domain.LoanStatusClosed
+		})
+	}
+
+	tests := []struct {
+		name           string
+		cfg            config.AdminConfig
+		setAuth        func(*http.Request)
+		setupMock      func(*mocks.MockLoanRepository)
+		expectedStatus int
+		expectedBody   string
+		expectedClosed int64
+	}{
+		{
+			name:    "Success - Only fully paid open loans close",
+			cfg:     admin,
+			setAuth: func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+			setupMock: func(m *mocks.MockLoanRepository) {
+				m.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002", "LOAN003", "LOAN004"}, nil).Once()
+
+				// Fully paid
+				m.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN001").Return(nil, customError.WrapNoOutstandingBalance("LOAN001")).Once()
+				m.On("CountSchedule", mock.Anything, "LOAN001").Return(50, nil).Once()
+				m.On("GetByLoanID", mock.Anything, "LOAN001").Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusActive}, nil).Once()
+				m.On("Update", mock.Anything, closedLoan("LOAN001")).Return(nil).Once()
+
+				// Still owing
+				m.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN002").
+					Return(&domain.LoanSchedule{LoanID: "LOAN002", WeekNumber: 7, Status: domain.ScheduleStatusOverdue}, nil).Once()
+
+				// No schedule at all
+				m.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN003").Return(nil, customError.WrapNoOutstandingBalance("LOAN003")).Once()
+				m.On("CountSchedule", mock.Anything, "LOAN003").Return(0, nil).Once()
+
+				// Fully paid and delinquent
+				m.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN004").Return(nil, customError.WrapNoOutstandingBalance("LOAN004")).Once()
+				m.On("CountSchedule", mock.Anything, "LOAN004").Return(50, nil).Once()
+				m.On("GetByLoanID", mock.Anything, "LOAN004").Return(&domain.Loan{LoanID: "LOAN004", Status: domain.LoanStatusDelinquent}, nil).Once()
+				m.On("Update", mock.Anything, closedLoan("LOAN004")).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedClosed: 2,
+		},
+		{
+			name:    "Success - Nothing to close",
+			cfg:     admin,
+			setAuth: func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+			setupMock: func(m *mocks.MockLoanRepository) {
+				m.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedClosed: 0,
+		},
+		{
+			name:    "Database error - Internal server error",
+			cfg:     admin,
+			setAuth: func(r *http.Request) { r.SetBasicAuth("admin", "secret") },
+			setupMock: func(m *mocks.MockLoanRepository) {
+				m.On("ListActiveLoanIDs", mock.Anything, "", 10).Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to close fully paid loans",
+		},
+		{
+			name:           "Missing credentials - Unauthorized",
+			cfg:            admin,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Admin credentials required",
+		},
+		{
+			name:           "Wrong password - Unauthorized",
+			cfg:            admin,
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("admin", "wrong") },
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Admin credentials required",
+		},
+		{
+			name:           "Admin API disabled - Not found",
+			cfg:            config.AdminConfig{},
+			setAuth:        func(r *http.Request) { r.SetBasicAuth("", "") },
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Admin API is disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockLoanRepo)
+			}
+
+			cfg := &config.Config{
+				App: config.AppConfig{
+					SchedulerBatchSize: 10,
+					BatchConcurrency:   2,
+				},
+			}
+			adminHandler := handler.NewAdminHandler(tt.cfg, scheduler.NewScheduler(mockLoanRepo, cfg))
+
+			router := mux.NewRouter()
+			adminRoutes := router.PathPrefix("/api/v1/admin").Subrouter()
+			adminRoutes.Use(adminHandler.RequireAuth)
+			adminRoutes.HandleFunc("/loans/close-fully-paid", adminHandler.CloseFullyPaidLoans).Methods("POST")
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/loans/close-fully-paid", nil)
+			if tt.setAuth != nil {
+				tt.setAuth(req)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp struct {
+					Success   bool                               `json:"success"`
+					Data      domain.CloseFullyPaidLoansResponse `json:"data"`
+					Timestamp time.Time                          `json:"timestamp"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.True(t, resp.Success)
+				assert.Equal(t, tt.expectedClosed, resp.Data.Closed)
+			} else {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="admin"`, w.Header().Get("WWW-Authenticate"))
+			}
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -2,21 +2,30 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/domain"
 	"github.com/segyhp/billing-engine/internal/handler"
+	"github.com/segyhp/billing-engine/internal/metrics"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
 	"github.com/segyhp/billing-engine/tests/mocks"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBillingHandler_CreateLoan(t *testing.T) {
@@ -102,9 +111,9 @@ func TestBillingHandler_CreateLoan(t *testing.T) {
 		},
 		{
 			name: "successful loan creation with default values",
-			requestBody: domain.CreateLoanRequest{
-				LoanID: "loan456",
-				// Amount, DurationWeeks, InterestRate will use defaults
+			requestBody: map[string]interface{}{
+				"loan_id": "loan456",
+				// Amount, DurationWeeks, InterestRate are omitted so defaults apply
 			},
 			setupMock: func(mockService *mocks.MockBillingService) {
 				expectedLoan := &domain.Loan{
@@ -151,6 +160,176 @@ func TestBillingHandler_CreateLoan(t *testing.T) {
 				assert.Equal(t, "loan456", response.Loan.LoanID)
 			},
 		},
+		{
+			name: "explicit zero interest rate is kept",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-zero-rate",
+				"amount":         1000,
+				"duration_weeks": 10,
+				"interest_rate":  0,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				expectedLoan := &domain.Loan{
+					LoanID:        "loan-zero-rate",
+					Amount:        decimal.NewFromInt(1000),
+					DurationWeeks: 10,
+					InterestRate:  decimal.Zero,
+					WeeklyPayment: decimal.NewFromInt(100),
+					Status:        domain.LoanStatusActive,
+				}
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-zero-rate" && req.InterestRate.IsZero()
+				})).Return(expectedLoan, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "string zero interest rate is kept",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-zero-rate-str",
+				"amount":         "1000",
+				"duration_weeks": 10,
+				"interest_rate":  "0",
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-zero-rate-str" && req.InterestRate.IsZero()
+				})).Return(&domain.Loan{LoanID: "loan-zero-rate-str"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "omitted interest rate uses default",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-default-rate",
+				"amount":         1000,
+				"duration_weeks": 10,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-default-rate" && req.InterestRate.Equal(decimal.NewFromFloat(10.0))
+				})).Return(&domain.Loan{LoanID: "loan-default-rate"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "null interest rate uses default",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-null-rate",
+				"amount":         1000,
+				"duration_weeks": 10,
+				"interest_rate":  nil,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-null-rate" && req.InterestRate.Equal(decimal.NewFromFloat(10.0))
+				})).Return(&domain.Loan{LoanID: "loan-null-rate"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "omitted amount and duration use defaults",
+			requestBody: map[string]interface{}{
+				"loan_id":       "loan-default-amount",
+				"interest_rate": 0.1,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-default-amount" &&
+						req.Amount.Equal(decimal.NewFromFloat(1000.0)) &&
+						req.DurationWeeks == 50
+				})).Return(&domain.Loan{LoanID: "loan-default-amount"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "null amount uses default",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-null-amount",
+				"amount":         nil,
+				"duration_weeks": 10,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-null-amount" && req.Amount.Equal(decimal.NewFromFloat(1000.0))
+				})).Return(&domain.Loan{LoanID: "loan-null-amount"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "positive amount is kept",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-positive-amount",
+				"amount":         "2500.50",
+				"duration_weeks": 10,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-positive-amount" && req.Amount.Equal(decimal.RequireFromString("2500.50"))
+				})).Return(&domain.Loan{LoanID: "loan-positive-amount"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "explicit zero amount is rejected instead of defaulted",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-zero-amount",
+				"amount":         0,
+				"duration_weeks": 10,
+			},
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name: "string zero amount is rejected instead of defaulted",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-zero-amount-str",
+				"amount":         "0",
+				"duration_weeks": 10,
+			},
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name: "explicit zero duration is rejected instead of defaulted",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-zero-weeks",
+				"amount":         1000,
+				"duration_weeks": 0,
+			},
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name: "tags are passed through on create",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-tagged",
+				"amount":         1000,
+				"duration_weeks": 10,
+				"tags":           []string{"branch-a", "micro"},
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "loan-tagged" && assert.ObjectsAreEqual([]string{"branch-a", "micro"}, req.Tags)
+				})).Return(&domain.Loan{LoanID: "loan-tagged"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "blank tag is rejected",
+			requestBody: map[string]interface{}{
+				"loan_id":        "loan-blank-tag",
+				"amount":         1000,
+				"duration_weeks": 10,
+				"tags":           []string{""},
+			},
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
 		{
 			name:           "invalid JSON payload",
 			requestBody:    "invalid json",
@@ -205,6 +384,19 @@ func TestBillingHandler_CreateLoan(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Validation failed",
 		},
+		{
+			name: "validation error - interest-only period covers the whole term",
+			requestBody: domain.CreateLoanRequest{
+				LoanID:            "loan789",
+				Amount:            decimal.NewFromFloat(1000.0),
+				DurationWeeks:     10,
+				InterestOnlyWeeks: 10,
+				InterestRate:      decimal.NewFromFloat(0.10),
+			},
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
 		{
 			name: "service error - loan already exists",
 			requestBody: domain.CreateLoanRequest{
@@ -221,6 +413,23 @@ func TestBillingHandler_CreateLoan(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "Failed to create loan",
 		},
+		{
+			name: "service error - schedule beyond maximum horizon",
+			requestBody: domain.CreateLoanRequest{
+				LoanID:        "long_loan",
+				Amount:        decimal.NewFromFloat(1500.0),
+				DurationWeeks: 5000,
+				InterestRate:  decimal.NewFromFloat(0.12),
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "long_loan"
+				})).Return((*domain.Loan)(nil), ([]*domain.LoanSchedule)(nil),
+					customError.WrapScheduleTooLong(time.Now().AddDate(0, 0, 7*4999), 520)).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Loan schedule exceeds the maximum horizon",
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,7 +439,7 @@ func TestBillingHandler_CreateLoan(t *testing.T) {
 			tt.setupMock(mockService)
 
 			// Create handler with mock service
-			billingHandler := handler.NewBillingHandler(mockService, cfg)
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
 
 			// Create request
 			var body bytes.Buffer
@@ -287,8 +496,22 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 			name:   "successful outstanding calculation",
 			loanID: "loan123",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("GetOutstanding", mock.Anything, "loan123").
-					Return(decimal.NewFromFloat(1500.50), nil).Once()
+				mockService.On("GetLoan", mock.Anything, "loan123").
+					Return(&domain.Loan{LoanID: "loan123"}, nil).Once()
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "loan123").
+					Return(&domain.OutstandingBreakdown{
+						PrincipalOutstanding: decimal.NewFromFloat(1200.25),
+						InterestOutstanding:  decimal.NewFromFloat(300.25),
+						TotalOutstanding:     decimal.NewFromFloat(1500.50),
+					}, nil).Once()
+				mockService.On("GetTotalPaid", mock.Anything, "loan123").
+					Return(decimal.NewFromInt(330000), nil).Once()
+				mockService.On("GetNextDue", mock.Anything, "loan123").
+					Return(&domain.NextDue{
+						WeekNumber: 4,
+						DueDate:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+						DueAmount:  decimal.NewFromInt(110000),
+					}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -303,18 +526,68 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 				response := wrapperResponse.Data
 				assert.Equal(t, "loan123", response.LoanID)
 				assert.True(t, response.Outstanding.Equal(decimal.NewFromFloat(1500.50)))
+				assert.True(t, response.TotalOutstanding.Equal(decimal.NewFromFloat(1500.50)))
+				assert.True(t, response.PrincipalOutstanding.Equal(decimal.NewFromFloat(1200.25)))
+				assert.True(t, response.InterestOutstanding.Equal(decimal.NewFromFloat(300.25)))
+				assert.True(t, response.TotalPaid.Equal(decimal.NewFromInt(330000)))
+				require.NotNil(t, response.NextDue)
+				assert.Equal(t, 4, response.NextDue.WeekNumber)
+				assert.True(t, response.NextDue.DueDate.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+				assert.True(t, response.NextDue.DueAmount.Equal(decimal.NewFromInt(110000)))
 			},
 		},
 		{
 			name:   "loan not found",
 			loanID: "nonexistent",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("GetOutstanding", mock.Anything, "nonexistent").
-					Return(decimal.Zero, assert.AnError).Once()
+				mockService.On("GetLoan", mock.Anything, "nonexistent").
+					Return(&domain.Loan{LoanID: "nonexistent"}, nil).Once()
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "nonexistent").
+					Return(nil, assert.AnError).Once()
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "Failed to get outstanding",
 		},
+		{
+			name:   "total paid lookup fails",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "loan123").
+					Return(&domain.Loan{LoanID: "loan123"}, nil).Once()
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "loan123").
+					Return(&domain.OutstandingBreakdown{TotalOutstanding: decimal.NewFromInt(110000)}, nil).Once()
+				mockService.On("GetTotalPaid", mock.Anything, "loan123").
+					Return(decimal.Zero, customError.WrapDatabaseError(assert.AnError)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get total paid",
+		},
+		{
+			name:   "next due lookup fails",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "loan123").
+					Return(&domain.Loan{LoanID: "loan123"}, nil).Once()
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "loan123").
+					Return(&domain.OutstandingBreakdown{TotalOutstanding: decimal.NewFromInt(110000)}, nil).Once()
+				mockService.On("GetTotalPaid", mock.Anything, "loan123").
+					Return(decimal.NewFromInt(110000), nil).Once()
+				mockService.On("GetNextDue", mock.Anything, "loan123").
+					Return(nil, customError.WrapDatabaseError(assert.AnError)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get next due payment",
+		},
+		{
+			name:   "unknown loan",
+			loanID: "missing",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "missing").
+					Return(nil, customError.WrapLoanNotFound("missing")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
 		{
 			name:           "missing loan ID",
 			loanID:         "",
@@ -326,8 +599,14 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 			name:   "zero outstanding balance",
 			loanID: "paid_loan",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("GetOutstanding", mock.Anything, "paid_loan").
-					Return(decimal.Zero, nil).Once()
+				mockService.On("GetLoan", mock.Anything, "paid_loan").
+					Return(&domain.Loan{LoanID: "paid_loan"}, nil).Once()
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "paid_loan").
+					Return(&domain.OutstandingBreakdown{}, nil).Once()
+				mockService.On("GetTotalPaid", mock.Anything, "paid_loan").
+					Return(decimal.NewFromInt(5500000), nil).Once()
+				mockService.On("GetNextDue", mock.Anything, "paid_loan").
+					Return(nil, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -342,6 +621,10 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 				response := wrapperResponse.Data
 				assert.Equal(t, "paid_loan", response.LoanID)
 				assert.True(t, response.Outstanding.Equal(decimal.Zero))
+				assert.True(t, response.PrincipalOutstanding.Equal(decimal.Zero))
+				assert.True(t, response.InterestOutstanding.Equal(decimal.Zero))
+				assert.True(t, response.TotalPaid.Equal(decimal.NewFromInt(5500000)))
+				assert.Nil(t, response.NextDue)
 			},
 		},
 	}
@@ -351,7 +634,7 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 			mockService := mocks.NewMockBillingService()
 			tt.setupMock(mockService)
 
-			billingHandler := handler.NewBillingHandler(mockService, cfg)
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
 
 			// Create request with loan ID in URL path
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/outstanding", nil)
@@ -378,6 +661,63 @@ func TestBillingHandler_GetOutstanding(t *testing.T) {
 	}
 }
 
+func TestBillingHandler_GetOutstanding_DisplayPrecision(t *testing.T) {
+	// The service works at storage precision; only the response is rounded
+	stored := &domain.OutstandingBreakdown{
+		PrincipalOutstanding: decimal.RequireFromString("142857.1449"),
+		InterestOutstanding:  decimal.RequireFromString("14285.7122"),
+		TotalOutstanding:     decimal.RequireFromString("157142.8571"),
+	}
+	zero, four := int32(0), int32(4)
+
+	tests := []struct {
+		name         string
+		display      *int32
+		expectedBody []string
+	}{
+		{
+			name: "unset display precision shows cents",
+			// 142857.14 + 14285.71 would come to a cent short of the total
+			expectedBody: []string{`"outstanding":"157142.86"`, `"principal_outstanding":"142857.14"`, `"interest_outstanding":"14285.72"`},
+		},
+		{
+			name:         "whole units",
+			display:      &zero,
+			expectedBody: []string{`"outstanding":"157143"`, `"principal_outstanding":"142857"`, `"interest_outstanding":"14286"`},
+		},
+		{
+			name:         "full stored precision",
+			display:      &four,
+			expectedBody: []string{`"outstanding":"157142.8571"`, `"principal_outstanding":"142857.1449"`, `"interest_outstanding":"14285.7122"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			mockService.On("GetLoan", mock.Anything, "loan267").Return(&domain.Loan{LoanID: "loan267"}, nil).Once()
+			mockService.On("GetOutstandingBreakdown", mock.Anything, "loan267").Return(stored, nil).Once()
+			mockService.On("GetTotalPaid", mock.Anything, "loan267").Return(decimal.NewFromInt(330000), nil).Once()
+			mockService.On("GetNextDue", mock.Anything, "loan267").Return(nil, nil).Once()
+
+			cfg := &config.Config{App: config.AppConfig{StoragePrecision: &four, DisplayPrecision: tt.display}}
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan267/outstanding", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan267"})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetOutstanding(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestBillingHandler_IsDelinquent(t *testing.T) {
 	cfg := &config.Config{
 		App: config.AppConfig{
@@ -399,8 +739,8 @@ func TestBillingHandler_IsDelinquent(t *testing.T) {
 			name:   "successful delinquency check - not delinquent",
 			loanID: "loan123",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("IsDelinquent", mock.Anything, "loan123").
-					Return(false, nil).Once()
+				mockService.On("GetDelinquencyStatus", mock.Anything, "loan123").
+					Return(&domain.DelinquentResponse{LoanID: "loan123"}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -422,8 +762,8 @@ func TestBillingHandler_IsDelinquent(t *testing.T) {
 			name:   "successful delinquency check - is delinquent",
 			loanID: "loan456",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("IsDelinquent", mock.Anything, "loan456").
-					Return(true, nil).Once()
+				mockService.On("GetDelinquencyStatus", mock.Anything, "loan456").
+					Return(&domain.DelinquentResponse{LoanID: "loan456", IsDelinquent: true, MissedWeeks: 3}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -438,15 +778,15 @@ func TestBillingHandler_IsDelinquent(t *testing.T) {
 				response := wrapperResponse.Data
 				assert.Equal(t, "loan456", response.LoanID)
 				assert.True(t, response.IsDelinquent)
-				assert.Equal(t, 2, response.MissedWeeks)
+				assert.Equal(t, 3, response.MissedWeeks)
 			},
 		},
 		{
 			name:   "loan not found",
 			loanID: "nonexistent",
 			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("IsDelinquent", mock.Anything, "nonexistent").
-					Return(false, assert.AnError).Once()
+				mockService.On("GetDelinquencyStatus", mock.Anything, "nonexistent").
+					Return(nil, assert.AnError).Once()
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "Failed to check delinquency",
@@ -458,16 +798,6 @@ func TestBillingHandler_IsDelinquent(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Loan ID is required",
 		},
-		{
-			name:   "closed loan - not delinquent",
-			loanID: "closed_loan",
-			setupMock: func(mockService *mocks.MockBillingService) {
-				mockService.On("IsDelinquent", mock.Anything, "closed_loan").
-					Return(false, assert.AnError).Once() // Service returns error for closed loans
-			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "Failed to check delinquency",
-		},
 	}
 
 	for _, tt := range tests {
@@ -475,7 +805,7 @@ func TestBillingHandler_IsDelinquent(t *testing.T) {
 			mockService := mocks.NewMockBillingService()
 			tt.setupMock(mockService)
 
-			billingHandler := handler.NewBillingHandler(mockService, cfg)
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
 
 			// Create request with loan ID in URL path
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/delinquent", nil)
@@ -545,6 +875,22 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 
 				mockService.On("IsDelinquent", mock.Anything, "loan123").
 					Return(false, nil).Once()
+
+				mockService.On("GetNextDue", mock.Anything, "loan123").
+					Return(&domain.NextDue{
+						WeekNumber: 2,
+						DueDate:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+						DueAmount:  decimal.NewFromFloat(23.0),
+					}, nil).Once()
+
+				mockService.On("GetScheduleWeek", mock.Anything, "loan123", 1).
+					Return(&domain.LoanSchedule{
+						LoanID:     "loan123",
+						WeekNumber: 1,
+						DueAmount:  decimal.NewFromFloat(23.0),
+						DueDate:    time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC),
+						Status:     domain.ScheduleStatusPaid,
+					}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -564,6 +910,16 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 				assert.True(t, response.Outstanding.Equal(decimal.NewFromFloat(977.0)))
 				assert.False(t, response.IsDelinquent)
 				assert.Equal(t, 1, response.PaidWeekNumber)
+				require.NotNil(t, response.NextDue)
+				assert.Equal(t, 2, response.NextDue.WeekNumber)
+				assert.True(t, response.NextDue.DueDate.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)))
+				assert.True(t, response.NextDue.DueAmount.Equal(decimal.NewFromFloat(23.0)))
+				require.NotNil(t, response.SettledWeek)
+				assert.Equal(t, 1, response.SettledWeek.WeekNumber)
+				assert.True(t, response.SettledWeek.DueDate.Equal(time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)))
+				assert.True(t, response.SettledWeek.DueAmount.Equal(decimal.NewFromFloat(23.0)))
+				assert.Equal(t, domain.ScheduleStatusPaid, response.SettledWeek.Status)
+				assert.False(t, response.SettledWeek.IsOverdue)
 			},
 		},
 		{
@@ -591,6 +947,12 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 
 				mockService.On("IsDelinquent", mock.Anything, "loan456").
 					Return(false, nil).Once()
+
+				mockService.On("GetNextDue", mock.Anything, "loan456").
+					Return(nil, nil).Once()
+
+				mockService.On("GetScheduleWeek", mock.Anything, "loan456", 50).
+					Return(&domain.LoanSchedule{LoanID: "loan456", WeekNumber: 50, Status: domain.ScheduleStatusPaid}, nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
@@ -605,6 +967,8 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 				response := wrapperResponse.Data
 				assert.True(t, response.Outstanding.Equal(decimal.Zero))
 				assert.Equal(t, 50, response.PaidWeekNumber)
+				assert.Nil(t, response.NextDue)
+				assert.Contains(t, w.Body.String(), `"next_due":null`)
 			},
 		},
 		{
@@ -615,6 +979,47 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Invalid JSON payload",
 		},
+		{
+			name:   "payment date outside the backdate window",
+			loanID: "loan123",
+			requestBody: map[string]interface{}{
+				"amount":       23,
+				"payment_date": "2020-01-01T00:00:00Z",
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("MakePayment", mock.Anything, mock.MatchedBy(func(req domain.MakePaymentRequest) bool {
+					return req.PaymentDate != nil && req.PaymentDate.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+				})).Return(nil, customError.WrapInvalidPaymentDate("payments can be backdated at most 7 days")).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid payment date",
+		},
+		{
+			name:   "payment above the per-transaction maximum",
+			loanID: "loan123",
+			requestBody: map[string]interface{}{
+				"amount": 5000000,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("MakePayment", mock.Anything, mock.Anything).
+					Return(nil, customError.WrapPaymentAmountTooLarge("1000000", "5000000")).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Payment amount exceeds the per-transaction maximum",
+		},
+		{
+			name:   "payment right after the previous one",
+			loanID: "loan123",
+			requestBody: map[string]interface{}{
+				"amount": 110000,
+			},
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("MakePayment", mock.Anything, mock.Anything).
+					Return(nil, customError.WrapPaymentTooSoon("loan123", 5*time.Second, time.Minute)).Once()
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   "PAYMENT_TOO_SOON",
+		},
 		{
 			name:   "validation error - zero amount",
 			loanID: "loan123",
@@ -682,7 +1087,7 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 			mockService := mocks.NewMockBillingService()
 			tt.setupMock(mockService)
 
-			billingHandler := handler.NewBillingHandler(mockService, cfg)
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
 
 			// Create request body
 			var body bytes.Buffer
@@ -717,3 +1122,3246 @@ func TestBillingHandler_MakePayment(t *testing.T) {
 		})
 	}
 }
+
+func TestBillingHandler_GetSchedule(t *testing.T) {
+	cfg := &config.Config{
+		App: config.AppConfig{
+			LoanAmount:         1000.0,
+			LoanDurationWeeks:  50,
+			AnnualInterestRate: 10.0,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "successful schedule retrieval",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				schedule := []*domain.LoanSchedule{
+					{ID: uuid.New(), LoanID: "loan123", WeekNumber: 1, DueAmount: decimal.NewFromFloat(23.0), DueDate: time.Now(), Status: domain.ScheduleStatusPaid},
+					{ID: uuid.New(), LoanID: "loan123", WeekNumber: 2, DueAmount: decimal.NewFromFloat(23.0), DueDate: time.Now().AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+				}
+				mockService.On("GetSchedule", mock.Anything, "loan123").Return(schedule, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success   bool                    `json:"success"`
+					Data      domain.ScheduleResponse `json:"data"`
+					Timestamp time.Time               `json:"timestamp"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Equal(t, "loan123", response.LoanID)
+				assert.True(t, response.HasSchedule)
+				assert.Len(t, response.Schedule, 2)
+				assert.Equal(t, domain.ScheduleStatusPaid, response.Schedule[0].Status)
+				assert.True(t, response.Schedule[1].DueAmount.Equal(decimal.NewFromFloat(23.0)))
+			},
+		},
+		{
+			name:   "loan with empty schedule",
+			loanID: "loan_empty",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetSchedule", mock.Anything, "loan_empty").
+					Return([]*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"schedule":[]`,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                    `json:"success"`
+					Data    domain.ScheduleResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				assert.False(t, wrapperResponse.Data.HasSchedule)
+				assert.NotNil(t, wrapperResponse.Data.Schedule)
+				assert.Len(t, wrapperResponse.Data.Schedule, 0)
+			},
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetSchedule", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetSchedule", mock.Anything, "loan123").
+					Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get schedule",
+		},
+		{
+			name:   "query timed out",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetSchedule", mock.Anything, "loan123").
+					Return(nil, customError.WrapDatabaseError(customError.WrapQueryTimeout(context.DeadlineExceeded))).Once()
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedBody:   customError.ErrCodeQueryTimeout,
+		},
+		{
+			name:           "missing loan ID",
+			loanID:         "",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Loan ID is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/schedule", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetSchedule(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetSchedule_OverdueFilter(t *testing.T) {
+	now := time.Now()
+	// Stored statuses and due dates disagree on purpose: the filter must go by due date
+	schedule := []*domain.LoanSchedule{
+		{LoanID: "loan123", WeekNumber: 1, DueDate: now.AddDate(0, 0, -21), Status: domain.ScheduleStatusPaid},
+		{LoanID: "loan123", WeekNumber: 2, DueDate: now.AddDate(0, 0, -14), Status: "PAID"},
+		{LoanID: "loan123", WeekNumber: 3, DueDate: now.AddDate(0, 0, -10), Status: domain.ScheduleStatusPending},
+		{LoanID: "loan123", WeekNumber: 4, DueDate: now.AddDate(0, 0, -8), Status: domain.ScheduleStatusOverdue},
+		{LoanID: "loan123", WeekNumber: 5, DueDate: now.AddDate(0, 0, -1), Status: domain.ScheduleStatusOverdue},
+		{LoanID: "loan123", WeekNumber: 6, DueDate: now.AddDate(0, 0, 6), Status: domain.ScheduleStatusPending},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		graceDays      int
+		expectedStatus int
+		expectedBody   string
+		expectedWeeks  []int
+	}{
+		{
+			name:           "no filter returns every stored row",
+			query:          "",
+			expectedStatus: http.StatusOK,
+			expectedWeeks:  []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:           "overdue=false returns every stored row",
+			query:          "?overdue=false",
+			expectedStatus: http.StatusOK,
+			expectedWeeks:  []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:           "overdue without grace includes unpaid past-due rows regardless of stored status",
+			query:          "?overdue=true",
+			expectedStatus: http.StatusOK,
+			expectedWeeks:  []int{3, 4, 5},
+		},
+		{
+			name:           "overdue with grace excludes rows still inside the grace period",
+			query:          "?overdue=true",
+			graceDays:      3,
+			expectedStatus: http.StatusOK,
+			expectedWeeks:  []int{3, 4},
+		},
+		{
+			name:           "malformed overdue flag",
+			query:          "?overdue=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid query parameter overdue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("GetSchedule", mock.Anything, "loan123").Return(schedule, nil).Once()
+			}
+
+			cfg := &config.Config{App: config.AppConfig{OverdueGraceDays: tt.graceDays}}
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan123/schedule"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetSchedule(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.expectedWeeks != nil {
+				var wrapperResponse struct {
+					Data domain.ScheduleResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+
+				weeks := make([]int, 0, len(wrapperResponse.Data.Schedule))
+				for _, week := range wrapperResponse.Data.Schedule {
+					weeks = append(weeks, week.WeekNumber)
+				}
+				assert.Equal(t, tt.expectedWeeks, weeks)
+				assert.True(t, wrapperResponse.Data.HasSchedule)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetSchedule_OverdueFlags(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	schedule := []*domain.LoanSchedule{
+		{LoanID: "loan123", WeekNumber: 1, DueDate: today.AddDate(0, 0, -14), Status: domain.ScheduleStatusPaid},
+		{LoanID: "loan123", WeekNumber: 2, DueDate: today.AddDate(0, 0, -10), Status: domain.ScheduleStatusOverdue},
+		{LoanID: "loan123", WeekNumber: 3, DueDate: today.AddDate(0, 0, -2), Status: domain.ScheduleStatusPending},
+		{LoanID: "loan123", WeekNumber: 4, DueDate: today, Status: domain.ScheduleStatusPending},
+		{LoanID: "loan123", WeekNumber: 5, DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+	}
+
+	type flags struct {
+		isOverdue bool
+		daysLate  int
+	}
+
+	tests := []struct {
+		name      string
+		graceDays int
+		boundary  string
+		expected  map[int]flags
+	}{
+		{
+			name: "without grace",
+			expected: map[int]flags{
+				1: {isOverdue: false, daysLate: 0}, // paid
+				2: {isOverdue: true, daysLate: 10}, // past due
+				3: {isOverdue: true, daysLate: 2},  // past due, stored status not yet updated
+				4: {isOverdue: false, daysLate: 0}, // due today
+				5: {isOverdue: false, daysLate: 0}, // future
+			},
+		},
+		{
+			name:      "grace counts from the due date",
+			graceDays: 3,
+			expected: map[int]flags{
+				1: {isOverdue: false, daysLate: 0},
+				2: {isOverdue: true, daysLate: 7},
+				3: {isOverdue: false, daysLate: 0}, // still inside the grace period
+				4: {isOverdue: false, daysLate: 0},
+				5: {isOverdue: false, daysLate: 0},
+			},
+		},
+		{
+			name:     "inclusive boundary makes a week overdue on its due date",
+			boundary: config.OverdueBoundaryInclusive,
+			expected: map[int]flags{
+				1: {isOverdue: false, daysLate: 0},
+				2: {isOverdue: true, daysLate: 11},
+				3: {isOverdue: true, daysLate: 3},
+				4: {isOverdue: true, daysLate: 1}, // due today, the boundary day
+				5: {isOverdue: false, daysLate: 0},
+			},
+		},
+		{
+			name:      "inclusive boundary with grace",
+			graceDays: 2,
+			boundary:  config.OverdueBoundaryInclusive,
+			expected: map[int]flags{
+				1: {isOverdue: false, daysLate: 0},
+				2: {isOverdue: true, daysLate: 9},
+				3: {isOverdue: true, daysLate: 1}, // due exactly grace days ago
+				4: {isOverdue: false, daysLate: 0},
+				5: {isOverdue: false, daysLate: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			mockService.On("GetSchedule", mock.Anything, "loan123").Return(schedule, nil).Once()
+
+			cfg := &config.Config{App: config.AppConfig{OverdueGraceDays: tt.graceDays, OverdueBoundary: tt.boundary}}
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan123/schedule", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetSchedule(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var wrapperResponse struct {
+				Data domain.ScheduleResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+			require.Len(t, wrapperResponse.Data.Schedule, len(schedule))
+
+			for i, week := range wrapperResponse.Data.Schedule {
+				expected := tt.expected[week.WeekNumber]
+				assert.Equal(t, expected.isOverdue, week.IsOverdue, "week %d is_overdue", week.WeekNumber)
+				assert.Equal(t, expected.daysLate, week.DaysLate, "week %d days_late", week.WeekNumber)
+				// The stored status is reported as is
+				assert.Equal(t, schedule[i].Status, week.Status)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_ExportLoan(t *testing.T) {
+	cfg := &config.Config{}
+	deletedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "successful export",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				export := &domain.LoanExport{
+					Loan:    &domain.Loan{LoanID: "loan123", Status: domain.LoanStatusActive},
+					Deleted: false,
+					Schedule: []*domain.LoanSchedule{
+						{LoanID: "loan123", WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPaid},
+						{LoanID: "loan123", WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPending},
+					},
+					Payments: []*domain.Payment{
+						{ID: uuid.New(), LoanID: "loan123", Amount: decimal.NewFromInt(110000), WeekNumber: 1},
+					},
+					ExportedAt: time.Now(),
+				}
+				mockService.On("ExportLoan", mock.Anything, "loan123").Return(export, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Equal(t, `attachment; filename="loan123.json"`, w.Header().Get("Content-Disposition"))
+
+				var wrapperResponse struct {
+					Data map[string]json.RawMessage `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+				for _, section := range []string{"loan", "schedule", "payments"} {
+					assert.Contains(t, wrapperResponse.Data, section)
+				}
+
+				var exportResponse struct {
+					Data domain.LoanExport `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exportResponse))
+				export := exportResponse.Data
+				assert.Equal(t, "loan123", export.Loan.LoanID)
+				assert.False(t, export.Deleted)
+				assert.Len(t, export.Schedule, 2)
+				assert.Len(t, export.Payments, 1)
+			},
+		},
+		{
+			name:   "soft-deleted loan is flagged",
+			loanID: "loan_deleted",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				export := &domain.LoanExport{
+					Loan:     &domain.Loan{LoanID: "loan_deleted", DeletedAt: &deletedAt},
+					Deleted:  true,
+					Schedule: []*domain.LoanSchedule{},
+					Payments: []*domain.Payment{},
+				}
+				mockService.On("ExportLoan", mock.Anything, "loan_deleted").Return(export, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"deleted":true`,
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ExportLoan", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ExportLoan", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to export loan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/export", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+			w := httptest.NewRecorder()
+
+			billingHandler.ExportLoan(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetDelinquencyHistory(t *testing.T) {
+	cfg := &config.Config{}
+	today := time.Now().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "history with overdue weeks",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				history := []*domain.DelinquencySnapshot{
+					{WeekNumber: 1, AsOf: today.AddDate(0, 0, -14), MissedWeeks: 1, IsDelinquent: false},
+					{WeekNumber: 2, AsOf: today.AddDate(0, 0, -7), MissedWeeks: 2, IsDelinquent: true},
+					{WeekNumber: 3, AsOf: today, MissedWeeks: 3, IsDelinquent: true},
+				}
+				mockService.On("GetDelinquencyHistory", mock.Anything, "loan123").Return(history, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                              `json:"success"`
+					Data    domain.DelinquencyHistoryResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Equal(t, "loan123", response.LoanID)
+				assert.Len(t, response.History, 3)
+				assert.False(t, response.History[0].IsDelinquent)
+				assert.True(t, response.History[1].IsDelinquent)
+				assert.Equal(t, 3, response.History[2].MissedWeeks)
+			},
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyHistory", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyHistory", mock.Anything, "loan123").
+					Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get delinquency history",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/delinquency-history", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetDelinquencyHistory(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetOutstandingBatch(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "existing and missing loans",
+			requestBody: `{"loan_ids":["loan123","missing"]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetOutstandingBatch", mock.Anything, []string{"loan123", "missing"}).
+					Return(map[string]decimal.Decimal{"loan123": decimal.NewFromFloat(1500.5)}, []string{"missing"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                            `json:"success"`
+					Data    domain.BatchOutstandingResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Len(t, response.Outstanding, 1)
+				assert.True(t, response.Outstanding["loan123"].Equal(decimal.NewFromFloat(1500.5)))
+				assert.Equal(t, []string{"missing"}, response.NotFound)
+				assert.Contains(t, w.Body.String(), `"loan123":"1500.50"`)
+			},
+		},
+		{
+			name:           "empty loan id list",
+			requestBody:    `{"loan_ids":[]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `{"loan_ids":`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid JSON payload",
+		},
+		{
+			name:        "service error",
+			requestBody: `{"loan_ids":["loan123"]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetOutstandingBatch", mock.Anything, []string{"loan123"}).
+					Return(nil, nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get outstanding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/outstanding/batch", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetOutstandingBatch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetDelinquencyBatch(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "mixed states",
+			requestBody: `{"loan_ids":["late","current","missing"]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyBatch", mock.Anything, []string{"late", "current", "missing"}).
+					Return(map[string]*domain.DelinquencyStatus{
+						"late":    {IsDelinquent: true, MissedWeeks: 3},
+						"current": {IsDelinquent: false, MissedWeeks: 0},
+					}, []string{"missing"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                            `json:"success"`
+					Data    domain.BatchDelinquencyResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Len(t, response.Delinquency, 2)
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: true, MissedWeeks: 3}, response.Delinquency["late"])
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: false, MissedWeeks: 0}, response.Delinquency["current"])
+				assert.Equal(t, []string{"missing"}, response.NotFound)
+				assert.Contains(t, w.Body.String(), `"late":{"is_delinquent":true,"missed_weeks":3}`)
+			},
+		},
+		{
+			name:           "too many loan ids",
+			requestBody:    `{"loan_ids":[` + strings.Repeat(`"loan",`, 100) + `"loan"]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name:           "empty loan id list",
+			requestBody:    `{"loan_ids":[]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name:        "service error",
+			requestBody: `{"loan_ids":["loan123"]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyBatch", mock.Anything, []string{"loan123"}).
+					Return(nil, nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to check delinquency",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/delinquent/batch", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetDelinquencyBatch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetRemaining(t *testing.T) {
+	cfg := &config.Config{}
+	nextDueDate := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 7)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "partially paid loan",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				summary := &domain.RemainingSummary{
+					RemainingInstallments: 48,
+					RemainingAmount:       decimal.NewFromInt(5280000),
+					NextDueDate:           &nextDueDate,
+				}
+				mockService.On("GetRemaining", mock.Anything, "loan123").Return(summary, nil).Once()
+				deviation := &domain.OutstandingDeviation{
+					CurrentWeek:         2,
+					ExpectedOutstanding: decimal.NewFromInt(5280000),
+					ActualOutstanding:   decimal.NewFromInt(5390000),
+					Deviation:           decimal.NewFromInt(110000),
+				}
+				mockService.On("GetOutstandingDeviation", mock.Anything, "loan123").Return(deviation, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                     `json:"success"`
+					Data    domain.RemainingResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Equal(t, "loan123", response.LoanID)
+				assert.Equal(t, 48, response.RemainingInstallments)
+				assert.True(t, response.RemainingAmount.Equal(decimal.NewFromInt(5280000)))
+				require.NotNil(t, response.NextDueDate)
+				assert.True(t, nextDueDate.Equal(*response.NextDueDate))
+				assert.Equal(t, 2, response.CurrentWeek)
+				assert.True(t, response.ExpectedOutstanding.Equal(decimal.NewFromInt(5280000)))
+				assert.True(t, response.ActualOutstanding.Equal(decimal.NewFromInt(5390000)))
+				assert.True(t, response.Deviation.Equal(decimal.NewFromInt(110000)))
+			},
+		},
+		{
+			name:   "fully paid loan has no next due date",
+			loanID: "loan124",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				summary := &domain.RemainingSummary{RemainingAmount: decimal.Zero}
+				mockService.On("GetRemaining", mock.Anything, "loan124").Return(summary, nil).Once()
+				deviation := &domain.OutstandingDeviation{
+					CurrentWeek:         50,
+					ExpectedOutstanding: decimal.Zero,
+					ActualOutstanding:   decimal.Zero,
+					Deviation:           decimal.Zero,
+				}
+				mockService.On("GetOutstandingDeviation", mock.Anything, "loan124").Return(deviation, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"remaining_installments":0`)
+				assert.Contains(t, w.Body.String(), `"remaining_amount":"0.00"`)
+				assert.Contains(t, w.Body.String(), `"next_due_date":null`)
+				assert.Contains(t, w.Body.String(), `"deviation":"0.00"`)
+			},
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetRemaining", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetRemaining", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get remaining installments",
+		},
+		{
+			name:   "deviation error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				summary := &domain.RemainingSummary{RemainingAmount: decimal.Zero}
+				mockService.On("GetRemaining", mock.Anything, "loan123").Return(summary, nil).Once()
+				mockService.On("GetOutstandingDeviation", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get remaining installments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/remaining", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetRemaining(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetInterestPaid(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "partially paid loan",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				summary := &domain.InterestPaidSummary{
+					InterestPaid:  decimal.NewFromInt(30000),
+					PrincipalPaid: decimal.NewFromInt(300000),
+					TotalPaid:     decimal.NewFromInt(330000),
+				}
+				mockService.On("GetInterestPaid", mock.Anything, "loan123").Return(summary, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"loan_id":"loan123"`)
+				assert.Contains(t, w.Body.String(), `"interest_paid":"30000.00"`)
+				assert.Contains(t, w.Body.String(), `"principal_paid":"300000.00"`)
+				assert.Contains(t, w.Body.String(), `"total_paid":"330000.00"`)
+			},
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetInterestPaid", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetInterestPaid", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get interest paid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/interest-paid", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetInterestPaid(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_ListPayments(t *testing.T) {
+	cfg := &config.Config{}
+	paymentDate := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	payments := []*domain.Payment{
+		{ID: uuid.New(), LoanID: "loan123", Amount: decimal.NewFromInt(110000), PaymentDate: paymentDate, WeekNumber: 2},
+		{ID: uuid.New(), LoanID: "loan123", Amount: decimal.NewFromInt(110000), PaymentDate: paymentDate.AddDate(0, 0, -7), WeekNumber: 1},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "no filters uses default paging",
+			query: "",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListPayments", mock.Anything, domain.PaymentFilter{Limit: 50}).
+					Return(payments, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                       `json:"success"`
+					Data    domain.PaymentListResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Len(t, response.Payments, 2)
+				assert.Equal(t, 2, response.Total)
+				assert.Equal(t, 50, response.Limit)
+				assert.Equal(t, 0, response.Offset)
+			},
+		},
+		{
+			name:  "date range filter includes the whole to day",
+			query: "?from=2025-01-01&to=2025-01-31",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListPayments", mock.Anything, mock.MatchedBy(func(filter domain.PaymentFilter) bool {
+					return filter.LoanID == "" &&
+						filter.From != nil && filter.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+						filter.To != nil && filter.To.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC))
+				})).Return(payments, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "RFC3339 bounds are used as given",
+			query: "?from=2025-01-10T00:00:00Z&to=2025-01-15T12:00:00Z",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListPayments", mock.Anything, mock.MatchedBy(func(filter domain.PaymentFilter) bool {
+					return filter.From.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)) &&
+						filter.To.Equal(time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC))
+				})).Return(payments[:1], 1, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "loan filter with paging",
+			query: "?loan_id=loan123&limit=10&offset=20",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListPayments", mock.Anything, domain.PaymentFilter{LoanID: "loan123", Limit: 10, Offset: 20}).
+					Return([]*domain.Payment{}, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"payments":[]`)
+				assert.Contains(t, w.Body.String(), `"total":2`)
+			},
+		},
+		{
+			name:           "invalid from date",
+			query:          "?from=yesterday",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid from date",
+		},
+		{
+			name:           "from after to",
+			query:          "?from=2025-02-01&to=2025-01-01",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "from must be before to",
+		},
+		{
+			name:           "limit out of range",
+			query:          "?limit=1000",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be between 1 and 100",
+		},
+		{
+			name:           "malformed limit",
+			query:          "?limit=abc",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid query parameter limit",
+		},
+		{
+			name:           "negative offset",
+			query:          "?offset=-1",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "offset must be a non-negative integer",
+		},
+		{
+			name:  "service error",
+			query: "?loan_id=loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListPayments", mock.Anything, mock.Anything).Return(nil, 0, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to list payments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments"+tt.query, nil)
+
+			w := httptest.NewRecorder()
+
+			billingHandler.ListPayments(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_SetForbearance(t *testing.T) {
+	cfg := &config.Config{}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "forbearance granted",
+			loanID:      "loan123",
+			requestBody: `{"start_date":"2025-03-01T00:00:00Z","end_date":"2025-03-31T00:00:00Z"}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				loan := &domain.Loan{LoanID: "loan123", Status: domain.LoanStatusActive, ForbearanceStart: &start, ForbearanceEnd: &end}
+				mockService.On("SetForbearance", mock.Anything, "loan123", start, end).Return(loan, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"forbearance_end":"2025-03-31T00:00:00Z"`,
+		},
+		{
+			name:           "end before start",
+			loanID:         "loan123",
+			requestBody:    `{"start_date":"2025-03-31T00:00:00Z","end_date":"2025-03-01T00:00:00Z"}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name:           "missing dates",
+			loanID:         "loan123",
+			requestBody:    `{}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+		{
+			name:        "loan not found",
+			loanID:      "nonexistent",
+			requestBody: `{"start_date":"2025-03-01T00:00:00Z","end_date":"2025-03-31T00:00:00Z"}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("SetForbearance", mock.Anything, "nonexistent", start, end).
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:        "closed loan",
+			loanID:      "loan124",
+			requestBody: `{"start_date":"2025-03-01T00:00:00Z","end_date":"2025-03-31T00:00:00Z"}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("SetForbearance", mock.Anything, "loan124", start, end).
+					Return(nil, customError.WrapLoanAlreadyClosed("loan124")).Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Loan is not active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/"+tt.loanID+"/forbearance", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.SetForbearance(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_LoanExists(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+	}{
+		{
+			name:   "loan exists",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("LoanExists", mock.Anything, "loan123").Return(true, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "loan does not exist",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("LoanExists", mock.Anything, "nonexistent").Return(false, nil).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("LoanExists", mock.Anything, "loan123").Return(false, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:   "query timed out",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("LoanExists", mock.Anything, "loan123").
+					Return(false, customError.WrapQueryTimeout(context.DeadlineExceeded)).Once()
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodHead, "/api/v1/loans/"+tt.loanID, nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.LoanExists(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Empty(t, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_UpdateNotes(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "notes round-trip",
+			loanID:      "loan123",
+			requestBody: `{"contact":"called borrower","attempts":2}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UpdateNotes", mock.Anything, "loan123", json.RawMessage(`{"contact":"called borrower","attempts":2}`)).
+					Return(json.RawMessage(`{"attempts":2,"contact":"called borrower","priority":"high"}`), nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                     `json:"success"`
+					Data    domain.LoanNotesResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				assert.Equal(t, "loan123", wrapperResponse.Data.LoanID)
+				assert.JSONEq(t, `{"attempts":2,"contact":"called borrower","priority":"high"}`, string(wrapperResponse.Data.Notes))
+			},
+		},
+		{
+			name:           "invalid json",
+			loanID:         "loan123",
+			requestBody:    `{"contact":`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid JSON payload",
+		},
+		{
+			name:           "body too large",
+			loanID:         "loan123",
+			requestBody:    `{"big":"` + strings.Repeat("x", domain.MaxLoanNotesBytes) + `"}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "Notes too large",
+		},
+		{
+			name:        "not an object",
+			loanID:      "loan123",
+			requestBody: `"just a string"`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UpdateNotes", mock.Anything, "loan123", mock.Anything).
+					Return(nil, customError.WrapInvalidNotes("notes must be a JSON object")).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid notes",
+		},
+		{
+			name:        "loan not found",
+			loanID:      "nonexistent",
+			requestBody: `{"contact":"called borrower"}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UpdateNotes", mock.Anything, "nonexistent", mock.Anything).
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/loans/"+tt.loanID+"/notes", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.UpdateNotes(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_UndoLatestPayment(t *testing.T) {
+	cfg := &config.Config{}
+	reversed := &domain.Payment{
+		ID:         uuid.New(),
+		LoanID:     "loan123",
+		Amount:     decimal.NewFromInt(110000),
+		WeekNumber: 3,
+	}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "latest payment undone",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UndoLatestPayment", mock.Anything, "loan123").Return(reversed, nil).Once()
+				mockService.On("GetOutstanding", mock.Anything, "loan123").Return(decimal.NewFromInt(5170000), nil).Once()
+				mockService.On("IsDelinquent", mock.Anything, "loan123").Return(false, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"reopened_week_number":3`,
+		},
+		{
+			name:   "no payments to undo",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UndoLatestPayment", mock.Anything, "loan123").
+					Return(nil, customError.WrapNoPaymentsToUndo("loan123")).Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Loan has no payments to undo",
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("UndoLatestPayment", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/"+tt.loanID+"/payments/undo", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.UndoLatestPayment(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_RecomputeWeeklyPayment(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "weekly payment recomputed",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				loan := &domain.Loan{LoanID: "loan123", WeeklyPayment: decimal.NewFromInt(110000), Status: domain.LoanStatusActive}
+				mockService.On("RecomputeWeeklyPayment", mock.Anything, "loan123").Return(loan, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"weekly_payment":"110000"`,
+		},
+		{
+			name:   "loan has payments",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("RecomputeWeeklyPayment", mock.Anything, "loan123").
+					Return(nil, customError.WrapLoanHasPayments("loan123")).Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Loan already has payments",
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("RecomputeWeeklyPayment", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/"+tt.loanID+"/recompute-payment", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.RecomputeWeeklyPayment(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetPayment(t *testing.T) {
+	cfg := &config.Config{}
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name           string
+		paymentID      string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "payment found",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				payment := &domain.Payment{ID: paymentID, LoanID: "loan123", Amount: decimal.NewFromInt(110000), WeekNumber: 2}
+				mockService.On("GetPayment", mock.Anything, paymentID).Return(payment, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   paymentID.String(),
+		},
+		{
+			name:      "payment not found",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPayment", mock.Anything, paymentID).
+					Return(nil, customError.WrapPaymentNotFound(paymentID.String())).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Payment not found",
+		},
+		{
+			name:      "service error",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPayment", mock.Anything, paymentID).
+					Return(nil, customError.WrapDatabaseError(assert.AnError)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get payment",
+		},
+		{
+			name:           "invalid payment ID",
+			paymentID:      "not-a-uuid",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid payment ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/"+tt.paymentID, nil)
+			req = mux.SetURLVars(req, map[string]string{"paymentId": tt.paymentID})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetPayment(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_CreateLoan_GeneratedLoanID(t *testing.T) {
+	tests := []struct {
+		name           string
+		generateLoanID bool
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "generated when enabled and omitted",
+			generateLoanID: true,
+			requestBody:    `{"amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GenerateLoanID", mock.Anything).Return("LOAN-0190A0B2-7C3D-7E4F-8A5B-6C7D8E9F0A1B", nil).Once()
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "LOAN-0190A0B2-7C3D-7E4F-8A5B-6C7D8E9F0A1B"
+				})).Return(&domain.Loan{LoanID: "LOAN-0190A0B2-7C3D-7E4F-8A5B-6C7D8E9F0A1B"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"loan_id":"LOAN-0190A0B2-7C3D-7E4F-8A5B-6C7D8E9F0A1B"`,
+		},
+		{
+			name:           "client ID kept when enabled",
+			generateLoanID: true,
+			requestBody:    `{"loan_id":"client-loan","amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "client-loan"
+				})).Return(&domain.Loan{LoanID: "client-loan"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"loan_id":"client-loan"`,
+		},
+		{
+			name:           "generation failure",
+			generateLoanID: true,
+			requestBody:    `{"amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GenerateLoanID", mock.Anything).Return("", customError.WrapLoanIDGeneration(5)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to generate loan ID",
+		},
+		{
+			name:           "generated ID taken at insert is replaced",
+			generateLoanID: true,
+			requestBody:    `{"amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GenerateLoanID", mock.Anything).Return("LOAN-00000001", nil).Once()
+				mockService.On("GenerateLoanID", mock.Anything).Return("LOAN-00000002", nil).Once()
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "LOAN-00000001"
+				})).Return((*domain.Loan)(nil), ([]*domain.LoanSchedule)(nil), customError.WrapLoanAlreadyExists("LOAN-00000001")).Once()
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return req.LoanID == "LOAN-00000002"
+				})).Return(&domain.Loan{LoanID: "LOAN-00000002"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"loan_id":"LOAN-00000002"`,
+		},
+		{
+			name:           "generated IDs keep being taken",
+			generateLoanID: true,
+			requestBody:    `{"amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GenerateLoanID", mock.Anything).Return("LOAN-00000001", nil).Times(3)
+				mockService.On("CreateLoan", mock.Anything, mock.Anything).
+					Return((*domain.Loan)(nil), ([]*domain.LoanSchedule)(nil), customError.WrapLoanAlreadyExists("LOAN-00000001")).Times(3)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Loan already exists",
+		},
+		{
+			name:           "taken client ID is not replaced",
+			generateLoanID: true,
+			requestBody:    `{"loan_id":"client-loan","amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.Anything).
+					Return((*domain.Loan)(nil), ([]*domain.LoanSchedule)(nil), customError.WrapLoanAlreadyExists("client-loan")).Once()
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "LOAN_ALREADY_EXISTS",
+		},
+		{
+			name:           "not generated when disabled",
+			generateLoanID: false,
+			requestBody:    `{"amount":1000,"duration_weeks":10,"interest_rate":0.1}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoan", mock.Anything, mock.MatchedBy(func(req *domain.CreateLoanRequest) bool {
+					return strings.HasPrefix(req.LoanID, "loan_")
+				})).Return(&domain.Loan{LoanID: "loan_20250101_000000"}, []*domain.LoanSchedule{}, nil).Once()
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"loan_id":"loan_20250101_000000"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{App: config.AppConfig{GenerateLoanID: tt.generateLoanID}}
+
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			billingHandler.CreateLoan(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+			if !tt.generateLoanID {
+				mockService.AssertNotCalled(t, "GenerateLoanID", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestBillingHandler_CreateLoansBatch(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{LoanAmount: 5000000, LoanDurationWeeks: 50, AnnualInterestRate: 0.1}}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   []string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "mixed batch with an existing loan ID",
+			requestBody: `{"loans":[{"loan_id":"NEW-1","amount":1000000},{"loan_id":"EXISTING"},{"loan_id":"NEW-2","duration_weeks":10,"interest_rate":0}]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoansBatch", mock.Anything, mock.MatchedBy(func(requests []*domain.CreateLoanRequest) bool {
+					// Omitted fields take the configured defaults, as for a single loan
+					return len(requests) == 3 &&
+						requests[0].LoanID == "NEW-1" && requests[0].Amount.Equal(decimal.NewFromInt(1000000)) && requests[0].DurationWeeks == 50 &&
+						requests[1].LoanID == "EXISTING" && requests[1].Amount.Equal(decimal.NewFromInt(5000000)) &&
+						requests[2].DurationWeeks == 10 && requests[2].InterestRate.IsZero()
+				})).Return([]*domain.BatchCreateLoanResult{
+					{Index: 0, LoanID: "NEW-1", Loan: &domain.Loan{LoanID: "NEW-1"}},
+					{Index: 1, LoanID: "EXISTING", Code: customError.ErrCodeLoanAlreadyExists, Error: "Loan with ID EXISTING already exists"},
+					{Index: 2, LoanID: "NEW-2", Loan: &domain.Loan{LoanID: "NEW-2"}},
+				}).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                           `json:"success"`
+					Data    domain.BatchCreateLoanResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+
+				response := wrapperResponse.Data
+				assert.Equal(t, 2, response.Created)
+				assert.Equal(t, 1, response.Failed)
+				require.Len(t, response.Results, 3)
+				assert.Equal(t, 1, response.Results[1].Index)
+				assert.Equal(t, "LOAN_ALREADY_EXISTS", response.Results[1].Code)
+				assert.Equal(t, "Loan with ID EXISTING already exists", response.Results[1].Error)
+				assert.Nil(t, response.Results[1].Loan)
+			},
+		},
+		{
+			name:        "missing loan IDs get distinct timestamp IDs",
+			requestBody: `{"loans":[{},{}]}`,
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CreateLoansBatch", mock.Anything, mock.MatchedBy(func(requests []*domain.CreateLoanRequest) bool {
+					return len(requests) == 2 && strings.HasSuffix(requests[0].LoanID, "_1") && strings.HasSuffix(requests[1].LoanID, "_2")
+				})).Return([]*domain.BatchCreateLoanResult{
+					{Index: 0, Loan: &domain.Loan{}},
+					{Index: 1, Loan: &domain.Loan{}},
+				}).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"created":2`, `"failed":0`},
+		},
+		{
+			name:           "loan ID repeated within the batch",
+			requestBody:    `{"loans":[{"loan_id":"DUP"},{"loan_id":"OTHER"},{"loan_id":"DUP"}]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Validation failed", `loans[2]: loan_id \"DUP\" repeats loans[0]`},
+		},
+		{
+			name:           "one invalid entry rejects the whole batch",
+			requestBody:    `{"loans":[{"loan_id":"OK"},{"loan_id":"BAD","amount":-5},{"loan_id":"WORSE","duration_weeks":"ten"}]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Validation failed", "loans[1]", "loans[2]"},
+		},
+		{
+			name:           "empty batch",
+			requestBody:    `{"loans":[]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Validation failed"},
+		},
+		{
+			name:           "batch above the cap",
+			requestBody:    `{"loans":[` + strings.Repeat(`{},`, domain.MaxBatchCreateLoans) + `{}]}`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Validation failed"},
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    `{"loans":`,
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Invalid JSON payload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/batch", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			billingHandler.CreateLoansBatch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+			if tt.expectedStatus == http.StatusBadRequest {
+				mockService.AssertNotCalled(t, "CreateLoansBatch", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestBillingHandler_CreateLoan_Limits(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		limitErr       error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "amount above the maximum",
+			requestBody:    `{"loan_id":"loan268","amount":20000000,"duration_weeks":50,"interest_rate":0.1}`,
+			limitErr:       customError.WrapLoanLimitExceeded("amount", "10000000", "20000000"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"message":"amount 20000000 exceeds the maximum of 10000000"`,
+		},
+		{
+			name:           "duration above the maximum",
+			requestBody:    `{"loan_id":"loan268","amount":1000,"duration_weeks":10000,"interest_rate":0.1}`,
+			limitErr:       customError.WrapLoanLimitExceeded("duration_weeks", "104", "10000"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"message":"duration_weeks 10000 exceeds the maximum of 104"`,
+		},
+		{
+			name:           "interest rate above the ceiling",
+			requestBody:    `{"loan_id":"loan268","amount":1000,"duration_weeks":10,"interest_rate":10}`,
+			limitErr:       customError.WrapLoanLimitExceeded("interest_rate", "1", "10"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"code":"LOAN_LIMIT_EXCEEDED"`,
+		},
+		{
+			name:           "interest rate with too many decimal places",
+			requestBody:    `{"loan_id":"loan270","amount":1000,"duration_weeks":10,"interest_rate":0.1000001}`,
+			limitErr:       customError.WrapInvalidInterestRate("0.1000001 has more than 4 decimal places"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"message":"Invalid interest rate"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			mockService.On("CreateLoan", mock.Anything, mock.Anything).Return(nil, nil, tt.limitErr).Once()
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			billingHandler.CreateLoan(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetCollectionsReport(t *testing.T) {
+	cfg := &config.Config{}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	toExclusive := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			name:  "date-only window includes the whole to day",
+			query: "from=2025-01-01&to=2025-01-31",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				stats := &domain.CollectionStats{TotalCollected: decimal.NewFromInt(330000), PaymentCount: 3, LoansPaid: 2}
+				mockService.On("GetCollectionStats", mock.Anything, from, toExclusive).Return(stats, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"total_collected":"330000.00"`, `"payment_count":3`, `"loans_paid":2`},
+		},
+		{
+			name:  "empty window",
+			query: "from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				stats := &domain.CollectionStats{TotalCollected: decimal.Zero}
+				mockService.On("GetCollectionStats", mock.Anything, from, toExclusive).Return(stats, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"total_collected":"0.00"`, `"payment_count":0`, `"loans_paid":0`},
+		},
+		{
+			name:           "missing to",
+			query:          "from=2025-01-01",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"from and to are required"},
+		},
+		{
+			name:           "invalid from",
+			query:          "from=yesterday&to=2025-01-31",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Invalid from date"},
+		},
+		{
+			name:           "from after to",
+			query:          "from=2025-02-01&to=2025-01-01",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"from must be before to"},
+		},
+		{
+			name:  "service error",
+			query: "from=2025-01-01&to=2025-01-31",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCollectionStats", mock.Anything, from, toExclusive).
+					Return(nil, customError.WrapDatabaseError(assert.AnError)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   []string{"Failed to get collection stats"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/collections?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			billingHandler.GetCollectionsReport(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetExpectedCollectionsReport(t *testing.T) {
+	cfg := &config.Config{}
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	toExclusive := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			name:  "weeks are listed with the window total",
+			query: "from=2025-01-06&to=2025-01-19",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				weeks := []*domain.ExpectedCollectionWeek{
+					{WeekStart: from, ExpectedAmount: decimal.NewFromInt(70000), Installments: 3},
+					{WeekStart: from.AddDate(0, 0, 7), ExpectedAmount: decimal.NewFromInt(20000), Installments: 1},
+				}
+				mockService.On("GetExpectedCollections", mock.Anything, from, toExclusive).Return(weeks, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				`"total_expected":"90000.00"`,
+				`{"week_start":"2025-01-06T00:00:00Z","expected_amount":"70000.00","installments":3}`,
+				`{"week_start":"2025-01-13T00:00:00Z","expected_amount":"20000.00","installments":1}`,
+			},
+		},
+		{
+			name:  "nothing due",
+			query: "from=2025-01-06T00:00:00Z&to=2025-01-20T00:00:00Z",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetExpectedCollections", mock.Anything, from, toExclusive).Return(nil, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"total_expected":"0.00"`, `"weeks":[]`},
+		},
+		{
+			name:           "missing from",
+			query:          "to=2025-01-19",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"from and to are required"},
+		},
+		{
+			name:           "invalid to",
+			query:          "from=2025-01-06&to=soon",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"Invalid to date"},
+		},
+		{
+			name:           "from after to",
+			query:          "from=2025-01-20&to=2025-01-06",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   []string{"from must be before to"},
+		},
+		{
+			name:  "service error",
+			query: "from=2025-01-06&to=2025-01-19",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetExpectedCollections", mock.Anything, from, toExclusive).
+					Return(nil, customError.WrapDatabaseError(assert.AnError)).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   []string{"Failed to get expected collections"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/expected-collections?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			billingHandler.GetExpectedCollectionsReport(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetBorrowerLoans(t *testing.T) {
+	tests := []struct {
+		name           string
+		borrowerID     string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "borrower's loans with statuses",
+			borrowerID: "BRW-42",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetBorrowerLoans", mock.Anything, "BRW-42").Return([]*domain.Loan{
+					{LoanID: "loan-a", BorrowerID: "BRW-42", Status: domain.LoanStatusClosed},
+					{LoanID: "loan-b", BorrowerID: "BRW-42", Status: domain.LoanStatusActive},
+				}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"closed"`,
+		},
+		{
+			name:       "borrower without loans",
+			borrowerID: "BRW-404",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetBorrowerLoans", mock.Anything, "BRW-404").Return([]*domain.Loan{}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"borrower_id":"BRW-404","loans":[]`,
+		},
+		{
+			name:           "blank borrower ID",
+			borrowerID:     "  ",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Borrower ID is required",
+		},
+		{
+			name:       "service error",
+			borrowerID: "BRW-42",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetBorrowerLoans", mock.Anything, "BRW-42").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get borrower loans",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/borrowers/"+url.PathEscape(tt.borrowerID)+"/loans", nil)
+			req = mux.SetURLVars(req, map[string]string{"borrowerId": tt.borrowerID})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetBorrowerLoans(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_ListLoans(t *testing.T) {
+	cfg := &config.Config{}
+	loans := []*domain.Loan{
+		{LoanID: "loan-b", Tags: pq.StringArray{"branch-a"}},
+		{LoanID: "loan-a", Tags: pq.StringArray{"branch-a", "micro"}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "tag filter with default paging",
+			query: "?tag=branch-a",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, domain.LoanFilter{Tag: "branch-a", Limit: 50}).
+					Return(loans, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var wrapperResponse struct {
+					Success bool                    `json:"success"`
+					Data    domain.LoanListResponse `json:"data"`
+				}
+				err := json.Unmarshal(w.Body.Bytes(), &wrapperResponse)
+				assert.NoError(t, err)
+
+				response := wrapperResponse.Data
+				assert.Len(t, response.Loans, 2)
+				assert.Equal(t, 2, response.Total)
+				assert.Equal(t, 50, response.Limit)
+				assert.Equal(t, pq.StringArray{"branch-a", "micro"}, response.Loans[1].Tags)
+			},
+		},
+		{
+			name:  "tag is normalized like stored tags",
+			query: "?tag=%20Branch-A%20&limit=10&offset=10",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, domain.LoanFilter{Tag: "branch-a", Limit: 10, Offset: 10}).
+					Return([]*domain.Loan{}, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert.Contains(t, w.Body.String(), `"loans":[]`)
+			},
+		},
+		{
+			name:  "no tag lists all loans",
+			query: "",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, domain.LoanFilter{Limit: 50}).
+					Return(loans, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit out of range",
+			query:          "?tag=branch-a&limit=0",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be between 1 and 200",
+		},
+		{
+			name:  "status filter is normalized",
+			query: "?status=%20Active%20&limit=20",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, domain.LoanFilter{Status: domain.LoanStatusActive, Limit: 20}).
+					Return(loans, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown status",
+			query:          "?status=pending",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid query parameter status",
+		},
+		{
+			name:  "limit up to 200 is accepted",
+			query: "?limit=200",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, domain.LoanFilter{Limit: 200}).
+					Return(loans, 2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit above 200",
+			query:          "?limit=201",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "limit must be between 1 and 200",
+		},
+		{
+			name:  "service error",
+			query: "?tag=branch-a",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ListLoans", mock.Anything, mock.Anything).Return(nil, 0, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to list loans",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans"+tt.query, nil)
+
+			w := httptest.NewRecorder()
+
+			billingHandler.ListLoans(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, w)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetLoanPayments(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "explicit inclusive range",
+			query: "?from_week=3&to_week=6",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				result := &domain.WeekRangePayments{
+					LoanID:   "loan123",
+					FromWeek: 3,
+					ToWeek:   6,
+					Payments: []*domain.Payment{{LoanID: "loan123", Amount: decimal.NewFromInt(110000), WeekNumber: 3}},
+				}
+				mockService.On("GetPaymentsByWeekRange", mock.Anything, "loan123", 3, 6).Return(result, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"to_week":6`,
+		},
+		{
+			name:  "defaults to the whole schedule",
+			query: "",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				result := &domain.WeekRangePayments{LoanID: "loan123", FromWeek: 1, ToWeek: 50, Payments: []*domain.Payment{}}
+				mockService.On("GetPaymentsByWeekRange", mock.Anything, "loan123", 1, 0).Return(result, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"from_week":1`,
+		},
+		{
+			name:           "reversed range",
+			query:          "?from_week=6&to_week=3",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "from_week must not be after to_week",
+		},
+		{
+			name:           "non-positive week",
+			query:          "?from_week=0",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "must be positive integers",
+		},
+		{
+			name:           "non-numeric week",
+			query:          "?to_week=abc",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid query parameter to_week",
+		},
+		{
+			name:  "loan not found",
+			query: "",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPaymentsByWeekRange", mock.Anything, "loan123", 1, 0).
+					Return(nil, customError.WrapLoanNotFound("loan123")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:  "date range with a bare to date includes that whole day",
+			query: "?from=2025-01-01&to=2025-01-31",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+				result := &domain.DateRangePayments{LoanID: "loan123", From: &from, To: &to, Payments: []*domain.Payment{}}
+				mockService.On("GetPaymentsByDateRange", mock.Anything, "loan123",
+					mock.MatchedBy(func(got *time.Time) bool { return got != nil && got.Equal(from) }),
+					mock.MatchedBy(func(got *time.Time) bool { return got != nil && got.Equal(to) }),
+				).Return(result, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"payments":[]`,
+		},
+		{
+			name:  "open-ended date range",
+			query: "?from=2025-01-01T00:00:00Z",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				result := &domain.DateRangePayments{LoanID: "loan123", Payments: []*domain.Payment{}}
+				mockService.On("GetPaymentsByDateRange", mock.Anything, "loan123", mock.Anything, (*time.Time)(nil)).
+					Return(result, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"loan_id":"loan123"`,
+		},
+		{
+			name:           "invalid date",
+			query:          "?to=yesterday",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid to date",
+		},
+		{
+			name:           "date and week ranges combined",
+			query:          "?from=2025-01-01&from_week=3",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "not both",
+		},
+		{
+			name:  "date range on a missing loan",
+			query: "?from=2025-01-01",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPaymentsByDateRange", mock.Anything, "loan123", mock.Anything, mock.Anything).
+					Return(nil, customError.WrapLoanNotFound("loan123")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan123/payments"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.GetLoanPayments(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_IsDelinquent_AsOf(t *testing.T) {
+	cfg := &config.Config{}
+	asOf := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "future date forecasts delinquency",
+			query: "?as_of=2025-02-01",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyStatusAsOf", mock.Anything, "loan123", asOf).
+					Return(&domain.DelinquentResponse{LoanID: "loan123", IsDelinquent: true, MissedWeeks: 2}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"as_of":"2025-02-01T00:00:00Z"`,
+		},
+		{
+			name:           "malformed date",
+			query:          "?as_of=next-week",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid as_of date",
+		},
+		{
+			name:  "date before loan creation",
+			query: "?as_of=2025-02-01",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetDelinquencyStatusAsOf", mock.Anything, "loan123", asOf).
+					Return(nil, customError.WrapInvalidAsOfDate("before the loan was created")).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid as_of date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan123/delinquent"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+
+			w := httptest.NewRecorder()
+
+			billingHandler.IsDelinquent(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_DefaultedAndWrittenOffLoansConflict(t *testing.T) {
+	cfg := &config.Config{}
+
+	operations := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		setupMock func(*mocks.MockBillingService, error)
+		serve     func(*handler.BillingHandler, http.ResponseWriter, *http.Request)
+	}{
+		{
+			name:   "make payment",
+			method: http.MethodPost,
+			path:   "/payment",
+			body:   `{"amount": 110000}`,
+			setupMock: func(mockService *mocks.MockBillingService, err error) {
+				mockService.On("MakePayment", mock.Anything, mock.Anything).Return(nil, err).Once()
+			},
+			serve: (*handler.BillingHandler).MakePayment,
+		},
+		{
+			name:   "set forbearance",
+			method: http.MethodPost,
+			path:   "/forbearance",
+			body:   `{"start_date": "2025-03-01T00:00:00Z", "end_date": "2025-03-31T00:00:00Z"}`,
+			setupMock: func(mockService *mocks.MockBillingService, err error) {
+				mockService.On("SetForbearance", mock.Anything, "loan123", mock.Anything, mock.Anything).Return(nil, err).Once()
+			},
+			serve: (*handler.BillingHandler).SetForbearance,
+		},
+		{
+			name:   "recompute weekly payment",
+			method: http.MethodPost,
+			path:   "/recompute-payment",
+			setupMock: func(mockService *mocks.MockBillingService, err error) {
+				mockService.On("RecomputeWeeklyPayment", mock.Anything, "loan123").Return(nil, err).Once()
+			},
+			serve: (*handler.BillingHandler).RecomputeWeeklyPayment,
+		},
+		{
+			name:   "undo latest payment",
+			method: http.MethodPost,
+			path:   "/payments/undo",
+			setupMock: func(mockService *mocks.MockBillingService, err error) {
+				mockService.On("UndoLatestPayment", mock.Anything, "loan123").Return(nil, err).Once()
+			},
+			serve: (*handler.BillingHandler).UndoLatestPayment,
+		},
+	}
+
+	statuses := []struct {
+		name         string
+		err          error
+		expectedBody string
+	}{
+		{name: "defaulted", err: customError.WrapLoanDefaulted("loan123"), expectedBody: "Loan is in default"},
+		{name: "written off", err: customError.WrapLoanWrittenOff("loan123"), expectedBody: "Loan has been written off"},
+	}
+
+	for _, operation := range operations {
+		for _, tt := range statuses {
+			t.Run(operation.name+" on "+tt.name+" loan", func(t *testing.T) {
+				mockService := mocks.NewMockBillingService()
+				operation.setupMock(mockService, tt.err)
+
+				billingHandler := handler.NewBillingHandler(mockService, cfg, nil)
+
+				req := httptest.NewRequest(operation.method, "/api/v1/loans/loan123"+operation.path, strings.NewReader(operation.body))
+				req.Header.Set("Content-Type", "application/json")
+				req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+
+				w := httptest.NewRecorder()
+
+				operation.serve(billingHandler, w, req)
+
+				assert.Equal(t, http.StatusConflict, w.Code)
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+
+				mockService.AssertExpectations(t)
+			})
+		}
+	}
+}
+
+func TestBillingHandler_CreateLoan_IncludeSchedule(t *testing.T) {
+	schedule := []*domain.LoanSchedule{
+		{LoanID: "loan123", WeekNumber: 1, DueAmount: decimal.NewFromInt(110), Status: domain.ScheduleStatusPending},
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		callsService    bool
+		expectedStatus  int
+		expectSchedule  bool
+		expectedMessage string
+	}{
+		{
+			name:           "schedule included by default",
+			callsService:   true,
+			expectedStatus: http.StatusCreated,
+			expectSchedule: true,
+		},
+		{
+			name:           "schedule included when requested",
+			query:          "?include_schedule=true",
+			callsService:   true,
+			expectedStatus: http.StatusCreated,
+			expectSchedule: true,
+		},
+		{
+			name:           "schedule left out",
+			query:          "?include_schedule=false",
+			callsService:   true,
+			expectedStatus: http.StatusCreated,
+			expectSchedule: false,
+		},
+		{
+			name:            "invalid flag",
+			query:           "?include_schedule=maybe",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Invalid query parameter include_schedule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			if tt.callsService {
+				mockService.On("CreateLoan", mock.Anything, mock.Anything).Return(&domain.Loan{LoanID: "loan123"}, schedule, nil).Once()
+			}
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			body := `{"loan_id":"loan123","amount":1000,"duration_weeks":10,"interest_rate":0.1}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans"+tt.query, bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			billingHandler.CreateLoan(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Contains(t, w.Body.String(), tt.expectedMessage)
+				mockService.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+				return
+			}
+
+			var wrapperResponse struct {
+				Data map[string]json.RawMessage `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+			assert.Contains(t, wrapperResponse.Data, "loan")
+			_, hasSchedule := wrapperResponse.Data["schedule"]
+			assert.Equal(t, tt.expectSchedule, hasSchedule)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_MakePayment_AuditHeaders(t *testing.T) {
+	tests := []struct {
+		name               string
+		headers            map[string]string
+		expectedRecordedBy string
+		expectedChannel    string
+		expectedStatus     int
+		expectedBody       string
+	}{
+		{
+			name:               "headers recorded on the payment",
+			headers:            map[string]string{"X-Recorded-By": " agent-042 ", "X-Payment-Channel": "branch"},
+			expectedRecordedBy: "agent-042",
+			expectedChannel:    "branch",
+			expectedStatus:     http.StatusOK,
+			expectedBody:       `"recorded_by":"agent-042","channel":"branch"`,
+		},
+		{
+			name:           "headers are optional",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "recorded by too long",
+			headers:        map[string]string{"X-Recorded-By": strings.Repeat("a", 101)},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Validation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			if tt.expectedStatus == http.StatusOK {
+				mockService.On("MakePayment", mock.Anything, mock.MatchedBy(func(req domain.MakePaymentRequest) bool {
+					return req.RecordedBy == tt.expectedRecordedBy && req.Channel == tt.expectedChannel
+				})).Return(&domain.Payment{
+					LoanID:     "loan123",
+					Amount:     decimal.NewFromInt(110000),
+					WeekNumber: 1,
+					RecordedBy: tt.expectedRecordedBy,
+					Channel:    tt.expectedChannel,
+				}, nil).Once()
+				mockService.On("GetOutstanding", mock.Anything, "loan123").Return(decimal.NewFromInt(5390000), nil).Once()
+				mockService.On("IsDelinquent", mock.Anything, "loan123").Return(false, nil).Once()
+				mockService.On("GetNextDue", mock.Anything, "loan123").Return(&domain.NextDue{WeekNumber: 2}, nil).Once()
+				mockService.On("GetScheduleWeek", mock.Anything, "loan123", 1).
+					Return(&domain.LoanSchedule{LoanID: "loan123", WeekNumber: 1, Status: domain.ScheduleStatusPaid}, nil).Once()
+			}
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/loan123/payment", bytes.NewBufferString(`{"amount":110000}`))
+			req.Header.Set("Content-Type", "application/json")
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+			w := httptest.NewRecorder()
+
+			billingHandler.MakePayment(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusOK && tt.expectedRecordedBy == "" {
+				assert.NotContains(t, w.Body.String(), "recorded_by")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetLoan(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "loan details returned",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "loan123").Return(&domain.Loan{
+					LoanID:        "loan123",
+					Amount:        decimal.NewFromInt(5000000),
+					InterestRate:  decimal.NewFromFloat(0.1),
+					DurationWeeks: 50,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					Status:        domain.LoanStatusActive,
+				}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"duration_weeks":50,"weekly_payment":"110000","status":"active"`,
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:           "missing loan ID",
+			loanID:         "",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Loan ID is required",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetLoan", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get loan",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID, nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetLoan(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_CheckScheduleIntegrity(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "schedule with missing weeks is reported, not failed",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CheckScheduleIntegrity", mock.Anything, "loan123").
+					Return(&domain.ScheduleIntegrity{LoanID: "loan123", DurationWeeks: 50, ScheduleWeeks: 48}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"duration_weeks":50,"schedule_weeks":48,"consistent":false`,
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CheckScheduleIntegrity", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("CheckScheduleIntegrity", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to check schedule integrity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/schedule/integrity", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+			w := httptest.NewRecorder()
+
+			billingHandler.CheckScheduleIntegrity(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetPaymentStreak(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "streak returned",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPaymentStreak", mock.Anything, "loan123").
+					Return(&domain.PaymentStreak{LoanID: "loan123", CurrentStreak: 3, LongestStreak: 7}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"current_streak":3,"longest_streak":7`,
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPaymentStreak", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetPaymentStreak", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get payment streak",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/payment-streak", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetPaymentStreak(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetCurrentWeek(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "loan created three weeks ago is in week 4",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCurrentWeek", mock.Anything, "loan123").
+					Return(&domain.CurrentWeekResponse{LoanID: "loan123", CurrentWeek: 4, TotalWeeks: 50}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"current_week":4,"total_weeks":50`,
+		},
+		{
+			name:   "loan not found",
+			loanID: "nonexistent",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCurrentWeek", mock.Anything, "nonexistent").
+					Return(nil, customError.WrapLoanNotFound("nonexistent")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:   "service error",
+			loanID: "loan123",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCurrentWeek", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to get current week",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+tt.loanID+"/current-week", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": tt.loanID})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetCurrentWeek(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_RecordsMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	mockService := mocks.NewMockBillingService()
+	billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, metrics.New(registry))
+
+	// A created loan
+	mockService.On("CreateLoan", mock.Anything, mock.Anything).
+		Return(&domain.Loan{LoanID: "loan271"}, []*domain.LoanSchedule{}, nil).Once()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/loans", bytes.NewBufferString(`{"loan_id":"loan271","amount":1000,"duration_weeks":10,"interest_rate":0.1}`))
+	w := httptest.NewRecorder()
+	billingHandler.CreateLoan(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// An accepted payment and a refused one, which is not counted
+	mockService.On("MakePayment", mock.Anything, mock.MatchedBy(func(req domain.MakePaymentRequest) bool {
+		return req.Amount.Equal(decimal.NewFromInt(110000))
+	})).Return(&domain.Payment{LoanID: "loan271", Amount: decimal.NewFromInt(110000), WeekNumber: 1}, nil).Once()
+	mockService.On("MakePayment", mock.Anything, mock.Anything).Return(nil, customError.WrapPaymentAmountTooLarge("5000000", "9000000")).Once()
+	mockService.On("GetOutstanding", mock.Anything, "loan271").Return(decimal.NewFromInt(990000), nil).Once()
+	mockService.On("IsDelinquent", mock.Anything, "loan271").Return(false, nil).Once()
+	mockService.On("GetNextDue", mock.Anything, "loan271").Return(&domain.NextDue{WeekNumber: 2}, nil).Once()
+	mockService.On("GetScheduleWeek", mock.Anything, "loan271", 1).
+		Return(&domain.LoanSchedule{LoanID: "loan271", WeekNumber: 1, Status: domain.ScheduleStatusPaid}, nil).Once()
+	for _, body := range []string{`{"amount":110000}`, `{"amount":9000000}`} {
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/loans/loan271/payment", bytes.NewBufferString(body))
+		req = mux.SetURLVars(req, map[string]string{"loanId": "loan271"})
+		billingHandler.MakePayment(httptest.NewRecorder(), req)
+	}
+
+	// A delinquent loan and a failed check
+	mockService.On("GetDelinquencyStatus", mock.Anything, "loan271").
+		Return(&domain.DelinquentResponse{LoanID: "loan271", IsDelinquent: true, MissedWeeks: 2}, nil).Once()
+	mockService.On("GetDelinquencyStatus", mock.Anything, "loan272").
+		Return(nil, customError.WrapDatabaseError(errors.New("connection refused"))).Once()
+	for _, loanID := range []string{"loan271", "loan272"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+loanID+"/delinquent", nil)
+		req = mux.SetURLVars(req, map[string]string{"loanId": loanID})
+		billingHandler.IsDelinquent(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+# HELP loans_created_total Loans created.
+# TYPE loans_created_total counter
+loans_created_total 1
+# HELP payments_processed_total Payments accepted.
+# TYPE payments_processed_total counter
+payments_processed_total 1
+# HELP delinquency_checks_total Loan delinquency checks by result.
+# TYPE delinquency_checks_total counter
+delinquency_checks_total{result="delinquent"} 1
+delinquency_checks_total{result="error"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loans_created_total", "payments_processed_total", "delinquency_checks_total"))
+	mockService.AssertExpectations(t)
+}
+
+func TestBillingHandler_ConditionalReads(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 10, 9, 30, 15, 123456789, time.UTC)
+	loan := &domain.Loan{LoanID: "loan271", UpdatedAt: updatedAt}
+
+	// Validators from an unconditional read
+	mockService := mocks.NewMockBillingService()
+	mockService.On("GetLoan", mock.Anything, "loan271").Return(loan, nil).Once()
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan271", nil), map[string]string{"loanId": "loan271"})
+	w := httptest.NewRecorder()
+	handler.NewBillingHandler(mockService, &config.Config{}, nil).GetLoan(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "Mon, 10 Mar 2025 09:30:15 GMT", w.Header().Get("Last-Modified"))
+
+	tests := []struct {
+		name              string
+		path              string
+		headers           map[string]string
+		expectOutstanding bool
+		expectedStatus    int
+	}{
+		{
+			name:           "loan with matching ETag",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "loan with a weak ETag in a list",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-None-Match": `"stale", W/` + etag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "loan with stale ETag",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-None-Match": `"stale"`},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "loan not modified since",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-Modified-Since": "Mon, 10 Mar 2025 09:30:15 GMT"},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "loan modified since",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-Modified-Since": "Mon, 10 Mar 2025 09:30:14 GMT"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "stale ETag wins over If-Modified-Since",
+			path:           "/api/v1/loans/loan271",
+			headers:        map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": "Mon, 10 Mar 2025 09:30:15 GMT"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "outstanding with matching ETag skips the balance",
+			path:           "/api/v1/loans/loan271/outstanding",
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:              "outstanding with stale ETag",
+			path:              "/api/v1/loans/loan271/outstanding",
+			headers:           map[string]string{"If-None-Match": `"stale"`},
+			expectOutstanding: true,
+			expectedStatus:    http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			mockService.On("GetLoan", mock.Anything, "loan271").Return(loan, nil).Once()
+			if tt.expectOutstanding {
+				mockService.On("GetOutstandingBreakdown", mock.Anything, "loan271").
+					Return(&domain.OutstandingBreakdown{TotalOutstanding: decimal.NewFromInt(990000)}, nil).Once()
+				mockService.On("GetTotalPaid", mock.Anything, "loan271").Return(decimal.NewFromInt(110000), nil).Once()
+				mockService.On("GetNextDue", mock.Anything, "loan271").Return(&domain.NextDue{WeekNumber: 2}, nil).Once()
+			}
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan271"})
+			w := httptest.NewRecorder()
+
+			if strings.HasSuffix(tt.path, "/outstanding") {
+				billingHandler.GetOutstanding(w, req)
+			} else {
+				billingHandler.GetLoan(w, req)
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_ReversePayment(t *testing.T) {
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name           string
+		paymentID      string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "payment reversed",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ReversePayment", mock.Anything, "loan272", paymentID).Return(nil).Once()
+				mockService.On("GetOutstanding", mock.Anything, "loan272").Return(decimal.NewFromInt(110000), nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"reversed_payment_id":"` + paymentID.String() + `","outstanding":"110000.00"`,
+		},
+		{
+			name:           "invalid payment ID",
+			paymentID:      "not-a-uuid",
+			setupMock:      func(mockService *mocks.MockBillingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid payment ID",
+		},
+		{
+			name:      "payment not found",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ReversePayment", mock.Anything, "loan272", paymentID).
+					Return(customError.WrapPaymentNotFound(paymentID.String())).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Payment not found",
+		},
+		{
+			name:      "loan not found",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ReversePayment", mock.Anything, "loan272", paymentID).
+					Return(customError.WrapLoanNotFound("loan272")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Loan not found",
+		},
+		{
+			name:      "defaulted loan",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ReversePayment", mock.Anything, "loan272", paymentID).
+					Return(customError.WrapLoanDefaulted("loan272")).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:      "database error",
+			paymentID: paymentID.String(),
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("ReversePayment", mock.Anything, "loan272", paymentID).
+					Return(customError.WrapDatabaseError(errors.New("connection reset"))).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "Failed to reverse payment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/loans/loan272/payments/"+tt.paymentID+"/reverse", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan272", "paymentId": tt.paymentID})
+			w := httptest.NewRecorder()
+
+			billingHandler.ReversePayment(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetCostOfCredit(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*mocks.MockBillingService)
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			name: "standard loan",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCostOfCredit", mock.Anything, "loan123").Return(&domain.CostOfCredit{
+					Principal:      decimal.NewFromInt(5000000),
+					TotalRepayable: decimal.NewFromInt(5500000),
+					TotalInterest:  decimal.NewFromInt(500000),
+					TotalFees:      decimal.Zero,
+					CostPercent:    decimal.NewFromInt(10),
+				}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				`"principal":"5000000.00"`,
+				`"total_repayable":"5500000.00"`,
+				`"total_interest":"500000.00"`,
+				`"total_fees":"0.00"`,
+				`"cost_of_credit_percent":"10"`,
+			},
+		},
+		{
+			name: "loan not found",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCostOfCredit", mock.Anything, "loan123").Return(nil, customError.WrapLoanNotFound("loan123")).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   []string{"Loan not found"},
+		},
+		{
+			name: "service error",
+			setupMock: func(mockService *mocks.MockBillingService) {
+				mockService.On("GetCostOfCredit", mock.Anything, "loan123").Return(nil, assert.AnError).Once()
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   []string{"Failed to get cost of credit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := mocks.NewMockBillingService()
+			tt.setupMock(mockService)
+
+			billingHandler := handler.NewBillingHandler(mockService, &config.Config{}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/loan123/cost", nil)
+			req = mux.SetURLVars(req, map[string]string{"loanId": "loan123"})
+			w := httptest.NewRecorder()
+
+			billingHandler.GetCostOfCredit(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			for _, expected := range tt.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
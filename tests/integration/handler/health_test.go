@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segyhp/billing-engine/internal/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDBPinger struct {
+	err error
+}
+
+func (f fakeDBPinger) PingContext(ctx context.Context) error {
+	return f.err
+}
+
+type fakeRedisPinger struct {
+	err error
+}
+
+func (f fakeRedisPinger) Ping(ctx context.Context) *redis.StatusCmd {
+	cmd := redis.NewStatusCmd(ctx)
+	if f.err != nil {
+		cmd.SetErr(f.err)
+	} else {
+		cmd.SetVal("PONG")
+	}
+	return cmd
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	redisDown := errors.New("dial tcp: connection refused")
+
+	tests := []struct {
+		name           string
+		dbErr          error
+		redisErr       error
+		redisRequired  bool
+		expectedStatus int
+		expectedState  string
+		expectedRedis  string
+	}{
+		{
+			name:           "redis required and up",
+			redisRequired:  true,
+			expectedStatus: http.StatusOK,
+			expectedState:  "ok",
+			expectedRedis:  "ok",
+		},
+		{
+			name:           "redis required and down",
+			redisErr:       redisDown,
+			redisRequired:  true,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "redis optional and up",
+			redisRequired:  false,
+			expectedStatus: http.StatusOK,
+			expectedState:  "ok",
+			expectedRedis:  "ok",
+		},
+		{
+			name:           "redis optional and down degrades",
+			redisErr:       redisDown,
+			redisRequired:  false,
+			expectedStatus: http.StatusOK,
+			expectedState:  "degraded",
+			expectedRedis:  "degraded: dial tcp: connection refused",
+		},
+		{
+			name:           "database down fails even when redis is optional",
+			dbErr:          errors.New("connection refused"),
+			redisErr:       redisDown,
+			redisRequired:  false,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthHandler := handler.NewHealthHandler(fakeDBPinger{err: tt.dbErr}, fakeRedisPinger{err: tt.redisErr}, tt.redisRequired)
+
+			req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+			w := httptest.NewRecorder()
+
+			healthHandler.Ready(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, w.Body.String(), "Service not ready")
+				return
+			}
+
+			var wrapperResponse struct {
+				Data handler.HealthStatus `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &wrapperResponse))
+			assert.Equal(t, tt.expectedState, wrapperResponse.Data.Status)
+			assert.Equal(t, "ok", wrapperResponse.Data.Checks["database"])
+			assert.Equal(t, tt.expectedRedis, wrapperResponse.Data.Checks["redis"])
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/handler"
+	"github.com/segyhp/billing-engine/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler_Metrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.MetricsConfig
+		remoteAddr     string
+		setAuth        func(*http.Request)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Disabled - Not found",
+			cfg:            config.MetricsConfig{Enabled: false},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Metrics are disabled",
+		},
+		{
+			name:           "Enabled without protection - Metrics served",
+			cfg:            config.MetricsConfig{Enabled: true},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "memstats",
+		},
+		{
+			name:           "Basic auth - Missing credentials",
+			cfg:            config.MetricsConfig{Enabled: true, Username: "ops", Password: "secret"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Metrics credentials required",
+		},
+		{
+			name: "Basic auth - Wrong password",
+			cfg:  config.MetricsConfig{Enabled: true, Username: "ops", Password: "secret"},
+			setAuth: func(r *http.Request) {
+				r.SetBasicAuth("ops", "wrong")
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Metrics credentials required",
+		},
+		{
+			name: "Basic auth - Valid credentials",
+			cfg:  config.MetricsConfig{Enabled: true, Username: "ops", Password: "secret"},
+			setAuth: func(r *http.Request) {
+				r.SetBasicAuth("ops", "secret")
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "memstats",
+		},
+		{
+			name:           "Allowlist - Client inside range",
+			cfg:            config.MetricsConfig{Enabled: true, AllowedIPs: []string{"10.0.0.0/8"}},
+			remoteAddr:     "10.1.2.3:41000",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "memstats",
+		},
+		{
+			name:           "Allowlist - Client outside range",
+			cfg:            config.MetricsConfig{Enabled: true, AllowedIPs: []string{"10.0.0.0/8", "127.0.0.1"}},
+			remoteAddr:     "192.168.1.5:41000",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Metrics access denied",
+		},
+		{
+			name: "Allowlist and basic auth - Allowed client still needs credentials",
+			cfg: config.MetricsConfig{
+				Enabled:    true,
+				Username:   "ops",
+				Password:   "secret",
+				AllowedIPs: []string{"127.0.0.1"},
+			},
+			remoteAddr:     "127.0.0.1:41000",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Metrics credentials required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricsHandler := handler.NewMetricsHandler(tt.cfg, metrics.NewRegistry())
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.setAuth != nil {
+				tt.setAuth(req)
+			}
+			w := httptest.NewRecorder()
+
+			metricsHandler.Metrics(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Basic realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
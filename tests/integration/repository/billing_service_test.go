@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/internal/service"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run the billing service against the real repositories, for behavior that depends on
+// what the database actually stores rather than on what a mock hands back
+
+func newRepositoryBackedService(db *sqlx.DB, cfg *config.Config) service.BillingService {
+	return service.NewBillingService(
+		repository.NewLoanRepository(db),
+		repository.NewPaymentRepository(db),
+		repository.NewUnitOfWork(db),
+		nil,
+		cfg,
+	)
+}
+
+// seedWeeklyLoan creates an active loan of the given number of 110,000 weeks, the first due in a week
+func seedWeeklyLoan(t *testing.T, db *sqlx.DB, loanID string, weeks int) {
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(db)
+	now := time.Now()
+
+	require.NoError(t, loanRepo.Create(ctx, &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        loanID,
+		Amount:        decimal.NewFromInt(int64(weeks) * 100000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: weeks,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}))
+
+	schedules := make([]*domain.LoanSchedule, weeks)
+	for i := range schedules {
+		schedules[i] = &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loanID,
+			WeekNumber: i + 1,
+			DueAmount:  decimal.NewFromInt(110000),
+			DueDate:    now.AddDate(0, 0, 7*(i+1)),
+			Status:     domain.ScheduleStatusPending,
+			CreatedAt:  now,
+		}
+	}
+	require.NoError(t, loanRepo.CreateSchedule(ctx, schedules))
+}
+
+func TestBillingService_MakePayment_MinPaymentInterval(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	seedWeeklyLoan(t, db, "LOAN-INTERVAL", 3)
+	billingService := newRepositoryBackedService(db, &config.Config{
+		App: config.AppConfig{MinPaymentInterval: time.Minute},
+	})
+
+	payment, err := billingService.MakePayment(ctx, domain.MakePaymentRequest{LoanID: "LOAN-INTERVAL", Amount: decimal.NewFromInt(110000)})
+	require.NoError(t, err)
+
+	// The stored payment carries when it was recorded, which the interval is measured from
+	stored, err := repository.NewPaymentRepository(db).GetByID(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), stored.CreatedAt, time.Minute)
+
+	_, err = billingService.MakePayment(ctx, domain.MakePaymentRequest{LoanID: "LOAN-INTERVAL", Amount: decimal.NewFromInt(110000)})
+	assert.ErrorIs(t, err, customError.ErrPaymentTooSoon)
+}
+
+func TestBillingService_UndoLatestPayment_ReopensLoan(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	seedWeeklyLoan(t, db, "LOAN-UNDO", 1)
+	billingService := newRepositoryBackedService(db, nil)
+	loanRepo := repository.NewLoanRepository(db)
+
+	_, err := billingService.MakePayment(ctx, domain.MakePaymentRequest{LoanID: "LOAN-UNDO", Amount: decimal.NewFromInt(110000)})
+	require.NoError(t, err)
+
+	_, err = billingService.UndoLatestPayment(ctx, "LOAN-UNDO")
+	require.NoError(t, err)
+
+	// The delete, the week reset and the reopening were committed together
+	loan, err := loanRepo.GetByLoanID(ctx, "LOAN-UNDO")
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+
+	schedules, err := loanRepo.GetScheduleByLoanID(ctx, "LOAN-UNDO")
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, domain.ScheduleStatusPending, schedules[0].Status)
+
+	payments, err := repository.NewPaymentRepository(db).GetByLoanID(ctx, "LOAN-UNDO")
+	require.NoError(t, err)
+	assert.Empty(t, payments)
+}
+
+func TestBillingService_CreateLoan_StoresCreationTime(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	billingService := newRepositoryBackedService(db, nil)
+
+	_, _, err := billingService.CreateLoan(ctx, &domain.CreateLoanRequest{
+		LoanID:        "LOAN-CREATED",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+	})
+	require.NoError(t, err)
+
+	stored, err := repository.NewLoanRepository(db).GetByLoanID(ctx, "LOAN-CREATED")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), stored.CreatedAt, time.Minute)
+	assert.WithinDuration(t, time.Now(), stored.UpdatedAt, time.Minute)
+
+	// A loan created today is in its first week rather than clamped to its last
+	currentWeek, err := billingService.GetCurrentWeek(ctx, "LOAN-CREATED")
+	require.NoError(t, err)
+	assert.Equal(t, 1, currentWeek.CurrentWeek)
+
+	// Dates before the loan existed are rejected rather than evaluated
+	_, err = billingService.GetDelinquencyStatusAsOf(ctx, "LOAN-CREATED", time.Now().AddDate(0, 0, -7))
+	assert.ErrorIs(t, err, customError.ErrInvalidAsOfDate)
+}
+
+func TestBillingService_CreateLoan_KeepsInterestRateBasis(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	annual := newRepositoryBackedService(db, &config.Config{App: config.AppConfig{InterestRateBasis: config.InterestRateBasisAnnual}})
+
+	_, _, err := annual.CreateLoan(ctx, &domain.CreateLoanRequest{
+		LoanID:        "LOAN-BASIS",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 26,
+	})
+	require.NoError(t, err)
+
+	stored, err := repository.NewLoanRepository(db).GetByLoanID(ctx, "LOAN-BASIS")
+	require.NoError(t, err)
+	assert.Equal(t, config.InterestRateBasisAnnual, stored.InterestRateBasis)
+
+	// Switching the setting back to per-term leaves the loan priced as it was created
+	perTerm := newRepositoryBackedService(db, &config.Config{App: config.AppConfig{InterestRateBasis: config.InterestRateBasisPerTerm}})
+	outstanding, err := perTerm.GetOutstanding(ctx, "LOAN-BASIS")
+	require.NoError(t, err)
+	assert.True(t, outstanding.Equal(decimal.NewFromInt(5250000)), "expected 5250000, got %s", outstanding)
+}
@@ -2,18 +2,24 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/domain"
 	"github.com/segyhp/billing-engine/internal/repository"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/pkg/utils"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -135,6 +141,44 @@ func TestLoanRepository_Create(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestLoanRepository_Create_DuplicateLoanID(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	newLoan := func() *domain.Loan {
+		return &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        "LOAN-DUP-001",
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 1,
+			WeeklyPayment: decimal.NewFromInt(1100000),
+			Status:        "active",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+	}
+	require.NoError(t, repo.Create(ctx, newLoan()))
+
+	// The insert a concurrent request would make after both passed the existence check
+	err := repo.Create(ctx, newLoan())
+	assert.ErrorIs(t, err, customError.ErrLoanAlreadyExists)
+
+	err = repo.CreateWithSchedule(ctx, newLoan(), []*domain.LoanSchedule{{
+		ID:         uuid.New(),
+		LoanID:     "LOAN-DUP-001",
+		WeekNumber: 1,
+		DueAmount:  decimal.NewFromInt(1100000),
+		DueDate:    time.Now().AddDate(0, 0, 7),
+		Status:     "pending",
+		CreatedAt:  time.Now(),
+	}})
+	assert.ErrorIs(t, err, customError.ErrLoanAlreadyExists)
+}
+
 func TestLoanRepository_GetByLoanID(t *testing.T) {
 	db := setupTestDB(t)
 	defer cleanupTestDB(db)
@@ -425,6 +469,238 @@ func TestLoanRepository_GetOverdueSchedules(t *testing.T) {
 	assert.Equal(t, "pending", result[0].Status)
 }
 
+func TestLoanRepository_GetOverdueSchedules_GraceBoundary(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-BOUNDARY-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 1,
+		WeeklyPayment: decimal.NewFromInt(1100000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	dueDate := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateSchedule(ctx, []*domain.LoanSchedule{{
+		ID:         uuid.New(),
+		LoanID:     loan.LoanID,
+		WeekNumber: 1,
+		DueAmount:  decimal.NewFromInt(1100000),
+		DueDate:    dueDate,
+		Status:     "pending",
+		CreatedAt:  time.Now(),
+	}}))
+
+	// Two days' grace puts the boundary at the first second of Jan 12
+	grace := 2 * 24 * time.Hour
+	boundary := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	result, err := repo.GetOverdueSchedules(ctx, loan.LoanID, utils.OverdueCutoff(boundary, grace, false))
+	require.NoError(t, err)
+	assert.Empty(t, result, "exclusive: still on time at the boundary second")
+
+	result, err = repo.GetOverdueSchedules(ctx, loan.LoanID, utils.OverdueCutoff(boundary.AddDate(0, 0, 1), grace, false))
+	require.NoError(t, err)
+	assert.Len(t, result, 1, "exclusive: overdue the day after the boundary")
+
+	result, err = repo.GetOverdueSchedules(ctx, loan.LoanID, utils.OverdueCutoff(boundary.Add(-time.Second), grace, true))
+	require.NoError(t, err)
+	assert.Empty(t, result, "inclusive: on time the second before the boundary")
+
+	result, err = repo.GetOverdueSchedules(ctx, loan.LoanID, utils.OverdueCutoff(boundary, grace, true))
+	require.NoError(t, err)
+	assert.Len(t, result, 1, "inclusive: overdue at the boundary second")
+}
+
+func TestLoanRepository_MarkSchedulesOverdue(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	today := time.Now().Truncate(24 * time.Hour)
+	newLoan := func(loanID, status string) {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(20000),
+			Status:        status,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}))
+	}
+	week := func(loanID string, number int, dueDate time.Time, status string) *domain.LoanSchedule {
+		return &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loanID,
+			WeekNumber: number,
+			DueAmount:  decimal.NewFromInt(20000),
+			DueDate:    dueDate,
+			Status:     status,
+			CreatedAt:  time.Now(),
+		}
+	}
+
+	newLoan("LOAN-OVERDUE-001", domain.LoanStatusActive)
+	newLoan("LOAN-OVERDUE-002", domain.LoanStatusClosed)
+	require.NoError(t, repo.CreateSchedule(ctx, []*domain.LoanSchedule{
+		week("LOAN-OVERDUE-001", 1, today.AddDate(0, 0, -14), domain.ScheduleStatusPaid),
+		week("LOAN-OVERDUE-001", 2, today.AddDate(0, 0, -7), domain.ScheduleStatusPending),
+		week("LOAN-OVERDUE-001", 3, today, domain.ScheduleStatusPending),
+		week("LOAN-OVERDUE-002", 1, today.AddDate(0, 0, -7), domain.ScheduleStatusPending),
+	}))
+
+	// Only the active loan's pending week due before the cutoff changes
+	marked, err := repo.MarkSchedulesOverdue(ctx, today)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+
+	schedule, err := repo.GetScheduleByLoanID(ctx, "LOAN-OVERDUE-001")
+	require.NoError(t, err)
+	require.Len(t, schedule, 3)
+	assert.Equal(t, domain.ScheduleStatusPaid, schedule[0].Status)
+	assert.Equal(t, domain.ScheduleStatusOverdue, schedule[1].Status)
+	assert.Equal(t, domain.ScheduleStatusPending, schedule[2].Status)
+
+	// Overdue weeks are still unpaid
+	earliest, err := repo.GetEarliestUnpaidWeek(ctx, "LOAN-OVERDUE-001")
+	require.NoError(t, err)
+	assert.Equal(t, 2, earliest.WeekNumber)
+
+	// Running again the same day changes nothing
+	marked, err = repo.MarkSchedulesOverdue(ctx, today)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), marked)
+}
+
+func TestLoanRepository_GetExpectedCollections(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	newLoan := func(loanID, status string) {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(20000),
+			Status:        status,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}))
+	}
+	week := func(loanID string, number int, dueDate time.Time, amount int64, status string) *domain.LoanSchedule {
+		return &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loanID,
+			WeekNumber: number,
+			DueAmount:  decimal.NewFromInt(amount),
+			DueDate:    dueDate,
+			Status:     status,
+			CreatedAt:  time.Now(),
+		}
+	}
+
+	// Monday 6 January 2025 up to, but not including, Monday 20 January
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	newLoan("LOAN-EXPECTED-001", domain.LoanStatusActive)
+	newLoan("LOAN-EXPECTED-002", domain.LoanStatusDelinquent)
+	newLoan("LOAN-EXPECTED-003", domain.LoanStatusClosed)
+	newLoan("LOAN-EXPECTED-004", domain.LoanStatusActive)
+	require.NoError(t, repo.CreateSchedule(ctx, []*domain.LoanSchedule{
+		week("LOAN-EXPECTED-001", 1, from.AddDate(0, 0, -1), 20000, domain.ScheduleStatusPending), // before the window
+		week("LOAN-EXPECTED-001", 2, from, 20000, domain.ScheduleStatusPending),                   // window start is inclusive
+		week("LOAN-EXPECTED-001", 3, from.AddDate(0, 0, 2), 20000, domain.ScheduleStatusPaid),     // already paid
+		week("LOAN-EXPECTED-001", 4, from.AddDate(0, 0, 4), 20000, domain.ScheduleStatusPending),  // Friday, same week
+		week("LOAN-EXPECTED-001", 5, from.AddDate(0, 0, 7), 20000, domain.ScheduleStatusPending),  // next week
+		week("LOAN-EXPECTED-001", 6, to, 20000, domain.ScheduleStatusPending),                     // window end is exclusive
+		week("LOAN-EXPECTED-002", 1, from.AddDate(0, 0, 6), 30000, domain.ScheduleStatusPending),  // Sunday, delinquent loan
+		week("LOAN-EXPECTED-002", 2, from.AddDate(0, 0, 8), 30000, domain.ScheduleStatusOverdue),  // overdue, still owed
+		week("LOAN-EXPECTED-003", 1, from.AddDate(0, 0, 1), 40000, domain.ScheduleStatusPending),  // closed loan
+		week("LOAN-EXPECTED-004", 1, from.AddDate(0, 0, 1), 50000, domain.ScheduleStatusPending),  // deleted below
+	}))
+	require.NoError(t, repo.Delete(ctx, "LOAN-EXPECTED-004"))
+
+	weeks, err := repo.GetExpectedCollections(ctx, from, to)
+	require.NoError(t, err)
+	require.Len(t, weeks, 2)
+
+	assert.True(t, weeks[0].WeekStart.Equal(from), "first week starts %s", weeks[0].WeekStart)
+	assert.True(t, decimal.NewFromInt(70000).Equal(weeks[0].ExpectedAmount), "first week expects %s", weeks[0].ExpectedAmount)
+	assert.Equal(t, 3, weeks[0].Installments)
+
+	assert.True(t, weeks[1].WeekStart.Equal(from.AddDate(0, 0, 7)), "second week starts %s", weeks[1].WeekStart)
+	assert.True(t, decimal.NewFromInt(50000).Equal(weeks[1].ExpectedAmount), "second week expects %s", weeks[1].ExpectedAmount)
+	assert.Equal(t, 2, weeks[1].Installments)
+
+	// A window with nothing due is empty rather than an error
+	empty, err := repo.GetExpectedCollections(ctx, from.AddDate(-1, 0, 0), from.AddDate(-1, 1, 0))
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestLoanRepository_GetExpectedCollections_OverdueAndPartiallyPaid(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-EXPECTED-PARTIAL",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(20000),
+		Status:        domain.LoanStatusActive,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}))
+
+	// Monday 6 January 2025: week 1 is overdue and week 2 is pending with 5,000 paid towards it
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateSchedule(ctx, []*domain.LoanSchedule{
+		{ID: uuid.New(), LoanID: "LOAN-EXPECTED-PARTIAL", WeekNumber: 1, DueAmount: decimal.NewFromInt(20000), DueDate: from, Status: domain.ScheduleStatusOverdue, CreatedAt: time.Now()},
+		{ID: uuid.New(), LoanID: "LOAN-EXPECTED-PARTIAL", WeekNumber: 2, DueAmount: decimal.NewFromInt(20000), DueDate: from.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending, CreatedAt: time.Now()},
+	}))
+	require.NoError(t, repository.NewPaymentRepository(db).Create(ctx, &domain.Payment{
+		ID:          uuid.New(),
+		LoanID:      "LOAN-EXPECTED-PARTIAL",
+		Amount:      decimal.NewFromInt(5000),
+		PaymentDate: time.Now(),
+		WeekNumber:  2,
+		CreatedAt:   time.Now(),
+	}))
+
+	weeks, err := repo.GetExpectedCollections(ctx, from, from.AddDate(0, 0, 14))
+	require.NoError(t, err)
+	require.Len(t, weeks, 2)
+
+	assert.True(t, decimal.NewFromInt(20000).Equal(weeks[0].ExpectedAmount), "overdue week expects %s", weeks[0].ExpectedAmount)
+	assert.True(t, decimal.NewFromInt(15000).Equal(weeks[1].ExpectedAmount), "partially paid week expects %s", weeks[1].ExpectedAmount)
+	assert.Equal(t, 1, weeks[1].Installments)
+}
+
 func TestLoanRepository_CreateSchedule_TransactionRollback(t *testing.T) {
 	db := setupTestDB(t)
 	defer cleanupTestDB(db)
@@ -477,3 +753,845 @@ func TestLoanRepository_CreateSchedule_TransactionRollback(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, result, 0)
 }
+
+func TestLoanRepository_CreateSchedule_Idempotent(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-IDEMPOTENT-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 2,
+		WeeklyPayment: decimal.NewFromInt(550000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	buildSchedule := func() []*domain.LoanSchedule {
+		var schedules []*domain.LoanSchedule
+		for week := 1; week <= 2; week++ {
+			schedules = append(schedules, &domain.LoanSchedule{
+				ID:         uuid.New(),
+				LoanID:     loan.LoanID,
+				WeekNumber: week,
+				DueAmount:  decimal.NewFromInt(550000),
+				DueDate:    time.Now().AddDate(0, 0, 7*week),
+				Status:     "pending",
+				CreatedAt:  time.Now(),
+			})
+		}
+		return schedules
+	}
+
+	first := buildSchedule()
+	require.NoError(t, repo.CreateSchedule(ctx, first))
+
+	// A retry generates fresh row IDs but targets the same weeks
+	require.NoError(t, repo.CreateSchedule(ctx, buildSchedule()))
+
+	result, err := repo.GetScheduleByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, first[0].ID, result[0].ID)
+	assert.Equal(t, first[1].ID, result[1].ID)
+}
+
+func TestLoanRepository_CountSchedule(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-COUNT-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 3,
+		WeeklyPayment: decimal.NewFromInt(366667),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	count, err := repo.CountSchedule(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	var schedules []*domain.LoanSchedule
+	for week := 1; week <= 3; week++ {
+		schedules = append(schedules, &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loan.LoanID,
+			WeekNumber: week,
+			DueAmount:  decimal.NewFromInt(366667),
+			DueDate:    time.Now().AddDate(0, 0, 7*week),
+			Status:     "pending",
+			CreatedAt:  time.Now(),
+		})
+	}
+	require.NoError(t, repo.CreateSchedule(ctx, schedules))
+
+	count, err = repo.CountSchedule(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = repo.CountSchedule(ctx, "LOAN-COUNT-UNKNOWN")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestLoanRepository_GetScheduleWeek(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-WEEK-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 2,
+		WeeklyPayment: decimal.NewFromInt(550000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	var schedules []*domain.LoanSchedule
+	for week := 1; week <= 2; week++ {
+		schedules = append(schedules, &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loan.LoanID,
+			WeekNumber: week,
+			DueAmount:  decimal.NewFromInt(550000),
+			DueDate:    time.Now().AddDate(0, 0, 7*week),
+			Status:     "pending",
+			CreatedAt:  time.Now(),
+		})
+	}
+	require.NoError(t, repo.CreateSchedule(ctx, schedules))
+	require.NoError(t, repo.UpdateScheduleStatus(ctx, loan.LoanID, 1, "paid"))
+
+	week, err := repo.GetScheduleWeek(ctx, loan.LoanID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, week.WeekNumber)
+	assert.Equal(t, "paid", week.Status)
+	assert.True(t, week.DueAmount.Equal(decimal.NewFromInt(550000)))
+
+	_, err = repo.GetScheduleWeek(ctx, loan.LoanID, 3)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestLoanRepository_CreateWithSchedule(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	newLoan := func(loanID string) *domain.Loan {
+		return &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 2,
+			WeeklyPayment: decimal.NewFromInt(550000),
+			Status:        "active",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+	}
+	newWeek := func(id uuid.UUID, loanID string, week int) *domain.LoanSchedule {
+		return &domain.LoanSchedule{
+			ID:         id,
+			LoanID:     loanID,
+			WeekNumber: week,
+			DueAmount:  decimal.NewFromInt(550000),
+			DueDate:    time.Now().AddDate(0, 0, 7*week),
+			Status:     "pending",
+			CreatedAt:  time.Now(),
+		}
+	}
+
+	t.Run("failed schedule insert leaves no loan row", func(t *testing.T) {
+		duplicateID := uuid.New()
+		err := repo.CreateWithSchedule(ctx, newLoan("LOAN-CWS-001"), []*domain.LoanSchedule{
+			newWeek(duplicateID, "LOAN-CWS-001", 1),
+			newWeek(duplicateID, "LOAN-CWS-001", 2), // Duplicate ID
+		})
+		assert.Error(t, err)
+
+		var count int
+		require.NoError(t, db.GetContext(ctx, &count, `SELECT COUNT(*) FROM loans WHERE loan_id = $1`, "LOAN-CWS-001"))
+		assert.Equal(t, 0, count)
+
+		schedule, err := repo.GetScheduleByLoanID(ctx, "LOAN-CWS-001")
+		require.NoError(t, err)
+		assert.Len(t, schedule, 0)
+	})
+
+	t.Run("success stores the loan and every week", func(t *testing.T) {
+		err := repo.CreateWithSchedule(ctx, newLoan("LOAN-CWS-002"), []*domain.LoanSchedule{
+			newWeek(uuid.New(), "LOAN-CWS-002", 1),
+			newWeek(uuid.New(), "LOAN-CWS-002", 2),
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetByLoanID(ctx, "LOAN-CWS-002")
+		require.NoError(t, err)
+
+		schedule, err := repo.GetScheduleByLoanID(ctx, "LOAN-CWS-002")
+		require.NoError(t, err)
+		assert.Len(t, schedule, 2)
+	})
+}
+
+func TestLoanRepository_GetBalancesByLoanIDs(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	// Loan with two payments totalling 44,000
+	paidLoan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-BATCH-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	// Loan without payments
+	unpaidLoan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-BATCH-002",
+		Amount:        decimal.NewFromInt(500000),
+		InterestRate:  decimal.NewFromFloat(0.15),
+		DurationWeeks: 25,
+		WeeklyPayment: decimal.NewFromInt(23000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	require.NoError(t, repo.Create(ctx, paidLoan))
+	require.NoError(t, repo.Create(ctx, unpaidLoan))
+
+	for week := 1; week <= 2; week++ {
+		err := paymentRepo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      paidLoan.LoanID,
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: time.Now(),
+			WeekNumber:  week,
+			CreatedAt:   time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := repo.GetBalancesByLoanIDs(ctx, []string{"LOAN-BATCH-001", "LOAN-BATCH-002", "NON-EXISTENT"})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	totalPaid := make(map[string]decimal.Decimal)
+	for _, balance := range result {
+		totalPaid[balance.LoanID] = balance.TotalPaid
+	}
+	assert.True(t, decimal.NewFromInt(44000).Equal(totalPaid["LOAN-BATCH-001"]))
+	assert.True(t, decimal.Zero.Equal(totalPaid["LOAN-BATCH-002"]))
+	_, found := totalPaid["NON-EXISTENT"]
+	assert.False(t, found)
+}
+
+func TestLoanRepository_Delete_SoftDeletes(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-DELETE-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	err := repo.Create(ctx, loan)
+	require.NoError(t, err)
+
+	err = repo.Delete(ctx, "LOAN-DELETE-001")
+	require.NoError(t, err)
+
+	// Hidden from normal queries
+	_, err = repo.GetByLoanID(ctx, "LOAN-DELETE-001")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	balances, err := repo.GetBalancesByLoanIDs(ctx, []string{"LOAN-DELETE-001"})
+	require.NoError(t, err)
+	assert.Empty(t, balances)
+
+	// Still retrievable for admin queries
+	result, err := repo.FindByLoanID(ctx, "LOAN-DELETE-001", repository.LoanQueryOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, loan.LoanID, result.LoanID)
+	require.NotNil(t, result.DeletedAt)
+
+	// The row is physically kept
+	var count int
+	err = db.Get(&count, "SELECT COUNT(*) FROM loans WHERE loan_id = $1", "LOAN-DELETE-001")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestLoanRepository_Delete_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	err := repo.Delete(ctx, "NON-EXISTENT")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestLoanRepository_Exists(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-EXISTS-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	exists, err := repo.Exists(ctx, "LOAN-EXISTS-001")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.Exists(ctx, "NON-EXISTENT")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Soft-deleted loans no longer exist for clients
+	require.NoError(t, repo.Delete(ctx, "LOAN-EXISTS-001"))
+	exists, err = repo.Exists(ctx, "LOAN-EXISTS-001")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLoanRepository_NextLoanIDSequence(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	const callers = 50
+	values := make([]int64, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], errs[i] = repo.NextLoanIDSequence(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, callers)
+	for i, value := range values {
+		require.NoError(t, errs[i])
+		assert.False(t, seen[value], "sequence value %d handed out twice", value)
+		seen[value] = true
+	}
+}
+
+func TestLoanRepository_UpdateScheduleStatuses(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-BULK-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 4,
+		WeeklyPayment: decimal.NewFromInt(275000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	var schedules []*domain.LoanSchedule
+	for week := 1; week <= 4; week++ {
+		schedules = append(schedules, &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     "LOAN-BULK-001",
+			WeekNumber: week,
+			DueAmount:  decimal.NewFromInt(275000),
+			DueDate:    time.Now().AddDate(0, 0, 7*week),
+			Status:     domain.ScheduleStatusPending,
+			CreatedAt:  time.Now(),
+		})
+	}
+	require.NoError(t, repo.CreateSchedule(ctx, schedules))
+
+	err := repo.UpdateScheduleStatuses(ctx, "LOAN-BULK-001", []int{1, 2, 4}, domain.ScheduleStatusPaid)
+	require.NoError(t, err)
+
+	result, err := repo.GetScheduleByLoanID(ctx, "LOAN-BULK-001")
+	require.NoError(t, err)
+	require.Len(t, result, 4)
+
+	assert.Equal(t, domain.ScheduleStatusPaid, result[0].Status)
+	assert.Equal(t, domain.ScheduleStatusPaid, result[1].Status)
+	assert.Equal(t, domain.ScheduleStatusPending, result[2].Status)
+	assert.Equal(t, domain.ScheduleStatusPaid, result[3].Status)
+}
+
+func TestLoanRepository_UpdateNotes(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-NOTES-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	// New loans start with an empty object
+	result, err := repo.GetByLoanID(ctx, "LOAN-NOTES-001")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(result.Notes))
+
+	notes := json.RawMessage(`{"contact":"called borrower","attempts":2,"tags":["hardship"]}`)
+	require.NoError(t, repo.UpdateNotes(ctx, "LOAN-NOTES-001", notes))
+
+	result, err = repo.GetByLoanID(ctx, "LOAN-NOTES-001")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(notes), string(result.Notes))
+
+	err = repo.UpdateNotes(ctx, "NON-EXISTENT", notes)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestLoanRepository_ListActiveLoanIDs(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	for _, l := range []struct {
+		loanID string
+		status string
+	}{
+		{"LOAN-BATCH-001", "active"},
+		{"LOAN-BATCH-002", "closed"},
+		{"LOAN-BATCH-003", "active"},
+		{"LOAN-BATCH-004", "delinquent"},
+		{"LOAN-BATCH-005", "active"},
+		{"LOAN-BATCH-006", "default"},
+	} {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        l.loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        l.status,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "LOAN-BATCH-005"))
+
+	page, err := repo.ListActiveLoanIDs(ctx, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOAN-BATCH-001", "LOAN-BATCH-003"}, page)
+
+	page, err = repo.ListActiveLoanIDs(ctx, "LOAN-BATCH-003", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOAN-BATCH-004"}, page)
+}
+
+func TestLoanRepository_GetByBorrowerID(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	created := time.Now().Add(-time.Hour)
+	for i, l := range []struct {
+		loanID     string
+		borrowerID string
+		status     string
+	}{
+		{"LOAN-BRW-001", "BRW-1", "closed"},
+		{"LOAN-BRW-002", "BRW-1", "active"},
+		{"LOAN-BRW-003", "BRW-1", "active"},
+		{"LOAN-BRW-004", "BRW-2", "active"},
+		{"LOAN-BRW-005", "", "active"},
+	} {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        l.loanID,
+			BorrowerID:    l.borrowerID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        l.status,
+			CreatedAt:     created.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:     created,
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "LOAN-BRW-003"))
+
+	loans, err := repo.GetByBorrowerID(ctx, "BRW-1")
+	require.NoError(t, err)
+	require.Len(t, loans, 2)
+	assert.Equal(t, "LOAN-BRW-001", loans[0].LoanID)
+	assert.Equal(t, "closed", loans[0].Status)
+	assert.Equal(t, "LOAN-BRW-002", loans[1].LoanID)
+	assert.Equal(t, "BRW-1", loans[1].BorrowerID)
+
+	loans, err = repo.GetByBorrowerID(ctx, "BRW-UNKNOWN")
+	require.NoError(t, err)
+	assert.NotNil(t, loans)
+	assert.Empty(t, loans)
+}
+
+func TestLoanRepository_GetEarliestUnpaidWeek(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-EARLIEST-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 3,
+		WeeklyPayment: decimal.NewFromInt(366667),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, loan))
+
+	var schedules []*domain.LoanSchedule
+	for week := 1; week <= 3; week++ {
+		schedules = append(schedules, &domain.LoanSchedule{
+			ID:         uuid.New(),
+			LoanID:     loan.LoanID,
+			WeekNumber: week,
+			DueAmount:  decimal.NewFromInt(366667),
+			DueDate:    time.Now().AddDate(0, 0, 7*week),
+			Status:     domain.ScheduleStatusPending,
+			CreatedAt:  time.Now(),
+		})
+	}
+	require.NoError(t, repo.CreateSchedule(ctx, schedules))
+
+	// Week 1 paid, so week 2 is the earliest unpaid one
+	require.NoError(t, repo.UpdateScheduleStatus(ctx, loan.LoanID, 1, domain.ScheduleStatusPaid))
+
+	result, err := repo.GetEarliestUnpaidWeek(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.WeekNumber)
+	assert.Equal(t, domain.ScheduleStatusPending, result.Status)
+
+	// Once every week is paid there's nothing left to find
+	require.NoError(t, repo.UpdateScheduleStatuses(ctx, loan.LoanID, []int{2, 3}, domain.ScheduleStatusPaid))
+
+	result, err = repo.GetEarliestUnpaidWeek(ctx, loan.LoanID)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, customError.ErrNoOutstandingBalance))
+}
+
+func TestLoanRepository_List_FiltersByTag(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	for i, l := range []struct {
+		loanID string
+		tags   pq.StringArray
+	}{
+		{"LOAN-TAG-001", pq.StringArray{"branch-a", "micro"}},
+		{"LOAN-TAG-002", pq.StringArray{"branch-b"}},
+		{"LOAN-TAG-003", pq.StringArray{"branch-a"}},
+		{"LOAN-TAG-004", nil},
+		{"LOAN-TAG-005", pq.StringArray{"branch-a"}},
+	} {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        l.loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        "active",
+			Tags:          l.tags,
+			CreatedAt:     base.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:     base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, repo.Delete(ctx, "LOAN-TAG-005"))
+
+	t.Run("tag filter returns matching live loans newest first", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Tag: "branch-a", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, loans, 2)
+		assert.Equal(t, "LOAN-TAG-003", loans[0].LoanID)
+		assert.Equal(t, "LOAN-TAG-001", loans[1].LoanID)
+		assert.Equal(t, pq.StringArray{"branch-a", "micro"}, loans[1].Tags)
+	})
+
+	t.Run("tag filter pages with the total unaffected", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Tag: "branch-a", Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, loans, 1)
+		assert.Equal(t, "LOAN-TAG-001", loans[0].LoanID)
+	})
+
+	t.Run("unknown tag matches nothing", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Tag: "branch-z", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, loans)
+	})
+
+	t.Run("no tag lists every live loan", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+		require.Len(t, loans, 4)
+		assert.Equal(t, "LOAN-TAG-004", loans[0].LoanID)
+		assert.Empty(t, loans[0].Tags)
+	})
+}
+
+func TestLoanRepository_List_FiltersByStatus(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	for i, l := range []struct {
+		loanID string
+		status string
+		tags   pq.StringArray
+	}{
+		{"LOAN-STATUS-001", domain.LoanStatusActive, pq.StringArray{"branch-a"}},
+		{"LOAN-STATUS-002", domain.LoanStatusClosed, pq.StringArray{"branch-a"}},
+		{"LOAN-STATUS-003", domain.LoanStatusActive, pq.StringArray{"branch-b"}},
+		{"LOAN-STATUS-004", domain.LoanStatusDefault, nil},
+	} {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        l.loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        l.status,
+			Tags:          l.tags,
+			CreatedAt:     base.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:     base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	t.Run("status filter returns matching loans newest first", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Status: domain.LoanStatusActive, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, loans, 2)
+		assert.Equal(t, "LOAN-STATUS-003", loans[0].LoanID)
+		assert.Equal(t, "LOAN-STATUS-001", loans[1].LoanID)
+	})
+
+	t.Run("status and tag combine", func(t *testing.T) {
+		loans, total, err := repo.List(ctx, domain.LoanFilter{Tag: "branch-a", Status: domain.LoanStatusClosed, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, loans, 1)
+		assert.Equal(t, "LOAN-STATUS-002", loans[0].LoanID)
+	})
+}
+
+func TestLoanRepository_GetDelinquencyByLoanIDs(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// seed creates a loan whose weeks fell due one week apart, the last of them weeksDue-1 weeks ago,
+	// followed by one week due in the future; paidWeeks are marked paid
+	seed := func(loanID, status string, weeksDue int, paidWeeks ...int) {
+		require.NoError(t, repo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: weeksDue + 1,
+			WeeklyPayment: decimal.NewFromInt(110000),
+			Status:        status,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}))
+
+		var schedules []*domain.LoanSchedule
+		for week := 1; week <= weeksDue+1; week++ {
+			schedules = append(schedules, &domain.LoanSchedule{
+				ID:         uuid.New(),
+				LoanID:     loanID,
+				WeekNumber: week,
+				DueAmount:  decimal.NewFromInt(110000),
+				DueDate:    today.AddDate(0, 0, 7*(week-weeksDue)),
+				Status:     domain.ScheduleStatusPending,
+				CreatedAt:  time.Now(),
+			})
+		}
+		require.NoError(t, repo.CreateSchedule(ctx, schedules))
+
+		if len(paidWeeks) > 0 {
+			require.NoError(t, repo.UpdateScheduleStatuses(ctx, loanID, paidWeeks, domain.ScheduleStatusPaid))
+		}
+	}
+
+	// Missed weeks 2-3, paid week 4, missed week 5 (due today)
+	seed("LOAN-DQ-001", "active", 5, 1, 4)
+	// Everything due is paid
+	seed("LOAN-DQ-002", "active", 3, 1, 2, 3)
+	// Weeks 1-3 missed, but week 2 falls inside forbearance so 1 and 3 form one run
+	seed("LOAN-DQ-003", "active", 3)
+	require.NoError(t, repo.SetForbearance(ctx, "LOAN-DQ-003", today.AddDate(0, 0, -8), today.AddDate(0, 0, -6)))
+	// Closed loans are still summarized; the service decides what that means
+	seed("LOAN-DQ-004", "closed", 2)
+	// Soft-deleted loans are left out
+	seed("LOAN-DQ-005", "active", 3)
+	require.NoError(t, repo.Delete(ctx, "LOAN-DQ-005"))
+	// Weeks 1-5 missed, but week 3's row is missing, so 1-2 and 4-5 are separate runs
+	seed("LOAN-DQ-006", "active", 5)
+	_, err := db.ExecContext(ctx, `DELETE FROM loan_schedule WHERE loan_id = $1 AND week_number = 3`, "LOAN-DQ-006")
+	require.NoError(t, err)
+
+	result, err := repo.GetDelinquencyByLoanIDs(ctx,
+		[]string{"LOAN-DQ-001", "LOAN-DQ-002", "LOAN-DQ-003", "LOAN-DQ-004", "LOAN-DQ-005", "LOAN-DQ-006", "NON-EXISTENT"}, today)
+	require.NoError(t, err)
+	require.Len(t, result, 5)
+
+	byLoan := make(map[string]*domain.LoanDelinquency)
+	for _, delinquency := range result {
+		byLoan[delinquency.LoanID] = delinquency
+	}
+
+	assert.Equal(t, 1, byLoan["LOAN-DQ-001"].MissedWeeks)
+	assert.Equal(t, 2, byLoan["LOAN-DQ-001"].LongestMissedStreak)
+
+	assert.Equal(t, 0, byLoan["LOAN-DQ-002"].MissedWeeks)
+	assert.Equal(t, 0, byLoan["LOAN-DQ-002"].LongestMissedStreak)
+
+	assert.Equal(t, 2, byLoan["LOAN-DQ-003"].MissedWeeks)
+	assert.Equal(t, 2, byLoan["LOAN-DQ-003"].LongestMissedStreak)
+
+	assert.Equal(t, "closed", byLoan["LOAN-DQ-004"].Status)
+	assert.Equal(t, 2, byLoan["LOAN-DQ-004"].MissedWeeks)
+
+	_, found := byLoan["LOAN-DQ-005"]
+	assert.False(t, found)
+
+	assert.Equal(t, 2, byLoan["LOAN-DQ-006"].MissedWeeks)
+	assert.Equal(t, 2, byLoan["LOAN-DQ-006"].LongestMissedStreak)
+}
+
+func TestLoanRepository_QueryTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewLoanRepository(db, repository.WithQueryTimeout(time.Second))
+	unitOfWork := repository.NewUnitOfWork(db, repository.WithQueryTimeout(time.Second))
+
+	// A context already past its deadline, as a request that outlived the server write timeout has
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := repo.GetScheduleByLoanID(expired, "LOAN-TIMEOUT")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, customError.ErrQueryTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = unitOfWork.Do(expired, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, customError.ErrQueryTimeout)
+
+	// A plain failure is not reported as a timeout
+	_, err = repo.GetScheduleByLoanID(context.Background(), "LOAN-TIMEOUT")
+	assert.NotErrorIs(t, err, customError.ErrQueryTimeout)
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/segyhp/billing-engine/internal/domain"
 	"github.com/segyhp/billing-engine/internal/repository"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -189,7 +191,7 @@ func TestPaymentRepository_GetTotalPaid(t *testing.T) {
 
 	totalPaid, err := repo.GetTotalPaid(ctx, "LOAN-PAY-003")
 	require.NoError(t, err)
-	assert.Equal(t, 59000.0, totalPaid) // 22000 + 22000 + 15000
+	assert.Equal(t, "59000", totalPaid.String()) // 22000 + 22000 + 15000
 }
 
 func TestPaymentRepository_GetTotalPaid_NoPayments(t *testing.T) {
@@ -218,7 +220,7 @@ func TestPaymentRepository_GetTotalPaid_NoPayments(t *testing.T) {
 
 	totalPaid, err := repo.GetTotalPaid(ctx, "LOAN-PAY-004")
 	require.NoError(t, err)
-	assert.Equal(t, 0.0, totalPaid)
+	assert.True(t, totalPaid.IsZero())
 }
 
 func TestPaymentRepository_GetTotalPaid_NonExistentLoan(t *testing.T) {
@@ -230,7 +232,88 @@ func TestPaymentRepository_GetTotalPaid_NonExistentLoan(t *testing.T) {
 
 	totalPaid, err := repo.GetTotalPaid(ctx, "NON-EXISTENT-LOAN")
 	require.NoError(t, err)
-	assert.Equal(t, 0.0, totalPaid)
+	assert.True(t, totalPaid.IsZero())
+}
+
+func TestPaymentRepository_GetTotalPaid_FullPrecision(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	loanRepo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, loanRepo.Create(ctx, &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-DEC",
+		Amount:        decimal.RequireFromString("9999999999999.9999"),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 3,
+		WeeklyPayment: decimal.RequireFromString("3333333333333.3333"),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}))
+
+	for week := 1; week <= 3; week++ {
+		require.NoError(t, repo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      "LOAN-PAY-DEC",
+			Amount:      decimal.RequireFromString("3333333333333.3333"),
+			PaymentDate: time.Now(),
+			WeekNumber:  week,
+			CreatedAt:   time.Now(),
+		}))
+	}
+
+	// float64 can't hold all 17 digits and rounds this up to 10000000000000
+	totalPaid, err := repo.GetTotalPaid(ctx, "LOAN-PAY-DEC")
+	require.NoError(t, err)
+	assert.Equal(t, "9999999999999.9999", totalPaid.String())
+}
+
+func TestPaymentRepository_GetTotalPaidForWeek(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-WEEK",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	// Two partial payments towards week 1 and one towards week 2
+	for _, p := range []struct {
+		week   int
+		amount int64
+	}{{1, 10000}, {1, 7500}, {2, 22000}} {
+		require.NoError(t, repo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      loan.LoanID,
+			Amount:      decimal.NewFromInt(p.amount),
+			PaymentDate: time.Now(),
+			WeekNumber:  p.week,
+			CreatedAt:   time.Now(),
+		}))
+	}
+
+	totalPaid, err := repo.GetTotalPaidForWeek(ctx, loan.LoanID, 1)
+	require.NoError(t, err)
+	assert.True(t, totalPaid.Equal(decimal.NewFromInt(17500)), "week 1 paid %s", totalPaid)
+
+	totalPaid, err = repo.GetTotalPaidForWeek(ctx, loan.LoanID, 3)
+	require.NoError(t, err)
+	assert.True(t, totalPaid.IsZero())
 }
 
 func TestPaymentRepository_GetLatestPayment(t *testing.T) {
@@ -308,6 +391,102 @@ func TestPaymentRepository_GetLatestPayment_NotFound(t *testing.T) {
 
 	_, err := repo.GetLatestPayment(ctx, "NON-EXISTENT-LOAN")
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, customError.ErrPaymentNotFound)
+}
+
+func TestPaymentRepository_GetByID(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-GET",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	payment := &domain.Payment{
+		ID:          uuid.New(),
+		LoanID:      "LOAN-PAY-GET",
+		Amount:      decimal.NewFromInt(22000),
+		PaymentDate: time.Now(),
+		WeekNumber:  1,
+		CreatedAt:   time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, payment))
+
+	found, err := repo.GetByID(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, payment.ID, found.ID)
+	assert.Equal(t, 1, found.WeekNumber)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, customError.ErrPaymentNotFound)
+}
+
+func TestPaymentRepository_AuditFields(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-AUDIT",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	audited := &domain.Payment{
+		ID:          uuid.New(),
+		LoanID:      "LOAN-PAY-AUDIT",
+		Amount:      decimal.NewFromInt(22000),
+		PaymentDate: time.Now().Add(-time.Hour),
+		WeekNumber:  1,
+		RecordedBy:  "agent-042",
+		Channel:     "branch",
+		CreatedAt:   time.Now(),
+	}
+	anonymous := &domain.Payment{
+		ID:          uuid.New(),
+		LoanID:      "LOAN-PAY-AUDIT",
+		Amount:      decimal.NewFromInt(22000),
+		PaymentDate: time.Now(),
+		WeekNumber:  2,
+		CreatedAt:   time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, audited))
+	require.NoError(t, repo.Create(ctx, anonymous))
+
+	found, err := repo.GetByID(ctx, audited.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-042", found.RecordedBy)
+	assert.Equal(t, "branch", found.Channel)
+
+	// Payments recorded without audit headers read back empty
+	payments, err := repo.GetByLoanID(ctx, "LOAN-PAY-AUDIT")
+	require.NoError(t, err)
+	require.Len(t, payments, 2)
+	assert.Equal(t, anonymous.ID, payments[0].ID)
+	assert.Empty(t, payments[0].RecordedBy)
+	assert.Empty(t, payments[0].Channel)
+	assert.Equal(t, "agent-042", payments[1].RecordedBy)
 }
 
 func TestPaymentRepository_GetLatestPayment_SamePaymentDate_DifferentCreatedAt(t *testing.T) {
@@ -390,3 +569,351 @@ func TestPaymentRepository_Create_ForeignKeyConstraint(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "violates foreign key constraint")
 }
+
+func TestPaymentRepository_List(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	loanRepo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	for _, loanID := range []string{"LOAN-LIST-001", "LOAN-LIST-002"} {
+		err := loanRepo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        "active",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	base := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	payments := []*domain.Payment{
+		{ID: uuid.New(), LoanID: "LOAN-LIST-001", Amount: decimal.NewFromInt(22000), PaymentDate: base, WeekNumber: 1, CreatedAt: time.Now()},
+		{ID: uuid.New(), LoanID: "LOAN-LIST-001", Amount: decimal.NewFromInt(22000), PaymentDate: base.AddDate(0, 0, 7), WeekNumber: 2, CreatedAt: time.Now()},
+		{ID: uuid.New(), LoanID: "LOAN-LIST-002", Amount: decimal.NewFromInt(22000), PaymentDate: base.AddDate(0, 0, 14), WeekNumber: 1, CreatedAt: time.Now()},
+	}
+	for _, payment := range payments {
+		require.NoError(t, repo.Create(ctx, payment))
+	}
+
+	t.Run("no filter returns everything newest first", func(t *testing.T) {
+		result, total, err := repo.List(ctx, domain.PaymentFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, result, 3)
+		assert.Equal(t, "LOAN-LIST-002", result[0].LoanID)
+	})
+
+	t.Run("loan filter", func(t *testing.T) {
+		result, total, err := repo.List(ctx, domain.PaymentFilter{LoanID: "LOAN-LIST-001", Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("date range is from-inclusive and to-exclusive", func(t *testing.T) {
+		from := base
+		to := base.AddDate(0, 0, 14)
+		result, total, err := repo.List(ctx, domain.PaymentFilter{From: &from, To: &to, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("paging keeps the full total", func(t *testing.T) {
+		result, total, err := repo.List(ctx, domain.PaymentFilter{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, result, 1)
+		assert.Equal(t, 2, result[0].WeekNumber)
+	})
+}
+
+func TestPaymentRepository_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-DEL",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     lastWeek,
+		UpdatedAt:     lastWeek,
+	}
+	loanRepo := repository.NewLoanRepository(db)
+	require.NoError(t, loanRepo.Create(ctx, loan))
+
+	payment := &domain.Payment{
+		ID:          uuid.New(),
+		LoanID:      "LOAN-PAY-DEL",
+		Amount:      decimal.NewFromInt(22000),
+		PaymentDate: time.Now(),
+		WeekNumber:  1,
+		CreatedAt:   time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, payment))
+
+	// Recording the payment bumps the loan's updated_at so cached reads revalidate
+	stored, err := loanRepo.GetByLoanID(ctx, "LOAN-PAY-DEL")
+	require.NoError(t, err)
+	assert.True(t, stored.UpdatedAt.After(lastWeek))
+	afterCreate := stored.UpdatedAt
+
+	require.NoError(t, repo.Delete(ctx, payment.ID))
+
+	payments, err := repo.GetByLoanID(ctx, "LOAN-PAY-DEL")
+	require.NoError(t, err)
+	assert.Empty(t, payments)
+
+	stored, err = loanRepo.GetByLoanID(ctx, "LOAN-PAY-DEL")
+	require.NoError(t, err)
+	assert.False(t, stored.UpdatedAt.Before(afterCreate))
+
+	// Deleting again reports the payment as missing
+	assert.ErrorIs(t, repo.Delete(ctx, payment.ID), sql.ErrNoRows)
+}
+
+func TestPaymentRepository_GetCollectionStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	loanRepo := repository.NewLoanRepository(db)
+	ctx := context.Background()
+
+	for _, loanID := range []string{"LOAN-COLL-001", "LOAN-COLL-002", "LOAN-COLL-003"} {
+		require.NoError(t, loanRepo.Create(ctx, &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.1),
+			DurationWeeks: 50,
+			WeeklyPayment: decimal.NewFromInt(22000),
+			Status:        "active",
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}))
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	seeded := []struct {
+		loanID string
+		date   time.Time
+		week   int
+	}{
+		{"LOAN-COLL-001", from.Add(-time.Second), 1}, // just before the window
+		{"LOAN-COLL-001", from, 2},                   // window start is inclusive
+		{"LOAN-COLL-001", from.AddDate(0, 0, 7), 3},  // inside
+		{"LOAN-COLL-002", from.AddDate(0, 0, 20), 1}, // inside, second loan
+		{"LOAN-COLL-003", to, 1},                     // window end is exclusive
+		{"LOAN-COLL-003", to.AddDate(0, 0, 7), 2},    // after the window
+	}
+	for _, p := range seeded {
+		require.NoError(t, repo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      p.loanID,
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: p.date,
+			WeekNumber:  p.week,
+			CreatedAt:   p.date,
+		}))
+	}
+
+	stats, err := repo.GetCollectionStats(ctx, from, to)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(66000).Equal(stats.TotalCollected), "total collected %s", stats.TotalCollected)
+	assert.Equal(t, 3, stats.PaymentCount)
+	assert.Equal(t, 2, stats.LoansPaid)
+
+	// A window with no payments reports zeros rather than NULLs
+	empty, err := repo.GetCollectionStats(ctx, from.AddDate(-1, 0, 0), from.AddDate(-1, 1, 0))
+	require.NoError(t, err)
+	assert.True(t, empty.TotalCollected.IsZero())
+	assert.Equal(t, 0, empty.PaymentCount)
+	assert.Equal(t, 0, empty.LoansPaid)
+}
+
+func TestPaymentRepository_GetByWeekRange(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-WEEKS-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	for week := 1; week <= 6; week++ {
+		require.NoError(t, repo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      loan.LoanID,
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: time.Now().AddDate(0, 0, -7*(6-week)),
+			WeekNumber:  week,
+			CreatedAt:   time.Now(),
+		}))
+	}
+
+	tests := []struct {
+		name          string
+		fromWeek      int
+		toWeek        int
+		expectedWeeks []int
+	}{
+		{name: "both bounds inclusive", fromWeek: 2, toWeek: 4, expectedWeeks: []int{2, 3, 4}},
+		{name: "single week", fromWeek: 5, toWeek: 5, expectedWeeks: []int{5}},
+		{name: "range past the last payment", fromWeek: 6, toWeek: 50, expectedWeeks: []int{6}},
+		{name: "range with no payments", fromWeek: 7, toWeek: 10, expectedWeeks: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := repo.GetByWeekRange(ctx, loan.LoanID, tt.fromWeek, tt.toWeek)
+			require.NoError(t, err)
+
+			weeks := make([]int, 0, len(payments))
+			for _, payment := range payments {
+				weeks = append(weeks, payment.WeekNumber)
+			}
+			assert.Equal(t, tt.expectedWeeks, weeks)
+		})
+	}
+}
+
+func TestPaymentRepository_GetByLoanIDBetween(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-DATES-001",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for week := 1; week <= 4; week++ {
+		require.NoError(t, repo.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      loan.LoanID,
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: base.AddDate(0, 0, 7*(week-1)),
+			WeekNumber:  week,
+			CreatedAt:   time.Now(),
+		}))
+	}
+
+	dateOf := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name          string
+		from          *time.Time
+		to            *time.Time
+		expectedWeeks []int
+	}{
+		{name: "from inclusive, to exclusive", from: dateOf(base.AddDate(0, 0, 7)), to: dateOf(base.AddDate(0, 0, 21)), expectedWeeks: []int{2, 3}},
+		{name: "open start", to: dateOf(base.AddDate(0, 0, 8)), expectedWeeks: []int{1, 2}},
+		{name: "open end", from: dateOf(base.AddDate(0, 0, 15)), expectedWeeks: []int{4}},
+		{name: "no bounds", expectedWeeks: []int{1, 2, 3, 4}},
+		{name: "window with no payments", from: dateOf(base.AddDate(1, 0, 0)), expectedWeeks: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := repo.GetByLoanIDBetween(ctx, loan.LoanID, tt.from, tt.to)
+			require.NoError(t, err)
+			require.NotNil(t, payments)
+
+			weeks := make([]int, 0, len(payments))
+			for _, payment := range payments {
+				weeks = append(weeks, payment.WeekNumber)
+			}
+			assert.Equal(t, tt.expectedWeeks, weeks)
+		})
+	}
+}
+
+func TestPaymentRepository_GetLatestPayment_BackdatedPaymentRecordedLast(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	repo := repository.NewPaymentRepository(db)
+	ctx := context.Background()
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        "LOAN-PAY-BACKDATED",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(22000),
+		Status:        "active",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, repository.NewLoanRepository(db).Create(ctx, loan))
+
+	now := time.Now()
+	payments := []*domain.Payment{
+		{
+			ID:          uuid.New(),
+			LoanID:      "LOAN-PAY-BACKDATED",
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: now,
+			WeekNumber:  1,
+			CreatedAt:   now.Add(-time.Hour),
+		},
+		{
+			// Recorded later but dated three days back
+			ID:          uuid.New(),
+			LoanID:      "LOAN-PAY-BACKDATED",
+			Amount:      decimal.NewFromInt(22000),
+			PaymentDate: now.AddDate(0, 0, -3),
+			WeekNumber:  2,
+			CreatedAt:   now,
+		},
+	}
+	for _, payment := range payments {
+		require.NoError(t, repo.Create(ctx, payment))
+	}
+
+	latestPayment, err := repo.GetLatestPayment(ctx, "LOAN-PAY-BACKDATED")
+	require.NoError(t, err)
+	assert.Equal(t, 2, latestPayment.WeekNumber)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/tests/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedLoan(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	seeded := testutil.SeedLoan(t, db, testutil.LoanSeed{
+		LoanID:        "LOAN-SEED",
+		Amount:        decimal.NewFromInt(1000000),
+		DurationWeeks: 10,
+		StartDate:     start,
+		PaidWeeks:     3,
+	})
+
+	var loanRows, scheduleRows, paidRows, paymentRows int
+	require.NoError(t, db.GetContext(ctx, &loanRows, `SELECT COUNT(*) FROM loans WHERE loan_id = $1`, "LOAN-SEED"))
+	require.NoError(t, db.GetContext(ctx, &scheduleRows, `SELECT COUNT(*) FROM loan_schedule WHERE loan_id = $1`, "LOAN-SEED"))
+	require.NoError(t, db.GetContext(ctx, &paidRows,
+		`SELECT COUNT(*) FROM loan_schedule WHERE loan_id = $1 AND status = $2`, "LOAN-SEED", domain.ScheduleStatusPaid))
+	require.NoError(t, db.GetContext(ctx, &paymentRows, `SELECT COUNT(*) FROM payments WHERE loan_id = $1`, "LOAN-SEED"))
+	assert.Equal(t, 1, loanRows)
+	assert.Equal(t, 10, scheduleRows)
+	assert.Equal(t, 3, paidRows)
+	assert.Equal(t, 3, paymentRows)
+
+	// The seeded schedule adds up to principal plus the 10% flat interest
+	total := decimal.Zero
+	for _, schedule := range seeded.Schedule {
+		total = total.Add(schedule.DueAmount)
+	}
+	assert.True(t, decimal.NewFromInt(1100000).Equal(total), "schedule total %s", total)
+	assert.True(t, seeded.Schedule[9].DueDate.Equal(start.AddDate(0, 0, 63)))
+	assert.Equal(t, domain.ScheduleStatusPaid, seeded.Schedule[2].Status)
+	assert.Equal(t, domain.ScheduleStatusPending, seeded.Schedule[3].Status)
+
+	paid, err := repository.NewPaymentRepository(db).GetTotalPaid(ctx, "LOAN-SEED")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(330000).Equal(paid), "total paid %s", paid)
+
+	earliest, err := repository.NewLoanRepository(db).GetEarliestUnpaidWeek(ctx, "LOAN-SEED")
+	require.NoError(t, err)
+	assert.Equal(t, 4, earliest.WeekNumber)
+}
+
+func TestSeedLoan_Defaults(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	first := testutil.SeedLoan(t, db, testutil.LoanSeed{})
+	second := testutil.SeedLoan(t, db, testutil.LoanSeed{})
+
+	assert.NotEqual(t, first.Loan.LoanID, second.Loan.LoanID)
+	assert.Equal(t, domain.LoanStatusActive, first.Loan.Status)
+	assert.Len(t, first.Schedule, 50)
+	assert.Empty(t, first.Payments)
+	assert.True(t, decimal.NewFromInt(110000).Equal(first.Loan.WeeklyPayment))
+}
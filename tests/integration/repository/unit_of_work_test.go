@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedFinalWeekLoan creates an active loan whose only remaining week is week 1
+func seedFinalWeekLoan(t *testing.T, db *sqlx.DB, loanID string) *domain.Loan {
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(db)
+
+	loan := &domain.Loan{
+		ID:            uuid.New(),
+		LoanID:        loanID,
+		Amount:        decimal.NewFromInt(100000),
+		InterestRate:  decimal.NewFromFloat(0.1),
+		DurationWeeks: 1,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	require.NoError(t, loanRepo.Create(ctx, loan))
+
+	require.NoError(t, loanRepo.CreateSchedule(ctx, []*domain.LoanSchedule{{
+		ID:         uuid.New(),
+		LoanID:     loanID,
+		WeekNumber: 1,
+		DueAmount:  decimal.NewFromInt(110000),
+		DueDate:    time.Now().AddDate(0, 0, 7),
+		Status:     domain.ScheduleStatusPending,
+		CreatedAt:  time.Now(),
+	}}))
+
+	return loan
+}
+
+// payOff records the final payment, marks the week paid and closes the loan, failing on purpose
+// after the payment insert when failAfterInsert is set
+func payOff(ctx context.Context, uow repository.UnitOfWork, loan *domain.Loan, failAfterInsert error) error {
+	return uow.Do(ctx, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		err := payments.Create(ctx, &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      loan.LoanID,
+			Amount:      decimal.NewFromInt(110000),
+			PaymentDate: time.Now(),
+			WeekNumber:  1,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if failAfterInsert != nil {
+			return failAfterInsert
+		}
+
+		if err := loans.UpdateScheduleStatuses(ctx, loan.LoanID, []int{1}, domain.ScheduleStatusPaid); err != nil {
+			return err
+		}
+
+		closed := *loan
+		closed.Status = domain.LoanStatusClosed
+		return loans.Update(ctx, &closed)
+	})
+}
+
+func TestUnitOfWork_RollsBackOnFailure(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	loan := seedFinalWeekLoan(t, db, "LOAN-UOW-001")
+	uow := repository.NewUnitOfWork(db)
+
+	crash := errors.New("crash after payment insert")
+	err := payOff(ctx, uow, loan, crash)
+	assert.ErrorIs(t, err, crash)
+
+	// Nothing from the failed unit of work is visible
+	payments, err := repository.NewPaymentRepository(db).GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Empty(t, payments)
+
+	loanRepo := repository.NewLoanRepository(db)
+	schedule, err := loanRepo.GetScheduleByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	require.Len(t, schedule, 1)
+	assert.Equal(t, domain.ScheduleStatusPending, schedule[0].Status)
+
+	stored, err := loanRepo.GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusActive, stored.Status)
+}
+
+func TestUnitOfWork_CommitsOnSuccess(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	loan := seedFinalWeekLoan(t, db, "LOAN-UOW-002")
+	uow := repository.NewUnitOfWork(db)
+
+	require.NoError(t, payOff(ctx, uow, loan, nil))
+
+	payments, err := repository.NewPaymentRepository(db).GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Len(t, payments, 1)
+
+	loanRepo := repository.NewLoanRepository(db)
+	schedule, err := loanRepo.GetScheduleByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	require.Len(t, schedule, 1)
+	assert.Equal(t, domain.ScheduleStatusPaid, schedule[0].Status)
+
+	stored, err := loanRepo.GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusClosed, stored.Status)
+}
+
+func TestPaymentRepository_CreateAndSettleWeek(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestDB(db)
+
+	ctx := context.Background()
+	loan := seedFinalWeekLoan(t, db, "LOAN-SETTLE-001")
+	loanRepo := repository.NewLoanRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+
+	newPayment := func(week int) *domain.Payment {
+		return &domain.Payment{
+			ID:          uuid.New(),
+			LoanID:      loan.LoanID,
+			Amount:      decimal.NewFromInt(110000),
+			PaymentDate: time.Now(),
+			WeekNumber:  week,
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	// A missing week rolls back the payment insert
+	err := paymentRepo.CreateAndSettleWeek(ctx, newPayment(2), loan.LoanID, 2, domain.ScheduleStatusPaid)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	payments, err := paymentRepo.GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Empty(t, payments)
+
+	// An existing week records the payment and settles the week together
+	require.NoError(t, paymentRepo.CreateAndSettleWeek(ctx, newPayment(1), loan.LoanID, 1, domain.ScheduleStatusPaid))
+
+	payments, err = paymentRepo.GetByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	assert.Len(t, payments, 1)
+
+	schedule, err := loanRepo.GetScheduleByLoanID(ctx, loan.LoanID)
+	require.NoError(t, err)
+	require.Len(t, schedule, 1)
+	assert.Equal(t, domain.ScheduleStatusPaid, schedule[0].Status)
+}
@@ -2,9 +2,13 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -25,16 +29,67 @@ func (m *MockLoanRepository) GetByLoanID(ctx context.Context, loanID string) (*d
 	return args.Get(0).(*domain.Loan), args.Error(1)
 }
 
+func (m *MockLoanRepository) Exists(ctx context.Context, loanID string) (bool, error) {
+	args := m.Called(ctx, loanID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoanRepository) NextLoanIDSequence(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLoanRepository) FindByLoanID(ctx context.Context, loanID string, opts repository.LoanQueryOptions) (*domain.Loan, error) {
+	args := m.Called(ctx, loanID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) SetForbearance(ctx context.Context, loanID string, start, end time.Time) error {
+	args := m.Called(ctx, loanID, start, end)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) UpdateNotes(ctx context.Context, loanID string, notes json.RawMessage) error {
+	args := m.Called(ctx, loanID, notes)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) Delete(ctx context.Context, loanID string) error {
+	args := m.Called(ctx, loanID)
+	return args.Error(0)
+}
+
 func (m *MockLoanRepository) Update(ctx context.Context, loan *domain.Loan) error {
 	args := m.Called(ctx, loan)
 	return args.Error(0)
 }
 
+func (m *MockLoanRepository) CreateWithSchedule(ctx context.Context, loan *domain.Loan, schedules []*domain.LoanSchedule) error {
+	args := m.Called(ctx, loan, schedules)
+	return args.Error(0)
+}
+
 func (m *MockLoanRepository) CreateSchedule(ctx context.Context, schedules []*domain.LoanSchedule) error {
 	args := m.Called(ctx, schedules)
 	return args.Error(0)
 }
 
+func (m *MockLoanRepository) GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error) {
+	args := m.Called(ctx, loanID, weekNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanSchedule), args.Error(1)
+}
+
+func (m *MockLoanRepository) CountSchedule(ctx context.Context, loanID string) (int, error) {
+	args := m.Called(ctx, loanID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockLoanRepository) GetScheduleByLoanID(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error) {
 	args := m.Called(ctx, loanID)
 	if args.Get(0) == nil {
@@ -43,19 +98,85 @@ func (m *MockLoanRepository) GetScheduleByLoanID(ctx context.Context, loanID str
 	return args.Get(0).([]*domain.LoanSchedule), args.Error(1)
 }
 
+func (m *MockLoanRepository) GetEarliestUnpaidWeek(ctx context.Context, loanID string) (*domain.LoanSchedule, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanSchedule), args.Error(1)
+}
+
 func (m *MockLoanRepository) UpdateScheduleStatus(ctx context.Context, loanID string, weekNumber int, status string) error {
 	args := m.Called(ctx, loanID, weekNumber, status)
 	return args.Error(0)
 }
 
-func (m *MockLoanRepository) GetOverdueSchedules(ctx context.Context, loanID string, currentDate time.Time) ([]*domain.LoanSchedule, error) {
-	args := m.Called(ctx, loanID, currentDate)
+func (m *MockLoanRepository) UpdateScheduleStatuses(ctx context.Context, loanID string, weeks []int, status string) error {
+	args := m.Called(ctx, loanID, weeks, status)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetOverdueSchedules(ctx context.Context, loanID string, cutoff time.Time) ([]*domain.LoanSchedule, error) {
+	args := m.Called(ctx, loanID, cutoff)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.LoanSchedule), args.Error(1)
 }
 
+func (m *MockLoanRepository) ListActiveLoanIDs(ctx context.Context, afterLoanID string, limit int) ([]string, error) {
+	args := m.Called(ctx, afterLoanID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockLoanRepository) MarkSchedulesOverdue(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLoanRepository) List(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Loan), args.Int(1), args.Error(2)
+}
+
+func (m *MockLoanRepository) GetByBorrowerID(ctx context.Context, borrowerID string) ([]*domain.Loan, error) {
+	args := m.Called(ctx, borrowerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetDelinquencyByLoanIDs(ctx context.Context, loanIDs []string, asOf time.Time) ([]*domain.LoanDelinquency, error) {
+	args := m.Called(ctx, loanIDs, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanDelinquency), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetBalancesByLoanIDs(ctx context.Context, loanIDs []string) ([]*domain.LoanBalance, error) {
+	args := m.Called(ctx, loanIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanBalance), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ExpectedCollectionWeek), args.Error(1)
+}
+
 type MockPaymentRepository struct {
 	mock.Mock
 }
@@ -65,6 +186,27 @@ func (m *MockPaymentRepository) Create(ctx context.Context, payment *domain.Paym
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) CreateAndSettleWeek(ctx context.Context, payment *domain.Payment, loanID string, week int, status string) error {
+	args := m.Called(ctx, payment, loanID, week, status)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) ([]*domain.Payment, error) {
+	args := m.Called(ctx, loanID, fromWeek, toWeek)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByLoanIDBetween(ctx context.Context, loanID string, from, to *time.Time) ([]*domain.Payment, error) {
+	args := m.Called(ctx, loanID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Payment), args.Error(1)
+}
+
 func (m *MockPaymentRepository) GetByLoanID(ctx context.Context, loanID string) ([]*domain.Payment, error) {
 	args := m.Called(ctx, loanID)
 	if args.Get(0) == nil {
@@ -73,9 +215,22 @@ func (m *MockPaymentRepository) GetByLoanID(ctx context.Context, loanID string)
 	return args.Get(0).([]*domain.Payment), args.Error(1)
 }
 
-func (m *MockPaymentRepository) GetTotalPaid(ctx context.Context, loanID string) (float64, error) {
+func (m *MockPaymentRepository) GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error) {
 	args := m.Called(ctx, loanID)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetTotalPaidForWeek(ctx context.Context, loanID string, weekNumber int) (decimal.Decimal, error) {
+	args := m.Called(ctx, loanID, weekNumber)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
 func (m *MockPaymentRepository) GetLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error) {
@@ -85,3 +240,39 @@ func (m *MockPaymentRepository) GetLatestPayment(ctx context.Context, loanID str
 	}
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
+
+func (m *MockPaymentRepository) List(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Payment), args.Int(1), args.Error(2)
+}
+
+func (m *MockPaymentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CollectionStats), args.Error(1)
+}
+
+// MockUnitOfWork runs the work directly against the mock repositories; there is no
+// transaction, so tests can only assert which calls were made
+type MockUnitOfWork struct {
+	LoanRepo    *MockLoanRepository
+	PaymentRepo *MockPaymentRepository
+}
+
+func NewMockUnitOfWork(loanRepo *MockLoanRepository, paymentRepo *MockPaymentRepository) *MockUnitOfWork {
+	return &MockUnitOfWork{LoanRepo: loanRepo, PaymentRepo: paymentRepo}
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(loans repository.LoanRepository, payments repository.PaymentRepository) error) error {
+	return fn(m.LoanRepo, m.PaymentRepo)
+}
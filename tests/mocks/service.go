@@ -2,7 +2,10 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/segyhp/billing-engine/internal/domain"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
@@ -20,16 +23,55 @@ func (m *MockBillingService) CreateLoan(ctx context.Context, request *domain.Cre
 	return args.Get(0).(*domain.Loan), args.Get(1).([]*domain.LoanSchedule), args.Error(2)
 }
 
+func (m *MockBillingService) CreateLoansBatch(ctx context.Context, requests []*domain.CreateLoanRequest) []*domain.BatchCreateLoanResult {
+	args := m.Called(ctx, requests)
+	return args.Get(0).([]*domain.BatchCreateLoanResult)
+}
+
 func (m *MockBillingService) GetOutstanding(ctx context.Context, loanID string) (decimal.Decimal, error) {
 	args := m.Called(ctx, loanID)
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
+func (m *MockBillingService) GetTotalPaid(ctx context.Context, loanID string) (decimal.Decimal, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockBillingService) GetOutstandingBreakdown(ctx context.Context, loanID string) (*domain.OutstandingBreakdown, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OutstandingBreakdown), args.Error(1)
+}
+
+func (m *MockBillingService) IsDelinquentAsOf(ctx context.Context, loanID string, asOf time.Time) (bool, error) {
+	args := m.Called(ctx, loanID, asOf)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockBillingService) IsDelinquent(ctx context.Context, loanID string) (bool, error) {
 	args := m.Called(ctx, loanID)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockBillingService) GetDelinquencyStatus(ctx context.Context, loanID string) (*domain.DelinquentResponse, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DelinquentResponse), args.Error(1)
+}
+
+func (m *MockBillingService) GetDelinquencyStatusAsOf(ctx context.Context, loanID string, asOf time.Time) (*domain.DelinquentResponse, error) {
+	args := m.Called(ctx, loanID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DelinquentResponse), args.Error(1)
+}
+
 func (m *MockBillingService) MakePayment(ctx context.Context, request domain.MakePaymentRequest) (*domain.Payment, error) {
 	args := m.Called(ctx, request)
 	if args.Get(0) == nil {
@@ -38,7 +80,238 @@ func (m *MockBillingService) MakePayment(ctx context.Context, request domain.Mak
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
+func (m *MockBillingService) GetScheduleWeek(ctx context.Context, loanID string, weekNumber int) (*domain.LoanSchedule, error) {
+	args := m.Called(ctx, loanID, weekNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanSchedule), args.Error(1)
+}
+
+func (m *MockBillingService) GetSchedule(ctx context.Context, loanID string) ([]*domain.LoanSchedule, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.LoanSchedule), args.Error(1)
+}
+
+func (m *MockBillingService) GetDelinquencyHistory(ctx context.Context, loanID string) ([]*domain.DelinquencySnapshot, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DelinquencySnapshot), args.Error(1)
+}
+
+func (m *MockBillingService) GetOutstandingBatch(ctx context.Context, loanIDs []string) (map[string]decimal.Decimal, []string, error) {
+	args := m.Called(ctx, loanIDs)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(map[string]decimal.Decimal), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockBillingService) GetNextDue(ctx context.Context, loanID string) (*domain.NextDue, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NextDue), args.Error(1)
+}
+
+func (m *MockBillingService) GetRemaining(ctx context.Context, loanID string) (*domain.RemainingSummary, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RemainingSummary), args.Error(1)
+}
+
+func (m *MockBillingService) GetBorrowerLoans(ctx context.Context, borrowerID string) ([]*domain.Loan, error) {
+	args := m.Called(ctx, borrowerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Loan), args.Error(1)
+}
+
+func (m *MockBillingService) CheckScheduleIntegrity(ctx context.Context, loanID string) (*domain.ScheduleIntegrity, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScheduleIntegrity), args.Error(1)
+}
+
+func (m *MockBillingService) GetPaymentStreak(ctx context.Context, loanID string) (*domain.PaymentStreak, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentStreak), args.Error(1)
+}
+
+func (m *MockBillingService) GetCurrentWeek(ctx context.Context, loanID string) (*domain.CurrentWeekResponse, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CurrentWeekResponse), args.Error(1)
+}
+
+func (m *MockBillingService) GetOutstandingDeviation(ctx context.Context, loanID string) (*domain.OutstandingDeviation, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OutstandingDeviation), args.Error(1)
+}
+
+func (m *MockBillingService) GetDelinquencyBatch(ctx context.Context, loanIDs []string) (map[string]*domain.DelinquencyStatus, []string, error) {
+	args := m.Called(ctx, loanIDs)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(map[string]*domain.DelinquencyStatus), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockBillingService) GetPaymentsByWeekRange(ctx context.Context, loanID string, fromWeek, toWeek int) (*domain.WeekRangePayments, error) {
+	args := m.Called(ctx, loanID, fromWeek, toWeek)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WeekRangePayments), args.Error(1)
+}
+
+func (m *MockBillingService) GetPaymentsByDateRange(ctx context.Context, loanID string, from, to *time.Time) (*domain.DateRangePayments, error) {
+	args := m.Called(ctx, loanID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DateRangePayments), args.Error(1)
+}
+
+func (m *MockBillingService) GetCostOfCredit(ctx context.Context, loanID string) (*domain.CostOfCredit, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CostOfCredit), args.Error(1)
+}
+
+func (m *MockBillingService) GetInterestPaid(ctx context.Context, loanID string) (*domain.InterestPaidSummary, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InterestPaidSummary), args.Error(1)
+}
+
+func (m *MockBillingService) ListLoans(ctx context.Context, filter domain.LoanFilter) ([]*domain.Loan, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Loan), args.Int(1), args.Error(2)
+}
+
+func (m *MockBillingService) ListPayments(ctx context.Context, filter domain.PaymentFilter) ([]*domain.Payment, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Payment), args.Int(1), args.Error(2)
+}
+
+func (m *MockBillingService) SetForbearance(ctx context.Context, loanID string, start, end time.Time) (*domain.Loan, error) {
+	args := m.Called(ctx, loanID, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
+func (m *MockBillingService) LoanExists(ctx context.Context, loanID string) (bool, error) {
+	args := m.Called(ctx, loanID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBillingService) GetLoan(ctx context.Context, loanID string) (*domain.Loan, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
+func (m *MockBillingService) UpdateNotes(ctx context.Context, loanID string, patch json.RawMessage) (json.RawMessage, error) {
+	args := m.Called(ctx, loanID, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
 // NewMockBillingService creates a new mock billing service instance
 func NewMockBillingService() *MockBillingService {
 	return &MockBillingService{}
 }
+
+func (m *MockBillingService) UndoLatestPayment(ctx context.Context, loanID string) (*domain.Payment, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockBillingService) ReversePayment(ctx context.Context, loanID string, paymentID uuid.UUID) error {
+	args := m.Called(ctx, loanID, paymentID)
+	return args.Error(0)
+}
+
+func (m *MockBillingService) RecomputeWeeklyPayment(ctx context.Context, loanID string) (*domain.Loan, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
+func (m *MockBillingService) GetPayment(ctx context.Context, paymentID uuid.UUID) (*domain.Payment, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockBillingService) GenerateLoanID(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockBillingService) GetCollectionStats(ctx context.Context, from, to time.Time) (*domain.CollectionStats, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CollectionStats), args.Error(1)
+}
+
+func (m *MockBillingService) GetExpectedCollections(ctx context.Context, from, to time.Time) ([]*domain.ExpectedCollectionWeek, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ExpectedCollectionWeek), args.Error(1)
+}
+
+func (m *MockBillingService) ExportLoan(ctx context.Context, loanID string) (*domain.LoanExport, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanExport), args.Error(1)
+}
@@ -0,0 +1,135 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/utils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// LoanSeed describes a loan for SeedLoan to create; zero fields take the defaults below
+type LoanSeed struct {
+	// LoanID defaults to LOAN- followed by 8 random hex digits
+	LoanID string
+	// Amount defaults to 5,000,000 and InterestRate to 0.10, charged flat over the term
+	Amount       decimal.Decimal
+	InterestRate decimal.Decimal
+	// DurationWeeks defaults to 50
+	DurationWeeks int
+	// StartDate is week 1's due date, with each later week due 7 days after the one before;
+	// it defaults to today at midnight, as for loans created through the service
+	StartDate time.Time
+	// Status defaults to active
+	Status string
+	// PaidWeeks is how many weeks from week 1 get a full payment, dated on the due date, and are marked paid
+	PaidWeeks int
+}
+
+// SeededLoan is what SeedLoan wrote
+type SeededLoan struct {
+	Loan     *domain.Loan
+	Schedule []*domain.LoanSchedule
+	Payments []*domain.Payment
+}
+
+// SeedLoan writes a loan, its schedule and any payments in a single transaction, failing t on error
+func SeedLoan(t testing.TB, db *sqlx.DB, seed LoanSeed) *SeededLoan {
+	t.Helper()
+
+	if seed.LoanID == "" {
+		seed.LoanID = "LOAN-" + uuid.NewString()[:8]
+	}
+	if seed.Amount.IsZero() {
+		seed.Amount = decimal.NewFromInt(5000000)
+	}
+	if seed.InterestRate.IsZero() {
+		seed.InterestRate = decimal.NewFromFloat(0.10)
+	}
+	if seed.DurationWeeks == 0 {
+		seed.DurationWeeks = 50
+	}
+	if seed.StartDate.IsZero() {
+		seed.StartDate = time.Now().Truncate(24 * time.Hour)
+	}
+	if seed.Status == "" {
+		seed.Status = domain.LoanStatusActive
+	}
+	require.LessOrEqual(t, seed.PaidWeeks, seed.DurationWeeks, "cannot pay more weeks than the loan has")
+
+	now := time.Now()
+	installments := utils.BuildInstallments(seed.Amount, seed.InterestRate, seed.DurationWeeks, 0, money.Scale)
+
+	seeded := &SeededLoan{
+		Loan: &domain.Loan{
+			ID:            uuid.New(),
+			LoanID:        seed.LoanID,
+			Amount:        seed.Amount,
+			InterestRate:  seed.InterestRate,
+			DurationWeeks: seed.DurationWeeks,
+			WeeklyPayment: utils.RegularInstallment(installments, 0).Total,
+			Status:        seed.Status,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+	}
+
+	paidWeeks := make([]int, 0, seed.PaidWeeks)
+	for week := 1; week <= seed.DurationWeeks; week++ {
+		installment := installments[week-1]
+		schedule := &domain.LoanSchedule{
+			ID:              uuid.New(),
+			LoanID:          seed.LoanID,
+			WeekNumber:      week,
+			DueAmount:       installment.Total,
+			PrincipalAmount: installment.Principal,
+			InterestAmount:  installment.Interest,
+			DueDate:         seed.StartDate.AddDate(0, 0, 7*(week-1)),
+			Status:          domain.ScheduleStatusPending,
+			CreatedAt:       now,
+		}
+		seeded.Schedule = append(seeded.Schedule, schedule)
+
+		if week <= seed.PaidWeeks {
+			seeded.Payments = append(seeded.Payments, &domain.Payment{
+				ID:          uuid.New(),
+				LoanID:      seed.LoanID,
+				Amount:      installment.Total,
+				PaymentDate: schedule.DueDate,
+				WeekNumber:  week,
+				CreatedAt:   now,
+			})
+			paidWeeks = append(paidWeeks, week)
+		}
+	}
+
+	ctx := context.Background()
+	err := repository.NewUnitOfWork(db).Do(ctx, func(loans repository.LoanRepository, payments repository.PaymentRepository) error {
+		if err := loans.CreateWithSchedule(ctx, seeded.Loan, seeded.Schedule); err != nil {
+			return err
+		}
+		for _, payment := range seeded.Payments {
+			if err := payments.Create(ctx, payment); err != nil {
+				return err
+			}
+		}
+		if len(paidWeeks) == 0 {
+			return nil
+		}
+		return loans.UpdateScheduleStatuses(ctx, seed.LoanID, paidWeeks, domain.ScheduleStatusPaid)
+	})
+	require.NoError(t, err, "seeding loan %s", seed.LoanID)
+
+	for _, schedule := range seeded.Schedule[:seed.PaidWeeks] {
+		schedule.Status = domain.ScheduleStatusPaid
+	}
+
+	return seeded
+}
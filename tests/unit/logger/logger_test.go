@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/segyhp/billing-engine/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level       string
+		expected    slog.Level
+		expectError bool
+	}{
+		{level: "debug", expected: slog.LevelDebug},
+		{level: "", expected: slog.LevelInfo},
+		{level: "INFO", expected: slog.LevelInfo},
+		{level: "warn", expected: slog.LevelWarn},
+		{level: "error", expected: slog.LevelError},
+		{level: "verbose", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			level, err := logger.ParseLevel(tt.level)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("json format filters below the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(&buf, "warn", logger.FormatJSON)
+		require.NoError(t, err)
+
+		l.Info("skipped")
+		l.Warn("kept", "loan_id", "LOAN123")
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "kept", line["msg"])
+		assert.Equal(t, "LOAN123", line["loan_id"])
+	})
+
+	t.Run("text format", func(t *testing.T) {
+		var buf bytes.Buffer
+		l, err := logger.New(&buf, "info", logger.FormatText)
+		require.NoError(t, err)
+
+		l.Info("hello")
+		assert.Contains(t, buf.String(), "msg=hello")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := logger.New(&bytes.Buffer{}, "info", "xml")
+		assert.Error(t, err)
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, "info", logger.FormatJSON)
+	require.NoError(t, err)
+
+	previous := slog.Default()
+	slog.SetDefault(l)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	ctx := logger.WithRequestID(context.Background(), "req-123")
+	assert.Equal(t, "req-123", logger.RequestID(ctx))
+	assert.Equal(t, "", logger.RequestID(context.Background()))
+
+	logger.FromContext(ctx).Error("Failed to move loan status")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-123", line[logger.RequestIDKey])
+}
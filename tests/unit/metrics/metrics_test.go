@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segyhp/billing-engine/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Record(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := metrics.New(registry)
+
+	m.LoanCreated()
+	m.LoanCreated()
+	m.PaymentProcessed(110000)
+	m.DelinquencyChecked(metrics.DelinquencyResultDelinquent)
+	m.DelinquencyChecked(metrics.DelinquencyResultCurrent)
+	m.DelinquencyChecked(metrics.DelinquencyResultCurrent)
+
+	expected := `
+# HELP loans_created_total Loans created.
+# TYPE loans_created_total counter
+loans_created_total 2
+# HELP payments_processed_total Payments accepted.
+# TYPE payments_processed_total counter
+payments_processed_total 1
+# HELP delinquency_checks_total Loan delinquency checks by result.
+# TYPE delinquency_checks_total counter
+delinquency_checks_total{result="current"} 2
+delinquency_checks_total{result="delinquent"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"loans_created_total", "payments_processed_total", "delinquency_checks_total"))
+
+	count, err := testutil.GatherAndCount(registry, "payment_amount_rupiah")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMetrics_NilRecordsNothing(t *testing.T) {
+	var m *metrics.Metrics
+
+	assert.NotPanics(t, func() {
+		m.LoanCreated()
+		m.PaymentProcessed(110000)
+		m.DelinquencyChecked(metrics.DelinquencyResultError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestMetrics_MiddlewareLabelsByRouteTemplate(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := metrics.New(registry)
+
+	router := mux.NewRouter()
+	router.Use(m.Middleware)
+	router.HandleFunc("/api/v1/loans/{loanId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	for _, loanID := range []string{"LOAN1", "LOAN2"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/"+loanID, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Both requests share one series, labeled by the template rather than the loan ID
+	count, err := testutil.GatherAndCount(registry, "http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "http_request_duration_seconds" {
+			continue
+		}
+		metric := family.GetMetric()[0]
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		assert.Equal(t, "/api/v1/loans/{loanId}", labels["route"])
+		assert.Equal(t, "404", labels["status"])
+		assert.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount())
+	}
+}
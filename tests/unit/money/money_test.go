@@ -0,0 +1,158 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   decimal.Decimal
+		expected string
+	}{
+		{
+			name:     "whole amount is padded",
+			amount:   decimal.NewFromInt(5500000),
+			expected: `"5500000.00"`,
+		},
+		{
+			name:     "single fractional digit is padded",
+			amount:   decimal.RequireFromString("1500.5"),
+			expected: `"1500.50"`,
+		},
+		{
+			name:     "zero",
+			amount:   decimal.Zero,
+			expected: `"0.00"`,
+		},
+		{
+			name:     "extra precision is rounded",
+			amount:   decimal.RequireFromString("10.005"),
+			expected: `"10.01"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(money.New(tt.amount))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestMoney_Display(t *testing.T) {
+	stored := decimal.RequireFromString("157142.8571")
+
+	tests := []struct {
+		name     string
+		places   int32
+		expected string
+	}{
+		{
+			name:     "rounded to whole units",
+			places:   0,
+			expected: `"157143"`,
+		},
+		{
+			name:     "rounded to cents",
+			places:   2,
+			expected: `"157142.86"`,
+		},
+		{
+			name:     "full stored precision",
+			places:   4,
+			expected: `"157142.8571"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			displayed := money.Display(stored, tt.places)
+			assert.Equal(t, tt.places, displayed.Places())
+
+			data, err := json.Marshal(displayed)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+
+	// The stored amount keeps its precision however it is displayed
+	assert.Equal(t, "157142.8571", stored.String())
+}
+
+func TestMoney_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      decimal.Decimal
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name:     "quoted string",
+			input:    `"110000.00"`,
+			expected: decimal.NewFromInt(110000),
+		},
+		{
+			name:     "bare number",
+			input:    `977`,
+			expected: decimal.NewFromInt(977),
+		},
+		{
+			name:     "trailing zeros beyond scale are accepted",
+			input:    `"12.500"`,
+			expected: decimal.RequireFromString("12.5"),
+		},
+		{
+			name:          "sub-cent precision is rejected",
+			input:         `"12.345"`,
+			expectedError: true,
+			errorContains: "exceeds 2 decimal places",
+		},
+		{
+			name:          "not a number",
+			input:         `"abc"`,
+			expectedError: true,
+			errorContains: "invalid money amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m money.Money
+			err := json.Unmarshal([]byte(tt.input), &m)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(m.Decimal), "Expected %s, got %s", tt.expected, m)
+		})
+	}
+}
+
+func TestMoney_RoundTrip(t *testing.T) {
+	type payload struct {
+		Outstanding money.Money `json:"outstanding"`
+	}
+
+	original := payload{Outstanding: money.New(decimal.RequireFromString("5280000.10"))}
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"outstanding":"5280000.10"}`, string(data))
+
+	var decoded payload
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, original.Outstanding.Equal(decoded.Outstanding.Decimal))
+}
@@ -0,0 +1,118 @@
+package request
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/segyhp/billing-engine/pkg/request"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      decimal.Decimal
+		expectedFound bool
+		expectedError bool
+	}{
+		{name: "valid amount", query: "amount=110000.50", expected: decimal.RequireFromString("110000.50"), expectedFound: true},
+		{name: "negative amount", query: "amount=-5", expected: decimal.NewFromInt(-5), expectedFound: true},
+		{name: "absent", query: "", expected: decimal.Zero, expectedFound: false},
+		{name: "empty", query: "amount=", expected: decimal.Zero, expectedFound: false},
+		{name: "malformed", query: "amount=abc", expectedFound: true, expectedError: true},
+		{name: "trailing garbage", query: "amount=10abc", expectedFound: true, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			value, found, err := request.Decimal(query, "amount")
+
+			assert.Equal(t, tt.expectedFound, found)
+			if tt.expectedError {
+				var paramErr *request.ParamError
+				require.True(t, errors.As(err, &paramErr), "expected a ParamError, got %v", err)
+				assert.Equal(t, "amount", paramErr.Param)
+				assert.Contains(t, err.Error(), "amount")
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(value), "expected %s, got %s", tt.expected, value)
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      int
+		expectedFound bool
+		expectedError bool
+	}{
+		{name: "valid", query: "limit=25", expected: 25, expectedFound: true},
+		{name: "absent", query: "", expectedFound: false},
+		{name: "malformed", query: "limit=abc", expectedFound: true, expectedError: true},
+		{name: "decimal is not an integer", query: "limit=2.5", expectedFound: true, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			value, found, err := request.Int(query, "limit")
+
+			assert.Equal(t, tt.expectedFound, found)
+			if tt.expectedError {
+				var paramErr *request.ParamError
+				require.True(t, errors.As(err, &paramErr), "expected a ParamError, got %v", err)
+				assert.Equal(t, "limit", paramErr.Param)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		expected      bool
+		expectedFound bool
+		expectedError bool
+	}{
+		{name: "true", query: "overdue=true", expected: true, expectedFound: true},
+		{name: "false", query: "overdue=false", expected: false, expectedFound: true},
+		{name: "numeric", query: "overdue=1", expected: true, expectedFound: true},
+		{name: "absent", query: "", expectedFound: false},
+		{name: "malformed", query: "overdue=yes", expectedFound: true, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			value, found, err := request.Bool(query, "overdue")
+
+			assert.Equal(t, tt.expectedFound, found)
+			if tt.expectedError {
+				var paramErr *request.ParamError
+				require.True(t, errors.As(err, &paramErr), "expected a ParamError, got %v", err)
+				assert.Equal(t, "overdue", paramErr.Param)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
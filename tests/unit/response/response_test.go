@@ -0,0 +1,219 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/pkg/logger"
+	"github.com/segyhp/billing-engine/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseTimestamps(t *testing.T) {
+	tests := []struct {
+		name     string
+		location *time.Location
+		utc      bool
+		write    func(http.ResponseWriter)
+		pattern  string
+	}{
+		{
+			name:    "success response in RFC3339 UTC",
+			utc:     true,
+			write:   func(w http.ResponseWriter) { response.Success(w, "ok") },
+			pattern: `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`,
+		},
+		{
+			name:    "error response in RFC3339 UTC",
+			utc:     true,
+			write:   func(w http.ResponseWriter) { response.NotFound(w, "Loan not found") },
+			pattern: `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`,
+		},
+		{
+			name:     "configured timezone offset",
+			location: time.FixedZone("WIB", 7*60*60),
+			write:    func(w http.ResponseWriter) { response.Success(w, "ok") },
+			pattern:  `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?\+07:00$`,
+		},
+		{
+			name:     "UTC overrides configured timezone",
+			location: time.FixedZone("WIB", 7*60*60),
+			utc:      true,
+			write:    func(w http.ResponseWriter) { response.InternalServerError(w, "boom", nil) },
+			pattern:  `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response.ConfigureTimestamps(tt.location, tt.utc)
+			t.Cleanup(func() { response.ConfigureTimestamps(nil, false) })
+
+			w := httptest.NewRecorder()
+			tt.write(w)
+
+			var body struct {
+				Timestamp string `json:"timestamp"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			assert.Regexp(t, regexp.MustCompile(tt.pattern), body.Timestamp)
+			stamped, err := time.Parse(time.RFC3339, body.Timestamp)
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), stamped, 5*time.Second)
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	dbErr := errors.New(`pq: relation "loans" does not exist`)
+
+	tests := []struct {
+		name           string
+		showDetails    bool
+		err            error
+		expectedError  string
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:          "development includes the raw error",
+			showDetails:   true,
+			err:           dbErr,
+			expectedError: dbErr.Error(),
+		},
+		{
+			name:        "production hides the raw error",
+			showDetails: false,
+			err:         dbErr,
+		},
+		{
+			name:         "production keeps the business error code",
+			showDetails:  false,
+			err:          customError.WrapLoanNotFound("LOAN-404"),
+			expectedCode: customError.ErrCodeLoanNotFound,
+		},
+		{
+			name:           "query timeout answers 504 with its own code",
+			showDetails:    false,
+			err:            customError.WrapDatabaseError(customError.WrapQueryTimeout(context.DeadlineExceeded)),
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedCode:   customError.ErrCodeQueryTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response.ConfigureErrorDetails(tt.showDetails)
+			t.Cleanup(func() { response.ConfigureErrorDetails(true) })
+
+			w := httptest.NewRecorder()
+			response.InternalServerError(w, "Failed to get loan", tt.err)
+
+			expectedStatus := tt.expectedStatus
+			if expectedStatus == 0 {
+				expectedStatus = http.StatusInternalServerError
+			}
+			assert.Equal(t, expectedStatus, w.Code)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			assert.Equal(t, "Failed to get loan", body["message"])
+			if tt.expectedError == "" {
+				assert.NotContains(t, body, "error")
+				assert.NotContains(t, w.Body.String(), "pq:")
+			} else {
+				assert.Equal(t, tt.expectedError, body["error"])
+			}
+			if tt.expectedCode == "" {
+				assert.NotContains(t, body, "code")
+			} else {
+				assert.Equal(t, tt.expectedCode, body["code"])
+			}
+		})
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		incomingID    string
+		expectedID    string
+		errorResponse bool
+	}{
+		{name: "incoming request ID is echoed", incomingID: "req-abc-123", expectedID: "req-abc-123"},
+		{name: "missing request ID is generated"},
+		{name: "request ID with spaces is replaced", incomingID: "forged id\nlevel=ERROR"},
+		{name: "request ID is attached to hidden error details", incomingID: "req-err-1", expectedID: "req-err-1", errorResponse: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			previous := slog.Default()
+			slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+			t.Cleanup(func() { slog.SetDefault(previous) })
+
+			response.ConfigureErrorDetails(!tt.errorResponse)
+			t.Cleanup(func() { response.ConfigureErrorDetails(true) })
+
+			var contextID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contextID = logger.RequestID(r.Context())
+				if tt.errorResponse {
+					response.InternalServerError(w, "Failed to get loan", errors.New("pq: connection refused"))
+					return
+				}
+				response.Success(w, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/loans/LOAN123", nil)
+			if tt.incomingID != "" {
+				req.Header.Set(response.RequestIDHeader, tt.incomingID)
+			}
+			w := httptest.NewRecorder()
+
+			response.LoggingMiddleware(next).ServeHTTP(w, req)
+
+			requestID := w.Header().Get(response.RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, requestID)
+			} else {
+				assert.NotEqual(t, tt.incomingID, requestID)
+			}
+			assert.Equal(t, requestID, contextID)
+
+			// Every log line written for the request carries its ID; the access line comes last
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			expectedLines := 1
+			if tt.errorResponse {
+				expectedLines = 2
+			}
+			require.Len(t, lines, expectedLines)
+			for _, raw := range lines {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal(raw, &line))
+				assert.Equal(t, requestID, line[logger.RequestIDKey])
+			}
+
+			var access map[string]interface{}
+			require.NoError(t, json.Unmarshal(lines[len(lines)-1], &access))
+			assert.Equal(t, http.MethodGet, access["method"])
+			assert.Equal(t, "/api/v1/loans/LOAN123", access["path"])
+			assert.Contains(t, access, "status")
+			assert.Contains(t, access, "duration")
+		})
+	}
+}
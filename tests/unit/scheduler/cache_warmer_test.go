@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/segyhp/billing-engine/internal/cache"
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	"github.com/segyhp/billing-engine/tests/mocks"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheWarmer_Warm(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{
+		App:   config.AppConfig{SchedulerBatchSize: 10, BatchConcurrency: 2},
+		Redis: config.RedisConfig{CacheWarmerInterval: time.Minute},
+	}
+
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	mockLoanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002", "LOAN003"}, nil).Once()
+
+	// Two weeks of 100,000 principal and 10,000 interest, the first of them paid
+	for _, loanID := range []string{"LOAN001", "LOAN002"} {
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(200000),
+			InterestRate:  decimal.NewFromFloat(0.10),
+			DurationWeeks: 2,
+			WeeklyPayment: decimal.NewFromInt(110000),
+			Status:        domain.LoanStatusActive,
+		}, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), PrincipalAmount: decimal.NewFromInt(100000), InterestAmount: decimal.NewFromInt(10000), Status: domain.ScheduleStatusPaid},
+			{LoanID: loanID, WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), PrincipalAmount: decimal.NewFromInt(100000), InterestAmount: decimal.NewFromInt(10000), Status: domain.ScheduleStatusPending},
+		}, nil)
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return([]*domain.Payment{
+			{LoanID: loanID, WeekNumber: 1, Amount: decimal.NewFromInt(110000)},
+		}, nil)
+	}
+	// One loan failing doesn't stop the others from being warmed
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN003").Return(nil, errors.New("database unavailable")).Once()
+
+	// A stale entry from before the payment is recomputed rather than read back and kept alive
+	require.NoError(t, server.Set(cache.OutstandingKey("LOAN001"), "220000"))
+
+	warmer := scheduler.NewCacheWarmer(
+		scheduler.NewScheduler(mockLoanRepo, cfg),
+		mockLoanRepo,
+		mockPaymentRepo,
+		cache.NewCache(client, 5*time.Minute),
+		cfg,
+	)
+
+	err := warmer.Warm(context.Background())
+	assert.ErrorContains(t, err, "LOAN003")
+
+	for _, loanID := range []string{"LOAN001", "LOAN002"} {
+		outstanding, err := server.Get(cache.OutstandingKey(loanID))
+		require.NoError(t, err)
+		assert.Equal(t, "110000", outstanding)
+		assert.Equal(t, 5*time.Minute, server.TTL(cache.OutstandingKey(loanID)))
+
+		breakdown, err := server.Get(cache.OutstandingBreakdownKey(loanID))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"principal_outstanding":"100000","interest_outstanding":"10000","total_outstanding":"110000"}`, breakdown)
+	}
+
+	assert.False(t, server.Exists(cache.OutstandingKey("LOAN003")))
+	assert.False(t, server.Exists(cache.OutstandingBreakdownKey("LOAN003")))
+
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
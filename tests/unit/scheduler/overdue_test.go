@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	"github.com/segyhp/billing-engine/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateOverduePayments(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	today := now.Truncate(24 * time.Hour)
+
+	delinquency := func(loanID, status string, missed int) []*domain.LoanDelinquency {
+		return []*domain.LoanDelinquency{{LoanID: loanID, Status: status, MissedWeeks: missed, LongestMissedStreak: missed}}
+	}
+	movedTo := func(loanID, status string) interface{} {
+		return mock.MatchedBy(func(loan *domain.Loan) bool {
+			return loan.LoanID == loanID && loan.Status == status && loan.UpdatedAt.Equal(now)
+		})
+	}
+
+	tests := []struct {
+		name                string
+		graceDays           int
+		boundary            string
+		setupMocks          func(*mocks.MockLoanRepository)
+		expectedMarked      int64
+		expectedDelinquent  int64
+		expectedDefaulted   int64
+		expectedReactivated int64
+		expectedError       bool
+		errorContains       string
+	}{
+		{
+			name: "marks overdue weeks and moves loans at the delinquency threshold to delinquent",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(3), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN001"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN001", domain.LoanStatusActive, 2), nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN002"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN002", domain.LoanStatusActive, 1), nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN001").
+					Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusActive}, nil).Once()
+				loanRepo.On("Update", mock.Anything, movedTo("LOAN001", domain.LoanStatusDelinquent)).Return(nil).Once()
+			},
+			expectedMarked:     3,
+			expectedDelinquent: 1,
+		},
+		{
+			name: "delinquent loans default at the default threshold and recover once caught up",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(1), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002", "LOAN003"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN001"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN001", domain.LoanStatusDelinquent, 4), nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN002"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN002", domain.LoanStatusDelinquent, 3), nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN003"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN003", domain.LoanStatusDelinquent, 0), nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN001").
+					Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusDelinquent}, nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN003").
+					Return(&domain.Loan{LoanID: "LOAN003", Status: domain.LoanStatusDelinquent}, nil).Once()
+				loanRepo.On("Update", mock.Anything, movedTo("LOAN001", domain.LoanStatusDefault)).Return(nil).Once()
+				loanRepo.On("Update", mock.Anything, movedTo("LOAN003", domain.LoanStatusActive)).Return(nil).Once()
+			},
+			expectedMarked:      1,
+			expectedDefaulted:   1,
+			expectedReactivated: 1,
+		},
+		{
+			name: "loan closed since it was listed is left alone",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(0), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN001"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN001", domain.LoanStatusActive, 2), nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN001").
+					Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusClosed}, nil).Once()
+			},
+		},
+		{
+			name: "rerun with nothing new changes nothing",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(0), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN002"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN002"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN002", domain.LoanStatusActive, 1), nil).Once()
+			},
+		},
+		{
+			name:      "grace days move the cutoff back",
+			graceDays: 3,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				cutoff := today.AddDate(0, 0, -3)
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, cutoff).Return(int64(1), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{}, nil).Once()
+			},
+			expectedMarked: 1,
+		},
+		{
+			name:      "inclusive boundary also marks weeks due exactly grace days ago",
+			graceDays: 3,
+			boundary:  config.OverdueBoundaryInclusive,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				cutoff := today.AddDate(0, 0, -2)
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, cutoff).Return(int64(1), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN001"}, today.AddDate(0, 0, -3)).
+					Return(delinquency("LOAN001", domain.LoanStatusActive, 1), nil).Once()
+			},
+			expectedMarked: 1,
+		},
+		{
+			name: "failing loan does not stop the others",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(2), nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN001"}, today.AddDate(0, 0, -1)).
+					Return(nil, errors.New("database error")).Once()
+				loanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN002"}, today.AddDate(0, 0, -1)).
+					Return(delinquency("LOAN002", domain.LoanStatusActive, 4), nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN002").
+					Return(&domain.Loan{LoanID: "LOAN002", Status: domain.LoanStatusActive}, nil).Once()
+				loanRepo.On("Update", mock.Anything, movedTo("LOAN002", domain.LoanStatusDefault)).Return(nil).Once()
+			},
+			expectedMarked:    2,
+			expectedDefaulted: 1,
+			expectedError:     true,
+			errorContains:     "loan LOAN001: database error",
+		},
+		{
+			name: "marking overdue weeks fails",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("MarkSchedulesOverdue", mock.Anything, today).Return(int64(0), errors.New("database error")).Once()
+			},
+			expectedError: true,
+			errorContains: "mark overdue weeks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			tt.setupMocks(mockLoanRepo)
+
+			cfg := &config.Config{
+				App: config.AppConfig{
+					SchedulerBatchSize:       10,
+					BatchConcurrency:         2,
+					DelinquentWeeksThreshold: 2,
+					DefaultWeeksThreshold:    4,
+					OverdueGraceDays:         tt.graceDays,
+					OverdueBoundary:          tt.boundary,
+				},
+			}
+			s := scheduler.NewScheduler(mockLoanRepo, cfg)
+
+			run, err := s.UpdateOverduePayments(context.Background(), now)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				require.NoError(t, err)
+			}
+			if run != nil {
+				assert.Equal(t, tt.expectedMarked, run.SchedulesMarked)
+				assert.Equal(t, tt.expectedDelinquent, run.LoansDelinquent)
+				assert.Equal(t, tt.expectedDefaulted, run.LoansDefaulted)
+				assert.Equal(t, tt.expectedReactivated, run.LoansReactivated)
+			}
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
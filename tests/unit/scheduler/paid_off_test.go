@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseFullyPaidLoans(t *testing.T) {
+	now := time.Date(2024, 3, 15, 1, 0, 0, 0, time.UTC)
+
+	fullyPaid := func(loanID string) error {
+		return customError.WrapNoOutstandingBalance(loanID)
+	}
+	closed := func(loanID string) interface{} {
+		return mock.MatchedBy(func(loan *domain.Loan) bool {
+			return loan.LoanID == loanID && loan.Status == domain.LoanStatusClosed && loan.UpdatedAt.Equal(now)
+		})
+	}
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*mocks.MockLoanRepository)
+		expectedClosed int64
+		expectedError  bool
+		errorContains  string
+	}{
+		{
+			name: "closes a fully paid active loan and leaves loans still owing untouched",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN001").Return(nil, fullyPaid("LOAN001")).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN002").
+					Return(&domain.LoanSchedule{LoanID: "LOAN002", WeekNumber: 3, Status: domain.ScheduleStatusPending}, nil).Once()
+				loanRepo.On("CountSchedule", mock.Anything, "LOAN001").Return(50, nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN001").
+					Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusActive}, nil).Once()
+				loanRepo.On("Update", mock.Anything, closed("LOAN001")).Return(nil).Once()
+			},
+			expectedClosed: 1,
+		},
+		{
+			name: "fully paid delinquent loan is closed too",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN003"}, nil).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN003").Return(nil, fullyPaid("LOAN003")).Once()
+				loanRepo.On("CountSchedule", mock.Anything, "LOAN003").Return(10, nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN003").
+					Return(&domain.Loan{LoanID: "LOAN003", Status: domain.LoanStatusDelinquent}, nil).Once()
+				loanRepo.On("Update", mock.Anything, closed("LOAN003")).Return(nil).Once()
+			},
+			expectedClosed: 1,
+		},
+		{
+			name: "loan without a schedule is not treated as paid off",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN004"}, nil).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN004").Return(nil, fullyPaid("LOAN004")).Once()
+				loanRepo.On("CountSchedule", mock.Anything, "LOAN004").Return(0, nil).Once()
+			},
+		},
+		{
+			name: "loan closed since it was listed is left alone",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001"}, nil).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN001").Return(nil, fullyPaid("LOAN001")).Once()
+				loanRepo.On("CountSchedule", mock.Anything, "LOAN001").Return(50, nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN001").
+					Return(&domain.Loan{LoanID: "LOAN001", Status: domain.LoanStatusClosed}, nil).Once()
+			},
+		},
+		{
+			name: "failing loan does not stop the others",
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN001").Return(nil, errors.New("database error")).Once()
+				loanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN002").Return(nil, fullyPaid("LOAN002")).Once()
+				loanRepo.On("CountSchedule", mock.Anything, "LOAN002").Return(50, nil).Once()
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN002").
+					Return(&domain.Loan{LoanID: "LOAN002", Status: domain.LoanStatusActive}, nil).Once()
+				loanRepo.On("Update", mock.Anything, closed("LOAN002")).Return(nil).Once()
+			},
+			expectedClosed: 1,
+			expectedError:  true,
+			errorContains:  "loan LOAN001: database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			tt.setupMocks(mockLoanRepo)
+
+			cfg := &config.Config{
+				App: config.AppConfig{
+					SchedulerBatchSize: 10,
+					BatchConcurrency:   2,
+				},
+			}
+			s := scheduler.NewScheduler(mockLoanRepo, cfg)
+
+			closedLoans, err := s.CloseFullyPaidLoans(context.Background(), now)
+
+			if tt.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedClosed, closedLoans)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/segyhp/billing-engine/internal/config"
+	"github.com/segyhp/billing-engine/internal/scheduler"
+	"github.com/segyhp/billing-engine/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestForEachActiveLoan(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		setupMocks    func(*mocks.MockLoanRepository)
+		callback      func(loanID string) error
+		expectedLoans []string
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name:      "pages through loans using the configured batch size",
+			batchSize: 2,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 2).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "LOAN002", 2).Return([]string{"LOAN003", "LOAN004"}, nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "LOAN004", 2).Return([]string{"LOAN005"}, nil).Once()
+			},
+			expectedLoans: []string{"LOAN001", "LOAN002", "LOAN003", "LOAN004", "LOAN005"},
+		},
+		{
+			name:      "full last page requires one more empty fetch",
+			batchSize: 3,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 3).Return([]string{"LOAN001", "LOAN002", "LOAN003"}, nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "LOAN003", 3).Return([]string{}, nil).Once()
+			},
+			expectedLoans: []string{"LOAN001", "LOAN002", "LOAN003"},
+		},
+		{
+			name:      "no active loans",
+			batchSize: 10,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 10).Return([]string{}, nil).Once()
+			},
+			expectedLoans: []string{},
+		},
+		{
+			name:      "failing loan does not stop the run",
+			batchSize: 2,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 2).Return([]string{"LOAN001", "LOAN002"}, nil).Once()
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "LOAN002", 2).Return([]string{"LOAN003"}, nil).Once()
+			},
+			callback: func(loanID string) error {
+				if loanID == "LOAN002" {
+					return errors.New("boom")
+				}
+				return nil
+			},
+			expectedLoans: []string{"LOAN001", "LOAN002", "LOAN003"},
+			expectedError: true,
+			errorContains: "boom",
+		},
+		{
+			name:      "repository error",
+			batchSize: 2,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository) {
+				loanRepo.On("ListActiveLoanIDs", mock.Anything, "", 2).Return(nil, errors.New("database error")).Once()
+			},
+			expectedLoans: []string{},
+			expectedError: true,
+			errorContains: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			tt.setupMocks(mockLoanRepo)
+
+			cfg := &config.Config{
+				App: config.AppConfig{SchedulerBatchSize: tt.batchSize, BatchConcurrency: 2},
+			}
+			s := scheduler.NewScheduler(mockLoanRepo, cfg)
+
+			var (
+				mu      sync.Mutex
+				visited = []string{}
+			)
+			err := s.ForEachActiveLoan(context.Background(), func(ctx context.Context, loanID string) error {
+				mu.Lock()
+				visited = append(visited, loanID)
+				mu.Unlock()
+				if tt.callback != nil {
+					return tt.callback(loanID)
+				}
+				return nil
+			})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			sort.Strings(visited)
+			assert.Equal(t, tt.expectedLoans, visited)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -3,17 +3,31 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 	billingService "github.com/segyhp/billing-engine/internal/service"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/segyhp/billing-engine/internal/cache"
+	"github.com/segyhp/billing-engine/internal/config"
 	"github.com/segyhp/billing-engine/internal/domain"
+	"github.com/segyhp/billing-engine/internal/repository"
+	customError "github.com/segyhp/billing-engine/pkg/errors"
+	"github.com/segyhp/billing-engine/pkg/money"
+	"github.com/segyhp/billing-engine/pkg/utils"
 	"github.com/segyhp/billing-engine/tests/mocks"
 )
 
@@ -37,10 +51,9 @@ func TestCreateLoan(t *testing.T) {
 			durationWeeks: 50,
 			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
-				mockLoanRepo.On("Create", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
 					return loan.LoanID == loanID
-				})).Return(nil)
-				mockLoanRepo.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedules []*domain.LoanSchedule) bool {
+				}), mock.MatchedBy(func(schedules []*domain.LoanSchedule) bool {
 					return len(schedules) == 50
 				})).Return(nil)
 			},
@@ -49,6 +62,9 @@ func TestCreateLoan(t *testing.T) {
 				assert.Equal(t, "LOAN123", loan.LoanID)
 				assert.Equal(t, 50, len(schedule))
 				assert.True(t, loan.WeeklyPayment.Equal(decimal.NewFromInt(110000)))
+				assert.WithinDuration(t, time.Now(), loan.CreatedAt, time.Minute)
+				assert.Equal(t, loan.CreatedAt, loan.UpdatedAt)
+				assert.Equal(t, loan.CreatedAt, schedule[0].CreatedAt)
 			},
 		},
 		{
@@ -85,16 +101,16 @@ func TestCreateLoan(t *testing.T) {
 			},
 		},
 		{
-			name:          "Failure - Database error on Create loan",
+			name:          "Failure - Database error creating the loan",
 			loanID:        "LOAN101",
 			amount:        decimal.NewFromFloat(5000000),
 			interestRate:  decimal.NewFromFloat(0.10),
 			durationWeeks: 50,
 			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
-				mockLoanRepo.On("Create", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
 					return loan.LoanID == loanID
-				})).Return(errors.New("failed to create loan"))
+				}), mock.Anything).Return(errors.New("failed to create loan"))
 			},
 			expectedError: true,
 			errorContains: "database",
@@ -104,17 +120,16 @@ func TestCreateLoan(t *testing.T) {
 			},
 		},
 		{
-			name:          "Failure - Database error on CreateSchedule",
+			name:          "Failure - Database error creating the schedule",
 			loanID:        "LOAN202",
 			amount:        decimal.NewFromFloat(5000000),
 			interestRate:  decimal.NewFromFloat(0.10),
 			durationWeeks: 50,
 			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
-				mockLoanRepo.On("Create", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
 					return loan.LoanID == loanID
-				})).Return(nil)
-				mockLoanRepo.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedules []*domain.LoanSchedule) bool {
+				}), mock.MatchedBy(func(schedules []*domain.LoanSchedule) bool {
 					return len(schedules) == 50
 				})).Return(errors.New("failed to create schedule"))
 			},
@@ -133,7 +148,7 @@ func TestCreateLoan(t *testing.T) {
 			mockLoanRepo := &mocks.MockLoanRepository{}
 			mockPaymentRepo := &mocks.MockPaymentRepository{}
 
-			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, nil, nil)
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
 
 			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
 
@@ -163,6 +178,34 @@ func TestCreateLoan(t *testing.T) {
 	}
 }
 
+func TestCreateLoan_ConcurrentDuplicate(t *testing.T) {
+	// Arrange: the existence check passes, then another request inserts the same loan ID first
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN281").Return(nil, sql.ErrNoRows).Once()
+	mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).
+		Return(customError.WrapLoanAlreadyExists("LOAN281")).Once()
+
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	// Act
+	loan, schedule, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+		LoanID:        "LOAN281",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, customError.ErrLoanAlreadyExists)
+	var businessErr *customError.BusinessError
+	require.ErrorAs(t, err, &businessErr)
+	assert.Equal(t, customError.ErrCodeLoanAlreadyExists, businessErr.Code)
+	assert.Nil(t, loan)
+	assert.Nil(t, schedule)
+	mockLoanRepo.AssertExpectations(t)
+}
+
 func TestGetOutstanding(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -245,6 +288,34 @@ func TestGetOutstanding(t *testing.T) {
 			expectedOutstanding: decimal.Zero, // Fully paid
 			expectedLoanStatus:  domain.LoanStatusClosed,
 		},
+		{
+			name:   "Success - Payments exceed total owed",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				loan := &domain.Loan{
+					LoanID:        loanID,
+					Amount:        decimal.NewFromInt(1000000),
+					InterestRate:  decimal.NewFromFloat(0.10),
+					DurationWeeks: 10,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					CreditBalance: decimal.NewFromInt(50000),
+					Status:        domain.LoanStatusClosed,
+				}
+
+				// 10 weekly payments plus an accepted 50,000 overpayment on the last one
+				payments := make([]*domain.Payment, 10)
+				for i := range payments {
+					payments[i] = &domain.Payment{LoanID: loanID, Amount: decimal.NewFromInt(110000)}
+				}
+				payments[9].Amount = decimal.NewFromInt(160000)
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments, nil)
+			},
+			expectedError:       false,
+			expectedOutstanding: decimal.Zero, // 1,100,000 owed - 1,150,000 paid, clamped
+			expectedLoanStatus:  domain.LoanStatusClosed,
+		},
 		{
 			name:   "Failure - Loan not found",
 			loanID: "NONEXISTENT",
@@ -293,7 +364,7 @@ func TestGetOutstanding(t *testing.T) {
 			mockLoanRepo := &mocks.MockLoanRepository{}
 			mockPaymentRepo := &mocks.MockPaymentRepository{}
 
-			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, nil, nil)
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
 
 			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
 
@@ -349,10 +420,37 @@ func TestIsDelinquent(t *testing.T) {
 
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
 				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusDelinquent
+				})).Return(nil).Once()
 			},
 			expectedError:      false,
 			expectedDelinquent: true,
 		},
+		{
+			name:   "Success - Week paid before the lowercase status breaks the run of missed weeks",
+			loanID: "LOAN128",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				loan := &domain.Loan{
+					LoanID:        loanID,
+					DurationWeeks: 50,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					Status:        domain.LoanStatusActive,
+					CreatedAt:     time.Now().AddDate(0, 0, -28),
+				}
+
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueDate: time.Now().AddDate(0, 0, -21), DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusOverdue},
+					{LoanID: loanID, WeekNumber: 2, DueDate: time.Now().AddDate(0, 0, -14), DueAmount: decimal.NewFromInt(110000), Status: "PAID"},
+					{LoanID: loanID, WeekNumber: 3, DueDate: time.Now().AddDate(0, 0, -7), DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusOverdue},
+				}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+			},
+			expectedError:      false,
+			expectedDelinquent: false,
+		},
 		{
 			name:   "Success - Loan is not delinquent (only 1 missed payment)",
 			loanID: "LOAN124",
@@ -459,7 +557,7 @@ func TestIsDelinquent(t *testing.T) {
 			expectedDelinquent: false,
 		},
 		{
-			name:   "Failure - loan status is already closed",
+			name:   "Success - Closed loan is not delinquent",
 			loanID: "LOAN127",
 			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
 				loan := &domain.Loan{
@@ -468,10 +566,25 @@ func TestIsDelinquent(t *testing.T) {
 					CreatedAt: time.Now().AddDate(0, 0, -21),
 				}
 
+				// The schedule isn't read and the loan isn't moved
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
 			},
-			expectedError:      true,
-			errorContains:      "closed",
+			expectedError:      false,
+			expectedDelinquent: false,
+		},
+		{
+			name:   "Success - Written-off loan is not delinquent",
+			loanID: "LOAN128",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				loan := &domain.Loan{
+					LoanID:    loanID,
+					Status:    domain.LoanStatusWrittenOff,
+					CreatedAt: time.Now().AddDate(0, 0, -21),
+				}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+			},
+			expectedError:      false,
 			expectedDelinquent: false,
 		},
 	}
@@ -482,7 +595,7 @@ func TestIsDelinquent(t *testing.T) {
 			mockLoanRepo := &mocks.MockLoanRepository{}
 			mockPaymentRepo := &mocks.MockPaymentRepository{}
 
-			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, nil, nil)
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
 
 			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
 
@@ -537,11 +650,13 @@ func TestMakePayment(t *testing.T) {
 				}
 
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(schedules[0], nil)
 				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
 				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
 					return payment.LoanID == loanID && payment.Amount.Equal(decimal.NewFromInt(110000)) && payment.WeekNumber == 1
 				})).Return(nil)
-				mockLoanRepo.On("UpdateScheduleStatus", mock.Anything, loanID, 1, "PAID").Return(nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil)
 			},
 			expectedError: false,
 			validateResult: func(t *testing.T, payment *domain.Payment) {
@@ -572,11 +687,13 @@ func TestMakePayment(t *testing.T) {
 				}
 
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(schedules[1], nil)
 				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
 				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
 					return payment.LoanID == loanID && payment.WeekNumber == 2
 				})).Return(nil)
-				mockLoanRepo.On("UpdateScheduleStatus", mock.Anything, loanID, 2, "PAID").Return(nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPaid).Return(nil)
 				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
 					return updatedLoan.Status == domain.LoanStatusClosed
 				})).Return(nil)
@@ -587,6 +704,40 @@ func TestMakePayment(t *testing.T) {
 				assert.Equal(t, 2, payment.WeekNumber)
 			},
 		},
+		{
+			name: "Success - Audit fields recorded on the payment",
+			request: domain.MakePaymentRequest{
+				LoanID:     "LOAN126",
+				Amount:     decimal.NewFromInt(110000),
+				RecordedBy: "agent-042",
+				Channel:    "branch",
+			},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				loan := &domain.Loan{
+					LoanID:        loanID,
+					Amount:        decimal.NewFromInt(5000000),
+					InterestRate:  decimal.NewFromFloat(0.10),
+					DurationWeeks: 50,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					Status:        domain.LoanStatusActive,
+				}
+				week := &domain.LoanSchedule{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: decimal.NewFromInt(110000)}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(week, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{week, {LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending}}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+					return payment.RecordedBy == "agent-042" && payment.Channel == "branch"
+				})).Return(nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, "agent-042", payment.RecordedBy)
+				assert.Equal(t, "branch", payment.Channel)
+			},
+		},
 		{
 			name: "Failure - Zero payment amount",
 			request: domain.MakePaymentRequest{
@@ -653,7 +804,9 @@ func TestMakePayment(t *testing.T) {
 					{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: decimal.NewFromInt(110000)},
 				}
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(schedules[0], nil)
 				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
 			},
 			expectedError: true,
 			errorContains: "payment amount",
@@ -673,12 +826,8 @@ func TestMakePayment(t *testing.T) {
 					WeeklyPayment: decimal.NewFromInt(110000),
 					Status:        domain.LoanStatusActive,
 				}
-				schedules := []*domain.LoanSchedule{
-					{LoanID: loanID, WeekNumber: 1, Status: "PAID", DueAmount: decimal.NewFromInt(110000)},
-					{LoanID: loanID, WeekNumber: 2, Status: "PAID", DueAmount: decimal.NewFromInt(110000)},
-				}
 				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
-				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(nil, customError.WrapNoOutstandingBalance(loanID))
 			},
 			expectedError: true,
 			errorContains: "outstanding balance",
@@ -686,6 +835,35 @@ func TestMakePayment(t *testing.T) {
 				assert.Nil(t, payment)
 			},
 		},
+		{
+			name: "Failure - Schedule update fails after the payment insert",
+			request: domain.MakePaymentRequest{
+				LoanID: "LOAN130",
+				Amount: decimal.NewFromInt(110000),
+			},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				loan := &domain.Loan{
+					LoanID:        loanID,
+					DurationWeeks: 1,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					Status:        domain.LoanStatusActive,
+				}
+				week := &domain.LoanSchedule{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: decimal.NewFromInt(110000)}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(week, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{week}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(assert.AnError)
+				// The unit of work stops at the failure, so the loan is never closed
+			},
+			expectedError: true,
+			errorContains: "database operation failed",
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
 		{
 			name: "Failure - Loan not found",
 			request: domain.MakePaymentRequest{
@@ -708,7 +886,7 @@ func TestMakePayment(t *testing.T) {
 			mockLoanRepo := &mocks.MockLoanRepository{}
 			mockPaymentRepo := &mocks.MockPaymentRepository{}
 
-			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, nil, nil)
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
 
 			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.request.LoanID)
 
@@ -731,3 +909,5241 @@ func TestMakePayment(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, []*domain.LoanSchedule)
+	}{
+		{
+			name:   "Success - Loan with schedule",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPending},
+					{LoanID: loanID, WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPending},
+				}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, schedule []*domain.LoanSchedule) {
+				assert.Len(t, schedule, 2)
+				assert.Equal(t, 1, schedule[0].WeekNumber)
+			},
+		},
+		{
+			name:   "Success - Mixed paid and pending weeks keep the repository's week order",
+			loanID: "LOAN126",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 3, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPending},
+					{LoanID: loanID, WeekNumber: 4, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 5, DueAmount: decimal.NewFromInt(110000), Status: domain.ScheduleStatusPending},
+				}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, schedule []*domain.LoanSchedule) {
+				require.Len(t, schedule, 5)
+				expectedStatuses := []string{
+					domain.ScheduleStatusPaid,
+					domain.ScheduleStatusPaid,
+					domain.ScheduleStatusPending,
+					domain.ScheduleStatusPaid,
+					domain.ScheduleStatusPending,
+				}
+				for i, week := range schedule {
+					assert.Equal(t, i+1, week.WeekNumber)
+					assert.Equal(t, expectedStatuses[i], week.Status)
+				}
+			},
+		},
+		{
+			name:   "Success - Loan with empty schedule returns empty slice",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				var schedules []*domain.LoanSchedule // No rows
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, schedule []*domain.LoanSchedule) {
+				assert.NotNil(t, schedule)
+				assert.Len(t, schedule, 0)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, schedule []*domain.LoanSchedule) {
+				assert.Nil(t, schedule)
+			},
+		},
+		{
+			name:   "Failure - Database error getting schedule",
+			loanID: "LOAN125",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(nil, errors.New("schedule query failed"))
+			},
+			expectedError: true,
+			errorContains: "database",
+			validateResult: func(t *testing.T, schedule []*domain.LoanSchedule) {
+				assert.Nil(t, schedule)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			schedule, err := service.GetSchedule(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, schedule)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportLoan(t *testing.T) {
+	deletedAt := time.Now().Add(-time.Hour)
+	includeDeleted := repository.LoanQueryOptions{IncludeDeleted: true}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  error
+		validateResult func(*testing.T, *domain.LoanExport)
+	}{
+		{
+			name:   "Success - Loan, schedule and payments",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("FindByLoanID", mock.Anything, loanID, includeDeleted).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending},
+				}, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return([]*domain.Payment{
+					{LoanID: loanID, WeekNumber: 1},
+				}, nil)
+			},
+			validateResult: func(t *testing.T, export *domain.LoanExport) {
+				assert.Equal(t, "LOAN123", export.Loan.LoanID)
+				assert.False(t, export.Deleted)
+				assert.Len(t, export.Schedule, 2)
+				assert.Len(t, export.Payments, 1)
+				assert.False(t, export.ExportedAt.IsZero())
+			},
+		},
+		{
+			name:   "Success - Soft-deleted loan without payments",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("FindByLoanID", mock.Anything, loanID, includeDeleted).Return(&domain.Loan{LoanID: loanID, DeletedAt: &deletedAt}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(nil, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			validateResult: func(t *testing.T, export *domain.LoanExport) {
+				assert.True(t, export.Deleted)
+				assert.NotNil(t, export.Schedule)
+				assert.Empty(t, export.Schedule)
+				assert.NotNil(t, export.Payments)
+				assert.Empty(t, export.Payments)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("FindByLoanID", mock.Anything, loanID, includeDeleted).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: customError.ErrLoanNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			export, err := service.ExportLoan(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError), "expected %v, got %v", tt.expectedError, err)
+				assert.Nil(t, export)
+			} else {
+				require.NoError(t, err)
+				tt.validateResult(t, export)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDelinquencyHistory(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, []*domain.DelinquencySnapshot)
+	}{
+		{
+			name:   "Success - Missed weeks then catch-up payment",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueDate: today.AddDate(0, 0, -28), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 2, DueDate: today.AddDate(0, 0, -21), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 3, DueDate: today.AddDate(0, 0, -14), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 4, DueDate: today.AddDate(0, 0, -7), Status: domain.ScheduleStatusPending},
+					{LoanID: loanID, WeekNumber: 5, DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+				}
+				payments := []*domain.Payment{
+					{LoanID: loanID, WeekNumber: 1, PaymentDate: today.AddDate(0, 0, -28)},
+					// Weeks 2 and 3 were paid late, after week 3 came due
+					{LoanID: loanID, WeekNumber: 2, PaymentDate: today.AddDate(0, 0, -10)},
+					{LoanID: loanID, WeekNumber: 3, PaymentDate: today.AddDate(0, 0, -10)},
+				}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, history []*domain.DelinquencySnapshot) {
+				assert.Len(t, history, 4) // Week 5 is not due yet
+
+				assert.Equal(t, 1, history[0].WeekNumber)
+				assert.Equal(t, 0, history[0].MissedWeeks)
+				assert.False(t, history[0].IsDelinquent)
+
+				assert.Equal(t, 1, history[1].MissedWeeks)
+				assert.False(t, history[1].IsDelinquent)
+
+				assert.Equal(t, 2, history[2].MissedWeeks)
+				assert.True(t, history[2].IsDelinquent)
+
+				// Catch-up payments cleared weeks 2-3, only week 4 is missed
+				assert.Equal(t, 4, history[3].WeekNumber)
+				assert.Equal(t, 1, history[3].MissedWeeks)
+				assert.False(t, history[3].IsDelinquent)
+			},
+		},
+		{
+			name:   "Success - Week counts as missed until the payment that completes it",
+			loanID: "LOAN125",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueDate: today.AddDate(0, 0, -21), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 2, DueDate: today.AddDate(0, 0, -14), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 3, DueDate: today.AddDate(0, 0, -7), Status: domain.ScheduleStatusPending},
+				}
+				payments := []*domain.Payment{
+					// Week 1 was started on time but only completed after week 2 came due
+					{LoanID: loanID, WeekNumber: 1, PaymentDate: today.AddDate(0, 0, -22)},
+					{LoanID: loanID, WeekNumber: 1, PaymentDate: today.AddDate(0, 0, -10)},
+					{LoanID: loanID, WeekNumber: 2, PaymentDate: today.AddDate(0, 0, -10)},
+					// Week 3 is still only partially paid
+					{LoanID: loanID, WeekNumber: 3, PaymentDate: today.AddDate(0, 0, -8)},
+				}
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, history []*domain.DelinquencySnapshot) {
+				require.Len(t, history, 3)
+
+				assert.Equal(t, 1, history[0].MissedWeeks)
+
+				assert.Equal(t, 2, history[1].MissedWeeks)
+				assert.True(t, history[1].IsDelinquent)
+
+				assert.Equal(t, 1, history[2].MissedWeeks)
+				assert.False(t, history[2].IsDelinquent)
+			},
+		},
+		{
+			name:   "Success - New loan has no history",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+				}
+				var payments []*domain.Payment
+
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, history []*domain.DelinquencySnapshot) {
+				assert.NotNil(t, history)
+				assert.Len(t, history, 0)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, history []*domain.DelinquencySnapshot) {
+				assert.Nil(t, history)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			history, err := service.GetDelinquencyHistory(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, history)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDelinquencyHistory_GraceAndScheduleGap(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	cfg := &config.Config{App: config.AppConfig{OverdueGraceDays: 3}}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+	today := time.Now().Truncate(24 * time.Hour)
+	loanID := "LOAN123"
+	mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+	// Week 3 is missing from the schedule, and week 6 is still within grace
+	mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{
+		{LoanID: loanID, WeekNumber: 1, DueDate: today.AddDate(0, 0, -28), Status: domain.ScheduleStatusPaid},
+		{LoanID: loanID, WeekNumber: 2, DueDate: today.AddDate(0, 0, -21), Status: domain.ScheduleStatusOverdue},
+		{LoanID: loanID, WeekNumber: 4, DueDate: today.AddDate(0, 0, -14), Status: domain.ScheduleStatusOverdue},
+		{LoanID: loanID, WeekNumber: 5, DueDate: today.AddDate(0, 0, -7), Status: domain.ScheduleStatusOverdue},
+		{LoanID: loanID, WeekNumber: 6, DueDate: today.AddDate(0, 0, -2), Status: domain.ScheduleStatusPending},
+	}, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return([]*domain.Payment{
+		// Two days late but inside the three-day grace
+		{LoanID: loanID, WeekNumber: 1, PaymentDate: today.AddDate(0, 0, -26)},
+	}, nil)
+
+	history, err := service.GetDelinquencyHistory(context.Background(), loanID)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+
+	// Week 1 was paid within grace, so its snapshot, taken once grace ran out, finds nothing missed
+	assert.Equal(t, 1, history[0].WeekNumber)
+	assert.True(t, history[0].AsOf.Equal(today.AddDate(0, 0, -24)), "snapshot taken %s", history[0].AsOf)
+	assert.Equal(t, 0, history[0].MissedWeeks)
+
+	assert.Equal(t, 1, history[1].MissedWeeks)
+
+	// The gap before week 4 ends the run week 2 started
+	assert.Equal(t, 4, history[2].WeekNumber)
+	assert.Equal(t, 1, history[2].MissedWeeks)
+	assert.False(t, history[2].IsDelinquent)
+
+	assert.Equal(t, 5, history[3].WeekNumber)
+	assert.Equal(t, 2, history[3].MissedWeeks)
+	assert.True(t, history[3].IsDelinquent)
+
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
+
+func TestGetOutstandingBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanIDs        []string
+		setupMocks     func(*mocks.MockLoanRepository)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, map[string]decimal.Decimal, []string)
+	}{
+		{
+			name:    "Success - Mix of existing and missing loans",
+			loanIDs: []string{"LOAN123", "MISSING1", "LOAN456", "MISSING2"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetBalancesByLoanIDs", mock.Anything, []string{"LOAN123", "MISSING1", "LOAN456", "MISSING2"}).
+					Return([]*domain.LoanBalance{
+						{
+							Loan:      domain.Loan{LoanID: "LOAN123", Amount: decimal.NewFromInt(5000000), InterestRate: decimal.NewFromFloat(0.10), DurationWeeks: 50},
+							TotalPaid: decimal.Zero,
+						},
+						{
+							Loan:      domain.Loan{LoanID: "LOAN456", Amount: decimal.NewFromInt(5000000), InterestRate: decimal.NewFromFloat(0.10), DurationWeeks: 50},
+							TotalPaid: decimal.NewFromInt(220000),
+						},
+					}, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, outstanding map[string]decimal.Decimal, notFound []string) {
+				assert.Len(t, outstanding, 2)
+				assert.True(t, outstanding["LOAN123"].Equal(decimal.NewFromInt(5500000)))
+				assert.True(t, outstanding["LOAN456"].Equal(decimal.NewFromInt(5280000)))
+				assert.Equal(t, []string{"MISSING1", "MISSING2"}, notFound)
+			},
+		},
+		{
+			name:    "Success - Duplicate IDs are queried once",
+			loanIDs: []string{"LOAN123", "LOAN123"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetBalancesByLoanIDs", mock.Anything, []string{"LOAN123"}).
+					Return([]*domain.LoanBalance{
+						{
+							Loan:      domain.Loan{LoanID: "LOAN123", Amount: decimal.NewFromInt(1000000), InterestRate: decimal.NewFromFloat(0.10), DurationWeeks: 10},
+							TotalPaid: decimal.NewFromInt(1100000),
+						},
+					}, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, outstanding map[string]decimal.Decimal, notFound []string) {
+				assert.Len(t, outstanding, 1)
+				assert.True(t, outstanding["LOAN123"].IsZero())
+				assert.Empty(t, notFound)
+			},
+		},
+		{
+			name:    "Success - Overpaid loan reports zero",
+			loanIDs: []string{"LOAN123"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetBalancesByLoanIDs", mock.Anything, []string{"LOAN123"}).
+					Return([]*domain.LoanBalance{
+						{
+							Loan:      domain.Loan{LoanID: "LOAN123", Amount: decimal.NewFromInt(1000000), InterestRate: decimal.NewFromFloat(0.10), DurationWeeks: 10},
+							TotalPaid: decimal.NewFromInt(1150000),
+						},
+					}, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, outstanding map[string]decimal.Decimal, notFound []string) {
+				assert.True(t, outstanding["LOAN123"].IsZero(), "outstanding %s", outstanding["LOAN123"])
+				assert.Empty(t, notFound)
+			},
+		},
+		{
+			name:    "Failure - Database error",
+			loanIDs: []string{"LOAN123"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetBalancesByLoanIDs", mock.Anything, []string{"LOAN123"}).
+					Return(nil, errors.New("database connection failed"))
+			},
+			expectedError: true,
+			errorContains: "database operation failed",
+			validateResult: func(t *testing.T, outstanding map[string]decimal.Decimal, notFound []string) {
+				assert.Nil(t, outstanding)
+				assert.Nil(t, notFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo)
+
+			// Act
+			outstanding, notFound, err := service.GetOutstandingBatch(context.Background(), tt.loanIDs)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, outstanding, notFound)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDelinquencyBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanIDs        []string
+		setupMocks     func(*mocks.MockLoanRepository)
+		expectedError  bool
+		validateResult func(*testing.T, map[string]*domain.DelinquencyStatus, []string)
+	}{
+		{
+			name:    "Success - Mixed states",
+			loanIDs: []string{"DELINQUENT", "CURRENT", "RECOVERED", "CLOSED", "MISSING", "DELINQUENT"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"DELINQUENT", "CURRENT", "RECOVERED", "CLOSED", "MISSING"}, mock.Anything).
+					Return([]*domain.LoanDelinquency{
+						{LoanID: "DELINQUENT", Status: domain.LoanStatusActive, MissedWeeks: 3, LongestMissedStreak: 3},
+						{LoanID: "CURRENT", Status: domain.LoanStatusActive, MissedWeeks: 1, LongestMissedStreak: 1},
+						// Missed two weeks in a row earlier, caught up since: still delinquent like IsDelinquent
+						{LoanID: "RECOVERED", Status: domain.LoanStatusActive, MissedWeeks: 0, LongestMissedStreak: 2},
+						{LoanID: "CLOSED", Status: domain.LoanStatusClosed, MissedWeeks: 2, LongestMissedStreak: 2},
+					}, nil)
+			},
+			validateResult: func(t *testing.T, statuses map[string]*domain.DelinquencyStatus, notFound []string) {
+				assert.Len(t, statuses, 4)
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: true, MissedWeeks: 3}, statuses["DELINQUENT"])
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: false, MissedWeeks: 1}, statuses["CURRENT"])
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: true, MissedWeeks: 0}, statuses["RECOVERED"])
+				assert.Equal(t, &domain.DelinquencyStatus{IsDelinquent: false, MissedWeeks: 0}, statuses["CLOSED"])
+				assert.Equal(t, []string{"MISSING"}, notFound)
+			},
+		},
+		{
+			name:    "Success - Weeks due yesterday are the latest that can count",
+			loanIDs: []string{"LOAN123"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN123"}, mock.MatchedBy(func(asOf time.Time) bool {
+					return asOf.Equal(time.Now().Truncate(24*time.Hour).AddDate(0, 0, -1))
+				})).Return([]*domain.LoanDelinquency{}, nil)
+			},
+			validateResult: func(t *testing.T, statuses map[string]*domain.DelinquencyStatus, notFound []string) {
+				assert.Empty(t, statuses)
+				assert.Equal(t, []string{"LOAN123"}, notFound)
+			},
+		},
+		{
+			name:    "Failure - Database error",
+			loanIDs: []string{"LOAN123"},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetDelinquencyByLoanIDs", mock.Anything, []string{"LOAN123"}, mock.Anything).
+					Return(nil, errors.New("database connection failed"))
+			},
+			expectedError: true,
+			validateResult: func(t *testing.T, statuses map[string]*domain.DelinquencyStatus, notFound []string) {
+				assert.Nil(t, statuses)
+				assert.Nil(t, notFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo)
+
+			// Act
+			statuses, notFound, err := service.GetDelinquencyBatch(context.Background(), tt.loanIDs)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "database operation failed")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, statuses, notFound)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_OverduePaymentPolicy(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	weeklyPayment := decimal.NewFromInt(110000)
+
+	// Three-week loan where every week is already overdue
+	overdueSchedules := func(loanID string) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, -21)},
+			{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, -14)},
+			{LoanID: loanID, WeekNumber: 3, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, -7)},
+		}
+	}
+	loan := func(loanID string) *domain.Loan {
+		return &domain.Loan{
+			LoanID:        loanID,
+			DurationWeeks: 3,
+			WeeklyPayment: weeklyPayment,
+			Status:        domain.LoanStatusActive,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		policy         string
+		request        domain.MakePaymentRequest
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, *domain.Payment)
+	}{
+		{
+			name:    "Catch-up - Pays the oldest overdue week",
+			policy:  config.OverduePaymentPolicyCatchUp,
+			request: domain.MakePaymentRequest{LoanID: "LOAN200", Amount: weeklyPayment},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(overdueSchedules(loanID)[0], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(overdueSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+					return payment.WeekNumber == 1 && payment.Amount.Equal(weeklyPayment)
+				})).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 1, payment.WeekNumber)
+			},
+		},
+		{
+			name:    "All overdue - Single week payment is rejected",
+			policy:  config.OverduePaymentPolicyAllOverdue,
+			request: domain.MakePaymentRequest{LoanID: "LOAN201", Amount: weeklyPayment},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(overdueSchedules(loanID)[0], nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(overdueSchedules(loanID), nil)
+			},
+			expectedError: true,
+			errorContains: "payment amount",
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+		{
+			name:    "All overdue - Full overdue amount settles every overdue week",
+			policy:  config.OverduePaymentPolicyAllOverdue,
+			request: domain.MakePaymentRequest{LoanID: "LOAN202", Amount: weeklyPayment.Mul(decimal.NewFromInt(3))},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(overdueSchedules(loanID)[0], nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(overdueSchedules(loanID), nil)
+
+				for week := 1; week <= 3; week++ {
+					week := week
+					mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+						return payment.WeekNumber == week && payment.Amount.Equal(weeklyPayment)
+					})).Return(nil).Once()
+				}
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1, 2, 3}, domain.ScheduleStatusPaid).Return(nil).Once()
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
+					return updatedLoan.Status == domain.LoanStatusClosed
+				})).Return(nil).Once()
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 3, payment.WeekNumber)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{OverduePaymentPolicy: tt.policy}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.request.LoanID)
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, payment)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_OverpaymentPolicy(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	weeklyPayment := decimal.NewFromInt(110000)
+	overpaid := weeklyPayment.Add(decimal.NewFromInt(500))
+
+	// Two-week loan; week 1 is paid, so paying week 2 closes the loan
+	finalWeekSchedules := func(loanID string) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPaid, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, -7)},
+			{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, 7)},
+		}
+	}
+	// Two-week loan with both weeks pending, so paying week 1 leaves the loan open
+	firstWeekSchedules := func(loanID string) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, 7)},
+			{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment, DueDate: today.AddDate(0, 0, 14)},
+		}
+	}
+	loan := func(loanID string) *domain.Loan {
+		return &domain.Loan{
+			LoanID:        loanID,
+			DurationWeeks: 2,
+			WeeklyPayment: weeklyPayment,
+			Status:        domain.LoanStatusActive,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		tolerance     float64
+		request       domain.MakePaymentRequest
+		setupMocks    func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError bool
+	}{
+		{
+			name:      "Credit - Overpaid final week closes the loan with a credit",
+			policy:    config.OverpaymentPolicyCredit,
+			tolerance: 1000,
+			request:   domain.MakePaymentRequest{LoanID: "LOAN210", Amount: overpaid},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(finalWeekSchedules(loanID)[1], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(finalWeekSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+					return payment.WeekNumber == 2 && payment.Amount.Equal(weeklyPayment)
+				})).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPaid).Return(nil).Once()
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
+					return updatedLoan.Status == domain.LoanStatusClosed &&
+						updatedLoan.CreditBalance.Equal(decimal.NewFromInt(500))
+				})).Return(nil).Once()
+			},
+			expectedError: false,
+		},
+		{
+			name:      "Credit - Exact final payment closes the loan without a credit",
+			policy:    config.OverpaymentPolicyCredit,
+			tolerance: 1000,
+			request:   domain.MakePaymentRequest{LoanID: "LOAN211", Amount: weeklyPayment},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(finalWeekSchedules(loanID)[1], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(finalWeekSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPaid).Return(nil).Once()
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
+					return updatedLoan.Status == domain.LoanStatusClosed && updatedLoan.CreditBalance.IsZero()
+				})).Return(nil).Once()
+			},
+			expectedError: false,
+		},
+		{
+			name:      "Reject - Overpaid final week is rejected",
+			policy:    config.OverpaymentPolicyReject,
+			tolerance: 1000,
+			request:   domain.MakePaymentRequest{LoanID: "LOAN212", Amount: overpaid},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(finalWeekSchedules(loanID)[1], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(finalWeekSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
+			},
+			expectedError: true,
+		},
+		{
+			name:      "Credit - Overpayment beyond the tolerance is rejected",
+			policy:    config.OverpaymentPolicyCredit,
+			tolerance: 100,
+			request:   domain.MakePaymentRequest{LoanID: "LOAN213", Amount: overpaid},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(finalWeekSchedules(loanID)[1], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(finalWeekSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
+			},
+			expectedError: true,
+		},
+		{
+			name:      "Credit - Overpayment that doesn't close the loan rolls into the next week",
+			policy:    config.OverpaymentPolicyCredit,
+			tolerance: 1000,
+			request:   domain.MakePaymentRequest{LoanID: "LOAN214", Amount: overpaid},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(firstWeekSchedules(loanID)[0], nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(firstWeekSchedules(loanID), nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+					return payment.WeekNumber == 1 && payment.Amount.Equal(weeklyPayment)
+				})).Return(nil).Once()
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+					return payment.WeekNumber == 2 && payment.Amount.Equal(decimal.NewFromInt(500))
+				})).Return(nil).Once()
+				// Week 2 is only part paid, so it stays pending and the loan stays open
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{OverpaymentPolicy: tt.policy, OverpaymentTolerance: tt.tolerance}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.request.LoanID)
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), tt.request)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, customError.ErrPaymentAmountMismatch))
+				assert.Nil(t, payment)
+				mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, payment.WeekNumber)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_Backdating(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	weeklyPayment := decimal.NewFromInt(110000)
+	dateOf := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name            string
+		maxBackdateDays int
+		paymentDate     *time.Time
+		expectedError   bool
+		checkDate       func(*testing.T, time.Time)
+	}{
+		{
+			name:            "Success - Omitted date records the payment now",
+			maxBackdateDays: 7,
+			checkDate: func(t *testing.T, recorded time.Time) {
+				assert.WithinDuration(t, time.Now(), recorded, time.Minute)
+			},
+		},
+		{
+			name:            "Success - Date inside the window is kept",
+			maxBackdateDays: 7,
+			paymentDate:     dateOf(today.AddDate(0, 0, -3).Add(10 * time.Hour)),
+			checkDate: func(t *testing.T, recorded time.Time) {
+				assert.True(t, recorded.Equal(today.AddDate(0, 0, -3).Add(10*time.Hour)))
+			},
+		},
+		{
+			name:            "Success - Start of the oldest allowed day is accepted",
+			maxBackdateDays: 7,
+			paymentDate:     dateOf(today.AddDate(0, 0, -7)),
+			checkDate: func(t *testing.T, recorded time.Time) {
+				assert.True(t, recorded.Equal(today.AddDate(0, 0, -7)))
+			},
+		},
+		{
+			name:            "Failure - Just before the oldest allowed day",
+			maxBackdateDays: 7,
+			paymentDate:     dateOf(today.AddDate(0, 0, -7).Add(-time.Second)),
+			expectedError:   true,
+		},
+		{
+			name:            "Failure - Well beyond the window",
+			maxBackdateDays: 7,
+			paymentDate:     dateOf(today.AddDate(0, 0, -30)),
+			expectedError:   true,
+		},
+		{
+			name:            "Success - No backdating still allows earlier today",
+			maxBackdateDays: 0,
+			paymentDate:     dateOf(today),
+		},
+		{
+			name:            "Failure - No backdating rejects yesterday",
+			maxBackdateDays: 0,
+			paymentDate:     dateOf(today.Add(-time.Second)),
+			expectedError:   true,
+		},
+		{
+			name:            "Failure - Future date",
+			maxBackdateDays: 7,
+			paymentDate:     dateOf(time.Now().Add(time.Hour)),
+			expectedError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{MaxBackdateDays: tt.maxBackdateDays}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loanID := "LOAN245"
+			if !tt.expectedError {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+					LoanID:        loanID,
+					DurationWeeks: 50,
+					WeeklyPayment: weeklyPayment,
+					Status:        domain.LoanStatusActive,
+				}, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(&domain.LoanSchedule{
+					LoanID:     loanID,
+					WeekNumber: 1,
+					DueAmount:  weeklyPayment,
+					DueDate:    today.AddDate(0, 0, 7),
+					Status:     domain.ScheduleStatusPending,
+				}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending}, {LoanID: loanID, WeekNumber: 50, Status: domain.ScheduleStatusPending}}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{
+				LoanID:      loanID,
+				Amount:      weeklyPayment,
+				PaymentDate: tt.paymentDate,
+			})
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, customError.ErrInvalidPaymentDate))
+				assert.Nil(t, payment)
+				mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				if tt.checkDate != nil {
+					tt.checkDate(t, payment.PaymentDate)
+				}
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_PaymentDayPolicy(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	saturday := today.AddDate(0, 0, -int((today.Weekday()-time.Saturday+7)%7))
+	friday := saturday.AddDate(0, 0, -1)
+	monday := saturday.AddDate(0, 0, 2)
+	weeklyPayment := decimal.NewFromInt(110000)
+	dateOf := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name          string
+		policy        string
+		holidays      []string
+		paymentDate   time.Time
+		expectedError bool
+		expectedDate  time.Time
+	}{
+		{
+			name:         "Success - Default policy keeps a weekend date",
+			policy:       config.PaymentDayPolicyAny,
+			paymentDate:  saturday,
+			expectedDate: saturday,
+		},
+		{
+			name:          "Failure - Reject policy refuses a weekend payment",
+			policy:        config.PaymentDayPolicyReject,
+			paymentDate:   saturday,
+			expectedError: true,
+		},
+		{
+			name:         "Success - Reject policy accepts a weekday payment",
+			policy:       config.PaymentDayPolicyReject,
+			paymentDate:  friday,
+			expectedDate: friday,
+		},
+		{
+			name:          "Failure - Reject policy refuses a holiday",
+			policy:        config.PaymentDayPolicyReject,
+			holidays:      []string{friday.Format("2006-01-02")},
+			paymentDate:   friday,
+			expectedError: true,
+		},
+		{
+			name:         "Success - Defer policy posts a weekend payment on Monday",
+			policy:       config.PaymentDayPolicyDefer,
+			paymentDate:  saturday,
+			expectedDate: monday,
+		},
+		{
+			name:         "Success - Defer policy skips a Monday holiday",
+			policy:       config.PaymentDayPolicyDefer,
+			holidays:     []string{monday.Format("2006-01-02")},
+			paymentDate:  saturday,
+			expectedDate: monday.AddDate(0, 0, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{
+				MaxBackdateDays:  14,
+				PaymentDayPolicy: tt.policy,
+				Holidays:         tt.holidays,
+			}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loanID := "LOAN246"
+			if !tt.expectedError {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+					LoanID:        loanID,
+					DurationWeeks: 50,
+					WeeklyPayment: weeklyPayment,
+					Status:        domain.LoanStatusActive,
+				}, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(&domain.LoanSchedule{
+					LoanID:     loanID,
+					WeekNumber: 1,
+					DueAmount:  weeklyPayment,
+					DueDate:    today.AddDate(0, 0, 7),
+					Status:     domain.ScheduleStatusPending,
+				}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending}, {LoanID: loanID, WeekNumber: 50, Status: domain.ScheduleStatusPending}}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{
+				LoanID:      loanID,
+				Amount:      weeklyPayment,
+				PaymentDate: dateOf(tt.paymentDate),
+			})
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, customError.ErrInvalidPaymentDate))
+				assert.Contains(t, err.Error(), "business days")
+				assert.Nil(t, payment)
+				mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, payment.PaymentDate.Equal(tt.expectedDate),
+					"expected %v, got %v", tt.expectedDate, payment.PaymentDate)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_MinPaymentInterval(t *testing.T) {
+	weeklyPayment := decimal.NewFromInt(110000)
+	loanID := "LOAN278"
+
+	tests := []struct {
+		name          string
+		interval      time.Duration
+		setupLatest   func(*mocks.MockPaymentRepository)
+		expectedError bool
+	}{
+		{
+			name:     "Success - Check is off by default",
+			interval: 0,
+		},
+		{
+			name:     "Success - First payment on the loan",
+			interval: time.Minute,
+			setupLatest: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(nil, customError.WrapLatestPaymentNotFound(loanID)).Once()
+			},
+		},
+		{
+			name:     "Failure - Back-to-back payment is refused",
+			interval: time.Minute,
+			setupLatest: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).
+					Return(&domain.Payment{LoanID: loanID, WeekNumber: 1, CreatedAt: time.Now().Add(-5 * time.Second)}, nil).Once()
+			},
+			expectedError: true,
+		},
+		{
+			name:     "Success - Payment after the interval",
+			interval: time.Minute,
+			setupLatest: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).
+					Return(&domain.Payment{LoanID: loanID, WeekNumber: 1, CreatedAt: time.Now().Add(-2 * time.Minute)}, nil).Once()
+			},
+		},
+		{
+			name:     "Success - Backdated payment recorded long ago",
+			interval: time.Minute,
+			setupLatest: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				// The interval is measured from when the payment was recorded, not the date it carries
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(&domain.Payment{
+					LoanID:      loanID,
+					WeekNumber:  1,
+					PaymentDate: time.Now(),
+					CreatedAt:   time.Now().Add(-time.Hour),
+				}, nil).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{MinPaymentInterval: tt.interval}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+				LoanID:        loanID,
+				DurationWeeks: 50,
+				WeeklyPayment: weeklyPayment,
+				Status:        domain.LoanStatusActive,
+			}, nil)
+			if tt.setupLatest != nil {
+				tt.setupLatest(mockPaymentRepo)
+			}
+			if !tt.expectedError {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(&domain.LoanSchedule{
+					LoanID:     loanID,
+					WeekNumber: 2,
+					DueAmount:  weeklyPayment,
+					DueDate:    time.Now().AddDate(0, 0, 7),
+					Status:     domain.ScheduleStatusPending,
+				}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending}, {LoanID: loanID, WeekNumber: 50, Status: domain.ScheduleStatusPending}}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPaid).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: loanID, Amount: weeklyPayment})
+
+			// Assert
+			if tt.expectedError {
+				assert.ErrorIs(t, err, customError.ErrPaymentTooSoon)
+				assert.Contains(t, err.Error(), "must be at least 1m0s apart")
+				assert.Nil(t, payment)
+				mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 2, payment.WeekNumber)
+			}
+			if tt.setupLatest == nil {
+				mockPaymentRepo.AssertNotCalled(t, "GetLatestPayment", mock.Anything, mock.Anything)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_PartialPayments(t *testing.T) {
+	weeklyPayment := decimal.NewFromInt(110000)
+
+	loan := func(loanID string, weeks int) *domain.Loan {
+		return &domain.Loan{
+			LoanID:        loanID,
+			DurationWeeks: weeks,
+			WeeklyPayment: weeklyPayment,
+			Status:        domain.LoanStatusActive,
+		}
+	}
+	// Three-week loan with nothing settled yet
+	openSchedules := func(loanID string) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment},
+			{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment},
+			{LoanID: loanID, WeekNumber: 3, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment},
+		}
+	}
+	paymentFor := func(week int, amount decimal.Decimal) interface{} {
+		return mock.MatchedBy(func(payment *domain.Payment) bool {
+			return payment.WeekNumber == week && payment.Amount.Equal(amount)
+		})
+	}
+
+	tests := []struct {
+		name           string
+		request        domain.MakePaymentRequest
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		validateResult func(*testing.T, *domain.Payment)
+	}{
+		{
+			name:    "Partial payment is recorded and leaves the week pending",
+			request: domain.MakePaymentRequest{LoanID: "LOAN400", Amount: decimal.NewFromInt(50000)},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID, 3), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(openSchedules(loanID)[0], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(openSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, paymentFor(1, decimal.NewFromInt(50000))).Return(nil).Once()
+			},
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 1, payment.WeekNumber)
+				assert.True(t, payment.Amount.Equal(decimal.NewFromInt(50000)))
+			},
+		},
+		{
+			name:    "Completing payment tops the week up to its due amount and marks it paid",
+			request: domain.MakePaymentRequest{LoanID: "LOAN401", Amount: decimal.NewFromInt(60000)},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID, 3), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(openSchedules(loanID)[0], nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(openSchedules(loanID), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.NewFromInt(50000), nil)
+				mockPaymentRepo.On("Create", mock.Anything, paymentFor(1, decimal.NewFromInt(60000))).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			},
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 1, payment.WeekNumber)
+			},
+		},
+		{
+			name:    "Payment spanning two weeks settles the first and rolls the rest into the next",
+			request: domain.MakePaymentRequest{LoanID: "LOAN402", Amount: decimal.NewFromInt(100000)},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID, 3), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(openSchedules(loanID)[0], nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.NewFromInt(50000), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(openSchedules(loanID), nil)
+				mockPaymentRepo.On("Create", mock.Anything, paymentFor(1, decimal.NewFromInt(60000))).Return(nil).Once()
+				mockPaymentRepo.On("Create", mock.Anything, paymentFor(2, decimal.NewFromInt(40000))).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			},
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 2, payment.WeekNumber)
+				assert.True(t, payment.Amount.Equal(decimal.NewFromInt(40000)))
+			},
+		},
+		{
+			name:    "Completing the final week after a partial payment closes the loan",
+			request: domain.MakePaymentRequest{LoanID: "LOAN403", Amount: decimal.NewFromInt(100000)},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				finalWeek := &domain.LoanSchedule{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: weeklyPayment}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID, 1), nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(finalWeek, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{finalWeek}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.NewFromInt(10000), nil)
+				mockPaymentRepo.On("Create", mock.Anything, paymentFor(1, decimal.NewFromInt(100000))).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
+					return updatedLoan.Status == domain.LoanStatusClosed
+				})).Return(nil).Once()
+			},
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Equal(t, 1, payment.WeekNumber)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.request.LoanID)
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), tt.request)
+
+			// Assert
+			require.NoError(t, err)
+			tt.validateResult(t, payment)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_MaxPaymentAmount(t *testing.T) {
+	weeklyPayment := decimal.NewFromInt(110000)
+
+	tests := []struct {
+		name          string
+		maxPayment    float64
+		amount        decimal.Decimal
+		expectedError bool
+	}{
+		{
+			name:       "Success - Amount at the maximum is accepted",
+			maxPayment: 110000,
+			amount:     weeklyPayment,
+		},
+		{
+			name:          "Failure - Amount just over the maximum",
+			maxPayment:    109999.99,
+			amount:        weeklyPayment,
+			expectedError: true,
+		},
+		{
+			name:   "Success - No maximum configured",
+			amount: weeklyPayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{MaxPaymentAmount: tt.maxPayment}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loanID := "LOAN262"
+			if !tt.expectedError {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+					LoanID:        loanID,
+					DurationWeeks: 50,
+					WeeklyPayment: weeklyPayment,
+					Status:        domain.LoanStatusActive,
+				}, nil)
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(&domain.LoanSchedule{
+					LoanID:     loanID,
+					WeekNumber: 1,
+					DueAmount:  weeklyPayment,
+					DueDate:    time.Now().AddDate(0, 0, 7),
+					Status:     domain.ScheduleStatusPending,
+				}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending}, {LoanID: loanID, WeekNumber: 50, Status: domain.ScheduleStatusPending}}, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+				mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{
+				LoanID: loanID,
+				Amount: tt.amount,
+			})
+
+			// Assert
+			if tt.expectedError {
+				assert.True(t, errors.Is(err, customError.ErrPaymentAmountTooLarge))
+				assert.Nil(t, payment)
+				mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, tt.amount.Equal(payment.Amount))
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_StoragePrecision(t *testing.T) {
+	// 1,100,000 over 7 weeks stored at 4 places; responses may show it as 157142.86
+	weeklyPayment := decimal.RequireFromString("157142.8571")
+	storage := int32(4)
+
+	tests := []struct {
+		name           string
+		amount         decimal.Decimal
+		expectedAmount decimal.Decimal
+		settlesWeek    bool
+	}{
+		{
+			name:           "Success - Stored amount settles the week",
+			amount:         weeklyPayment,
+			expectedAmount: weeklyPayment,
+			settlesWeek:    true,
+		},
+		{
+			name:           "Success - Extra digits are rounded to storage precision before matching",
+			amount:         decimal.RequireFromString("157142.85714"),
+			expectedAmount: weeklyPayment,
+			settlesWeek:    true,
+		},
+		{
+			name:           "Success - Amount short at storage precision is a partial payment",
+			amount:         decimal.RequireFromString("157142.857"),
+			expectedAmount: decimal.RequireFromString("157142.857"),
+			settlesWeek:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{StoragePrecision: &storage}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loanID := "LOAN267"
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{
+				LoanID:        loanID,
+				DurationWeeks: 7,
+				WeeklyPayment: weeklyPayment,
+				Status:        domain.LoanStatusActive,
+			}, nil)
+			mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(&domain.LoanSchedule{
+				LoanID:     loanID,
+				WeekNumber: 1,
+				DueAmount:  weeklyPayment,
+				DueDate:    time.Now().AddDate(0, 0, 7),
+				Status:     domain.ScheduleStatusPending,
+			}, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return([]*domain.LoanSchedule{{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPending}, {LoanID: loanID, WeekNumber: 7, Status: domain.ScheduleStatusPending}}, nil)
+			mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+			mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+				return p.WeekNumber == 1 && p.Amount.Equal(tt.expectedAmount)
+			})).Return(nil).Once()
+			if tt.settlesWeek {
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{1}, domain.ScheduleStatusPaid).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{
+				LoanID: loanID,
+				Amount: tt.amount,
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, tt.expectedAmount.Equal(payment.Amount), "expected %v, got %v", tt.expectedAmount, payment.Amount)
+			if !tt.settlesWeek {
+				mockLoanRepo.AssertNotCalled(t, "UpdateScheduleStatuses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMakePayment_OnTimeRebate(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	installment := decimal.NewFromInt(55000)
+	// Rp 100,000 at 10% is Rp 10,000 interest; half of it is rebated
+	rebate := decimal.NewFromInt(5000)
+
+	loan := func(loanID string) *domain.Loan {
+		return &domain.Loan{
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(100000),
+			InterestRate:  decimal.NewFromFloat(0.10),
+			DurationWeeks: 2,
+			WeeklyPayment: installment,
+			Status:        domain.LoanStatusActive,
+		}
+	}
+	// Week 1 is paid; week 2 is due finalDue and is the payment that closes the loan
+	schedules := func(loanID string, finalDue time.Time) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, Status: domain.ScheduleStatusPaid, DueAmount: installment, DueDate: finalDue.AddDate(0, 0, -7)},
+			{LoanID: loanID, WeekNumber: 2, Status: domain.ScheduleStatusPending, DueAmount: installment, DueDate: finalDue},
+		}
+	}
+	// Week 1 is paid in full at paidAt, or in two halves when partialAt is set
+	firstPayment := func(loanID string, partialAt, paidAt time.Time) []*domain.Payment {
+		if partialAt.IsZero() {
+			return []*domain.Payment{{LoanID: loanID, WeekNumber: 1, Amount: installment, PaymentDate: paidAt}}
+		}
+		half := installment.Div(decimal.NewFromInt(2))
+		return []*domain.Payment{
+			{LoanID: loanID, WeekNumber: 1, Amount: half, PaymentDate: paidAt},
+			{LoanID: loanID, WeekNumber: 1, Amount: half, PaymentDate: partialAt},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		rebateRate     float64
+		loanID         string
+		amount         decimal.Decimal
+		finalDue       time.Time
+		week1PartialAt time.Time
+		week1PaidAt    time.Time
+		partial        bool
+		expectedRebate decimal.Decimal
+	}{
+		{
+			name:           "Success - All weeks on time, final installment reduced by the rebate",
+			rebateRate:     0.5,
+			loanID:         "LOAN300",
+			amount:         installment.Sub(rebate),
+			finalDue:       today.AddDate(0, 0, 1),
+			week1PaidAt:    today.AddDate(0, 0, -7),
+			expectedRebate: rebate,
+		},
+		{
+			name:        "Partial - Late earlier week earns no rebate, so the reduced amount leaves the week open",
+			rebateRate:  0.5,
+			loanID:      "LOAN301",
+			amount:      installment.Sub(rebate),
+			finalDue:    today.AddDate(0, 0, 1),
+			week1PaidAt: today.AddDate(0, 0, -3),
+			partial:     true,
+		},
+		{
+			name:           "Success - Late earlier week pays the full final installment",
+			rebateRate:     0.5,
+			loanID:         "LOAN302",
+			amount:         installment,
+			finalDue:       today.AddDate(0, 0, 1),
+			week1PaidAt:    today.AddDate(0, 0, -3),
+			expectedRebate: decimal.Zero,
+		},
+		{
+			name:           "Success - Earlier week started on time but completed late earns no rebate",
+			rebateRate:     0.5,
+			loanID:         "LOAN305",
+			amount:         installment,
+			finalDue:       today.AddDate(0, 0, 1),
+			week1PartialAt: today.AddDate(0, 0, -9),
+			week1PaidAt:    today.AddDate(0, 0, -3),
+			expectedRebate: decimal.Zero,
+		},
+		{
+			name:           "Success - Late final week pays the full final installment",
+			rebateRate:     0.5,
+			loanID:         "LOAN303",
+			amount:         installment,
+			finalDue:       today.AddDate(0, 0, -2),
+			week1PaidAt:    today.AddDate(0, 0, -10),
+			expectedRebate: decimal.Zero,
+		},
+		{
+			name:        "Partial - Rebate disabled, so the reduced amount leaves the week open",
+			rebateRate:  0,
+			loanID:      "LOAN304",
+			amount:      installment.Sub(rebate),
+			finalDue:    today.AddDate(0, 0, 1),
+			week1PaidAt: today.AddDate(0, 0, -7),
+			partial:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{OnTimeRebateRate: tt.rebateRate}}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			schedule := schedules(tt.loanID, tt.finalDue)
+			mockLoanRepo.On("GetByLoanID", mock.Anything, tt.loanID).Return(loan(tt.loanID), nil)
+			mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, tt.loanID).Return(schedule[1], nil)
+			mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, tt.loanID, 2).Return(decimal.Zero, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, tt.loanID).Return(schedule, nil)
+			if tt.rebateRate > 0 {
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, tt.loanID).Return(firstPayment(tt.loanID, tt.week1PartialAt, tt.week1PaidAt), nil)
+			}
+			mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+				return payment.WeekNumber == 2 && payment.Amount.Equal(tt.amount)
+			})).Return(nil).Once()
+			if !tt.partial {
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, tt.loanID, []int{2}, domain.ScheduleStatusPaid).Return(nil).Once()
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updatedLoan *domain.Loan) bool {
+					return updatedLoan.Status == domain.LoanStatusClosed && updatedLoan.RebateAmount.Equal(tt.expectedRebate)
+				})).Return(nil).Once()
+			}
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: tt.loanID, Amount: tt.amount})
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, payment.Amount.Equal(tt.amount))
+			if tt.partial {
+				// The week isn't settled, so it stays pending and the loan stays open
+				mockLoanRepo.AssertNotCalled(t, "UpdateScheduleStatuses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				mockLoanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetOutstanding_AfterOnTimeRebate(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	// Rp 110,000 repayable, Rp 5,000 rebated at payoff, Rp 105,000 paid
+	loan := &domain.Loan{
+		LoanID:        "LOAN300",
+		Amount:        decimal.NewFromInt(100000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 2,
+		Status:        domain.LoanStatusClosed,
+		RebateAmount:  decimal.NewFromInt(5000),
+	}
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN300").Return(loan, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN300").Return([]*domain.Payment{
+		{LoanID: "LOAN300", WeekNumber: 1, Amount: decimal.NewFromInt(55000)},
+		{LoanID: "LOAN300", WeekNumber: 2, Amount: decimal.NewFromInt(50000)},
+	}, nil)
+
+	outstanding, err := service.GetOutstanding(context.Background(), "LOAN300")
+	require.NoError(t, err)
+	assert.True(t, outstanding.IsZero(), "outstanding %s", outstanding)
+}
+
+func TestGetRemaining(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, *domain.RemainingSummary)
+	}{
+		{
+			name:   "Success - Partially paid loan",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, -7), Status: "PAID"},
+					{LoanID: loanID, WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), DueDate: today, Status: domain.ScheduleStatusPending},
+					{LoanID: loanID, WeekNumber: 3, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+				}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, mock.Anything).Return(decimal.Zero, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, summary *domain.RemainingSummary) {
+				assert.Equal(t, 2, summary.RemainingInstallments)
+				assert.True(t, summary.RemainingAmount.Equal(decimal.NewFromInt(220000)))
+				assert.NotNil(t, summary.NextDueDate)
+				assert.True(t, today.Equal(*summary.NextDueDate))
+			},
+		},
+		{
+			name:   "Success - Partial payment on the next week is deducted",
+			loanID: "LOAN125",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, -7), Status: domain.ScheduleStatusPaid},
+					{LoanID: loanID, WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), DueDate: today, Status: domain.ScheduleStatusPending},
+					{LoanID: loanID, WeekNumber: 3, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+				}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.NewFromInt(40000), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 3).Return(decimal.Zero, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, summary *domain.RemainingSummary) {
+				assert.Equal(t, 2, summary.RemainingInstallments)
+				assert.True(t, summary.RemainingAmount.Equal(decimal.NewFromInt(180000)), "got %s", summary.RemainingAmount)
+			},
+		},
+		{
+			name:   "Success - Fully paid loan",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				schedules := []*domain.LoanSchedule{
+					{LoanID: loanID, WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), DueDate: today, Status: domain.ScheduleStatusPaid},
+				}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedules, nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, summary *domain.RemainingSummary) {
+				assert.Equal(t, 0, summary.RemainingInstallments)
+				assert.True(t, summary.RemainingAmount.IsZero())
+				assert.Nil(t, summary.NextDueDate)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, summary *domain.RemainingSummary) {
+				assert.Nil(t, summary)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			summary, err := service.GetRemaining(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, summary)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetNextDue(t *testing.T) {
+	start := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)
+	week := func(loanID string, number int, status string) *domain.LoanSchedule {
+		return &domain.LoanSchedule{
+			LoanID:     loanID,
+			WeekNumber: number,
+			DueAmount:  decimal.NewFromInt(110000),
+			DueDate:    start.AddDate(0, 0, 7*(number-1)),
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		loanID        string
+		setupMocks    func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError bool
+		expected      *domain.NextDue
+	}{
+		{
+			name:   "Success - Fresh loan is due week 1",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(week(loanID, 1, domain.ScheduleStatusPending), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 1).Return(decimal.Zero, nil)
+			},
+			expected: &domain.NextDue{WeekNumber: 1, DueDate: start, DueAmount: decimal.NewFromInt(110000)},
+		},
+		{
+			name:   "Success - Mid-term loan is due its earliest unpaid week, even when overdue",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(week(loanID, 4, domain.ScheduleStatusOverdue), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 4).Return(decimal.Zero, nil)
+			},
+			expected: &domain.NextDue{WeekNumber: 4, DueDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), DueAmount: decimal.NewFromInt(110000)},
+		},
+		{
+			name:   "Success - Partially paid week is due what is left on it",
+			loanID: "LOAN127",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(week(loanID, 2, domain.ScheduleStatusPending), nil)
+				mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loanID, 2).Return(decimal.NewFromInt(40000), nil)
+			},
+			expected: &domain.NextDue{WeekNumber: 2, DueDate: start.AddDate(0, 0, 7), DueAmount: decimal.NewFromInt(70000)},
+		},
+		{
+			name:   "Success - Fully paid loan has nothing due",
+			loanID: "LOAN125",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(nil, customError.WrapNoOutstandingBalance(loanID))
+			},
+			expected: nil,
+		},
+		{
+			name:   "Failure - Database error",
+			loanID: "LOAN126",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loanID).Return(nil, errors.New("connection refused"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			nextDue, err := service.GetNextDue(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.ErrorContains(t, err, "database operation failed")
+				assert.Nil(t, nextDue)
+			} else {
+				require.NoError(t, err)
+				if tt.expected == nil {
+					assert.Nil(t, nextDue)
+				} else {
+					require.NotNil(t, nextDue)
+					assert.Equal(t, tt.expected.WeekNumber, nextDue.WeekNumber)
+					assert.True(t, tt.expected.DueDate.Equal(nextDue.DueDate))
+					assert.True(t, tt.expected.DueAmount.Equal(nextDue.DueAmount))
+				}
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetScheduleWeek(t *testing.T) {
+	t.Run("returns the week as stored", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetScheduleWeek", mock.Anything, "LOAN279", 3).
+			Return(&domain.LoanSchedule{LoanID: "LOAN279", WeekNumber: 3, Status: domain.ScheduleStatusPaid}, nil).Once()
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		week, err := service.GetScheduleWeek(context.Background(), "LOAN279", 3)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, week.WeekNumber)
+		assert.Equal(t, domain.ScheduleStatusPaid, week.Status)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetScheduleWeek", mock.Anything, "LOAN279", 3).Return(nil, errors.New("connection refused")).Once()
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		week, err := service.GetScheduleWeek(context.Background(), "LOAN279", 3)
+
+		// Assert
+		assert.ErrorContains(t, err, "database operation failed")
+		assert.Nil(t, week)
+	})
+}
+
+func TestGetCurrentWeek(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		createdAt     time.Time
+		durationWeeks int
+		expectedWeek  int
+	}{
+		{name: "Created today", createdAt: now, durationWeeks: 50, expectedWeek: 1},
+		{name: "Created three weeks ago", createdAt: now.AddDate(0, 0, -21), durationWeeks: 50, expectedWeek: 4},
+		{name: "Past the last week", createdAt: now.AddDate(0, 0, -70), durationWeeks: 5, expectedWeek: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			loan := &domain.Loan{LoanID: "LOAN123", DurationWeeks: tt.durationWeeks, Status: domain.LoanStatusActive, CreatedAt: tt.createdAt}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+
+			// Act
+			result, err := service.GetCurrentWeek(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWeek, result.CurrentWeek)
+			assert.Equal(t, tt.durationWeeks, result.TotalWeeks)
+		})
+	}
+
+	t.Run("Loan not found", func(t *testing.T) {
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "NONEXISTENT").Return(nil, sql.ErrNoRows)
+
+		_, err := service.GetCurrentWeek(context.Background(), "NONEXISTENT")
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+	})
+}
+
+func TestGetOutstandingDeviation(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// Flat 10% over 4 weeks: 440,000 repayable at 110,000 a week, with weeks 1 and 2 already due
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(400000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 4,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+	}
+	schedules := []*domain.LoanSchedule{
+		{LoanID: "LOAN123", WeekNumber: 1, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, -7)},
+		{LoanID: "LOAN123", WeekNumber: 2, DueAmount: decimal.NewFromInt(110000), DueDate: today},
+		{LoanID: "LOAN123", WeekNumber: 3, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, 7)},
+		{LoanID: "LOAN123", WeekNumber: 4, DueAmount: decimal.NewFromInt(110000), DueDate: today.AddDate(0, 0, 14)},
+	}
+	paid := func(weeks ...int) []*domain.Payment {
+		payments := make([]*domain.Payment, 0, len(weeks))
+		for _, week := range weeks {
+			payments = append(payments, &domain.Payment{LoanID: "LOAN123", WeekNumber: week, Amount: decimal.NewFromInt(110000)})
+		}
+		return payments
+	}
+
+	tests := []struct {
+		name              string
+		payments          []*domain.Payment
+		expectedActual    int64
+		expectedDeviation int64
+	}{
+		{
+			name:              "Ahead of schedule",
+			payments:          paid(1, 2, 3),
+			expectedActual:    110000,
+			expectedDeviation: -110000,
+		},
+		{
+			name:              "On schedule",
+			payments:          paid(1, 2),
+			expectedActual:    220000,
+			expectedDeviation: 0,
+		},
+		{
+			name:              "Behind schedule",
+			payments:          paid(1),
+			expectedActual:    330000,
+			expectedDeviation: 110000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(schedules, nil)
+			mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(tt.payments, nil)
+
+			// Act
+			deviation, err := service.GetOutstandingDeviation(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, 2, deviation.CurrentWeek)
+			assert.True(t, deviation.ExpectedOutstanding.Equal(decimal.NewFromInt(220000)), "expected %s", deviation.ExpectedOutstanding)
+			assert.True(t, deviation.ActualOutstanding.Equal(decimal.NewFromInt(tt.expectedActual)), "actual %s", deviation.ActualOutstanding)
+			assert.True(t, deviation.Deviation.Equal(decimal.NewFromInt(tt.expectedDeviation)), "deviation %s", deviation.Deviation)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Loan not found", func(t *testing.T) {
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "NONEXISTENT").Return(nil, sql.ErrNoRows)
+
+		deviation, err := service.GetOutstandingDeviation(context.Background(), "NONEXISTENT")
+
+		assert.Nil(t, deviation)
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+	})
+}
+
+func TestGetInterestPaid(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// Flat 10% over 10 weeks: 1,000,000 principal + 100,000 interest, i.e. 100,000 + 10,000 a week
+	loan := func(loanID string) *domain.Loan {
+		return &domain.Loan{
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(1000000),
+			InterestRate:  decimal.NewFromFloat(0.10),
+			DurationWeeks: 10,
+			WeeklyPayment: decimal.NewFromInt(110000),
+			Status:        domain.LoanStatusActive,
+		}
+	}
+	schedule := func(loanID string, withBreakdown bool) []*domain.LoanSchedule {
+		schedules := make([]*domain.LoanSchedule, 0, 10)
+		for week := 1; week <= 10; week++ {
+			entry := &domain.LoanSchedule{
+				LoanID:     loanID,
+				WeekNumber: week,
+				DueAmount:  decimal.NewFromInt(110000),
+				DueDate:    today.AddDate(0, 0, 7*(week-1)),
+				Status:     domain.ScheduleStatusPending,
+			}
+			if withBreakdown {
+				entry.PrincipalAmount = decimal.NewFromInt(100000)
+				entry.InterestAmount = decimal.NewFromInt(10000)
+			}
+			schedules = append(schedules, entry)
+		}
+		return schedules
+	}
+	payments := func(loanID string, amounts ...int64) []*domain.Payment {
+		result := make([]*domain.Payment, 0, len(amounts))
+		for i, amount := range amounts {
+			result = append(result, &domain.Payment{LoanID: loanID, WeekNumber: i + 1, Amount: decimal.NewFromInt(amount)})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, *domain.InterestPaidSummary)
+	}{
+		{
+			name:   "Success - Partially paid flat-interest loan",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedule(loanID, true), nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments(loanID, 110000, 110000, 110000), nil)
+			},
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				assert.True(t, summary.InterestPaid.Equal(decimal.NewFromInt(30000)))
+				assert.True(t, summary.PrincipalPaid.Equal(decimal.NewFromInt(300000)))
+				assert.True(t, summary.TotalPaid.Equal(decimal.NewFromInt(330000)))
+			},
+		},
+		{
+			name:   "Success - Two partial payments on one week share its principal",
+			loanID: "LOAN127",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedule(loanID, true), nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return([]*domain.Payment{
+					{LoanID: loanID, WeekNumber: 1, Amount: decimal.NewFromInt(60000)},
+					{LoanID: loanID, WeekNumber: 1, Amount: decimal.NewFromInt(50000)},
+				}, nil)
+			},
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				assert.True(t, summary.InterestPaid.Equal(decimal.NewFromInt(10000)), "got %s", summary.InterestPaid)
+				assert.True(t, summary.PrincipalPaid.Equal(decimal.NewFromInt(100000)), "got %s", summary.PrincipalPaid)
+				assert.True(t, summary.TotalPaid.Equal(decimal.NewFromInt(110000)))
+			},
+		},
+		{
+			name:   "Success - No payments yet",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedule(loanID, true), nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return([]*domain.Payment{}, nil)
+			},
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				assert.True(t, summary.InterestPaid.IsZero())
+				assert.True(t, summary.PrincipalPaid.IsZero())
+				assert.True(t, summary.TotalPaid.IsZero())
+			},
+		},
+		{
+			name:   "Success - Rebated installment counts as less interest",
+			loanID: "LOAN125",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedule(loanID, true), nil)
+				// Week 2 was paid 4,000 short thanks to an on-time rebate
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments(loanID, 110000, 106000), nil)
+			},
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				assert.True(t, summary.InterestPaid.Equal(decimal.NewFromInt(16000)))
+				assert.True(t, summary.PrincipalPaid.Equal(decimal.NewFromInt(200000)))
+			},
+		},
+		{
+			name:   "Success - Schedule without a breakdown is split by loan totals",
+			loanID: "LOAN126",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan(loanID), nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(schedule(loanID, false), nil)
+				mockPaymentRepo.On("GetByLoanID", mock.Anything, loanID).Return(payments(loanID, 110000, 110000), nil)
+			},
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				// 1/11 of each payment is interest
+				assert.True(t, summary.InterestPaid.Equal(decimal.NewFromInt(20000)))
+				assert.True(t, summary.PrincipalPaid.Equal(decimal.NewFromInt(200000)))
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, summary *domain.InterestPaidSummary) {
+				assert.Nil(t, summary)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			summary, err := service.GetInterestPaid(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, summary)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTotalPaid(t *testing.T) {
+	t.Run("sums at full precision", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockPaymentRepo.On("GetTotalPaid", mock.Anything, "LOAN279").Return(decimal.RequireFromString("9999999999999.9999"), nil).Once()
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		totalPaid, err := service.GetTotalPaid(context.Background(), "LOAN279")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "9999999999999.9999", totalPaid.String())
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockPaymentRepo.On("GetTotalPaid", mock.Anything, "LOAN279").Return(decimal.Zero, errors.New("connection refused")).Once()
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		totalPaid, err := service.GetTotalPaid(context.Background(), "LOAN279")
+
+		// Assert
+		assert.ErrorContains(t, err, "database operation failed")
+		assert.True(t, totalPaid.IsZero())
+	})
+}
+
+func TestGetOutstandingBreakdown(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// Flat 10% over 10 weeks: 100,000 principal + 10,000 interest a week
+	loan := &domain.Loan{
+		LoanID:        "LOAN277",
+		Amount:        decimal.NewFromInt(1000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 10,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+	}
+	schedules := make([]*domain.LoanSchedule, 0, 10)
+	for week := 1; week <= 10; week++ {
+		schedules = append(schedules, &domain.LoanSchedule{
+			LoanID:          loan.LoanID,
+			WeekNumber:      week,
+			DueAmount:       decimal.NewFromInt(110000),
+			PrincipalAmount: decimal.NewFromInt(100000),
+			InterestAmount:  decimal.NewFromInt(10000),
+			DueDate:         today.AddDate(0, 0, 7*(week-1)),
+			Status:          domain.ScheduleStatusPending,
+		})
+	}
+	// Two full weeks and a partial third, which covers its principal first
+	payments := []*domain.Payment{
+		{LoanID: loan.LoanID, WeekNumber: 1, Amount: decimal.NewFromInt(110000)},
+		{LoanID: loan.LoanID, WeekNumber: 2, Amount: decimal.NewFromInt(110000)},
+		{LoanID: loan.LoanID, WeekNumber: 3, Amount: decimal.NewFromInt(60000)},
+	}
+
+	t.Run("partial payments split into principal and interest", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil)
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(payments, nil)
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		breakdown, err := service.GetOutstandingBreakdown(context.Background(), loan.LoanID)
+		require.NoError(t, err)
+		outstanding, err := service.GetOutstanding(context.Background(), loan.LoanID)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, breakdown.PrincipalOutstanding.Equal(decimal.NewFromInt(740000)), breakdown.PrincipalOutstanding.String())
+		assert.True(t, breakdown.InterestOutstanding.Equal(decimal.NewFromInt(80000)), breakdown.InterestOutstanding.String())
+		assert.True(t, breakdown.TotalOutstanding.Equal(breakdown.PrincipalOutstanding.Add(breakdown.InterestOutstanding)))
+		assert.True(t, breakdown.TotalOutstanding.Equal(outstanding), "breakdown %s, outstanding %s", breakdown.TotalOutstanding, outstanding)
+	})
+
+	t.Run("two partial payments on one week share its principal", func(t *testing.T) {
+		// Arrange
+		completed := append(append([]*domain.Payment{}, payments...), &domain.Payment{LoanID: loan.LoanID, WeekNumber: 3, Amount: decimal.NewFromInt(50000)})
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil)
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(completed, nil)
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		breakdown, err := service.GetOutstandingBreakdown(context.Background(), loan.LoanID)
+		require.NoError(t, err)
+
+		// Assert: week 3 is complete, so three weeks of principal and interest are paid
+		assert.True(t, breakdown.PrincipalOutstanding.Equal(decimal.NewFromInt(700000)), breakdown.PrincipalOutstanding.String())
+		assert.True(t, breakdown.InterestOutstanding.Equal(decimal.NewFromInt(70000)), breakdown.InterestOutstanding.String())
+	})
+
+	t.Run("rebate comes off the interest", func(t *testing.T) {
+		// Arrange
+		rebated := *loan
+		rebated.RebateAmount = decimal.NewFromInt(5000)
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(&rebated, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil)
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(payments, nil)
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		breakdown, err := service.GetOutstandingBreakdown(context.Background(), loan.LoanID)
+		require.NoError(t, err)
+		outstanding, err := service.GetOutstanding(context.Background(), loan.LoanID)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, breakdown.PrincipalOutstanding.Equal(decimal.NewFromInt(740000)))
+		assert.True(t, breakdown.InterestOutstanding.Equal(decimal.NewFromInt(75000)))
+		assert.True(t, breakdown.TotalOutstanding.Equal(outstanding), "breakdown %s, outstanding %s", breakdown.TotalOutstanding, outstanding)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "NONEXISTENT").Return(nil, sql.ErrNoRows)
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		// Act
+		breakdown, err := service.GetOutstandingBreakdown(context.Background(), "NONEXISTENT")
+
+		// Assert
+		assert.Nil(t, breakdown)
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+	})
+}
+
+func TestInterestRateBasis(t *testing.T) {
+	// 5,000,000 at 10% over 25 weeks
+	amount := decimal.NewFromInt(5000000)
+	interestRate := decimal.NewFromFloat(0.10)
+	durationWeeks := 25
+
+	tests := []struct {
+		name                  string
+		basis                 string
+		expectedWeeklyPayment decimal.Decimal
+		expectedOutstanding   decimal.Decimal
+	}{
+		{
+			name:                  "Per-term rate is charged once over the term",
+			basis:                 config.InterestRateBasisPerTerm,
+			expectedWeeklyPayment: decimal.NewFromInt(220000),  // 5,500,000 / 25
+			expectedOutstanding:   decimal.NewFromInt(5500000), // 5,000,000 + 500,000
+		},
+		{
+			name:                  "Default basis is per-term",
+			basis:                 "",
+			expectedWeeklyPayment: decimal.NewFromInt(220000),
+			expectedOutstanding:   decimal.NewFromInt(5500000),
+		},
+		{
+			name:                  "Annual rate is scaled by weeks/52",
+			basis:                 config.InterestRateBasisAnnual,
+			expectedWeeklyPayment: decimal.RequireFromString("209615.38"),  // 5,240,384.62 / 25
+			expectedOutstanding:   decimal.RequireFromString("5240384.62"), // 5,000,000 + 5,000,000 * 0.10 * 25/52
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{InterestRateBasis: tt.basis}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN-CREATE").Return(nil, sql.ErrNoRows)
+			mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			// Act
+			loan, schedules, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+				LoanID:        "LOAN-CREATE",
+				Amount:        amount,
+				InterestRate:  interestRate,
+				DurationWeeks: durationWeeks,
+			})
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, loan.WeeklyPayment.Equal(tt.expectedWeeklyPayment),
+				"Expected weekly payment %s, got %s", tt.expectedWeeklyPayment, loan.WeeklyPayment)
+			assert.Len(t, schedules, durationWeeks)
+
+			// Outstanding on the stored loan uses the basis it was created under
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN-OUTSTANDING").Return(&domain.Loan{
+				LoanID:            "LOAN-OUTSTANDING",
+				Amount:            amount,
+				InterestRate:      interestRate,
+				DurationWeeks:     durationWeeks,
+				InterestRateBasis: loan.InterestRateBasis,
+			}, nil)
+			mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN-OUTSTANDING").Return([]*domain.Payment{}, nil)
+
+			outstanding, err := service.GetOutstanding(context.Background(), "LOAN-OUTSTANDING")
+			require.NoError(t, err)
+			assert.True(t, outstanding.Equal(tt.expectedOutstanding),
+				"Expected outstanding %s, got %s", tt.expectedOutstanding, outstanding)
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInterestRateBasis_ChangingSettingKeepsExistingLoans(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	cfg := &config.Config{App: config.AppConfig{InterestRateBasis: config.InterestRateBasisPerTerm}}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows).Once()
+	mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	loan, _, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 25,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, config.InterestRateBasisPerTerm, loan.InterestRateBasis)
+
+	// The setting moves to annual after the loan was priced
+	cfg.App.InterestRateBasis = config.InterestRateBasisAnnual
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return([]*domain.Payment{}, nil)
+
+	outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+	require.NoError(t, err)
+	assert.True(t, outstanding.Equal(decimal.NewFromInt(5500000)), "expected 5500000, got %s", outstanding)
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
+
+func TestIsDelinquent_ConfiguredThreshold(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{DelinquentWeeksThreshold: 3}}
+
+	tests := []struct {
+		name               string
+		missedWeeks        int
+		expectedDelinquent bool
+	}{
+		{name: "Success - 2 missed weeks is below a threshold of 3", missedWeeks: 2, expectedDelinquent: false},
+		{name: "Success - 3 missed weeks reaches a threshold of 3", missedWeeks: 3, expectedDelinquent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loan := &domain.Loan{
+				LoanID:        "LOAN123",
+				Amount:        decimal.NewFromInt(5000000),
+				InterestRate:  decimal.NewFromFloat(0.10),
+				DurationWeeks: 50,
+				WeeklyPayment: decimal.NewFromInt(110000),
+				Status:        domain.LoanStatusActive,
+				CreatedAt:     time.Now().AddDate(0, 0, -7*(tt.missedWeeks+1)),
+			}
+
+			// The missed weeks are all past due; the next one is due today, which is not missed yet
+			var schedules []*domain.LoanSchedule
+			for week := 1; week <= tt.missedWeeks+1; week++ {
+				schedules = append(schedules, &domain.LoanSchedule{
+					LoanID:     "LOAN123",
+					WeekNumber: week,
+					DueDate:    time.Now().AddDate(0, 0, 7*(week-tt.missedWeeks-1)),
+					DueAmount:  decimal.NewFromInt(110000),
+					Status:     domain.ScheduleStatusPending,
+				})
+			}
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(schedules, nil)
+			if tt.expectedDelinquent {
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusDelinquent
+				})).Return(nil).Once()
+			}
+
+			// Act
+			isDelinquent, err := service.IsDelinquent(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDelinquent, isDelinquent)
+			mockLoanRepo.AssertExpectations(t)
+			if !tt.expectedDelinquent {
+				mockLoanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestIsDelinquent_Forbearance(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	tests := []struct {
+		name               string
+		forbearanceStart   time.Time
+		forbearanceEnd     time.Time
+		expectedDelinquent bool
+	}{
+		{
+			name:               "Missed weeks inside the window are suppressed",
+			forbearanceStart:   today.AddDate(0, 0, -22),
+			forbearanceEnd:     today.AddDate(0, 0, -6),
+			expectedDelinquent: false,
+		},
+		{
+			name:               "Delinquency resumes for weeks due after the window ends",
+			forbearanceStart:   today.AddDate(0, 0, -22),
+			forbearanceEnd:     today.AddDate(0, 0, -20),
+			expectedDelinquent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			start, end := tt.forbearanceStart, tt.forbearanceEnd
+			loan := &domain.Loan{
+				LoanID:           "LOAN-FORBEAR",
+				Status:           domain.LoanStatusActive,
+				ForbearanceStart: &start,
+				ForbearanceEnd:   &end,
+			}
+			// Weeks 1-3 missed (due 21, 14 and 7 days ago), week 4 in the future
+			schedules := []*domain.LoanSchedule{
+				{LoanID: loan.LoanID, WeekNumber: 1, DueDate: today.AddDate(0, 0, -21), Status: domain.ScheduleStatusPending},
+				{LoanID: loan.LoanID, WeekNumber: 2, DueDate: today.AddDate(0, 0, -14), Status: domain.ScheduleStatusPending},
+				{LoanID: loan.LoanID, WeekNumber: 3, DueDate: today.AddDate(0, 0, -7), Status: domain.ScheduleStatusPending},
+				{LoanID: loan.LoanID, WeekNumber: 4, DueDate: today.AddDate(0, 0, 7), Status: domain.ScheduleStatusPending},
+			}
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil)
+			if tt.expectedDelinquent {
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusDelinquent
+				})).Return(nil).Once()
+			}
+
+			// Act
+			isDelinquent, err := service.IsDelinquent(context.Background(), loan.LoanID)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDelinquent, isDelinquent)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIsDelinquent_ScheduleGaps(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	week := func(number, daysFromToday int, status string) *domain.LoanSchedule {
+		return &domain.LoanSchedule{LoanID: "LOAN-GAP", WeekNumber: number, DueDate: today.AddDate(0, 0, daysFromToday), Status: status}
+	}
+
+	tests := []struct {
+		name               string
+		schedules          []*domain.LoanSchedule
+		expectedDelinquent bool
+	}{
+		{
+			name: "Missing week breaks the missed streak",
+			// Week 4 is missing, so weeks 3 and 5 aren't known to be consecutive misses
+			schedules: []*domain.LoanSchedule{
+				week(1, -28, domain.ScheduleStatusPaid),
+				week(2, -21, domain.ScheduleStatusPaid),
+				week(3, -14, domain.ScheduleStatusPending),
+				week(5, -7, domain.ScheduleStatusPending),
+				week(6, 7, domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: false,
+		},
+		{
+			name: "Consecutive misses after a gap still count",
+			schedules: []*domain.LoanSchedule{
+				week(1, -28, domain.ScheduleStatusPaid),
+				week(3, -14, domain.ScheduleStatusPending),
+				week(4, -7, domain.ScheduleStatusPending),
+				week(5, 7, domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+		},
+		{
+			name: "Out-of-order rows are walked by week number",
+			schedules: []*domain.LoanSchedule{
+				week(4, 7, domain.ScheduleStatusPending),
+				week(2, -14, domain.ScheduleStatusPending),
+				week(1, -21, domain.ScheduleStatusPaid),
+				week(3, -7, domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+		},
+		{
+			name: "Due date out of step with week number doesn't stop the walk",
+			// Week 2 has a bad future due date; the overdue weeks after it are still checked
+			schedules: []*domain.LoanSchedule{
+				week(1, -28, domain.ScheduleStatusPaid),
+				week(2, 70, domain.ScheduleStatusPending),
+				week(3, -14, domain.ScheduleStatusPending),
+				week(4, -7, domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+		},
+		{
+			name: "Gap among weeks not yet due leaves the current run alone",
+			// Week 4 is missing but week 5 isn't due, so the run of weeks 2-3 still stands
+			schedules: []*domain.LoanSchedule{
+				week(1, -21, domain.ScheduleStatusPaid),
+				week(2, -14, domain.ScheduleStatusPending),
+				week(3, -7, domain.ScheduleStatusPending),
+				week(5, 7, domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			loan := &domain.Loan{LoanID: "LOAN-GAP", Status: domain.LoanStatusActive}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(tt.schedules, nil)
+			if tt.expectedDelinquent {
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusDelinquent
+				})).Return(nil).Once()
+			}
+
+			// Act
+			isDelinquent, err := service.IsDelinquent(context.Background(), loan.LoanID)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDelinquent, isDelinquent)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDelinquencyStatus(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	week := func(number int, dueDate time.Time, status string) *domain.LoanSchedule {
+		return &domain.LoanSchedule{LoanID: "LOAN123", WeekNumber: number, DueDate: dueDate, DueAmount: decimal.NewFromInt(110000), Status: status}
+	}
+
+	tests := []struct {
+		name               string
+		schedules          []*domain.LoanSchedule
+		expectedDelinquent bool
+		expectedMissed     int
+	}{
+		{
+			name: "Three missed weeks are counted",
+			schedules: []*domain.LoanSchedule{
+				week(1, today.AddDate(0, 0, -21), domain.ScheduleStatusPending),
+				week(2, today.AddDate(0, 0, -14), domain.ScheduleStatusPending),
+				week(3, today.AddDate(0, 0, -7), domain.ScheduleStatusPending),
+				week(4, today.AddDate(0, 0, 7), domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+			expectedMissed:     3,
+		},
+		{
+			name: "Weeks the scheduler marked overdue count as missed",
+			schedules: []*domain.LoanSchedule{
+				week(1, today.AddDate(0, 0, -21), domain.ScheduleStatusPaid),
+				week(2, today.AddDate(0, 0, -14), domain.ScheduleStatusOverdue),
+				week(3, today.AddDate(0, 0, -7), domain.ScheduleStatusOverdue),
+				week(4, today.AddDate(0, 0, 7), domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: true,
+			expectedMissed:     2,
+		},
+		{
+			name: "One missed week is not delinquent",
+			schedules: []*domain.LoanSchedule{
+				week(1, today.AddDate(0, 0, -14), domain.ScheduleStatusPaid),
+				week(2, today.AddDate(0, 0, -7), domain.ScheduleStatusPending),
+				week(3, today.AddDate(0, 0, 7), domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: false,
+			expectedMissed:     1,
+		},
+		{
+			name: "Up to date",
+			schedules: []*domain.LoanSchedule{
+				week(1, today.AddDate(0, 0, -7), domain.ScheduleStatusPaid),
+				week(2, today.AddDate(0, 0, 7), domain.ScheduleStatusPending),
+			},
+			expectedDelinquent: false,
+			expectedMissed:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			loan := &domain.Loan{LoanID: "LOAN123", Status: domain.LoanStatusActive, CreatedAt: today.AddDate(0, 0, -28)}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(tt.schedules, nil)
+			if tt.expectedDelinquent {
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusDelinquent
+				})).Return(nil).Once()
+			}
+
+			// Act
+			status, err := service.GetDelinquencyStatus(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "LOAN123", status.LoanID)
+			assert.Equal(t, tt.expectedDelinquent, status.IsDelinquent)
+			assert.Equal(t, tt.expectedMissed, status.MissedWeeks)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDelinquencyStatus_LoanStatusProgression(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	cfg := &config.Config{App: config.AppConfig{DefaultWeeksThreshold: 4}}
+
+	// A loan created five weeks ago whose last `missed` due weeks are unpaid
+	schedule := func(missed int) []*domain.LoanSchedule {
+		schedules := make([]*domain.LoanSchedule, 0, 6)
+		for week := 1; week <= 6; week++ {
+			status := domain.ScheduleStatusPaid
+			if week > 5-missed {
+				status = domain.ScheduleStatusPending
+			}
+			schedules = append(schedules, &domain.LoanSchedule{
+				LoanID:     "LOAN-PROGRESS",
+				WeekNumber: week,
+				DueDate:    today.AddDate(0, 0, 7*(week-5)-1),
+				DueAmount:  decimal.NewFromInt(110000),
+				Status:     status,
+			})
+		}
+		return schedules
+	}
+
+	tests := []struct {
+		name           string
+		status         string
+		missed         int
+		expectedStatus string
+	}{
+		{name: "Active loan with one missed week stays active", status: domain.LoanStatusActive, missed: 1, expectedStatus: domain.LoanStatusActive},
+		{name: "Active loan becomes delinquent at two missed weeks", status: domain.LoanStatusActive, missed: 2, expectedStatus: domain.LoanStatusDelinquent},
+		{name: "Delinquent loan stays delinquent at three missed weeks", status: domain.LoanStatusDelinquent, missed: 3, expectedStatus: domain.LoanStatusDelinquent},
+		{name: "Delinquent loan defaults at the hard threshold", status: domain.LoanStatusDelinquent, missed: 4, expectedStatus: domain.LoanStatusDefault},
+		{name: "Active loan skips straight to default", status: domain.LoanStatusActive, missed: 5, expectedStatus: domain.LoanStatusDefault},
+		{name: "Delinquent loan that catches up is active again", status: domain.LoanStatusDelinquent, missed: 0, expectedStatus: domain.LoanStatusActive},
+		{name: "Default is final even once caught up", status: domain.LoanStatusDefault, missed: 0, expectedStatus: domain.LoanStatusDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loan := &domain.Loan{LoanID: "LOAN-PROGRESS", Status: tt.status, CreatedAt: today.AddDate(0, 0, -35)}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedule(tt.missed), nil)
+			if tt.expectedStatus != tt.status {
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == tt.expectedStatus
+				})).Return(nil).Once()
+			}
+
+			// Act
+			status, err := service.GetDelinquencyStatus(context.Background(), loan.LoanID)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.missed, status.MissedWeeks)
+			assert.Equal(t, tt.expectedStatus, loan.Status)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Forecast never changes the status", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+		loan := &domain.Loan{LoanID: "LOAN-PROGRESS", Status: domain.LoanStatusActive, CreatedAt: today.AddDate(0, 0, -35)}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedule(1), nil)
+
+		// Act
+		status, err := service.GetDelinquencyStatusAsOf(context.Background(), loan.LoanID, today.AddDate(0, 0, 7))
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, status.IsDelinquent)
+		assert.Equal(t, 2, status.MissedWeeks)
+		assert.Equal(t, domain.LoanStatusActive, loan.Status)
+		mockLoanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failing to persist the status still reports delinquency", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+		loan := &domain.Loan{LoanID: "LOAN-PROGRESS", Status: domain.LoanStatusActive, CreatedAt: today.AddDate(0, 0, -35)}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedule(2), nil)
+		mockLoanRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Once()
+
+		// Act
+		isDelinquent, err := service.IsDelinquent(context.Background(), loan.LoanID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, isDelinquent)
+		mockLoanRepo.AssertExpectations(t)
+	})
+}
+
+func TestIsDelinquentAsOf(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+	// Created three weeks ago; weeks 1-2 paid, week 3 missed yesterday, weeks 4-5 still to come
+	schedules := []*domain.LoanSchedule{
+		{LoanID: "LOAN-ASOF", WeekNumber: 1, DueDate: today.AddDate(0, 0, -15), Status: domain.ScheduleStatusPaid},
+		{LoanID: "LOAN-ASOF", WeekNumber: 2, DueDate: today.AddDate(0, 0, -8), Status: domain.ScheduleStatusPaid},
+		{LoanID: "LOAN-ASOF", WeekNumber: 3, DueDate: today.AddDate(0, 0, -1), Status: domain.ScheduleStatusPending},
+		{LoanID: "LOAN-ASOF", WeekNumber: 4, DueDate: today.AddDate(0, 0, 6), Status: domain.ScheduleStatusPending},
+		{LoanID: "LOAN-ASOF", WeekNumber: 5, DueDate: today.AddDate(0, 0, 13), Status: domain.ScheduleStatusPending},
+	}
+
+	tests := []struct {
+		name               string
+		asOf               time.Time
+		expectedDelinquent bool
+		expectedError      error
+	}{
+		{
+			name:               "Today sees one missed week",
+			asOf:               time.Now(),
+			expectedDelinquent: false,
+		},
+		{
+			name:               "Next week forecasts a second missed week",
+			asOf:               today.AddDate(0, 0, 7),
+			expectedDelinquent: true,
+		},
+		{
+			name:               "Before the second week falls due",
+			asOf:               today.AddDate(0, 0, 5),
+			expectedDelinquent: false,
+		},
+		{
+			name:          "Before loan creation is rejected",
+			asOf:          today.AddDate(0, 0, -30),
+			expectedError: customError.ErrInvalidAsOfDate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			loan := &domain.Loan{LoanID: "LOAN-ASOF", Status: domain.LoanStatusActive, CreatedAt: today.AddDate(0, 0, -22)}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil).Maybe()
+
+			// Act
+			isDelinquent, err := service.IsDelinquentAsOf(context.Background(), loan.LoanID, tt.asOf)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.False(t, isDelinquent)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDelinquent, isDelinquent)
+		})
+	}
+}
+
+func TestGetDelinquencyStatusAsOf_GraceBoundary(t *testing.T) {
+	// Two unpaid weeks with two days' grace: week 2 reaches its boundary day on Jan 17
+	schedules := []*domain.LoanSchedule{
+		{LoanID: "LOAN280", WeekNumber: 1, DueDate: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Status: domain.ScheduleStatusPending},
+		{LoanID: "LOAN280", WeekNumber: 2, DueDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Status: domain.ScheduleStatusPending},
+	}
+	boundary := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		boundaryPolicy      string
+		asOf                time.Time
+		expectedMissedWeeks int
+	}{
+		{name: "Exclusive - The boundary second", boundaryPolicy: config.OverdueBoundaryExclusive, asOf: boundary, expectedMissedWeeks: 1},
+		{name: "Exclusive - The last second of the boundary day", boundaryPolicy: config.OverdueBoundaryExclusive, asOf: boundary.Add(24*time.Hour - time.Second), expectedMissedWeeks: 1},
+		{name: "Exclusive - The day after the boundary", boundaryPolicy: config.OverdueBoundaryExclusive, asOf: boundary.AddDate(0, 0, 1), expectedMissedWeeks: 2},
+		{name: "Inclusive - The second before the boundary", boundaryPolicy: config.OverdueBoundaryInclusive, asOf: boundary.Add(-time.Second), expectedMissedWeeks: 1},
+		{name: "Inclusive - The boundary second", boundaryPolicy: config.OverdueBoundaryInclusive, asOf: boundary, expectedMissedWeeks: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			cfg := &config.Config{App: config.AppConfig{OverdueGraceDays: 2, OverdueBoundary: tt.boundaryPolicy}}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			loan := &domain.Loan{LoanID: "LOAN280", Status: domain.LoanStatusActive, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(schedules, nil)
+
+			// Act
+			status, err := service.GetDelinquencyStatusAsOf(context.Background(), loan.LoanID, tt.asOf)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMissedWeeks, status.MissedWeeks)
+			assert.Equal(t, tt.expectedMissedWeeks >= 2, status.IsDelinquent)
+		})
+	}
+}
+
+func TestSetForbearance(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, *domain.Loan)
+	}{
+		{
+			name:   "Success - Window stored on active loan",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID, Status: domain.LoanStatusActive}, nil)
+				mockLoanRepo.On("SetForbearance", mock.Anything, loanID, start, end).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, loan *domain.Loan) {
+				require.NotNil(t, loan.ForbearanceStart)
+				require.NotNil(t, loan.ForbearanceEnd)
+				assert.True(t, loan.InForbearance(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)))
+				assert.False(t, loan.InForbearance(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)))
+			},
+		},
+		{
+			name:   "Failure - Closed loan",
+			loanID: "LOAN124",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID, Status: domain.LoanStatusClosed}, nil)
+			},
+			expectedError: true,
+			errorContains: "already closed",
+			validateResult: func(t *testing.T, loan *domain.Loan) {
+				assert.Nil(t, loan)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, loan *domain.Loan) {
+				assert.Nil(t, loan)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, tt.loanID)
+
+			// Act
+			loan, err := service.SetForbearance(context.Background(), tt.loanID, start, end)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, loan)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoanExists(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		setupMocks     func(*mocks.MockLoanRepository, string)
+		expectedError  bool
+		errorContains  string
+		expectedExists bool
+	}{
+		{
+			name:   "Success - Loan exists",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("Exists", mock.Anything, loanID).Return(true, nil)
+			},
+			expectedExists: true,
+		},
+		{
+			name:   "Success - Loan does not exist",
+			loanID: "NONEXISTENT",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("Exists", mock.Anything, loanID).Return(false, nil)
+			},
+			expectedExists: false,
+		},
+		{
+			name:   "Failure - Database error",
+			loanID: "LOAN123",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("Exists", mock.Anything, loanID).Return(false, errors.New("database connection failed"))
+			},
+			expectedError: true,
+			errorContains: "database operation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, tt.loanID)
+
+			// Act
+			exists, err := service.LoanExists(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedExists, exists)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateNotes(t *testing.T) {
+	tests := []struct {
+		name           string
+		loanID         string
+		patch          string
+		setupMocks     func(*mocks.MockLoanRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, json.RawMessage)
+	}{
+		{
+			name:   "Success - Notes merged into existing",
+			loanID: "LOAN123",
+			patch:  `{"follow_up":"2025-03-10","tags":["hardship"]}`,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				loan := &domain.Loan{LoanID: loanID, Notes: json.RawMessage(`{"contact":"called borrower"}`)}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("UpdateNotes", mock.Anything, loanID, mock.Anything).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, notes json.RawMessage) {
+				assert.JSONEq(t, `{"contact":"called borrower","follow_up":"2025-03-10","tags":["hardship"]}`, string(notes))
+			},
+		},
+		{
+			name:   "Success - Null removes a key",
+			loanID: "LOAN124",
+			patch:  `{"contact":null}`,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				loan := &domain.Loan{LoanID: loanID, Notes: json.RawMessage(`{"contact":"called borrower","priority":"high"}`)}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(loan, nil)
+				mockLoanRepo.On("UpdateNotes", mock.Anything, loanID, mock.Anything).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, notes json.RawMessage) {
+				assert.JSONEq(t, `{"priority":"high"}`, string(notes))
+			},
+		},
+		{
+			name:          "Failure - Patch is not an object",
+			loanID:        "LOAN125",
+			patch:         `["not","an","object"]`,
+			setupMocks:    func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {},
+			expectedError: true,
+			errorContains: "must be a JSON object",
+			validateResult: func(t *testing.T, notes json.RawMessage) {
+				assert.Nil(t, notes)
+			},
+		},
+		{
+			name:   "Failure - Merged notes too large",
+			loanID: "LOAN126",
+			patch:  `{"big":"` + strings.Repeat("x", domain.MaxLoanNotesBytes) + `"}`,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(&domain.Loan{LoanID: loanID}, nil)
+			},
+			expectedError: true,
+			errorContains: "notes exceed",
+			validateResult: func(t *testing.T, notes json.RawMessage) {
+				assert.Nil(t, notes)
+			},
+		},
+		{
+			name:   "Failure - Loan not found",
+			loanID: "NONEXISTENT",
+			patch:  `{"contact":"called borrower"}`,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, loanID string) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loanID).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: true,
+			errorContains: "not found",
+			validateResult: func(t *testing.T, notes json.RawMessage) {
+				assert.Nil(t, notes)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo, tt.loanID)
+
+			// Act
+			notes, err := service.UpdateNotes(context.Background(), tt.loanID, json.RawMessage(tt.patch))
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, notes)
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUndoLatestPayment(t *testing.T) {
+	weeklyPayment := decimal.NewFromInt(110000)
+	latest := &domain.Payment{
+		ID:         uuid.New(),
+		LoanID:     "LOAN123",
+		Amount:     weeklyPayment,
+		WeekNumber: 2,
+	}
+	paidSchedule := func(loanID string) []*domain.LoanSchedule {
+		return []*domain.LoanSchedule{
+			{LoanID: loanID, WeekNumber: 1, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, 7), Status: domain.ScheduleStatusPaid},
+			{LoanID: loanID, WeekNumber: 2, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, 14), Status: domain.ScheduleStatusPaid},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		loanID         string
+		loanStatus     string
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, string)
+		expectedError  bool
+		errorContains  string
+		validateResult func(*testing.T, *domain.Payment)
+	}{
+		{
+			name:       "Success - Payment removed and week back to pending",
+			loanID:     "LOAN123",
+			loanStatus: domain.LoanStatusActive,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(latest, nil)
+				mockPaymentRepo.On("Delete", mock.Anything, latest.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(paidSchedule(loanID), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPending).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				require.NotNil(t, payment)
+				assert.Equal(t, latest.ID, payment.ID)
+				assert.Equal(t, 2, payment.WeekNumber)
+			},
+		},
+		{
+			name:       "Success - Loan closed by the final payment is reopened",
+			loanID:     "LOAN123",
+			loanStatus: domain.LoanStatusClosed,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(latest, nil)
+				mockPaymentRepo.On("Delete", mock.Anything, latest.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(paidSchedule(loanID), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPending).Return(nil)
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusActive && loan.RebateAmount.IsZero() && loan.CreditBalance.IsZero()
+				})).Return(nil)
+			},
+			expectedError: false,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				require.NotNil(t, payment)
+			},
+		},
+		{
+			name:       "Failure - No payments to undo",
+			loanID:     "LOAN123",
+			loanStatus: domain.LoanStatusActive,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(nil, customError.WrapLatestPaymentNotFound(loanID))
+			},
+			expectedError: true,
+			errorContains: "no payments to undo",
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+		{
+			name:       "Failure - Delete fails",
+			loanID:     "LOAN123",
+			loanStatus: domain.LoanStatusActive,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(latest, nil)
+				mockPaymentRepo.On("Delete", mock.Anything, latest.ID).Return(errors.New("database error"))
+			},
+			expectedError: true,
+			errorContains: "database",
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+		{
+			name:       "Failure - Reopening the loan fails after the week is reset",
+			loanID:     "LOAN123",
+			loanStatus: domain.LoanStatusClosed,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loanID string) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loanID).Return(latest, nil)
+				mockPaymentRepo.On("Delete", mock.Anything, latest.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loanID).Return(paidSchedule(loanID), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loanID, []int{2}, domain.ScheduleStatusPending).Return(nil)
+				mockLoanRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("connection reset"))
+			},
+			expectedError: true,
+			errorContains: "database",
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, tt.loanID).Return(&domain.Loan{
+				LoanID:        tt.loanID,
+				WeeklyPayment: weeklyPayment,
+				Status:        tt.loanStatus,
+				RebateAmount:  decimal.NewFromInt(5000),
+				CreditBalance: decimal.NewFromInt(2000),
+			}, nil)
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loanID)
+
+			// Act
+			payment, err := service.UndoLatestPayment(context.Background(), tt.loanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				if tt.errorContains != "" {
+					assert.Contains(t, err.Error(), tt.errorContains)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			tt.validateResult(t, payment)
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUndoLatestPayment_PaymentCoveringTwoWeeks(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	weeklyPayment := decimal.NewFromInt(110000)
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		DurationWeeks: 50,
+		WeeklyPayment: weeklyPayment,
+		Status:        domain.LoanStatusActive,
+	}
+	paidAt := time.Now().Add(-time.Hour)
+	earlier := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: weeklyPayment, WeekNumber: 1, CreatedAt: paidAt.AddDate(0, 0, -14)}
+	overdueWeek := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: weeklyPayment, WeekNumber: 2, CreatedAt: paidAt}
+	currentWeek := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: weeklyPayment, WeekNumber: 3, CreatedAt: paidAt}
+
+	// One request paid the overdue week 2 and the week 3 not yet due
+	mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+	mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(currentWeek, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return([]*domain.Payment{currentWeek, overdueWeek, earlier}, nil)
+	mockPaymentRepo.On("Delete", mock.Anything, currentWeek.ID).Return(nil).Once()
+	mockPaymentRepo.On("Delete", mock.Anything, overdueWeek.ID).Return(nil).Once()
+	mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return([]*domain.LoanSchedule{
+		{LoanID: loan.LoanID, WeekNumber: 1, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, -14), Status: domain.ScheduleStatusPaid},
+		{LoanID: loan.LoanID, WeekNumber: 2, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, -7), Status: domain.ScheduleStatusPaid},
+		{LoanID: loan.LoanID, WeekNumber: 3, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, 7), Status: domain.ScheduleStatusPaid},
+	}, nil)
+	mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loan.LoanID, []int{3}, domain.ScheduleStatusPending).Return(nil).Once()
+	mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loan.LoanID, []int{2}, domain.ScheduleStatusOverdue).Return(nil).Once()
+
+	payment, err := service.UndoLatestPayment(context.Background(), loan.LoanID)
+	require.NoError(t, err)
+
+	assert.Equal(t, currentWeek.ID, payment.ID)
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertNotCalled(t, "Delete", mock.Anything, earlier.ID)
+}
+
+func TestReversePayment(t *testing.T) {
+	weeklyPayment := decimal.NewFromInt(110000)
+	paymentFor := func(loanID string, week int) *domain.Payment {
+		return &domain.Payment{ID: uuid.New(), LoanID: loanID, Amount: weeklyPayment, WeekNumber: week}
+	}
+	scheduleWith := func(weeks int, status func(week int) string) []*domain.LoanSchedule {
+		schedule := make([]*domain.LoanSchedule, weeks)
+		for i := range schedule {
+			schedule[i] = &domain.LoanSchedule{LoanID: "LOAN123", WeekNumber: i + 1, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, 7*(i+1)), Status: status(i + 1)}
+		}
+		return schedule
+	}
+	allPaid := func(int) string { return domain.ScheduleStatusPaid }
+
+	tests := []struct {
+		name          string
+		loanStatus    string
+		payment       *domain.Payment
+		setupMocks    func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, *domain.Payment)
+		expectedError error
+		errorContains string
+	}{
+		{
+			name:       "Success - Reversing the final payment reopens the closed loan",
+			loanStatus: domain.LoanStatusClosed,
+			payment:    paymentFor("LOAN123", 50),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+				mockPaymentRepo.On("Delete", mock.Anything, payment.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(scheduleWith(50, allPaid), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{50}, domain.ScheduleStatusPending).Return(nil)
+				mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+					return loan.Status == domain.LoanStatusActive && loan.RebateAmount.IsZero() && loan.CreditBalance.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "Success - Week marked paid before the lowercase status still reopens",
+			loanStatus: domain.LoanStatusActive,
+			payment:    paymentFor("LOAN123", 3),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+				mockPaymentRepo.On("Delete", mock.Anything, payment.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(scheduleWith(50, func(week int) string {
+					if week <= 5 {
+						return "PAID"
+					}
+					return domain.ScheduleStatusPending
+				}), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{3}, domain.ScheduleStatusPending).Return(nil)
+			},
+		},
+		{
+			name:       "Success - Reversing a mid-term payment reopens only its week",
+			loanStatus: domain.LoanStatusActive,
+			payment:    paymentFor("LOAN123", 3),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+				mockPaymentRepo.On("Delete", mock.Anything, payment.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(scheduleWith(50, func(week int) string {
+					if week <= 5 {
+						return domain.ScheduleStatusPaid
+					}
+					return domain.ScheduleStatusPending
+				}), nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{3}, domain.ScheduleStatusPending).Return(nil)
+			},
+		},
+		{
+			name:       "Success - Partial payment on an unpaid week leaves the week as is",
+			loanStatus: domain.LoanStatusActive,
+			payment:    paymentFor("LOAN123", 6),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+				mockPaymentRepo.On("Delete", mock.Anything, payment.ID).Return(nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(scheduleWith(50, func(week int) string {
+					if week <= 5 {
+						return domain.ScheduleStatusPaid
+					}
+					return domain.ScheduleStatusOverdue
+				}), nil)
+			},
+		},
+		{
+			name:       "Failure - Payment belongs to another loan",
+			loanStatus: domain.LoanStatusActive,
+			payment:    paymentFor("LOAN999", 3),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+			},
+			expectedError: customError.ErrPaymentNotFound,
+		},
+		{
+			name:       "Failure - Defaulted loan stays frozen",
+			loanStatus: domain.LoanStatusDefault,
+			payment:    paymentFor("LOAN123", 3),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+			},
+			expectedError: customError.ErrLoanDefaulted,
+		},
+		{
+			name:       "Failure - Delete fails",
+			loanStatus: domain.LoanStatusActive,
+			payment:    paymentFor("LOAN123", 3),
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, payment *domain.Payment) {
+				mockPaymentRepo.On("Delete", mock.Anything, payment.ID).Return(errors.New("connection reset"))
+			},
+			errorContains: "database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(&domain.Loan{
+				LoanID:        "LOAN123",
+				WeeklyPayment: weeklyPayment,
+				Status:        tt.loanStatus,
+				RebateAmount:  decimal.NewFromInt(5000),
+				CreditBalance: decimal.NewFromInt(2000),
+			}, nil)
+			if tt.loanStatus != domain.LoanStatusDefault {
+				mockPaymentRepo.On("GetByID", mock.Anything, tt.payment.ID).Return(tt.payment, nil)
+			}
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.payment)
+
+			// Act
+			err := service.ReversePayment(context.Background(), "LOAN123", tt.payment.ID)
+
+			// Assert
+			switch {
+			case tt.expectedError != nil:
+				assert.ErrorIs(t, err, tt.expectedError)
+			case tt.errorContains != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			default:
+				assert.NoError(t, err)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+			if tt.expectedError != nil || tt.errorContains != "" {
+				mockLoanRepo.AssertNotCalled(t, "UpdateScheduleStatuses", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestReversePayment_MidTermWeekPaidAgainClosesLoan(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	weeklyPayment := decimal.NewFromInt(110000)
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		DurationWeeks: 50,
+		WeeklyPayment: weeklyPayment,
+		Status:        domain.LoanStatusClosed,
+	}
+	scheduleWith := func(unpaidWeek int) []*domain.LoanSchedule {
+		schedule := make([]*domain.LoanSchedule, loan.DurationWeeks)
+		for i := range schedule {
+			schedule[i] = &domain.LoanSchedule{LoanID: loan.LoanID, WeekNumber: i + 1, DueAmount: weeklyPayment, DueDate: time.Now().AddDate(0, 0, 7*(i+1)), Status: domain.ScheduleStatusPaid}
+		}
+		if unpaidWeek > 0 {
+			schedule[unpaidWeek-1].Status = domain.ScheduleStatusPending
+		}
+		return schedule
+	}
+	reversed := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: weeklyPayment, WeekNumber: 3}
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+	mockPaymentRepo.On("GetByID", mock.Anything, reversed.ID).Return(reversed, nil)
+	mockPaymentRepo.On("Delete", mock.Anything, reversed.ID).Return(nil)
+	mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(scheduleWith(0), nil).Once()
+	mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loan.LoanID, []int{3}, domain.ScheduleStatusPending).Return(nil).Once()
+	mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *domain.Loan) bool {
+		return updated.Status == domain.LoanStatusActive
+	})).Return(nil).Once()
+
+	require.NoError(t, service.ReversePayment(context.Background(), loan.LoanID, reversed.ID))
+
+	// Week 3 is the only unpaid week left, so paying it closes the loan even though week 50 is later
+	reopened := scheduleWith(3)
+	mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, loan.LoanID).Return(reopened[2], nil)
+	mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, loan.LoanID, 3).Return(decimal.Zero, nil)
+	mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return(reopened, nil)
+	mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(payment *domain.Payment) bool {
+		return payment.WeekNumber == 3 && payment.Amount.Equal(weeklyPayment)
+	})).Return(nil).Once()
+	mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loan.LoanID, []int{3}, domain.ScheduleStatusPaid).Return(nil).Once()
+	mockLoanRepo.On("Update", mock.Anything, mock.MatchedBy(func(updated *domain.Loan) bool {
+		return updated.Status == domain.LoanStatusClosed
+	})).Return(nil).Once()
+
+	payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: loan.LoanID, Amount: weeklyPayment})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, payment.WeekNumber)
+	assert.Equal(t, domain.LoanStatusClosed, loan.Status)
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
+
+func TestUndoLatestPayment_RevertsOutstanding(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+	}
+	first := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: loan.WeeklyPayment, WeekNumber: 1}
+	second := &domain.Payment{ID: uuid.New(), LoanID: loan.LoanID, Amount: loan.WeeklyPayment, WeekNumber: 2}
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return([]*domain.Payment{second, first}, nil).Once()
+	mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(second, nil)
+	mockPaymentRepo.On("Delete", mock.Anything, second.ID).Return(nil)
+	mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, loan.LoanID).Return([]*domain.LoanSchedule{
+		{LoanID: loan.LoanID, WeekNumber: 1, DueAmount: loan.WeeklyPayment, DueDate: time.Now().AddDate(0, 0, 7), Status: domain.ScheduleStatusPaid},
+		{LoanID: loan.LoanID, WeekNumber: 2, DueAmount: loan.WeeklyPayment, DueDate: time.Now().AddDate(0, 0, 14), Status: domain.ScheduleStatusPaid},
+	}, nil)
+	mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, loan.LoanID, []int{2}, domain.ScheduleStatusPending).Return(nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return([]*domain.Payment{first}, nil).Once()
+
+	before, err := service.GetOutstanding(context.Background(), loan.LoanID)
+	require.NoError(t, err)
+
+	_, err = service.UndoLatestPayment(context.Background(), loan.LoanID)
+	require.NoError(t, err)
+
+	after, err := service.GetOutstanding(context.Background(), loan.LoanID)
+	require.NoError(t, err)
+
+	assert.True(t, after.Equal(before.Add(loan.WeeklyPayment)), "expected %s, got %s", before.Add(loan.WeeklyPayment), after)
+	mockLoanRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertExpectations(t)
+}
+
+func TestCreateLoansBatch(t *testing.T) {
+	newRequest := func(loanID string) *domain.CreateLoanRequest {
+		return &domain.CreateLoanRequest{
+			LoanID:        loanID,
+			Amount:        decimal.NewFromInt(5000000),
+			InterestRate:  decimal.NewFromFloat(0.10),
+			DurationWeeks: 50,
+		}
+	}
+
+	t.Run("Mixed batch - existing loan IDs fail without stopping the rest", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		cfg := &config.Config{App: config.AppConfig{BatchConcurrency: 4}}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "NEW-1").Return(nil, sql.ErrNoRows)
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "EXISTING").Return(&domain.Loan{LoanID: "EXISTING"}, nil)
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "NEW-2").Return(nil, sql.ErrNoRows)
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "BROKEN").Return(nil, sql.ErrNoRows)
+		mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+			return loan.LoanID == "NEW-1" || loan.LoanID == "NEW-2"
+		}), mock.Anything).Return(nil).Twice()
+		mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+			return loan.LoanID == "BROKEN"
+		}), mock.Anything).Return(errors.New("connection reset")).Once()
+
+		// Act
+		results := service.CreateLoansBatch(context.Background(), []*domain.CreateLoanRequest{
+			newRequest("NEW-1"), newRequest("EXISTING"), newRequest("NEW-2"), newRequest("BROKEN"),
+		})
+
+		// Assert
+		require.Len(t, results, 4)
+		for i, result := range results {
+			assert.Equal(t, i, result.Index)
+		}
+
+		assert.Equal(t, "NEW-1", results[0].LoanID)
+		require.NotNil(t, results[0].Loan)
+		assert.True(t, results[0].Loan.WeeklyPayment.Equal(decimal.NewFromInt(110000)))
+		assert.Empty(t, results[0].Error)
+
+		assert.Equal(t, "EXISTING", results[1].LoanID)
+		assert.Nil(t, results[1].Loan)
+		assert.Equal(t, customError.ErrCodeLoanAlreadyExists, results[1].Code)
+		assert.Contains(t, results[1].Error, "already exists")
+
+		require.NotNil(t, results[2].Loan)
+		assert.Equal(t, "NEW-2", results[2].Loan.LoanID)
+
+		assert.Nil(t, results[3].Loan)
+		assert.Equal(t, customError.ErrCodeDatabaseError, results[3].Code)
+
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cancelled context - nothing is attempted", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Act
+		results := service.CreateLoansBatch(ctx, []*domain.CreateLoanRequest{newRequest("NEW-1"), newRequest("NEW-2")})
+
+		// Assert
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.Nil(t, result.Loan)
+			assert.Contains(t, result.Error, "context canceled")
+		}
+		mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateLoan_ScheduleHorizon(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{MaxScheduleHorizonWeeks: 52}}
+
+	tests := []struct {
+		name          string
+		durationWeeks int
+		expectCreate  bool
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name:          "Success - Last due date well inside the horizon",
+			durationWeeks: 50,
+			expectCreate:  true,
+		},
+		{
+			name:          "Success - Last due date exactly on the horizon",
+			durationWeeks: 53,
+			expectCreate:  true,
+		},
+		{
+			name:          "Failure - Last due date beyond the horizon",
+			durationWeeks: 54,
+			expectedError: true,
+			errorContains: "more than 52 weeks away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+			if tt.expectCreate {
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			// Act
+			loan, schedule, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+				LoanID:        "LOAN123",
+				Amount:        decimal.NewFromInt(5000000),
+				InterestRate:  decimal.NewFromFloat(0.10),
+				DurationWeeks: tt.durationWeeks,
+			})
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, customError.ErrScheduleTooLong)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Nil(t, loan)
+				assert.Nil(t, schedule)
+				mockLoanRepo.AssertNotCalled(t, "CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, schedule, tt.durationWeeks)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateLoan_Limits(t *testing.T) {
+	limits := &config.Config{App: config.AppConfig{MaxLoanAmount: 10000000, MaxDurationWeeks: 104, MaxInterestRate: 0.5}}
+
+	tests := []struct {
+		name          string
+		cfg           *config.Config
+		amount        decimal.Decimal
+		durationWeeks int
+		interestRate  decimal.Decimal
+		expectCreate  bool
+		errorContains string
+	}{
+		{
+			name:          "Success - Terms at every maximum",
+			cfg:           limits,
+			amount:        decimal.NewFromInt(10000000),
+			durationWeeks: 104,
+			interestRate:  decimal.NewFromFloat(0.5),
+			expectCreate:  true,
+		},
+		{
+			name:          "Failure - Amount above the maximum",
+			cfg:           limits,
+			amount:        decimal.NewFromInt(10000001),
+			durationWeeks: 50,
+			interestRate:  decimal.NewFromFloat(0.10),
+			errorContains: "amount 10000001 exceeds the maximum of 10000000",
+		},
+		{
+			name:          "Failure - Duration above the maximum",
+			cfg:           limits,
+			amount:        decimal.NewFromInt(5000000),
+			durationWeeks: 105,
+			interestRate:  decimal.NewFromFloat(0.10),
+			errorContains: "duration_weeks 105 exceeds the maximum of 104",
+		},
+		{
+			name:          "Failure - Interest rate above the configured maximum",
+			cfg:           limits,
+			amount:        decimal.NewFromInt(5000000),
+			durationWeeks: 50,
+			interestRate:  decimal.NewFromFloat(0.6),
+			errorContains: "interest_rate 0.6 exceeds the maximum of 0.5",
+		},
+		{
+			name:          "Success - Amount and duration unbounded when not configured",
+			amount:        decimal.NewFromInt(1000000000),
+			durationWeeks: 300,
+			interestRate:  decimal.NewFromFloat(0.10),
+			expectCreate:  true,
+		},
+		{
+			name:          "Success - 100% interest is at the default ceiling",
+			amount:        decimal.NewFromInt(5000000),
+			durationWeeks: 50,
+			interestRate:  decimal.NewFromInt(1),
+			expectCreate:  true,
+		},
+		{
+			name:          "Failure - Percentage sent as a whole number hits the default ceiling",
+			amount:        decimal.NewFromInt(5000000),
+			durationWeeks: 50,
+			interestRate:  decimal.NewFromInt(10),
+			errorContains: "interest_rate 10 exceeds the maximum of 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, tt.cfg)
+
+			if tt.expectCreate {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			// Act
+			loan, _, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+				LoanID:        "LOAN123",
+				Amount:        tt.amount,
+				InterestRate:  tt.interestRate,
+				DurationWeeks: tt.durationWeeks,
+			})
+
+			// Assert
+			if tt.expectCreate {
+				assert.NoError(t, err)
+				assert.NotNil(t, loan)
+			} else {
+				assert.ErrorIs(t, err, customError.ErrLoanLimitExceeded)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Nil(t, loan)
+				mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateLoan_RatePrecision(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           *config.Config
+		interestRate  decimal.Decimal
+		expectedRate  string
+		errorContains string
+	}{
+		{
+			name:         "Success - Over-precise rate rounded to the default 4 places",
+			interestRate: decimal.RequireFromString("0.1000001"),
+			expectedRate: "0.1",
+		},
+		{
+			name:         "Success - Over-precise rate rounded to a configured precision",
+			cfg:          &config.Config{App: config.AppConfig{RatePrecision: 2}},
+			interestRate: decimal.RequireFromString("0.1251"),
+			expectedRate: "0.13",
+		},
+		{
+			name:         "Success - Rate within the precision is kept under the reject policy",
+			cfg:          &config.Config{App: config.AppConfig{RatePrecision: 4, RatePrecisionPolicy: config.RatePrecisionPolicyReject}},
+			interestRate: decimal.RequireFromString("0.1025"),
+			expectedRate: "0.1025",
+		},
+		{
+			name:          "Failure - Over-precise rate rejected under the reject policy",
+			cfg:           &config.Config{App: config.AppConfig{RatePrecision: 4, RatePrecisionPolicy: config.RatePrecisionPolicyReject}},
+			interestRate:  decimal.RequireFromString("0.1000001"),
+			errorContains: "0.1000001 has more than 4 decimal places",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, tt.cfg)
+
+			if tt.errorContains == "" {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+				mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			// Act
+			loan, _, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+				LoanID:        "LOAN123",
+				Amount:        decimal.NewFromInt(5000000),
+				InterestRate:  tt.interestRate,
+				DurationWeeks: 50,
+			})
+
+			// Assert
+			if tt.errorContains != "" {
+				assert.ErrorIs(t, err, customError.ErrInvalidInterestRate)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Nil(t, loan)
+				mockLoanRepo.AssertNotCalled(t, "CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRate, loan.InterestRate.String())
+		})
+	}
+}
+
+func TestCreateLoan_NormalizesTags(t *testing.T) {
+	// Arrange
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+	mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return assert.ObjectsAreEqual(pq.StringArray{"branch-a", "micro"}, loan.Tags)
+	}), mock.Anything).Return(nil)
+
+	// Act
+	loan, _, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+		Tags:          []string{" Branch-A ", "micro", "branch-a", "  "},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, pq.StringArray{"branch-a", "micro"}, loan.Tags)
+	mockLoanRepo.AssertExpectations(t)
+}
+
+func TestRecomputeWeeklyPayment(t *testing.T) {
+	existingPayment := &domain.Payment{ID: uuid.New(), LoanID: "LOAN123", WeekNumber: 1}
+
+	tests := []struct {
+		name           string
+		loan           *domain.Loan
+		cfg            *config.Config
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository, *domain.Loan)
+		expectedError  bool
+		errorContains  string
+		expectedWeekly decimal.Decimal
+	}{
+		{
+			name: "Success - Stale weekly payment replaced",
+			loan: &domain.Loan{
+				LoanID:        "LOAN123",
+				Amount:        decimal.NewFromInt(5000000),
+				InterestRate:  decimal.NewFromFloat(0.10),
+				DurationWeeks: 50,
+				WeeklyPayment: decimal.NewFromInt(100000),
+				Status:        domain.LoanStatusActive,
+			},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loan *domain.Loan) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(nil, customError.WrapLatestPaymentNotFound(loan.LoanID))
+				mockLoanRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedWeekly: utils.CalculateWeeklyPayment(decimal.NewFromInt(5000000), decimal.NewFromFloat(0.10), 50, money.Scale),
+		},
+		{
+			name: "Success - Annual rate basis applied",
+			loan: &domain.Loan{
+				LoanID:            "LOAN124",
+				Amount:            decimal.NewFromInt(5200000),
+				InterestRate:      decimal.NewFromFloat(0.10),
+				DurationWeeks:     26,
+				WeeklyPayment:     decimal.NewFromInt(220000),
+				InterestRateBasis: config.InterestRateBasisAnnual,
+				Status:            domain.LoanStatusActive,
+			},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loan *domain.Loan) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(nil, customError.WrapLatestPaymentNotFound(loan.LoanID))
+				mockLoanRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedWeekly: utils.CalculateWeeklyPayment(decimal.NewFromInt(5200000), utils.TermInterestRate(decimal.NewFromFloat(0.10), 26), 26, money.Scale),
+		},
+		{
+			name: "Success - Regular installment kept when the final week absorbs rounding",
+			loan: &domain.Loan{
+				LoanID:        "LOAN125",
+				Amount:        decimal.NewFromInt(1000000),
+				InterestRate:  decimal.NewFromFloat(0.10),
+				DurationWeeks: 7,
+				WeeklyPayment: decimal.NewFromInt(150000),
+				Status:        domain.LoanStatusActive,
+			},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loan *domain.Loan) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(nil, customError.WrapLatestPaymentNotFound(loan.LoanID))
+				mockLoanRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			// Weeks 1-6 pay 157,142.86; week 7 pays the 157,142.84 remainder
+			expectedWeekly: decimal.RequireFromString("157142.86"),
+		},
+		{
+			name: "Failure - Loan already has payments",
+			loan: &domain.Loan{LoanID: "LOAN123", Status: domain.LoanStatusActive},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loan *domain.Loan) {
+				mockPaymentRepo.On("GetLatestPayment", mock.Anything, loan.LoanID).Return(existingPayment, nil)
+			},
+			expectedError: true,
+			errorContains: "already has payments",
+		},
+		{
+			name: "Failure - Closed loan",
+			loan: &domain.Loan{LoanID: "LOAN123", Status: domain.LoanStatusClosed},
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository, loan *domain.Loan) {
+			},
+			expectedError: true,
+			errorContains: "already closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, tt.cfg)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, tt.loan.LoanID).Return(tt.loan, nil)
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo, tt.loan)
+
+			// Act
+			loan, err := service.RecomputeWeeklyPayment(context.Background(), tt.loan.LoanID)
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Nil(t, loan)
+				mockLoanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				require.NotNil(t, loan)
+				assert.True(t, tt.expectedWeekly.Equal(loan.WeeklyPayment), "expected %s, got %s", tt.expectedWeekly, loan.WeeklyPayment)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateLoan_TrimsBorrowerID(t *testing.T) {
+	// Arrange
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+	mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.BorrowerID == "BRW-42"
+	}), mock.Anything).Return(nil)
+
+	// Act
+	loan, _, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+		LoanID:        "LOAN123",
+		BorrowerID:    "  BRW-42 ",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "BRW-42", loan.BorrowerID)
+	mockLoanRepo.AssertExpectations(t)
+}
+
+func TestGetBorrowerLoans(t *testing.T) {
+	t.Run("Success - Borrower's loans with their statuses", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		loans := []*domain.Loan{
+			{LoanID: "LOAN-001", BorrowerID: "BRW-42", Status: domain.LoanStatusClosed},
+			{LoanID: "LOAN-002", BorrowerID: "BRW-42", Status: domain.LoanStatusDelinquent},
+		}
+		mockLoanRepo.On("GetByBorrowerID", mock.Anything, "BRW-42").Return(loans, nil)
+
+		// Act
+		result, err := service.GetBorrowerLoans(context.Background(), "BRW-42")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, loans, result)
+		mockLoanRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error - Database error", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByBorrowerID", mock.Anything, "BRW-42").Return(nil, errors.New("connection refused"))
+
+		// Act
+		result, err := service.GetBorrowerLoans(context.Background(), "BRW-42")
+
+		// Assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database")
+		assert.Nil(t, result)
+	})
+}
+
+func TestCreateLoan_InterestOnlyPeriod(t *testing.T) {
+	// Arrange
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows).Once()
+	mockLoanRepo.On("CreateWithSchedule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	loan, schedule, err := service.CreateLoan(context.Background(), &domain.CreateLoanRequest{
+		LoanID:            "LOAN123",
+		Amount:            decimal.NewFromInt(5000000),
+		InterestRate:      decimal.NewFromFloat(0.10),
+		DurationWeeks:     50,
+		InterestOnlyWeeks: 10,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, schedule, 50)
+	assert.Equal(t, 10, loan.InterestOnlyWeeks)
+	assert.True(t, loan.WeeklyPayment.Equal(decimal.NewFromInt(135000)))
+
+	totalRepayable := decimal.Zero
+	for _, week := range schedule {
+		if week.WeekNumber <= 10 {
+			assert.True(t, week.PrincipalAmount.IsZero(), "week %d should be interest only", week.WeekNumber)
+			assert.True(t, week.DueAmount.Equal(decimal.NewFromInt(10000)), "week %d due %s", week.WeekNumber, week.DueAmount)
+		} else {
+			assert.True(t, week.PrincipalAmount.Equal(decimal.NewFromInt(125000)), "week %d principal %s", week.WeekNumber, week.PrincipalAmount)
+			assert.True(t, week.DueAmount.Equal(decimal.NewFromInt(135000)), "week %d due %s", week.WeekNumber, week.DueAmount)
+		}
+		assert.True(t, week.InterestAmount.Equal(decimal.NewFromInt(10000)))
+		totalRepayable = totalRepayable.Add(week.DueAmount)
+	}
+	assert.True(t, totalRepayable.Equal(decimal.NewFromInt(5500000)), "total repayable %s", totalRepayable)
+
+	// Outstanding before any payment matches the schedule total
+	mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+	mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return([]*domain.Payment{}, nil)
+
+	outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+	require.NoError(t, err)
+	assert.True(t, outstanding.Equal(totalRepayable), "outstanding %s", outstanding)
+}
+
+func TestMakePayment_InterestOnlyWeek(t *testing.T) {
+	loan := &domain.Loan{
+		LoanID:            "LOAN123",
+		DurationWeeks:     50,
+		WeeklyPayment:     decimal.NewFromInt(135000),
+		InterestOnlyWeeks: 10,
+		Status:            domain.LoanStatusActive,
+	}
+	schedules := []*domain.LoanSchedule{
+		{LoanID: "LOAN123", WeekNumber: 1, DueAmount: decimal.NewFromInt(10000), InterestAmount: decimal.NewFromInt(10000), Status: domain.ScheduleStatusPending},
+		{LoanID: "LOAN123", WeekNumber: 2, DueAmount: decimal.NewFromInt(10000), InterestAmount: decimal.NewFromInt(10000), Status: domain.ScheduleStatusPending},
+	}
+
+	tests := []struct {
+		name         string
+		amount       decimal.Decimal
+		expectedWeek int
+		setupMocks   func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository)
+	}{
+		{
+			name:         "Success - Interest-only amount accepted",
+			amount:       decimal.NewFromInt(10000),
+			expectedWeek: 1,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+					return p.WeekNumber == 1 && p.Amount.Equal(decimal.NewFromInt(10000))
+				})).Return(nil)
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{1}, domain.ScheduleStatusPaid).Return(nil)
+			},
+		},
+		{
+			name:         "Success - Amount above the interest-only due rolls into the next week",
+			amount:       decimal.NewFromInt(15000),
+			expectedWeek: 2,
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository, mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+					return p.WeekNumber == 1 && p.Amount.Equal(decimal.NewFromInt(10000))
+				})).Return(nil).Once()
+				mockPaymentRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.Payment) bool {
+					return p.WeekNumber == 2 && p.Amount.Equal(decimal.NewFromInt(5000))
+				})).Return(nil).Once()
+				mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{1}, domain.ScheduleStatusPaid).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+			mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN123").Return(schedules[0], nil)
+			mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, "LOAN123", 1).Return(decimal.Zero, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(schedules, nil)
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo)
+
+			// Act
+			payment, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: "LOAN123", Amount: tt.amount})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWeek, payment.WeekNumber)
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPayment(t *testing.T) {
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*mocks.MockPaymentRepository)
+		expectedError  error
+		validateResult func(*testing.T, *domain.Payment)
+	}{
+		{
+			name: "Success - Payment found",
+			setupMocks: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetByID", mock.Anything, paymentID).Return(&domain.Payment{ID: paymentID, LoanID: "LOAN123", WeekNumber: 1}, nil)
+			},
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				require.NotNil(t, payment)
+				assert.Equal(t, paymentID, payment.ID)
+			},
+		},
+		{
+			name: "Failure - Payment not found",
+			setupMocks: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetByID", mock.Anything, paymentID).Return(nil, customError.WrapPaymentNotFound(paymentID.String()))
+			},
+			expectedError: customError.ErrPaymentNotFound,
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+		{
+			name: "Failure - Database error is not reported as not found",
+			setupMocks: func(mockPaymentRepo *mocks.MockPaymentRepository) {
+				mockPaymentRepo.On("GetByID", mock.Anything, paymentID).Return(nil, errors.New("connection refused"))
+			},
+			expectedError: errors.New("connection refused"),
+			validateResult: func(t *testing.T, payment *domain.Payment) {
+				assert.Nil(t, payment)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockPaymentRepo)
+
+			// Act
+			payment, err := service.GetPayment(context.Background(), paymentID)
+
+			// Assert
+			switch {
+			case tt.expectedError == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.expectedError, customError.ErrPaymentNotFound):
+				assert.ErrorIs(t, err, customError.ErrPaymentNotFound)
+			default:
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, customError.ErrPaymentNotFound)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			}
+
+			tt.validateResult(t, payment)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGenerateLoanID(t *testing.T) {
+	loanIDPattern := `^LOAN-[0-9A-F]{8}-[0-9A-F]{4}-7[0-9A-F]{3}-[0-9A-F]{4}-[0-9A-F]{12}$`
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*mocks.MockLoanRepository)
+		expectedCalls int
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name: "Success - First candidate is unique",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(false, nil).Once()
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "Success - Collision retried with a new candidate",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil).Twice()
+				mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(false, nil).Once()
+			},
+			expectedCalls: 3,
+		},
+		{
+			name: "Failure - Every candidate collides",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(true, nil)
+			},
+			expectedCalls: 5,
+			expectedError: true,
+			errorContains: "No unique loan ID found after 5 attempts",
+		},
+		{
+			name: "Failure - Database error",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(false, errors.New("database error")).Once()
+			},
+			expectedCalls: 1,
+			expectedError: true,
+			errorContains: "database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			tt.setupMocks(mockLoanRepo)
+
+			// Act
+			loanID, err := service.GenerateLoanID(context.Background())
+
+			// Assert
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Empty(t, loanID)
+			} else {
+				assert.NoError(t, err)
+				assert.Regexp(t, loanIDPattern, loanID)
+				assert.LessOrEqual(t, len(loanID), 50, "must fit the loan_id column")
+			}
+
+			mockLoanRepo.AssertNumberOfCalls(t, "Exists", tt.expectedCalls)
+		})
+	}
+}
+
+func TestGenerateLoanID_CandidatesDiffer(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+	var candidates []string
+	mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { candidates = append(candidates, args.String(1)) }).
+		Return(true, nil).Once()
+	mockLoanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { candidates = append(candidates, args.String(1)) }).
+		Return(false, nil).Once()
+
+	loanID, err := service.GenerateLoanID(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.NotEqual(t, candidates[0], candidates[1], "a colliding ID must not be retried")
+	assert.Equal(t, candidates[1], loanID)
+}
+
+func TestGenerateLoanID_Sequence(t *testing.T) {
+	mockLoanRepo := &mocks.MockLoanRepository{}
+	mockPaymentRepo := &mocks.MockPaymentRepository{}
+	cfg := &config.Config{App: config.AppConfig{LoanIDScheme: config.LoanIDSchemeSequence}}
+	service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, cfg)
+
+	// A client already created LOAN-00000042 by hand, so the next sequence value is used
+	mockLoanRepo.On("NextLoanIDSequence", mock.Anything).Return(int64(42), nil).Once()
+	mockLoanRepo.On("NextLoanIDSequence", mock.Anything).Return(int64(43), nil).Once()
+	mockLoanRepo.On("Exists", mock.Anything, "LOAN-00000042").Return(true, nil).Once()
+	mockLoanRepo.On("Exists", mock.Anything, "LOAN-00000043").Return(false, nil).Once()
+
+	loanID, err := service.GenerateLoanID(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "LOAN-00000043", loanID)
+	mockLoanRepo.AssertExpectations(t)
+}
+
+// sequenceLoanRepository hands out loan ID sequence values the way Postgres nextval does
+type sequenceLoanRepository struct {
+	*mocks.MockLoanRepository
+	next atomic.Int64
+}
+
+func (r *sequenceLoanRepository) NextLoanIDSequence(ctx context.Context) (int64, error) {
+	return r.next.Add(1), nil
+}
+
+func TestGenerateLoanID_ConcurrentCallersGetDistinctIDs(t *testing.T) {
+	const callers = 200
+
+	for _, scheme := range []string{config.LoanIDSchemeUUID, config.LoanIDSchemeSequence} {
+		t.Run(scheme, func(t *testing.T) {
+			// Arrange
+			loanRepo := &sequenceLoanRepository{MockLoanRepository: &mocks.MockLoanRepository{}}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			loanRepo.On("Exists", mock.Anything, mock.AnythingOfType("string")).Return(false, nil)
+			cfg := &config.Config{App: config.AppConfig{LoanIDScheme: scheme}}
+			service := billingService.NewBillingService(loanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(loanRepo.MockLoanRepository, mockPaymentRepo), nil, cfg)
+
+			// Act
+			ids := make([]string, callers)
+			errs := make([]error, callers)
+			var wg sync.WaitGroup
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					ids[i], errs[i] = service.GenerateLoanID(context.Background())
+				}(i)
+			}
+			wg.Wait()
+
+			// Assert
+			seen := make(map[string]bool, callers)
+			for i, id := range ids {
+				require.NoError(t, errs[i])
+				assert.False(t, seen[id], "duplicate loan ID %s", id)
+				seen[id] = true
+			}
+			assert.Len(t, seen, callers)
+		})
+	}
+}
+
+func TestGetPaymentsByWeekRange(t *testing.T) {
+	loan := &domain.Loan{LoanID: "LOAN-001", DurationWeeks: 50, Status: domain.LoanStatusActive}
+
+	tests := []struct {
+		name           string
+		fromWeek       int
+		toWeek         int
+		setupMocks     func(*mocks.MockLoanRepository, *mocks.MockPaymentRepository)
+		expectedToWeek int
+		expectedError  error
+	}{
+		{
+			name:     "explicit range is passed through",
+			fromWeek: 3,
+			toWeek:   6,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository, paymentRepo *mocks.MockPaymentRepository) {
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN-001").Return(loan, nil)
+				paymentRepo.On("GetByWeekRange", mock.Anything, "LOAN-001", 3, 6).Return([]*domain.Payment{}, nil)
+			},
+			expectedToWeek: 6,
+		},
+		{
+			name:     "open range runs to the last week",
+			fromWeek: 10,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository, paymentRepo *mocks.MockPaymentRepository) {
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN-001").Return(loan, nil)
+				paymentRepo.On("GetByWeekRange", mock.Anything, "LOAN-001", 10, 50).Return([]*domain.Payment{}, nil)
+			},
+			expectedToWeek: 50,
+		},
+		{
+			name:     "loan not found",
+			fromWeek: 1,
+			setupMocks: func(loanRepo *mocks.MockLoanRepository, paymentRepo *mocks.MockPaymentRepository) {
+				loanRepo.On("GetByLoanID", mock.Anything, "LOAN-001").Return(nil, sql.ErrNoRows)
+			},
+			expectedError: customError.ErrLoanNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			tt.setupMocks(mockLoanRepo, mockPaymentRepo)
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			// Act
+			result, err := service.GetPaymentsByWeekRange(context.Background(), "LOAN-001", tt.fromWeek, tt.toWeek)
+
+			// Assert
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.fromWeek, result.FromWeek)
+				assert.Equal(t, tt.expectedToWeek, result.ToWeek)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+			mockPaymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLoan(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMocks    func(*mocks.MockLoanRepository)
+		expectedError error
+		errorContains string
+	}{
+		{
+			name: "Success - Loan returned",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(&domain.Loan{
+					LoanID:        "LOAN123",
+					Amount:        decimal.NewFromInt(5000000),
+					InterestRate:  decimal.NewFromFloat(0.10),
+					DurationWeeks: 50,
+					WeeklyPayment: decimal.NewFromInt(110000),
+					Status:        domain.LoanStatusActive,
+				}, nil)
+			},
+		},
+		{
+			name: "Error - Loan not found",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+			},
+			expectedError: customError.ErrLoanNotFound,
+		},
+		{
+			name: "Error - Database failure",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, errors.New("connection refused"))
+			},
+			errorContains: "database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+			tt.setupMocks(mockLoanRepo)
+
+			// Act
+			loan, err := service.GetLoan(context.Background(), "LOAN123")
+
+			// Assert
+			switch {
+			case tt.expectedError != nil:
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, loan)
+			case tt.errorContains != "":
+				require.Error(t, err)
+				assert.Contains(t, strings.ToLower(err.Error()), tt.errorContains)
+				assert.Nil(t, loan)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "LOAN123", loan.LoanID)
+				assert.True(t, loan.WeeklyPayment.Equal(decimal.NewFromInt(110000)))
+				assert.Equal(t, domain.LoanStatusActive, loan.Status)
+			}
+
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckScheduleIntegrity(t *testing.T) {
+	tests := []struct {
+		name               string
+		scheduleWeeks      int
+		expectedConsistent bool
+	}{
+		{name: "One row per week", scheduleWeeks: 50, expectedConsistent: true},
+		{name: "Missing weeks", scheduleWeeks: 48, expectedConsistent: false},
+		{name: "No schedule at all", scheduleWeeks: 0, expectedConsistent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(&domain.Loan{LoanID: "LOAN123", DurationWeeks: 50}, nil)
+			mockLoanRepo.On("CountSchedule", mock.Anything, "LOAN123").Return(tt.scheduleWeeks, nil)
+
+			// Act
+			result, err := service.CheckScheduleIntegrity(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, 50, result.DurationWeeks)
+			assert.Equal(t, tt.scheduleWeeks, result.ScheduleWeeks)
+			assert.Equal(t, tt.expectedConsistent, result.Consistent)
+			mockLoanRepo.AssertNotCalled(t, "GetScheduleByLoanID", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Error - Loan not found", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN404").Return(nil, sql.ErrNoRows)
+
+		// Act
+		result, err := service.CheckScheduleIntegrity(context.Background(), "LOAN404")
+
+		// Assert
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetPaymentStreak(t *testing.T) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	// Six weeks: weeks 1-4 fell due over the past four weeks, weeks 5-6 are still to come
+	dueDate := func(week int) time.Time {
+		return today.AddDate(0, 0, 7*(week-4)-1)
+	}
+	schedule := func(paidWeeks ...int) []*domain.LoanSchedule {
+		paid := make(map[int]bool, len(paidWeeks))
+		for _, week := range paidWeeks {
+			paid[week] = true
+		}
+		schedules := make([]*domain.LoanSchedule, 0, 6)
+		for week := 1; week <= 6; week++ {
+			status := domain.ScheduleStatusPending
+			if paid[week] {
+				status = domain.ScheduleStatusPaid
+			}
+			schedules = append(schedules, &domain.LoanSchedule{
+				LoanID:     "LOAN123",
+				WeekNumber: week,
+				DueAmount:  decimal.NewFromInt(110000),
+				DueDate:    dueDate(week),
+				Status:     status,
+			})
+		}
+		return schedules
+	}
+	payment := func(week int, paidAt time.Time) *domain.Payment {
+		return &domain.Payment{LoanID: "LOAN123", WeekNumber: week, Amount: decimal.NewFromInt(110000), PaymentDate: paidAt}
+	}
+	onDueDate := func(week int) *domain.Payment {
+		return payment(week, dueDate(week).Add(15*time.Hour))
+	}
+
+	tests := []struct {
+		name            string
+		schedules       []*domain.LoanSchedule
+		payments        []*domain.Payment
+		expectedCurrent int
+		expectedLongest int
+	}{
+		{
+			name:            "Perfect streak - Every due week paid on its due date",
+			schedules:       schedule(1, 2, 3, 4),
+			payments:        []*domain.Payment{onDueDate(1), onDueDate(2), onDueDate(3), onDueDate(4)},
+			expectedCurrent: 4,
+			expectedLongest: 4,
+		},
+		{
+			name:            "Perfect streak - Paying ahead counts",
+			schedules:       schedule(1, 2, 3, 4, 5),
+			payments:        []*domain.Payment{onDueDate(1), onDueDate(2), onDueDate(3), onDueDate(4), payment(5, today)},
+			expectedCurrent: 5,
+			expectedLongest: 5,
+		},
+		{
+			name:            "Broken streak - Late week resets the current streak",
+			schedules:       schedule(1, 2, 3, 4),
+			payments:        []*domain.Payment{onDueDate(1), onDueDate(2), payment(3, dueDate(3).AddDate(0, 0, 2)), onDueDate(4)},
+			expectedCurrent: 1,
+			expectedLongest: 2,
+		},
+		{
+			name:            "Broken streak - Missed week that is still unpaid",
+			schedules:       schedule(1, 2, 3),
+			payments:        []*domain.Payment{onDueDate(1), onDueDate(2), onDueDate(3)},
+			expectedCurrent: 0,
+			expectedLongest: 3,
+		},
+		{
+			name:      "Broken streak - Partial payment completed after the due date",
+			schedules: schedule(1, 2, 3, 4),
+			payments: []*domain.Payment{
+				onDueDate(1),
+				onDueDate(2),
+				onDueDate(3),
+				payment(3, dueDate(3).AddDate(0, 0, 3)),
+				onDueDate(4),
+			},
+			expectedCurrent: 1,
+			expectedLongest: 2,
+		},
+		{
+			name:            "No payments yet",
+			schedules:       schedule(),
+			payments:        []*domain.Payment{},
+			expectedCurrent: 0,
+			expectedLongest: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+			mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(&domain.Loan{LoanID: "LOAN123", DurationWeeks: 6}, nil)
+			mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(tt.schedules, nil)
+			mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(tt.payments, nil)
+
+			// Act
+			result, err := service.GetPaymentStreak(context.Background(), "LOAN123")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "LOAN123", result.LoanID)
+			assert.Equal(t, tt.expectedCurrent, result.CurrentStreak)
+			assert.Equal(t, tt.expectedLongest, result.LongestStreak)
+		})
+	}
+
+	t.Run("Error - Loan not found", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN404").Return(nil, sql.ErrNoRows)
+
+		// Act
+		result, err := service.GetPaymentStreak(context.Background(), "LOAN404")
+
+		// Assert
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetPaymentsByDateRange(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("payments in the window are returned with the bounds", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		payments := []*domain.Payment{{LoanID: "LOAN-001", WeekNumber: 2, Amount: decimal.NewFromInt(110000)}}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN-001").Return(&domain.Loan{LoanID: "LOAN-001"}, nil)
+		mockPaymentRepo.On("GetByLoanIDBetween", mock.Anything, "LOAN-001", &from, &to).Return(payments, nil)
+
+		// Act
+		result, err := service.GetPaymentsByDateRange(context.Background(), "LOAN-001", &from, &to)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, payments, result.Payments)
+		assert.Equal(t, &from, result.From)
+		assert.Equal(t, &to, result.To)
+		mockLoanRepo.AssertExpectations(t)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("loan not found", func(t *testing.T) {
+		// Arrange
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN-001").Return(nil, sql.ErrNoRows)
+
+		// Act
+		result, err := service.GetPaymentsByDateRange(context.Background(), "LOAN-001", &from, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, customError.ErrLoanNotFound)
+		assert.Nil(t, result)
+		mockPaymentRepo.AssertNotCalled(t, "GetByLoanIDBetween", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMutationsBlockedOnDefaultedAndWrittenOffLoans(t *testing.T) {
+	operations := []struct {
+		name string
+		call func(billingService.BillingService, string) error
+	}{
+		{
+			name: "MakePayment",
+			call: func(service billingService.BillingService, loanID string) error {
+				_, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: loanID, Amount: decimal.NewFromInt(110000)})
+				return err
+			},
+		},
+		{
+			name: "SetForbearance",
+			call: func(service billingService.BillingService, loanID string) error {
+				start := time.Now()
+				_, err := service.SetForbearance(context.Background(), loanID, start, start.AddDate(0, 0, 14))
+				return err
+			},
+		},
+		{
+			name: "RecomputeWeeklyPayment",
+			call: func(service billingService.BillingService, loanID string) error {
+				_, err := service.RecomputeWeeklyPayment(context.Background(), loanID)
+				return err
+			},
+		},
+		{
+			name: "UndoLatestPayment",
+			call: func(service billingService.BillingService, loanID string) error {
+				_, err := service.UndoLatestPayment(context.Background(), loanID)
+				return err
+			},
+		},
+	}
+
+	statuses := []struct {
+		status        string
+		expectedError error
+	}{
+		{status: domain.LoanStatusDefault, expectedError: customError.ErrLoanDefaulted},
+		{status: domain.LoanStatusWrittenOff, expectedError: customError.ErrLoanWrittenOff},
+	}
+
+	for _, operation := range operations {
+		for _, tt := range statuses {
+			t.Run(operation.name+" on "+tt.status+" loan", func(t *testing.T) {
+				// Arrange
+				mockLoanRepo := &mocks.MockLoanRepository{}
+				mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+				service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+
+				loan := &domain.Loan{LoanID: "LOAN-FROZEN", Status: tt.status, DurationWeeks: 50}
+				mockLoanRepo.On("GetByLoanID", mock.Anything, loan.LoanID).Return(loan, nil)
+
+				// Act
+				err := operation.call(service, loan.LoanID)
+
+				// Assert
+				assert.ErrorIs(t, err, tt.expectedError)
+				mockLoanRepo.AssertExpectations(t)
+				// Nothing is written for a frozen loan
+				mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				mockPaymentRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				mockLoanRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			})
+		}
+	}
+}
+
+func TestGetOutstanding_Cache(t *testing.T) {
+	cfg := &config.Config{Redis: config.RedisConfig{CacheTTL: 5 * time.Minute}}
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+		WeeklyPayment: decimal.NewFromInt(110000),
+		Status:        domain.LoanStatusActive,
+	}
+	key := cache.OutstandingKey("LOAN123")
+
+	setup := func(t *testing.T) (*miniredis.Miniredis, *mocks.MockLoanRepository, *mocks.MockPaymentRepository, billingService.BillingService) {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		mockLoanRepo := &mocks.MockLoanRepository{}
+		mockPaymentRepo := &mocks.MockPaymentRepository{}
+		service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), client, cfg)
+		return mr, mockLoanRepo, mockPaymentRepo, service
+	}
+
+	t.Run("Miss computes and caches with the TTL", func(t *testing.T) {
+		mr, mockLoanRepo, mockPaymentRepo, service := setup(t)
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil).Once()
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return([]*domain.Payment{}, nil).Once()
+
+		outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, outstanding.Equal(decimal.NewFromInt(5500000)))
+
+		cached, err := mr.Get(key)
+		require.NoError(t, err)
+		assert.Equal(t, "5500000", cached)
+		assert.Equal(t, 5*time.Minute, mr.TTL(key))
+
+		// The second read is served from the cache
+		outstanding, err = service.GetOutstanding(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, outstanding.Equal(decimal.NewFromInt(5500000)))
+		mockLoanRepo.AssertExpectations(t)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Hit skips the database", func(t *testing.T) {
+		mr, mockLoanRepo, mockPaymentRepo, service := setup(t)
+		require.NoError(t, mr.Set(key, "5280000"))
+
+		outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, outstanding.Equal(decimal.NewFromInt(5280000)))
+		mockLoanRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+		mockPaymentRepo.AssertNotCalled(t, "GetByLoanID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Payment invalidates the cached balance", func(t *testing.T) {
+		mr, mockLoanRepo, mockPaymentRepo, service := setup(t)
+		require.NoError(t, mr.Set(key, "5500000"))
+		require.NoError(t, mr.Set(cache.OutstandingBreakdownKey("LOAN123"), "{}"))
+
+		week := &domain.LoanSchedule{LoanID: "LOAN123", WeekNumber: 1, Status: domain.ScheduleStatusPending, DueAmount: decimal.NewFromInt(110000)}
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+		mockLoanRepo.On("GetEarliestUnpaidWeek", mock.Anything, "LOAN123").Return(week, nil)
+		mockPaymentRepo.On("GetTotalPaidForWeek", mock.Anything, "LOAN123", 1).Return(decimal.Zero, nil)
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return([]*domain.LoanSchedule{week, {LoanID: "LOAN123", WeekNumber: 2, Status: domain.ScheduleStatusPending}}, nil)
+		mockPaymentRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		mockLoanRepo.On("UpdateScheduleStatuses", mock.Anything, "LOAN123", []int{1}, domain.ScheduleStatusPaid).Return(nil)
+
+		_, err := service.MakePayment(context.Background(), domain.MakePaymentRequest{LoanID: "LOAN123", Amount: decimal.NewFromInt(110000)})
+		require.NoError(t, err)
+
+		assert.False(t, mr.Exists(key))
+		assert.False(t, mr.Exists(cache.OutstandingBreakdownKey("LOAN123")))
+
+		// The next read recomputes from the database
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").
+			Return([]*domain.Payment{{LoanID: "LOAN123", Amount: decimal.NewFromInt(110000)}}, nil).Once()
+		outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, outstanding.Equal(decimal.NewFromInt(5390000)))
+	})
+
+	t.Run("Cache outage falls back to the database", func(t *testing.T) {
+		mr, mockLoanRepo, mockPaymentRepo, service := setup(t)
+		mr.Close()
+
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil).Once()
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return([]*domain.Payment{}, nil).Once()
+
+		outstanding, err := service.GetOutstanding(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, outstanding.Equal(decimal.NewFromInt(5500000)))
+		mockLoanRepo.AssertExpectations(t)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Breakdown is cached alongside the balance", func(t *testing.T) {
+		mr, mockLoanRepo, mockPaymentRepo, service := setup(t)
+		mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil).Once()
+		mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return([]*domain.LoanSchedule{}, nil).Once()
+		mockPaymentRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return([]*domain.Payment{}, nil).Once()
+
+		breakdown, err := service.GetOutstandingBreakdown(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, breakdown.TotalOutstanding.Equal(decimal.NewFromInt(5500000)))
+		assert.Equal(t, 5*time.Minute, mr.TTL(cache.OutstandingBreakdownKey("LOAN123")))
+
+		// The second read is served from the cache
+		cached, err := service.GetOutstandingBreakdown(context.Background(), "LOAN123")
+		require.NoError(t, err)
+		assert.True(t, cached.PrincipalOutstanding.Equal(decimal.NewFromInt(5000000)))
+		assert.True(t, cached.InterestOutstanding.Equal(decimal.NewFromInt(500000)))
+		mockLoanRepo.AssertExpectations(t)
+		mockPaymentRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetCostOfCredit(t *testing.T) {
+	loan := &domain.Loan{
+		LoanID:        "LOAN123",
+		Amount:        decimal.NewFromInt(5000000),
+		InterestRate:  decimal.NewFromFloat(0.10),
+		DurationWeeks: 50,
+	}
+	standardSchedule := make([]*domain.LoanSchedule, 50)
+	for i := range standardSchedule {
+		standardSchedule[i] = &domain.LoanSchedule{LoanID: "LOAN123", WeekNumber: i + 1, DueAmount: decimal.NewFromInt(110000)}
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*mocks.MockLoanRepository)
+		expected      *domain.CostOfCredit
+		expectedError error
+		errorContains string
+	}{
+		{
+			name: "Success - Standard 50 week loan at 10%",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(standardSchedule, nil)
+			},
+			expected: &domain.CostOfCredit{
+				Principal:      decimal.NewFromInt(5000000),
+				TotalRepayable: decimal.NewFromInt(5500000),
+				TotalInterest:  decimal.NewFromInt(500000),
+				TotalFees:      decimal.Zero,
+				CostPercent:    decimal.NewFromInt(10),
+			},
+		},
+		{
+			name: "Success - Loan without schedule rows uses its terms",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return([]*domain.LoanSchedule{}, nil)
+			},
+			expected: &domain.CostOfCredit{
+				Principal:      decimal.NewFromInt(5000000),
+				TotalRepayable: decimal.NewFromInt(5500000),
+				TotalInterest:  decimal.NewFromInt(500000),
+				TotalFees:      decimal.Zero,
+				CostPercent:    decimal.NewFromInt(10),
+			},
+		},
+		{
+			name: "Failure - Loan not found",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(nil, sql.ErrNoRows)
+			},
+			expectedError: customError.ErrLoanNotFound,
+		},
+		{
+			name: "Failure - Schedule lookup fails",
+			setupMocks: func(mockLoanRepo *mocks.MockLoanRepository) {
+				mockLoanRepo.On("GetByLoanID", mock.Anything, "LOAN123").Return(loan, nil)
+				mockLoanRepo.On("GetScheduleByLoanID", mock.Anything, "LOAN123").Return(nil, errors.New("connection reset"))
+			},
+			errorContains: "database",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockLoanRepo := &mocks.MockLoanRepository{}
+			mockPaymentRepo := &mocks.MockPaymentRepository{}
+
+			service := billingService.NewBillingService(mockLoanRepo, mockPaymentRepo, mocks.NewMockUnitOfWork(mockLoanRepo, mockPaymentRepo), nil, nil)
+			tt.setupMocks(mockLoanRepo)
+
+			// Act
+			cost, err := service.GetCostOfCredit(context.Background(), "LOAN123")
+
+			// Assert
+			switch {
+			case tt.expectedError != nil:
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, cost)
+			case tt.errorContains != "":
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Nil(t, cost)
+			default:
+				require.NoError(t, err)
+				assert.True(t, tt.expected.Principal.Equal(cost.Principal))
+				assert.True(t, tt.expected.TotalRepayable.Equal(cost.TotalRepayable), "total repayable %s", cost.TotalRepayable)
+				assert.True(t, tt.expected.TotalInterest.Equal(cost.TotalInterest), "total interest %s", cost.TotalInterest)
+				assert.True(t, tt.expected.TotalFees.Equal(cost.TotalFees))
+				assert.True(t, tt.expected.CostPercent.Equal(cost.CostPercent), "cost percent %s", cost.CostPercent)
+			}
+			mockLoanRepo.AssertExpectations(t)
+		})
+	}
+}
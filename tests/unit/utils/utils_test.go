@@ -15,6 +15,7 @@ func TestCalculateWeeklyPayment(t *testing.T) {
 		principal decimal.Decimal
 		rate      decimal.Decimal
 		weeks     int
+		places    int32
 		expected  decimal.Decimal
 	}{
 		{
@@ -22,6 +23,7 @@ func TestCalculateWeeklyPayment(t *testing.T) {
 			principal: decimal.NewFromInt(5000000),
 			rate:      decimal.NewFromFloat(0.10),
 			weeks:     50,
+			places:    2,
 			expected:  decimal.NewFromInt(110000), // (5,000,000 * 1.10) / 50 = 110,000
 		},
 		{
@@ -29,6 +31,7 @@ func TestCalculateWeeklyPayment(t *testing.T) {
 			principal: decimal.NewFromInt(1000000),
 			rate:      decimal.NewFromFloat(0.10),
 			weeks:     10,
+			places:    2,
 			expected:  decimal.NewFromInt(110000), // (1,000,000 * 1.10) / 10 = 110,000
 		},
 		{
@@ -36,14 +39,75 @@ func TestCalculateWeeklyPayment(t *testing.T) {
 			principal: decimal.NewFromInt(5000000),
 			rate:      decimal.NewFromInt(0),
 			weeks:     50,
+			places:    2,
 			expected:  decimal.NewFromInt(100000), // 5,000,000 / 50 = 100,000
 		},
+		{
+			name:      "uneven split rounded to 2 places",
+			principal: decimal.NewFromInt(1000000),
+			rate:      decimal.NewFromFloat(0.10),
+			weeks:     7,
+			places:    2,
+			expected:  decimal.RequireFromString("157142.86"), // 1,100,000 / 7 = 157,142.857142...
+		},
+		{
+			name:      "uneven split keeps 4 places",
+			principal: decimal.NewFromInt(1000000),
+			rate:      decimal.NewFromFloat(0.10),
+			weeks:     7,
+			places:    4,
+			expected:  decimal.RequireFromString("157142.8571"),
+		},
+		{
+			name:      "uneven split rounded to whole units",
+			principal: decimal.NewFromInt(1000000),
+			rate:      decimal.NewFromFloat(0.10),
+			weeks:     7,
+			places:    0,
+			expected:  decimal.NewFromInt(157143),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// This test will fail initially (RED) - implement the function to make it pass (GREEN)
-			result := utils2.CalculateWeeklyPayment(tt.principal, tt.rate, tt.weeks)
+			result := utils2.CalculateWeeklyPayment(tt.principal, tt.rate, tt.weeks, tt.places)
+			assert.True(t, result.Equal(tt.expected),
+				"Expected %v, but got %v", tt.expected, result)
+		})
+	}
+}
+
+func TestTermInterestRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		annualRate decimal.Decimal
+		weeks      int
+		expected   decimal.Decimal
+	}{
+		{
+			name:       "one year term keeps the annual rate",
+			annualRate: decimal.NewFromFloat(0.10),
+			weeks:      52,
+			expected:   decimal.NewFromFloat(0.10),
+		},
+		{
+			name:       "half year term halves the rate",
+			annualRate: decimal.NewFromFloat(0.10),
+			weeks:      26,
+			expected:   decimal.NewFromFloat(0.05),
+		},
+		{
+			name:       "zero rate",
+			annualRate: decimal.Zero,
+			weeks:      25,
+			expected:   decimal.Zero,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := utils2.TermInterestRate(tt.annualRate, tt.weeks)
 			assert.True(t, result.Equal(tt.expected),
 				"Expected %v, but got %v", tt.expected, result)
 		})
@@ -129,3 +193,175 @@ func TestGetCurrentWeek(t *testing.T) {
 		})
 	}
 }
+
+func TestIsDateOverdue(t *testing.T) {
+	dueDate := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	grace := 2 * 24 * time.Hour
+	// The boundary day is the due date plus grace
+	boundary := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		inclusive bool
+		expected  bool
+	}{
+		{name: "exclusive, the second before the boundary", now: boundary.Add(-time.Second), expected: false},
+		{name: "exclusive, the boundary second", now: boundary, expected: false},
+		{name: "exclusive, the last second of the boundary day", now: boundary.Add(24*time.Hour - time.Second), expected: false},
+		{name: "exclusive, the day after the boundary", now: boundary.AddDate(0, 0, 1), expected: true},
+		{name: "inclusive, the second before the boundary", now: boundary.Add(-time.Second), inclusive: true, expected: false},
+		{name: "inclusive, the boundary second", now: boundary, inclusive: true, expected: true},
+		{name: "inclusive, the day after the boundary", now: boundary.AddDate(0, 0, 1), inclusive: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils2.IsDateOverdue(dueDate, tt.now, grace, tt.inclusive))
+		})
+	}
+}
+
+func TestOverdueCutoff(t *testing.T) {
+	now := time.Date(2024, 1, 12, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), utils2.OverdueCutoff(now, 0, false))
+	assert.Equal(t, time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC), utils2.OverdueCutoff(now, 0, true))
+	assert.Equal(t, time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), utils2.OverdueCutoff(now, 3*24*time.Hour, false))
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), utils2.OverdueCutoff(now, 3*24*time.Hour, true))
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	holidays := map[string]bool{"2024-01-08": true}
+
+	tests := []struct {
+		name     string
+		day      time.Time
+		expected bool
+	}{
+		{
+			name:     "friday",
+			day:      time.Date(2024, 1, 5, 15, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			name:     "saturday",
+			day:      time.Date(2024, 1, 6, 15, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "sunday",
+			day:      time.Date(2024, 1, 7, 15, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			name:     "holiday on a monday",
+			day:      time.Date(2024, 1, 8, 15, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils2.IsBusinessDay(tt.day, holidays))
+		})
+	}
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	saturday := time.Date(2024, 1, 6, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), utils2.NextBusinessDay(saturday, nil))
+	// A Monday holiday pushes the weekend on to Tuesday
+	assert.Equal(t, time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC),
+		utils2.NextBusinessDay(saturday, map[string]bool{"2024-01-08": true}))
+}
+
+func TestBuildInstallments(t *testing.T) {
+	tests := []struct {
+		name              string
+		principal         decimal.Decimal
+		rate              decimal.Decimal
+		weeks             int
+		interestOnlyWeeks int
+		expectedFirst     utils2.Installment
+		expectedLast      utils2.Installment
+		expectedTotal     decimal.Decimal
+	}{
+		{
+			name:              "no interest-only period keeps the flat weekly payment",
+			principal:         decimal.NewFromInt(5000000),
+			rate:              decimal.NewFromFloat(0.10),
+			weeks:             50,
+			interestOnlyWeeks: 0,
+			expectedFirst:     utils2.Installment{Principal: decimal.NewFromInt(100000), Interest: decimal.NewFromInt(10000), Total: decimal.NewFromInt(110000)},
+			expectedLast:      utils2.Installment{Principal: decimal.NewFromInt(100000), Interest: decimal.NewFromInt(10000), Total: decimal.NewFromInt(110000)},
+			expectedTotal:     decimal.NewFromInt(5500000),
+		},
+		{
+			name:              "interest-only weeks then amortizing principal",
+			principal:         decimal.NewFromInt(5000000),
+			rate:              decimal.NewFromFloat(0.10),
+			weeks:             50,
+			interestOnlyWeeks: 10,
+			// 500,000 interest / 50 = 10,000 a week; 5,000,000 principal / 40 = 125,000 a week after week 10
+			expectedFirst: utils2.Installment{Principal: decimal.Zero, Interest: decimal.NewFromInt(10000), Total: decimal.NewFromInt(10000)},
+			expectedLast:  utils2.Installment{Principal: decimal.NewFromInt(125000), Interest: decimal.NewFromInt(10000), Total: decimal.NewFromInt(135000)},
+			expectedTotal: decimal.NewFromInt(5500000),
+		},
+		{
+			name:              "zero interest with interest-only period pays nothing up front",
+			principal:         decimal.NewFromInt(1000000),
+			rate:              decimal.Zero,
+			weeks:             10,
+			interestOnlyWeeks: 2,
+			expectedFirst:     utils2.Installment{Principal: decimal.Zero, Interest: decimal.Zero, Total: decimal.Zero},
+			expectedLast:      utils2.Installment{Principal: decimal.NewFromInt(125000), Interest: decimal.Zero, Total: decimal.NewFromInt(125000)},
+			expectedTotal:     decimal.NewFromInt(1000000),
+		},
+		{
+			name:              "final week absorbs the rounding remainder",
+			principal:         decimal.NewFromInt(1000000),
+			rate:              decimal.NewFromFloat(0.10),
+			weeks:             7,
+			interestOnlyWeeks: 0,
+			// 1,100,000 / 7 = 157,142.857...: six weeks of 157,142.86 leave 157,142.84 for the last
+			expectedFirst: utils2.Installment{Principal: decimal.RequireFromString("142857.15"), Interest: decimal.RequireFromString("14285.71"), Total: decimal.RequireFromString("157142.86")},
+			expectedLast:  utils2.Installment{Principal: decimal.RequireFromString("142857.10"), Interest: decimal.RequireFromString("14285.74"), Total: decimal.RequireFromString("157142.84")},
+			expectedTotal: decimal.NewFromInt(1100000),
+		},
+		{
+			name:              "final week absorbs the remainder after an interest-only period",
+			principal:         decimal.NewFromInt(1000000),
+			rate:              decimal.NewFromFloat(0.10),
+			weeks:             10,
+			interestOnlyWeeks: 3,
+			// 1,000,000 / 7 = 142,857.142...: six weeks of 142,857.14 leave 142,857.16 for the last
+			expectedFirst: utils2.Installment{Principal: decimal.Zero, Interest: decimal.NewFromInt(10000), Total: decimal.NewFromInt(10000)},
+			expectedLast:  utils2.Installment{Principal: decimal.RequireFromString("142857.16"), Interest: decimal.NewFromInt(10000), Total: decimal.RequireFromString("152857.16")},
+			expectedTotal: decimal.NewFromInt(1100000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installments := utils2.BuildInstallments(tt.principal, tt.rate, tt.weeks, tt.interestOnlyWeeks, 2)
+			assert.Len(t, installments, tt.weeks)
+
+			first, last := installments[0], installments[len(installments)-1]
+			assert.True(t, first.Principal.Equal(tt.expectedFirst.Principal), "first principal: expected %v, got %v", tt.expectedFirst.Principal, first.Principal)
+			assert.True(t, first.Interest.Equal(tt.expectedFirst.Interest), "first interest: expected %v, got %v", tt.expectedFirst.Interest, first.Interest)
+			assert.True(t, first.Total.Equal(tt.expectedFirst.Total), "first total: expected %v, got %v", tt.expectedFirst.Total, first.Total)
+			assert.True(t, last.Principal.Equal(tt.expectedLast.Principal), "last principal: expected %v, got %v", tt.expectedLast.Principal, last.Principal)
+			assert.True(t, last.Interest.Equal(tt.expectedLast.Interest), "last interest: expected %v, got %v", tt.expectedLast.Interest, last.Interest)
+			assert.True(t, last.Total.Equal(tt.expectedLast.Total), "last total: expected %v, got %v", tt.expectedLast.Total, last.Total)
+
+			total := decimal.Zero
+			for _, installment := range installments {
+				assert.True(t, installment.Total.Equal(installment.Principal.Add(installment.Interest)))
+				total = total.Add(installment.Total)
+			}
+			assert.True(t, total.Equal(tt.expectedTotal), "total repayable: expected %v, got %v", tt.expectedTotal, total)
+		})
+	}
+}
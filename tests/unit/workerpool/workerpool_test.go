@@ -0,0 +1,84 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segyhp/billing-engine/pkg/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_ConcurrencyNeverExceedsLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		items int
+	}{
+		{name: "limit lower than items", limit: 3, items: 20},
+		{name: "limit equal to items", limit: 5, items: 5},
+		{name: "limit higher than items", limit: 10, items: 4},
+		{name: "zero limit falls back to one", limit: 0, items: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current, peak, processed int32
+
+			err := workerpool.Run(context.Background(), tt.limit, tt.items, func(ctx context.Context, i int) error {
+				n := atomic.AddInt32(&current, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&current, -1)
+				atomic.AddInt32(&processed, 1)
+				return nil
+			})
+
+			expectedLimit := tt.limit
+			if expectedLimit < 1 {
+				expectedLimit = 1
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, int32(tt.items), processed)
+			assert.LessOrEqual(t, int(peak), expectedLimit)
+		})
+	}
+}
+
+func TestRun_CollectsErrorsAndProcessesAllItems(t *testing.T) {
+	var processed int32
+	errOdd := errors.New("odd item failed")
+
+	err := workerpool.Run(context.Background(), 2, 10, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&processed, 1)
+		if i%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errOdd)
+	assert.Equal(t, int32(10), processed)
+}
+
+func TestRun_StopsDispatchingWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var processed int32
+	err := workerpool.Run(ctx, 2, 10, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), processed)
+}